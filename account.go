@@ -7,18 +7,20 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/julez-dev/chatuino/save"
-	"github.com/julez-dev/chatuino/server"
+	"github.com/julez-dev/chatuino/twitch/twitchapi"
 	"github.com/julez-dev/chatuino/ui/accountui"
 	"github.com/rs/zerolog/log"
-	"github.com/spf13/afero"
 	"github.com/urfave/cli/v3"
-	"github.com/zalando/go-keyring"
 )
 
+type userFetcher interface {
+	GetUsers(ctx context.Context, logins []string, ids []string) (twitchapi.UserResponse, error)
+}
+
 // migrateAccountLoginNames backfills LoginName for accounts created before
 // it was stored. Fetches user data by ID from the Twitch API and persists
 // the login name.
-func migrateAccountLoginNames(ctx context.Context, accounts []save.Account, provider save.AccountProvider, api *server.Client) error {
+func migrateAccountLoginNames(ctx context.Context, accounts []save.Account, provider save.AccountProvider, api userFetcher) error {
 	var needMigration []save.Account
 	for _, acc := range accounts {
 		if acc.IsAnonymous || acc.LoginName != "" {
@@ -70,6 +72,11 @@ var accountCMD = &cli.Command{
 	Name:        "account",
 	Description: "Chatuino account management",
 	Usage:       "Manage accounts used by Chatuino",
+	Commands: []*cli.Command{
+		accountMigrateCMD,
+		accountExportCMD,
+		accountImportCMD,
+	},
 	Flags: []cli.Flag{
 		&cli.StringFlag{
 			Name:  "api-host",
@@ -93,12 +100,9 @@ var accountCMD = &cli.Command{
 			return fmt.Errorf("failed to read theme file: %w", err)
 		}
 
-		var keyringBackend keyring.Keyring
-
-		if command.Bool("plain-auth-storage") {
-			keyringBackend = save.NewPlainKeyringFallback(afero.NewOsFs())
-		} else {
-			keyringBackend = save.NewKeyringWrapper()
+		keyringBackend, err := newAuthStorage(command)
+		if err != nil {
+			return err
 		}
 
 		p := tea.NewProgram(
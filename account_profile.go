@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/julez-dev/chatuino/save"
+	"github.com/spf13/afero"
+	"github.com/urfave/cli/v3"
+)
+
+const profilePassphraseEnv = "CHATUINO_PROFILE_PASSPHRASE"
+
+var accountExportCMD = &cli.Command{
+	Name:  "export",
+	Usage: "Export accounts, settings, theme, keymap and channel history into a profile file",
+	Description: "Bundles the accounts, settings.yaml, theme.yaml, keymap.yaml and the channel history into a single file to set up Chatuino on another machine. " +
+		"Profiles including tokens are encrypted with a passphrase, read from --passphrase-fd, " + profilePassphraseEnv + " or prompted.",
+	UsageText: "chatuino account export [--without-tokens] <file>",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "without-tokens",
+			Usage: "Export the accounts without their tokens, they need to be authorized again after the import",
+		},
+		&cli.BoolFlag{
+			Name:  "encrypt",
+			Usage: "Encrypt a profile without tokens as well",
+		},
+		&cli.IntFlag{
+			Name:  "passphrase-fd",
+			Usage: "File descriptor to read the passphrase of the profile from",
+			Value: -1,
+		},
+	},
+	Action: func(_ context.Context, command *cli.Command) error {
+		path := command.Args().First()
+		if path == "" {
+			return errors.New("missing file to export to")
+		}
+
+		keyringBackend, err := newAuthStorage(command)
+		if err != nil {
+			return err
+		}
+
+		fs := afero.NewOsFs()
+		includeTokens := !command.Bool("without-tokens")
+
+		profile, err := save.ExportProfile(fs, save.NewAccountProvider(keyringBackend), save.NewChannelHistoryManager(fs), includeTokens)
+		if err != nil {
+			return err
+		}
+
+		var passphrase string
+		if includeTokens || command.Bool("encrypt") {
+			passphrase, err = readPassphrase(int(command.Int("passphrase-fd")), profilePassphraseEnv, "profile", true)
+			if err != nil {
+				return err
+			}
+
+			if passphrase == "" {
+				return save.ErrEmptyPassphrase
+			}
+		}
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o600)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		if err := save.WriteProfile(f, profile, passphrase); err != nil {
+			return err
+		}
+
+		fmt.Printf("Exported %d accounts, %d config files and %d channels to %s\n", len(profile.Accounts), len(profile.ConfigFiles), len(profile.ChannelHistory), path)
+
+		return nil
+	},
+}
+
+var accountImportCMD = &cli.Command{
+	Name:  "import",
+	Usage: "Import a profile file created with chatuino account export",
+	Description: "Imports accounts, config files and the channel history of a profile. --conflict decides what happens with data existing on this machine: " +
+		"keep leaves it untouched, overwrite replaces it, merge combines both and keeps local values where both are set.",
+	UsageText: "chatuino account import [--conflict keep|overwrite|merge] <file>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "conflict",
+			Usage: "How to resolve data existing locally and in the profile: keep, overwrite or merge",
+			Value: string(save.ConflictKeep),
+		},
+		&cli.IntFlag{
+			Name:  "passphrase-fd",
+			Usage: "File descriptor to read the passphrase of an encrypted profile from",
+			Value: -1,
+		},
+	},
+	Action: func(_ context.Context, command *cli.Command) error {
+		path := command.Args().First()
+		if path == "" {
+			return errors.New("missing profile file to import")
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		profile, err := save.ReadProfile(f, func() (string, error) {
+			return readPassphrase(int(command.Int("passphrase-fd")), profilePassphraseEnv, "profile", false)
+		})
+		if err != nil {
+			return err
+		}
+
+		keyringBackend, err := newAuthStorage(command)
+		if err != nil {
+			return err
+		}
+
+		fs := afero.NewOsFs()
+
+		result, err := save.ImportProfile(fs, save.NewAccountProvider(keyringBackend), save.NewChannelHistoryManager(fs), profile, save.ConflictStrategy(command.String("conflict")))
+		if err != nil {
+			return err
+		}
+
+		printList := func(label string, values []string) {
+			if len(values) > 0 {
+				fmt.Printf("%s: %s\n", label, strings.Join(values, ", "))
+			}
+		}
+
+		printList("Added accounts", result.AccountsAdded)
+		printList("Updated accounts", result.AccountsUpdated)
+		printList("Kept accounts", result.AccountsKept)
+		printList("Written config files", result.ConfigFilesWritten)
+		printList("Kept config files", result.ConfigFilesKept)
+		fmt.Printf("Channel history: %d channels\n", result.Channels)
+
+		if len(result.AccountsWithoutTokens) > 0 {
+			fmt.Printf("\n%s were imported without tokens, authorize them again in chatuino account\n", strings.Join(result.AccountsWithoutTokens, ", "))
+		}
+
+		return nil
+	},
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/julez-dev/chatuino/save"
+	"github.com/spf13/afero"
+	"github.com/urfave/cli/v3"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
+)
+
+// auth storage backends, used by the migrate command
+const (
+	authStorageKeyring   = "keyring"
+	authStoragePlain     = "plain"
+	authStorageEncrypted = "encrypted"
+)
+
+const authPassphraseEnv = "CHATUINO_AUTH_PASSPHRASE"
+
+var accountMigrateCMD = &cli.Command{
+	Name:  "migrate",
+	Usage: "Move the accounts to another auth storage",
+	Description: "Moves the saved accounts between the system keyring (keyring), the plain text file (plain) and the encrypted file (encrypted). " +
+		"The accounts are removed from the old storage afterwards.",
+	UsageText: "chatuino account migrate --from plain --to encrypted",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "from",
+			Usage:    "Storage the accounts are currently saved in: keyring, plain or encrypted",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:     "to",
+			Usage:    "Storage the accounts should be saved in: keyring, plain or encrypted",
+			Required: true,
+		},
+	},
+	Action: func(_ context.Context, command *cli.Command) error {
+		from, to := command.String("from"), command.String("to")
+		if from == to {
+			return fmt.Errorf("the accounts are already saved in %s", from)
+		}
+
+		source, err := openAuthStorage(command, from)
+		if err != nil {
+			return err
+		}
+
+		target, err := openAuthStorage(command, to)
+		if err != nil {
+			return err
+		}
+
+		migrated, err := save.MigrateAccounts(source, target)
+		if err != nil {
+			return err
+		}
+
+		if !migrated {
+			fmt.Printf("No accounts saved in %s, nothing to migrate\n", from)
+			return nil
+		}
+
+		fmt.Printf("Moved accounts from %s to %s\n", from, to)
+
+		switch to {
+		case authStoragePlain:
+			fmt.Println("Start Chatuino with --plain-auth-storage to use them")
+		case authStorageEncrypted:
+			fmt.Println("Start Chatuino with --encrypted-auth-storage to use them")
+		}
+
+		return nil
+	},
+}
+
+// newAuthStorage returns the keyring backend selected by the global flags.
+func newAuthStorage(command *cli.Command) (keyring.Keyring, error) {
+	plain, encrypted := command.Bool("plain-auth-storage"), command.Bool("encrypted-auth-storage")
+
+	switch {
+	case plain && encrypted:
+		return nil, errors.New("--plain-auth-storage and --encrypted-auth-storage can not be used together")
+	case plain:
+		return openAuthStorage(command, authStoragePlain)
+	case encrypted:
+		return openAuthStorage(command, authStorageEncrypted)
+	default:
+		return openAuthStorage(command, authStorageKeyring)
+	}
+}
+
+func openAuthStorage(command *cli.Command, storage string) (keyring.Keyring, error) {
+	switch storage {
+	case authStorageKeyring:
+		return save.NewKeyringWrapper(), nil
+	case authStoragePlain:
+		return save.NewPlainKeyringFallback(afero.NewOsFs()), nil
+	case authStorageEncrypted:
+		fs := afero.NewOsFs()
+
+		exists, err := save.HasEncryptedAccounts(fs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open encrypted account file: %w", err)
+		}
+
+		passphrase, err := readPassphrase(int(command.Int("auth-passphrase-fd")), authPassphraseEnv, "encrypted account storage", !exists)
+		if err != nil {
+			return nil, err
+		}
+
+		return save.NewEncryptedKeyring(fs, passphrase)
+	default:
+		return nil, fmt.Errorf("unknown auth storage %q, use keyring, plain or encrypted", storage)
+	}
+}
+
+// readPassphrase reads a passphrase from the file descriptor, the environment variable env or prompts for it.
+// A prompted passphrase for a new file has to be entered twice.
+func readPassphrase(fd int, env, purpose string, confirm bool) (string, error) {
+	if fd >= 0 {
+		f := os.NewFile(uintptr(fd), "passphrase")
+		if f == nil {
+			return "", fmt.Errorf("invalid passphrase file descriptor %d", fd)
+		}
+
+		defer f.Close()
+
+		return readPassphraseLine(f)
+	}
+
+	if passphrase, ok := os.LookupEnv(env); ok {
+		return passphrase, nil
+	}
+
+	stdin := int(os.Stdin.Fd())
+	if !term.IsTerminal(stdin) {
+		return "", fmt.Errorf("no passphrase for the %s, set %s or pass a file descriptor", purpose, env)
+	}
+
+	passphrase, err := promptPassphrase(stdin, "Passphrase for the "+purpose+": ")
+	if err != nil {
+		return "", err
+	}
+
+	if !confirm {
+		return passphrase, nil
+	}
+
+	repeated, err := promptPassphrase(stdin, "Repeat the passphrase: ")
+	if err != nil {
+		return "", err
+	}
+
+	if passphrase != repeated {
+		return "", errors.New("the passphrases do not match")
+	}
+
+	return passphrase, nil
+}
+
+func promptPassphrase(fd int, prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	defer fmt.Fprintln(os.Stderr)
+
+	b, err := term.ReadPassword(fd)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+
+	return string(b), nil
+}
+
+// readPassphraseLine returns the first line of r without the line break.
+func readPassphraseLine(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadPassphraseLine(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]string{
+		"secret\n":         "secret",
+		"secret\r\n":       "secret",
+		"secret":           "secret",
+		"with space\nnext": "with space",
+		"":                 "",
+	} {
+		got, err := readPassphraseLine(strings.NewReader(input))
+		require.NoError(t, err)
+		require.Equal(t, want, got)
+	}
+}
@@ -11,6 +11,8 @@ import (
 	"charm.land/lipgloss/v2"
 	"github.com/dustin/go-humanize"
 	"github.com/julez-dev/chatuino/kittyimg"
+	"github.com/julez-dev/chatuino/save"
+	"github.com/spf13/afero"
 	"github.com/urfave/cli/v3"
 )
 
@@ -72,11 +74,15 @@ var cacheCMD = &cli.Command{
 			},
 			Action: func(ctx context.Context, c *cli.Command) error {
 				checkmark := cacheSuccessStyle.Render("✓")
+				index := newImageCacheIndex(0)
 
 				if c.Bool("emotes") {
 					if err := os.RemoveAll(filepath.Join(kittyimg.BaseImageDirectory, "emote")); err != nil && !errors.Is(err, os.ErrNotExist) {
 						return fmt.Errorf("failed to delete emote cache: %w", err)
 					}
+					if err := index.RemoveDirectory("emote"); err != nil {
+						return fmt.Errorf("failed to update image cache index: %w", err)
+					}
 					fmt.Println(checkmark + " " + cacheEmoteStyle.Render("Emote cache") + cacheTextStyle.Render(" deleted"))
 				}
 
@@ -84,6 +90,9 @@ var cacheCMD = &cli.Command{
 					if err := os.RemoveAll(filepath.Join(kittyimg.BaseImageDirectory, "badge")); err != nil && !errors.Is(err, os.ErrNotExist) {
 						return fmt.Errorf("failed to delete badge cache: %w", err)
 					}
+					if err := index.RemoveDirectory("badge"); err != nil {
+						return fmt.Errorf("failed to update image cache index: %w", err)
+					}
 					fmt.Println(checkmark + " " + cacheBadgeStyle.Render("Badge cache") + cacheTextStyle.Render(" deleted"))
 				}
 
@@ -128,6 +137,16 @@ var cacheCMD = &cli.Command{
 			Frames:    badgeFrames,
 		}
 
+		settings, err := save.SettingsFromDisk()
+		if err != nil {
+			return fmt.Errorf("failed to read settings file: %w", err)
+		}
+
+		indexStats, err := newImageCacheIndex(settings.Chat.ImageCacheBudgetBytes()).Stats()
+		if err != nil {
+			return fmt.Errorf("failed to read image cache index: %w", err)
+		}
+
 		// Collect message stats from database
 		rows, err := db.QueryContext(ctx, "SELECT broadcast_channel, COUNT(*) as count FROM messages GROUP BY broadcast_channel ORDER BY count DESC")
 		if err != nil {
@@ -147,7 +166,7 @@ var cacheCMD = &cli.Command{
 		}
 
 		// Render and print the styled output
-		fmt.Println(renderCacheOutput(emoteStats, badgeStats, channels, totalMessages))
+		fmt.Println(renderCacheOutput(emoteStats, badgeStats, indexStats, channels, totalMessages))
 
 		return nil
 	},
@@ -215,7 +234,7 @@ func renderBar(count, maxCount int64) string {
 
 // Main render function
 
-func renderCacheOutput(emote, badge imageStats, channels []channelMessageCount, totalMsgs int64) string {
+func renderCacheOutput(emote, badge imageStats, index kittyimg.CacheStats, channels []channelMessageCount, totalMsgs int64) string {
 	var b strings.Builder
 
 	// Top section: Cache Statistics
@@ -255,6 +274,35 @@ func renderCacheOutput(emote, badge imageStats, channels []channelMessageCount,
 	b.WriteString(cacheEmptyRow())
 	b.WriteString("\n")
 
+	// Image cache budget and usage
+	budget := "unlimited"
+	if index.BudgetBytes > 0 {
+		budget = humanize.Bytes(uint64(index.BudgetBytes))
+	}
+	budgetRow := cacheHeaderStyle.Render("Budget: ") + cacheTextStyle.Render(fmt.Sprintf("%s of %s used", humanize.Bytes(uint64(index.SizeBytes)), budget))
+	b.WriteString(cacheRow(budgetRow))
+	b.WriteString("\n")
+
+	var hitRate float64
+	if lookups := index.Hits + index.Misses; lookups > 0 {
+		hitRate = float64(index.Hits) / float64(lookups) * 100
+	}
+	hitRow := cacheHeaderStyle.Render("Hits: ") +
+		cacheTextStyle.Render(fmt.Sprintf("%s hits, %s misses ", humanize.Comma(index.Hits), humanize.Comma(index.Misses))) +
+		cacheDimmedStyle.Render(fmt.Sprintf("(%5.1f%%)", hitRate))
+	b.WriteString(cacheRow(hitRow))
+	b.WriteString("\n")
+
+	evictionRow := cacheHeaderStyle.Render("Evicted: ") + cacheTextStyle.Render(humanize.Comma(index.Evictions)+" images")
+	if !index.OldestAccessed.IsZero() {
+		evictionRow += cacheDimmedStyle.Render(", oldest used " + humanize.Time(index.OldestAccessed))
+	}
+	b.WriteString(cacheRow(evictionRow))
+	b.WriteString("\n")
+
+	b.WriteString(cacheEmptyRow())
+	b.WriteString("\n")
+
 	// Middle section: Messages by Channel
 	b.WriteString(cacheMiddleBorder("Messages by Channel"))
 	b.WriteString("\n")
@@ -323,6 +371,11 @@ func renderCacheOutput(emote, badge imageStats, channels []channelMessageCount,
 	return b.String()
 }
 
+// newImageCacheIndex opens the image cache index shared by all Chatuino instances.
+func newImageCacheIndex(budgetBytes int64) *kittyimg.CacheIndex {
+	return kittyimg.NewCacheIndex(afero.NewOsFs(), kittyimg.BaseImageDirectory, kittyimg.NewFileLock(kittyimg.BaseImageDirectory), budgetBytes)
+}
+
 func statsForImageDirectory(path string) (int64, int, int, error) {
 	entries, err := os.ReadDir(path)
 	if err != nil {
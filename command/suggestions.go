@@ -31,4 +31,9 @@ var CommandSuggestions = [...]string{
 	"/emotes",
 	"/refreshemotes",
 	"/join <channel>",
+	"/block <pattern>",
+	"/unblock <number|pattern>",
+	"/blocks",
+	"/twitchblock <username>",
+	"/twitchunblock <username>",
 }
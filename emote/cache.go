@@ -394,6 +394,27 @@ func (s *Cache) GetAllForChannel(id string) EmoteSet {
 	return data
 }
 
+// GetGlobal returns the global emotes of all platforms.
+func (s *Cache) GetGlobal() EmoteSet {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	return slices.Clone(s.global)
+}
+
+// GetForeign returns the emotes seen in chat messages which are not part of a loaded emote set.
+func (s *Cache) GetForeign() EmoteSet {
+	s.m.RLock()
+	defer s.m.RUnlock()
+
+	set := make(EmoteSet, 0, len(s.foreignEmotes))
+	for _, e := range s.foreignEmotes {
+		set = append(set, e)
+	}
+
+	return set
+}
+
 func (s *Cache) GetAll() EmoteSet {
 	s.m.RLock()
 	defer s.m.RUnlock()
@@ -9,8 +9,8 @@ import (
 )
 
 // RateLimitRetryTransport is an http.RoundTripper that automatically retries
-// requests that receive a 429 (Too Many Requests) response by waiting until
-// the time specified in the Ratelimit-Reset header.
+// requests that receive a 429 (Too Many Requests) response by waiting for
+// the delay in the Retry-After header or until the time in the Ratelimit-Reset header.
 type RateLimitRetryTransport struct {
 	// Transport is the underlying http.RoundTripper
 	Transport http.RoundTripper
@@ -47,17 +47,9 @@ func (t *RateLimitRetryTransport) RoundTrip(req *http.Request) (*http.Response,
 		return resp, nil
 	}
 
-	// Check for Ratelimit-Reset header
-	resetHeader := resp.Header.Get("Ratelimit-Reset")
-	if resetHeader == "" {
-		// No reset header, can't retry
-		return resp, nil
-	}
-
-	// Parse the reset timestamp
-	waitUntil, err := strconv.ParseInt(resetHeader, 10, 64)
-	if err != nil {
-		// Can't parse reset time, return original response
+	wait, ok := retryDelay(resp.Header, time.Now())
+	if !ok {
+		// No usable header, can't retry
 		return resp, nil
 	}
 
@@ -65,8 +57,8 @@ func (t *RateLimitRetryTransport) RoundTrip(req *http.Request) (*http.Response,
 	_, _ = io.Copy(io.Discard, resp.Body)
 	resp.Body.Close()
 
-	// Calculate wait duration (add 1 second buffer)
-	diff := time.Until(time.Unix(waitUntil, 0)) + time.Second
+	// add 1 second buffer
+	diff := wait + time.Second
 
 	// Create timer for the wait duration
 	timer := time.NewTimer(diff)
@@ -85,3 +77,17 @@ func (t *RateLimitRetryTransport) RoundTrip(req *http.Request) (*http.Response,
 		return nil, req.Context().Err()
 	}
 }
+
+// retryDelay returns how long to wait before retrying a 429 response. The Chatuino server sends the delay in seconds
+// in Retry-After, Twitch only the Unix timestamp of the reset in Ratelimit-Reset.
+func retryDelay(h http.Header, now time.Time) (time.Duration, bool) {
+	if seconds, err := strconv.ParseInt(h.Get("Retry-After"), 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if reset, err := strconv.ParseInt(h.Get("Ratelimit-Reset"), 10, 64); err == nil {
+		return time.Unix(reset, 0).Sub(now), true
+	}
+
+	return 0, false
+}
@@ -222,3 +222,31 @@ func TestRateLimitRetryTransport(t *testing.T) {
 		require.Equal(t, http.StatusOK, resp.StatusCode)
 	})
 }
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	now := time.Unix(1640000000, 0)
+
+	tests := []struct {
+		name   string
+		header http.Header
+		want   time.Duration
+		ok     bool
+	}{
+		{name: "retry after in seconds", header: http.Header{"Retry-After": {"30"}, "Ratelimit-Reset": {"30"}}, want: 30 * time.Second, ok: true},
+		{name: "twitch reset timestamp", header: http.Header{"Ratelimit-Reset": {"1640000010"}}, want: 10 * time.Second, ok: true},
+		{name: "no header", header: http.Header{}},
+		{name: "invalid values", header: http.Header{"Retry-After": {"soon"}, "Ratelimit-Reset": {"later"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, ok := retryDelay(tt.header, now)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
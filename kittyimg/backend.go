@@ -0,0 +1,136 @@
+package kittyimg
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+// Protocol is the terminal graphics protocol used to display images.
+type Protocol int
+
+const (
+	ProtocolNone Protocol = iota
+	ProtocolKitty
+	ProtocolSixel
+	ProtocolITerm2
+	ProtocolHalfBlock
+)
+
+func (p Protocol) String() string {
+	switch p {
+	case ProtocolKitty:
+		return "kitty"
+	case ProtocolSixel:
+		return "sixel"
+	case ProtocolITerm2:
+		return "iterm2"
+	case ProtocolHalfBlock:
+		return "halfblock"
+	}
+
+	return "none"
+}
+
+// ParseProtocol parses the protocol names used in the settings file.
+// An empty string or "auto" returns ProtocolNone, meaning the protocol should be detected.
+func ParseProtocol(s string) (Protocol, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "auto":
+		return ProtocolNone, nil
+	case "kitty":
+		return ProtocolKitty, nil
+	case "sixel":
+		return ProtocolSixel, nil
+	case "iterm2":
+		return ProtocolITerm2, nil
+	case "halfblock":
+		return ProtocolHalfBlock, nil
+	}
+
+	return ProtocolNone, fmt.Errorf("unknown graphics protocol %q", s)
+}
+
+// Backend converts images into terminal output for a specific graphics protocol.
+type Backend interface {
+	Protocol() Protocol
+	Convert(unit DisplayUnit) (KittyDisplayUnit, error)
+	CleanupOldImagesCommand(maxAge time.Duration) string
+	CleanupAllImagesCommand() string
+	// Close persists state kept in memory, it is called when Chatuino exits.
+	Close() error
+}
+
+// NewBackend returns the backend implementation for protocol.
+// cellWidth and cellHeight are the size of a terminal cell in pixels and may be zero
+// for the half block renderer, which does not depend on pixel sizes.
+// index is optional and only used by backends storing converted images on disk.
+func NewBackend(protocol Protocol, fs afero.Fs, cellWidth, cellHeight float32, index *CacheIndex) (Backend, error) {
+	switch protocol {
+	case ProtocolKitty:
+		if cellWidth <= 0 || cellHeight <= 0 {
+			return nil, fmt.Errorf("kitty graphics need the terminal cell size in pixels")
+		}
+		if index != nil {
+			return NewDisplayManager(fs, cellWidth, cellHeight, WithCacheIndex(index)), nil
+		}
+		return NewDisplayManager(fs, cellWidth, cellHeight), nil
+	case ProtocolSixel:
+		if cellWidth <= 0 || cellHeight <= 0 {
+			return nil, fmt.Errorf("sixel graphics need the terminal cell size in pixels")
+		}
+		return newRasterDisplayManager(ProtocolSixel, sixelEncoder{}, cellWidth, cellHeight), nil
+	case ProtocolITerm2:
+		if cellWidth <= 0 || cellHeight <= 0 {
+			return nil, fmt.Errorf("iTerm2 graphics need the terminal cell size in pixels")
+		}
+		return newRasterDisplayManager(ProtocolITerm2, iTerm2Encoder{}, cellWidth, cellHeight), nil
+	case ProtocolHalfBlock:
+		if cellWidth <= 0 || cellHeight <= 0 {
+			// most terminal fonts have cells about twice as high as wide
+			cellWidth, cellHeight = 1, 2
+		}
+		return newRasterDisplayManager(ProtocolHalfBlock, halfBlockEncoder{}, cellWidth, cellHeight), nil
+	}
+
+	return nil, fmt.Errorf("no graphics backend for protocol %s", protocol)
+}
+
+// DetectProtocol guesses the best graphics protocol supported by the terminal using
+// the environment variables terminals set for their child processes.
+func DetectProtocol(lookupEnv func(string) (string, bool)) Protocol {
+	getenv := func(key string) string {
+		v, _ := lookupEnv(key)
+		return v
+	}
+
+	term := getenv("TERM")
+	termProgram := getenv("TERM_PROGRAM")
+
+	// kitty always defines KITTY_WINDOW_ID
+	if _, isKitty := lookupEnv("KITTY_WINDOW_ID"); isKitty || term == "xterm-ghostty" || term == "xterm-kitty" {
+		return ProtocolKitty
+	}
+
+	if termProgram == "iTerm.app" || termProgram == "WezTerm" || getenv("LC_TERMINAL") == "iTerm2" {
+		return ProtocolITerm2
+	}
+
+	if _, isKonsole := lookupEnv("KONSOLE_VERSION"); isKonsole {
+		return ProtocolSixel
+	}
+
+	for _, t := range []string{"foot", "mlterm", "contour", "yaft"} {
+		if strings.HasPrefix(term, t) {
+			return ProtocolSixel
+		}
+	}
+
+	if colorTerm := getenv("COLORTERM"); colorTerm == "truecolor" || colorTerm == "24bit" {
+		return ProtocolHalfBlock
+	}
+
+	return ProtocolNone
+}
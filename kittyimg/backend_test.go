@@ -0,0 +1,211 @@
+package kittyimg
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+// testQuadrantPNG returns a 12x12 png with red, green and blue quadrants and a transparent bottom right quadrant.
+func testQuadrantPNG(t *testing.T) []byte {
+	t.Helper()
+
+	img := image.NewNRGBA(image.Rect(0, 0, 12, 12))
+	for y := range 12 {
+		for x := range 12 {
+			switch {
+			case x < 6 && y < 6:
+				img.Set(x, y, color.NRGBA{R: 255, A: 255})
+			case y < 6:
+				img.Set(x, y, color.NRGBA{G: 255, A: 255})
+			case x < 6:
+				img.Set(x, y, color.NRGBA{B: 255, A: 255})
+			}
+		}
+	}
+
+	var buff bytes.Buffer
+	require.NoError(t, png.Encode(&buff, img))
+
+	return buff.Bytes()
+}
+
+func requireGolden(t *testing.T, name string, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+
+	if *updateGolden {
+		require.NoError(t, os.WriteFile(path, []byte(got), 0o644))
+	}
+
+	want, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, string(want), got)
+}
+
+func TestNewBackend_Golden(t *testing.T) {
+	t.Parallel()
+
+	data := testQuadrantPNG(t)
+
+	tests := []struct {
+		name     string
+		protocol Protocol
+		golden   string
+	}{
+		{name: "sixel", protocol: ProtocolSixel, golden: "quadrant_sixel"},
+		{name: "iterm2", protocol: ProtocolITerm2, golden: "quadrant_iterm2"},
+		{name: "halfblock", protocol: ProtocolHalfBlock, golden: "quadrant_halfblock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			backend, err := NewBackend(tt.protocol, afero.NewMemMapFs(), 6, 12, nil)
+			require.NoError(t, err)
+			require.Equal(t, tt.protocol, backend.Protocol())
+
+			loads := 0
+			unit := DisplayUnit{
+				ID:        "quadrant",
+				Directory: "emote",
+				Load: func() (io.ReadCloser, string, error) {
+					loads++
+					return io.NopCloser(bytes.NewReader(data)), "image/png", nil
+				},
+			}
+
+			result, err := backend.Convert(unit)
+			require.NoError(t, err)
+			require.Empty(t, result.PrepareCommand)
+			requireGolden(t, tt.golden, result.ReplacementText)
+
+			// second conversion is served from the session cache
+			cached, err := backend.Convert(unit)
+			require.NoError(t, err)
+			require.Equal(t, result, cached)
+			require.Equal(t, 1, loads)
+		})
+	}
+}
+
+func TestNewBackend_HalfBlockWithoutCellSize(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile("../emote/testdata/pepeLaugh.webp")
+	require.NoError(t, err)
+
+	backend, err := NewBackend(ProtocolHalfBlock, afero.NewMemMapFs(), 0, 0, nil)
+	require.NoError(t, err)
+
+	result, err := backend.Convert(DisplayUnit{
+		ID: "pepeLaugh",
+		Load: func() (io.ReadCloser, string, error) {
+			return io.NopCloser(bytes.NewReader(data)), "image/webp", nil
+		},
+	})
+	require.NoError(t, err)
+	requireGolden(t, "pepelaugh_halfblock", result.ReplacementText)
+}
+
+func TestNewBackend_RequiresCellSize(t *testing.T) {
+	t.Parallel()
+
+	for _, p := range []Protocol{ProtocolKitty, ProtocolSixel, ProtocolITerm2} {
+		_, err := NewBackend(p, afero.NewMemMapFs(), 0, 0, nil)
+		require.Error(t, err, p.String())
+	}
+
+	_, err := NewBackend(ProtocolNone, afero.NewMemMapFs(), 10, 10, nil)
+	require.Error(t, err)
+}
+
+func TestRasterDisplayManager_CleanupOldImages(t *testing.T) {
+	t.Parallel()
+
+	data := testQuadrantPNG(t)
+	backend, err := NewBackend(ProtocolHalfBlock, afero.NewMemMapFs(), 0, 0, nil)
+	require.NoError(t, err)
+
+	loads := 0
+	unit := DisplayUnit{
+		ID: "quadrant",
+		Load: func() (io.ReadCloser, string, error) {
+			loads++
+			return io.NopCloser(bytes.NewReader(data)), "image/png", nil
+		},
+	}
+
+	_, err = backend.Convert(unit)
+	require.NoError(t, err)
+
+	require.Empty(t, backend.CleanupOldImagesCommand(0))
+
+	_, err = backend.Convert(unit)
+	require.NoError(t, err)
+	require.Equal(t, 2, loads, "image should be loaded again after clean up")
+}
+
+func TestParseProtocol(t *testing.T) {
+	t.Parallel()
+
+	for in, want := range map[string]Protocol{
+		"":          ProtocolNone,
+		"auto":      ProtocolNone,
+		"kitty":     ProtocolKitty,
+		"Sixel":     ProtocolSixel,
+		"iterm2":    ProtocolITerm2,
+		"halfblock": ProtocolHalfBlock,
+	} {
+		got, err := ParseProtocol(in)
+		require.NoError(t, err, in)
+		require.Equal(t, want, got, in)
+	}
+
+	_, err := ParseProtocol("braille")
+	require.Error(t, err)
+}
+
+func TestDetectProtocol(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want Protocol
+	}{
+		{name: "kitty", env: map[string]string{"KITTY_WINDOW_ID": "1", "TERM": "xterm-kitty"}, want: ProtocolKitty},
+		{name: "ghostty", env: map[string]string{"TERM": "xterm-ghostty"}, want: ProtocolKitty},
+		{name: "iterm", env: map[string]string{"TERM_PROGRAM": "iTerm.app", "COLORTERM": "truecolor"}, want: ProtocolITerm2},
+		{name: "wezterm", env: map[string]string{"TERM_PROGRAM": "WezTerm"}, want: ProtocolITerm2},
+		{name: "foot", env: map[string]string{"TERM": "foot-extra"}, want: ProtocolSixel},
+		{name: "konsole", env: map[string]string{"KONSOLE_VERSION": "240802", "TERM": "xterm-256color"}, want: ProtocolSixel},
+		{name: "truecolor", env: map[string]string{"TERM": "xterm-256color", "COLORTERM": "truecolor"}, want: ProtocolHalfBlock},
+		{name: "unsupported", env: map[string]string{"TERM": "xterm"}, want: ProtocolNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := DetectProtocol(func(key string) (string, bool) {
+				v, ok := tt.env[key]
+				return v, ok
+			})
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,379 @@
+package kittyimg
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	easyjson "github.com/mailru/easyjson"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/afero"
+)
+
+const (
+	cacheIndexFileName = "image_cache_index.json"
+	cacheLockFileName  = "image_cache_index.lock"
+)
+
+// Locker guards the cache index against concurrent modification by other Chatuino processes.
+type Locker interface {
+	Lock() error
+	Unlock() error
+}
+
+//easyjson:json
+type cacheIndexFile struct {
+	Entries   map[string]CacheIndexEntry `json:"entries"`
+	Hits      int64                      `json:"hits"`
+	Misses    int64                      `json:"misses"`
+	Evictions int64                      `json:"evictions"`
+}
+
+//easyjson:json
+type CacheIndexEntry struct {
+	Directory  string    `json:"directory"`
+	Files      []string  `json:"files"`
+	SizeBytes  int64     `json:"size_bytes"`
+	LastAccess time.Time `json:"last_access"`
+}
+
+// CacheStats summarizes the image cache index.
+type CacheStats struct {
+	Images         int
+	SizeBytes      int64
+	SizeByDir      map[string]int64
+	ImagesByDir    map[string]int
+	Hits, Misses   int64
+	Evictions      int64
+	BudgetBytes    int64
+	OldestAccessed time.Time
+}
+
+// CacheIndex tracks the converted images stored on disk together with their last access time.
+// When the total size exceeds the budget the least recently used images are deleted.
+// The index is persisted as JSON next to the images and re-read on every write while
+// holding the lock, so multiple Chatuino instances can share the same cache directory.
+// Hits, misses and access times are collected in memory and only written on Flush or when images are added.
+type CacheIndex struct {
+	fs          afero.Fs
+	baseDir     string
+	lock        Locker
+	budgetBytes int64 // 0 means unlimited
+
+	m       *sync.Mutex
+	touched map[string]time.Time // access times not yet persisted
+	hits    int64                // hits not yet persisted
+	misses  int64                // misses not yet persisted
+}
+
+func NewCacheIndex(fs afero.Fs, baseDir string, lock Locker, budgetBytes int64) *CacheIndex {
+	return &CacheIndex{
+		fs:          fs,
+		baseDir:     baseDir,
+		lock:        lock,
+		budgetBytes: budgetBytes,
+		m:           &sync.Mutex{},
+		touched:     map[string]time.Time{},
+	}
+}
+
+func cacheIndexKey(unit DisplayUnit) string {
+	return filepath.Join(unit.Directory, filepath.Clean(unit.ID))
+}
+
+// Touch marks an image as used. The access time is written on the next Flush.
+func (c *CacheIndex) Touch(unit DisplayUnit) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.touched[cacheIndexKey(unit)] = time.Now()
+}
+
+// Hit records that an image was loaded from the disk cache. It is written on the next Flush.
+func (c *CacheIndex) Hit(unit DisplayUnit) {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.touched[cacheIndexKey(unit)] = time.Now()
+	c.hits++
+}
+
+// Miss records that an image was not found in the disk cache. It is written on the next Flush.
+func (c *CacheIndex) Miss() {
+	c.m.Lock()
+	defer c.m.Unlock()
+
+	c.misses++
+}
+
+// Add registers the files written for an image and evicts the least recently used
+// images until the cache fits the budget again.
+func (c *CacheIndex) Add(unit DisplayUnit, files []string) error {
+	var size int64
+	for _, f := range files {
+		info, err := c.fs.Stat(f)
+		if err != nil {
+			return fmt.Errorf("failed to stat cached image file %s: %w", f, err)
+		}
+
+		size += info.Size()
+	}
+
+	key := cacheIndexKey(unit)
+
+	return c.update(func(index *cacheIndexFile) {
+		index.Entries[key] = CacheIndexEntry{
+			Directory:  unit.Directory,
+			Files:      files,
+			SizeBytes:  size,
+			LastAccess: time.Now(),
+		}
+
+		c.evict(index, key)
+	})
+}
+
+// RemoveDirectory drops all entries of a directory from the index, e.g. after the directory was deleted.
+func (c *CacheIndex) RemoveDirectory(dir string) error {
+	return c.update(func(index *cacheIndexFile) {
+		for key, e := range index.Entries {
+			if e.Directory == dir {
+				delete(index.Entries, key)
+			}
+		}
+	})
+}
+
+// Flush persists the access times, hits and misses collected in memory and evicts images if needed.
+func (c *CacheIndex) Flush() error {
+	c.m.Lock()
+	empty := len(c.touched) == 0 && c.hits == 0 && c.misses == 0
+	c.m.Unlock()
+
+	if empty {
+		return nil
+	}
+
+	return c.update(func(index *cacheIndexFile) {
+		c.evict(index, "")
+	})
+}
+
+// Stats reads the current index from disk, including the hits and misses not yet flushed.
+func (c *CacheIndex) Stats() (CacheStats, error) {
+	if err := c.lock.Lock(); err != nil {
+		return CacheStats{}, fmt.Errorf("failed to lock image cache index: %w", err)
+	}
+	defer c.unlock()
+
+	index, err := c.read()
+	if err != nil {
+		return CacheStats{}, err
+	}
+
+	c.m.Lock()
+	index.Hits += c.hits
+	index.Misses += c.misses
+	c.m.Unlock()
+
+	stats := CacheStats{
+		Images:      len(index.Entries),
+		SizeByDir:   map[string]int64{},
+		ImagesByDir: map[string]int{},
+		Hits:        index.Hits,
+		Misses:      index.Misses,
+		Evictions:   index.Evictions,
+		BudgetBytes: c.budgetBytes,
+	}
+
+	for _, e := range index.Entries {
+		stats.SizeBytes += e.SizeBytes
+		stats.SizeByDir[e.Directory] += e.SizeBytes
+		stats.ImagesByDir[e.Directory]++
+
+		if stats.OldestAccessed.IsZero() || e.LastAccess.Before(stats.OldestAccessed) {
+			stats.OldestAccessed = e.LastAccess
+		}
+	}
+
+	return stats, nil
+}
+
+// evict deletes the least recently used images until the cache fits the budget.
+// The image with the key keep is never evicted, even if it alone exceeds the budget.
+func (c *CacheIndex) evict(index *cacheIndexFile, keep string) {
+	if c.budgetBytes <= 0 {
+		return
+	}
+
+	var total int64
+	keys := make([]string, 0, len(index.Entries))
+	for key, e := range index.Entries {
+		total += e.SizeBytes
+		keys = append(keys, key)
+	}
+
+	if total <= c.budgetBytes {
+		return
+	}
+
+	slices.SortFunc(keys, func(a, b string) int {
+		return index.Entries[a].LastAccess.Compare(index.Entries[b].LastAccess)
+	})
+
+	for _, key := range keys {
+		if total <= c.budgetBytes {
+			return
+		}
+
+		if key == keep {
+			continue
+		}
+
+		e := index.Entries[key]
+		for _, f := range e.Files {
+			if err := c.fs.Remove(f); err != nil && !errors.Is(err, fs.ErrNotExist) {
+				log.Logger.Warn().Err(err).Str("file", f).Msg("failed to evict cached image file")
+			}
+		}
+
+		total -= e.SizeBytes
+		index.Evictions++
+		delete(index.Entries, key)
+	}
+}
+
+func (c *CacheIndex) update(fn func(index *cacheIndexFile)) error {
+	if err := c.lock.Lock(); err != nil {
+		return fmt.Errorf("failed to lock image cache index: %w", err)
+	}
+	defer c.unlock()
+
+	index, err := c.read()
+	if err != nil {
+		return err
+	}
+
+	c.m.Lock()
+	for key, t := range c.touched {
+		if e, ok := index.Entries[key]; ok && t.After(e.LastAccess) {
+			e.LastAccess = t
+			index.Entries[key] = e
+		}
+	}
+	clear(c.touched)
+	index.Hits += c.hits
+	index.Misses += c.misses
+	c.hits, c.misses = 0, 0
+	c.m.Unlock()
+
+	fn(&index)
+
+	return c.write(index)
+}
+
+func (c *CacheIndex) unlock() {
+	if err := c.lock.Unlock(); err != nil {
+		log.Logger.Warn().Err(err).Msg("failed to unlock image cache index")
+	}
+}
+
+func (c *CacheIndex) read() (cacheIndexFile, error) {
+	data, err := afero.ReadFile(c.fs, filepath.Join(c.baseDir, cacheIndexFileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return c.rebuild()
+		}
+
+		return cacheIndexFile{}, fmt.Errorf("failed to read image cache index: %w", err)
+	}
+
+	var index cacheIndexFile
+	if err := easyjson.Unmarshal(data, &index); err != nil {
+		log.Logger.Warn().Err(err).Msg("image cache index is corrupt, rebuilding")
+		return c.rebuild()
+	}
+
+	if index.Entries == nil {
+		index.Entries = map[string]CacheIndexEntry{}
+	}
+
+	return index, nil
+}
+
+func (c *CacheIndex) write(index cacheIndexFile) error {
+	data, err := easyjson.Marshal(index)
+	if err != nil {
+		return err
+	}
+
+	if err := c.fs.MkdirAll(c.baseDir, 0o755); err != nil {
+		return err
+	}
+
+	// write to a temporary file first so readers never observe a partially written index
+	path := filepath.Join(c.baseDir, cacheIndexFileName)
+	if err := afero.WriteFile(c.fs, path+".tmp", data, 0o600); err != nil {
+		return fmt.Errorf("failed to write image cache index: %w", err)
+	}
+
+	if err := c.fs.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to replace image cache index: %w", err)
+	}
+
+	return nil
+}
+
+// rebuild creates an index from the images already on disk, e.g. from versions
+// before the index existed. The modification time is used as last access time.
+func (c *CacheIndex) rebuild() (cacheIndexFile, error) {
+	index := cacheIndexFile{Entries: map[string]CacheIndexEntry{}}
+
+	for _, dir := range []string{"emote", "badge"} {
+		path := filepath.Join(c.baseDir, dir)
+
+		infos, err := afero.ReadDir(c.fs, path)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+
+			return cacheIndexFile{}, fmt.Errorf("failed to read image cache directory %s: %w", path, err)
+		}
+
+		// images are stored as <id>.json with their frames named <id>.<offset>
+		for _, info := range infos {
+			if info.IsDir() || filepath.Ext(info.Name()) != ".json" {
+				continue
+			}
+
+			index.Entries[filepath.Join(dir, strings.TrimSuffix(info.Name(), ".json"))] = CacheIndexEntry{
+				Directory:  dir,
+				LastAccess: info.ModTime(),
+			}
+		}
+
+		for _, info := range infos {
+			if info.IsDir() {
+				continue
+			}
+
+			key := filepath.Join(dir, strings.TrimSuffix(info.Name(), filepath.Ext(info.Name())))
+			entry, ok := index.Entries[key]
+			if !ok {
+				continue
+			}
+
+			entry.Files = append(entry.Files, filepath.Join(path, info.Name()))
+			entry.SizeBytes += info.Size()
+			index.Entries[key] = entry
+		}
+	}
+
+	return index, nil
+}
@@ -0,0 +1,215 @@
+package kittyimg
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/syncmap"
+)
+
+type mutexLocker struct {
+	m sync.Mutex
+}
+
+func (l *mutexLocker) Lock() error {
+	l.m.Lock()
+	return nil
+}
+
+func (l *mutexLocker) Unlock() error {
+	l.m.Unlock()
+	return nil
+}
+
+func writeCacheFiles(t *testing.T, fs afero.Fs, unit DisplayUnit, size int) []string {
+	t.Helper()
+
+	dir := filepath.Join("/cache", unit.Directory)
+	require.NoError(t, fs.MkdirAll(dir, 0o755))
+
+	meta := filepath.Join(dir, unit.ID+".json")
+	frame := filepath.Join(dir, unit.ID+".0")
+	require.NoError(t, afero.WriteFile(fs, meta, []byte("{}"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, frame, bytes.Repeat([]byte{1}, size-2), 0o644))
+
+	return []string{meta, frame}
+}
+
+func TestCacheIndex_EvictsLeastRecentlyUsed(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	index := NewCacheIndex(fs, "/cache", &mutexLocker{}, 250)
+
+	a := DisplayUnit{ID: "a", Directory: "emote"}
+	b := DisplayUnit{ID: "b", Directory: "emote"}
+	c := DisplayUnit{ID: "c", Directory: "badge"}
+
+	require.NoError(t, index.Add(a, writeCacheFiles(t, fs, a, 100)))
+	require.NoError(t, index.Add(b, writeCacheFiles(t, fs, b, 100)))
+
+	// a was used more recently than b
+	time.Sleep(time.Millisecond)
+	index.Touch(a)
+	require.NoError(t, index.Flush())
+
+	require.NoError(t, index.Add(c, writeCacheFiles(t, fs, c, 100)))
+
+	stats, err := index.Stats()
+	require.NoError(t, err)
+	require.Equal(t, 2, stats.Images)
+	require.Equal(t, int64(200), stats.SizeBytes)
+	require.Equal(t, int64(1), stats.Evictions)
+
+	exists, err := afero.Exists(fs, "/cache/emote/b.json")
+	require.NoError(t, err)
+	require.False(t, exists, "least recently used image should be evicted")
+
+	exists, err = afero.Exists(fs, "/cache/emote/a.0")
+	require.NoError(t, err)
+	require.True(t, exists)
+}
+
+func TestCacheIndex_KeepsNewImageLargerThanBudget(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	index := NewCacheIndex(fs, "/cache", &mutexLocker{}, 50)
+
+	a := DisplayUnit{ID: "a", Directory: "emote"}
+	require.NoError(t, index.Add(a, writeCacheFiles(t, fs, a, 100)))
+
+	stats, err := index.Stats()
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.Images)
+	require.Zero(t, stats.Evictions)
+}
+
+func TestCacheIndex_SharedBetweenInstances(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	lock := &mutexLocker{}
+
+	// two Chatuino processes using the same cache directory
+	first := NewCacheIndex(fs, "/cache", lock, 0)
+	second := NewCacheIndex(fs, "/cache", lock, 0)
+
+	var wg sync.WaitGroup
+	for i, index := range []*CacheIndex{first, second} {
+		wg.Go(func() {
+			for n := range 10 {
+				unit := DisplayUnit{ID: string(rune('a'+i)) + string(rune('0'+n)), Directory: "emote"}
+				index.Miss()
+				require.NoError(t, index.Add(unit, writeCacheFiles(t, fs, unit, 10)))
+				index.Hit(unit)
+			}
+		})
+	}
+	wg.Wait()
+
+	require.NoError(t, first.Flush())
+	require.NoError(t, second.Flush())
+
+	stats, err := first.Stats()
+	require.NoError(t, err)
+	require.Equal(t, 20, stats.Images)
+	require.Equal(t, int64(20), stats.Hits)
+	require.Equal(t, int64(20), stats.Misses)
+	require.Equal(t, 20, stats.ImagesByDir["emote"])
+}
+
+func TestCacheIndex_RebuildsFromExistingFiles(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+
+	emoteUnit := DisplayUnit{ID: "seventv.abc", Directory: "emote"}
+	badgeUnit := DisplayUnit{ID: "123subscriber1", Directory: "badge"}
+	writeCacheFiles(t, fs, emoteUnit, 40)
+	writeCacheFiles(t, fs, badgeUnit, 20)
+
+	index := NewCacheIndex(fs, "/cache", &mutexLocker{}, 0)
+	stats, err := index.Stats()
+	require.NoError(t, err)
+	require.Equal(t, 2, stats.Images)
+	require.Equal(t, int64(40), stats.SizeByDir["emote"])
+	require.Equal(t, int64(20), stats.SizeByDir["badge"])
+
+	require.NoError(t, index.RemoveDirectory("emote"))
+
+	stats, err = index.Stats()
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.Images)
+	require.Zero(t, stats.ImagesByDir["emote"])
+}
+
+func TestCacheIndex_HitsAreWrittenOnFlush(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	lock := &mutexLocker{}
+	index := NewCacheIndex(fs, "/cache", lock, 0)
+	other := NewCacheIndex(fs, "/cache", lock, 0)
+
+	unit := DisplayUnit{ID: "a", Directory: "emote"}
+	require.NoError(t, index.Add(unit, writeCacheFiles(t, fs, unit, 10)))
+
+	index.Hit(unit)
+	index.Miss()
+
+	stats, err := other.Stats()
+	require.NoError(t, err)
+	require.Zero(t, stats.Hits, "hits are only kept in memory until the next flush")
+
+	require.NoError(t, index.Flush())
+
+	stats, err = other.Stats()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), stats.Hits)
+	require.Equal(t, int64(1), stats.Misses)
+}
+
+func TestDisplayManager_Convert_RecordsCacheIndex(t *testing.T) {
+	// Reset global state for this test
+	globalImagePlacementIDCounter.Store(0)
+	globalPlacedImages = &syncmap.Map{}
+
+	fs := afero.NewMemMapFs()
+	index := NewCacheIndex(fs, BaseImageDirectory, &mutexLocker{}, 0)
+	dm := NewDisplayManager(fs, 10, 10, WithCacheIndex(index))
+
+	emoteData, err := os.ReadFile("../emote/testdata/pepeLaugh.webp")
+	require.NoError(t, err)
+
+	unit := DisplayUnit{
+		ID:        "indexed-emote",
+		Directory: "emote",
+		Load: func() (io.ReadCloser, string, error) {
+			return io.NopCloser(bytes.NewReader(emoteData)), "image/webp", nil
+		},
+	}
+
+	_, err = dm.Convert(unit)
+	require.NoError(t, err)
+
+	// drop the session placement so the image is loaded from disk again
+	globalPlacedImages = &syncmap.Map{}
+
+	_, err = dm.Convert(unit)
+	require.NoError(t, err)
+
+	stats, err := index.Stats()
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.Images)
+	require.Equal(t, int64(1), stats.Hits)
+	require.Equal(t, int64(1), stats.Misses)
+	require.Positive(t, stats.SizeBytes)
+}
@@ -0,0 +1,38 @@
+package kittyimg
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// halfBlockEncoder renders images as text using the upper and lower half block characters
+// with truecolor foreground and background colors. Each cell displays two vertically stacked
+// pixels, so it works in any terminal supporting 24-bit colors. Every cell resets the colors it set to the
+// terminal defaults, text styled around the image has to be styled again after it.
+type halfBlockEncoder struct{}
+
+func (halfBlockEncoder) encode(img image.Image, cols, _, _ int) (string, error) {
+	scaled := scaleImage(img, cols, 2)
+
+	var b strings.Builder
+	for x := range cols {
+		top := scaled.NRGBAAt(x, 0)
+		bottom := scaled.NRGBAAt(x, 1)
+
+		topVisible, bottomVisible := top.A >= 128, bottom.A >= 128
+
+		switch {
+		case topVisible && bottomVisible:
+			fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%d;48;2;%d;%d;%dm▀\x1b[39;49m", top.R, top.G, top.B, bottom.R, bottom.G, bottom.B)
+		case topVisible:
+			fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%dm▀\x1b[39m", top.R, top.G, top.B)
+		case bottomVisible:
+			fmt.Fprintf(&b, "\x1b[38;2;%d;%d;%dm▄\x1b[39m", bottom.R, bottom.G, bottom.B)
+		default:
+			b.WriteByte(' ')
+		}
+	}
+
+	return b.String(), nil
+}
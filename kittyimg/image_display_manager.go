@@ -117,14 +117,34 @@ type KittyDisplayUnit struct {
 type DisplayManager struct {
 	fs                    afero.Fs
 	cellWidth, cellHeight float32
+	index                 *CacheIndex
 }
 
-func NewDisplayManager(fs afero.Fs, cellWidth, cellHeight float32) *DisplayManager {
-	return &DisplayManager{
+type DisplayManagerOption func(*DisplayManager)
+
+// WithCacheIndex tracks images written to disk in index, evicting old images when the cache grows too large.
+func WithCacheIndex(index *CacheIndex) DisplayManagerOption {
+	return func(d *DisplayManager) {
+		d.index = index
+	}
+}
+
+func NewDisplayManager(fs afero.Fs, cellWidth, cellHeight float32, opts ...DisplayManagerOption) *DisplayManager {
+	d := &DisplayManager{
 		fs:         fs,
 		cellWidth:  cellWidth,
 		cellHeight: cellHeight,
 	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+func (d *DisplayManager) Protocol() Protocol {
+	return ProtocolKitty
 }
 
 func (d *DisplayManager) Convert(unit DisplayUnit) (KittyDisplayUnit, error) {
@@ -138,6 +158,10 @@ func (d *DisplayManager) Convert(unit DisplayUnit) (KittyDisplayUnit, error) {
 			i.lastUsed = time.Now()
 			globalPlacedImages.Swap(unit.ID, i)
 
+			if d.index != nil {
+				d.index.Touch(unit)
+			}
+
 			return KittyDisplayUnit{
 				// don't resend placement command
 				ReplacementText: i.DisplayUnicodePlaceholder(),
@@ -160,6 +184,11 @@ func (d *DisplayManager) Convert(unit DisplayUnit) (KittyDisplayUnit, error) {
 		//log.Logger.Info().Str("id", unit.ID).Int32("placement-id", cachedDecoded.ID).Msg("load image from storage cache")
 
 		globalPlacedImages.Store(unit.ID, cachedDecoded)
+
+		if d.index != nil {
+			d.index.Hit(unit)
+		}
+
 		return KittyDisplayUnit{
 			PrepareCommand:  cachedDecoded.PrepareCommand(),
 			ReplacementText: cachedDecoded.DisplayUnicodePlaceholder(),
@@ -167,6 +196,10 @@ func (d *DisplayManager) Convert(unit DisplayUnit) (KittyDisplayUnit, error) {
 	}
 
 	// 3rd: image was not downloaded yet, download and convert and save
+	if d.index != nil {
+		d.index.Miss()
+	}
+
 	imageBody, contentType, err := unit.Load()
 	if err != nil {
 		return KittyDisplayUnit{}, err
@@ -187,6 +220,10 @@ func (d *DisplayManager) Convert(unit DisplayUnit) (KittyDisplayUnit, error) {
 	globalPlacedImages.Store(unit.ID, decoded)                 // store placement
 	if err := d.cacheDecodedImage(decoded, unit); err != nil { // cache decoded image
 		log.Logger.Warn().Err(err).Str("id", unit.ID).Msg("failed to cache decoded image")
+	} else if d.index != nil {
+		if err := d.index.Add(unit, d.cachedFiles(decoded, unit)); err != nil {
+			log.Logger.Warn().Err(err).Str("id", unit.ID).Msg("failed to add image to cache index")
+		}
 	}
 
 	return KittyDisplayUnit{
@@ -211,6 +248,12 @@ func (d *DisplayManager) CleanupOldImagesCommand(maxAge time.Duration) string {
 		return true
 	})
 
+	if d.index != nil {
+		if err := d.index.Flush(); err != nil {
+			log.Logger.Warn().Err(err).Msg("failed to flush image cache index")
+		}
+	}
+
 	return cmd.String()
 }
 
@@ -218,6 +261,15 @@ func (d *DisplayManager) CleanupAllImagesCommand() string {
 	return "\x1b_Ga=D\x1b\\"
 }
 
+// Close writes the cache index statistics collected since the last cleanup.
+func (d *DisplayManager) Close() error {
+	if d.index == nil {
+		return nil
+	}
+
+	return d.index.Flush()
+}
+
 func (d *DisplayManager) convertImageBytes(r io.Reader, unit DisplayUnit, contentType string) (DecodedImage, error) {
 	if contentType == "image/avif" {
 		return d.convertAnimatedAvif(r, unit)
@@ -437,6 +489,18 @@ func (d *DisplayManager) cacheDecodedImage(decoded DecodedImage, unit DisplayUni
 	return nil
 }
 
+// cachedFiles returns the paths of all files written for a decoded image.
+func (d *DisplayManager) cachedFiles(decoded DecodedImage, unit DisplayUnit) []string {
+	dir := filepath.Join(BaseImageDirectory, unit.Directory)
+
+	files := []string{filepath.Join(dir, fmt.Sprintf("%s.json", filepath.Clean(unit.ID)))}
+	for offset := range decoded.Images {
+		files = append(files, filepath.Join(dir, fmt.Sprintf("%s.%d", filepath.Clean(unit.ID), offset)))
+	}
+
+	return files
+}
+
 func (d *DisplayManager) saveKittyFormattedImage(buff []byte, unit DisplayUnit, offset int) (string, error) {
 	cacheDir, err := d.createGetCacheDirectory(unit.Directory)
 	if err != nil {
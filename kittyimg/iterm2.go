@@ -0,0 +1,29 @@
+package kittyimg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/png"
+)
+
+// iTerm2Encoder encodes images using the iTerm2 inline image protocol (OSC 1337).
+// The protocol is also supported by WezTerm and some other terminals.
+type iTerm2Encoder struct{}
+
+func (iTerm2Encoder) encode(img image.Image, cols, width, height int) (string, error) {
+	var buff bytes.Buffer
+	if err := png.Encode(&buff, scaleImage(img, width, height)); err != nil {
+		return "", fmt.Errorf("failed to encode png: %w", err)
+	}
+
+	seq := fmt.Sprintf(
+		"\x1b]1337;File=inline=1;size=%d;width=%d;height=1;preserveAspectRatio=1:%s\a",
+		buff.Len(),
+		cols,
+		base64.StdEncoding.EncodeToString(buff.Bytes()),
+	)
+
+	return inlineImage(seq, cols), nil
+}
@@ -17,7 +17,121 @@ var (
 	_ easyjson.Marshaler
 )
 
-func easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg(in *jlexer.Lexer, out *DecodedImageFrame) {
+func easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg(in *jlexer.Lexer, out *cacheIndexFile) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "entries":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				in.Delim('{')
+				out.Entries = make(map[string]CacheIndexEntry)
+				for !in.IsDelim('}') {
+					key := string(in.String())
+					in.WantColon()
+					var v1 CacheIndexEntry
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						(v1).UnmarshalEasyJSON(in)
+					}
+					(out.Entries)[key] = v1
+					in.WantComma()
+				}
+				in.Delim('}')
+			}
+		case "hits":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Hits = int64(in.Int64())
+			}
+		case "misses":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Misses = int64(in.Int64())
+			}
+		case "evictions":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Evictions = int64(in.Int64())
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg(out *jwriter.Writer, in cacheIndexFile) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"entries\":"
+		out.RawString(prefix[1:])
+		if in.Entries == nil && (out.Flags&jwriter.NilMapAsEmpty) == 0 {
+			out.RawString(`null`)
+		} else {
+			out.RawByte('{')
+			v2First := true
+			for v2Name, v2Value := range in.Entries {
+				if v2First {
+					v2First = false
+				} else {
+					out.RawByte(',')
+				}
+				out.String(string(v2Name))
+				out.RawByte(':')
+				(v2Value).MarshalEasyJSON(out)
+			}
+			out.RawByte('}')
+		}
+	}
+	{
+		const prefix string = ",\"hits\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Hits))
+	}
+	{
+		const prefix string = ",\"misses\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Misses))
+	}
+	{
+		const prefix string = ",\"evictions\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.Evictions))
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v cacheIndexFile) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *cacheIndexFile) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg(l, v)
+}
+func easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg1(in *jlexer.Lexer, out *DecodedImageFrame) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -65,7 +179,7 @@ func easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg(in *jlexer.Lexer, o
 		in.Consumed()
 	}
 }
-func easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg(out *jwriter.Writer, in DecodedImageFrame) {
+func easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg1(out *jwriter.Writer, in DecodedImageFrame) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -94,14 +208,14 @@ func easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg(out *jwriter.Writer
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v DecodedImageFrame) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg(w, v)
+	easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg1(w, v)
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *DecodedImageFrame) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg(l, v)
+	easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg1(l, v)
 }
-func easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg1(in *jlexer.Lexer, out *DecodedImage) {
+func easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg2(in *jlexer.Lexer, out *DecodedImage) {
 	isTopLevel := in.IsStart()
 	if in.IsNull() {
 		if isTopLevel {
@@ -137,13 +251,13 @@ func easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg1(in *jlexer.Lexer,
 					out.Images = (out.Images)[:0]
 				}
 				for !in.IsDelim(']') {
-					var v1 DecodedImageFrame
+					var v3 DecodedImageFrame
 					if in.IsNull() {
 						in.Skip()
 					} else {
-						(v1).UnmarshalEasyJSON(in)
+						(v3).UnmarshalEasyJSON(in)
 					}
-					out.Images = append(out.Images, v1)
+					out.Images = append(out.Images, v3)
 					in.WantComma()
 				}
 				in.Delim(']')
@@ -158,7 +272,7 @@ func easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg1(in *jlexer.Lexer,
 		in.Consumed()
 	}
 }
-func easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg1(out *jwriter.Writer, in DecodedImage) {
+func easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg2(out *jwriter.Writer, in DecodedImage) {
 	out.RawByte('{')
 	first := true
 	_ = first
@@ -179,11 +293,11 @@ func easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg1(out *jwriter.Write
 			out.RawString("null")
 		} else {
 			out.RawByte('[')
-			for v2, v3 := range in.Images {
-				if v2 > 0 {
+			for v4, v5 := range in.Images {
+				if v4 > 0 {
 					out.RawByte(',')
 				}
-				(v3).MarshalEasyJSON(out)
+				(v5).MarshalEasyJSON(out)
 			}
 			out.RawByte(']')
 		}
@@ -193,10 +307,128 @@ func easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg1(out *jwriter.Write
 
 // MarshalEasyJSON supports easyjson.Marshaler interface
 func (v DecodedImage) MarshalEasyJSON(w *jwriter.Writer) {
-	easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg1(w, v)
+	easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg2(w, v)
 }
 
 // UnmarshalEasyJSON supports easyjson.Unmarshaler interface
 func (v *DecodedImage) UnmarshalEasyJSON(l *jlexer.Lexer) {
-	easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg1(l, v)
+	easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg2(l, v)
+}
+func easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg3(in *jlexer.Lexer, out *CacheIndexEntry) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		switch key {
+		case "directory":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.Directory = string(in.String())
+			}
+		case "files":
+			if in.IsNull() {
+				in.Skip()
+				out.Files = nil
+			} else {
+				in.Delim('[')
+				if out.Files == nil {
+					if !in.IsDelim(']') {
+						out.Files = make([]string, 0, 4)
+					} else {
+						out.Files = []string{}
+					}
+				} else {
+					out.Files = (out.Files)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v6 string
+					if in.IsNull() {
+						in.Skip()
+					} else {
+						v6 = string(in.String())
+					}
+					out.Files = append(out.Files, v6)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "size_bytes":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				out.SizeBytes = int64(in.Int64())
+			}
+		case "last_access":
+			if in.IsNull() {
+				in.Skip()
+			} else {
+				if data := in.Raw(); in.Ok() {
+					in.AddError((out.LastAccess).UnmarshalJSON(data))
+				}
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg3(out *jwriter.Writer, in CacheIndexEntry) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"directory\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Directory))
+	}
+	{
+		const prefix string = ",\"files\":"
+		out.RawString(prefix)
+		if in.Files == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v7, v8 := range in.Files {
+				if v7 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v8))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"size_bytes\":"
+		out.RawString(prefix)
+		out.Int64(int64(in.SizeBytes))
+	}
+	{
+		const prefix string = ",\"last_access\":"
+		out.RawString(prefix)
+		out.Raw((in.LastAccess).MarshalJSON())
+	}
+	out.RawByte('}')
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v CacheIndexEntry) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonA7e4ce3aEncodeGithubComJulezDevChatuinoKittyimg3(w, v)
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *CacheIndexEntry) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonA7e4ce3aDecodeGithubComJulezDevChatuinoKittyimg3(l, v)
 }
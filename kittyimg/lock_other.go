@@ -0,0 +1,27 @@
+//go:build !unix
+
+package kittyimg
+
+import (
+	"sync"
+)
+
+type fileLock struct {
+	m *sync.Mutex
+}
+
+// NewFileLock returns a lock for the cache index. File locking is only implemented
+// for unix platforms, on other platforms the lock only guards the current process.
+func NewFileLock(_ string) Locker {
+	return &fileLock{m: &sync.Mutex{}}
+}
+
+func (l *fileLock) Lock() error {
+	l.m.Lock()
+	return nil
+}
+
+func (l *fileLock) Unlock() error {
+	l.m.Unlock()
+	return nil
+}
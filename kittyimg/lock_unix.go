@@ -0,0 +1,64 @@
+//go:build unix
+
+package kittyimg
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/sys/unix"
+)
+
+type fileLock struct {
+	path string
+
+	m *sync.Mutex
+	f *os.File
+}
+
+// NewFileLock returns a lock shared between processes using flock on the lock file in dir.
+func NewFileLock(dir string) Locker {
+	return &fileLock{
+		path: filepath.Join(dir, cacheLockFileName),
+		m:    &sync.Mutex{},
+	}
+}
+
+func (l *fileLock) Lock() error {
+	l.m.Lock()
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		l.m.Unlock()
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR, 0o600)
+	if err != nil {
+		l.m.Unlock()
+		return err
+	}
+
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_EX); err != nil {
+		_ = f.Close()
+		l.m.Unlock()
+		return err
+	}
+
+	l.f = f
+	return nil
+}
+
+func (l *fileLock) Unlock() error {
+	defer l.m.Unlock()
+
+	f := l.f
+	l.f = nil
+
+	if err := unix.Flock(int(f.Fd()), unix.LOCK_UN); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
@@ -0,0 +1,172 @@
+package kittyimg
+
+import (
+	"fmt"
+	"image"
+	"io"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gen2brain/avif"
+	awebp "github.com/gen2brain/webp"
+	"github.com/rs/zerolog/log"
+	xdraw "golang.org/x/image/draw"
+)
+
+// rasterEncoder turns a decoded image into text that can be embedded into the chat.
+// width and height are the target size in pixels, cols the number of cells the image spans.
+type rasterEncoder interface {
+	encode(img image.Image, cols, width, height int) (string, error)
+}
+
+type rasterImage struct {
+	replacement string
+	lastUsed    time.Time
+}
+
+// rasterDisplayManager displays images for protocols that transmit the pixels inline
+// with the text (sixel, iTerm2, half blocks). Animations are not supported, only the
+// first frame is shown. Encoded images are kept in memory for the session.
+type rasterDisplayManager struct {
+	protocol              Protocol
+	encoder               rasterEncoder
+	cellWidth, cellHeight float32
+
+	m      *sync.Mutex
+	images map[string]rasterImage
+}
+
+func newRasterDisplayManager(protocol Protocol, encoder rasterEncoder, cellWidth, cellHeight float32) *rasterDisplayManager {
+	return &rasterDisplayManager{
+		protocol:   protocol,
+		encoder:    encoder,
+		cellWidth:  cellWidth,
+		cellHeight: cellHeight,
+		m:          &sync.Mutex{},
+		images:     map[string]rasterImage{},
+	}
+}
+
+func (d *rasterDisplayManager) Protocol() Protocol {
+	return d.protocol
+}
+
+func (d *rasterDisplayManager) Convert(unit DisplayUnit) (KittyDisplayUnit, error) {
+	d.m.Lock()
+	cached, ok := d.images[unit.ID]
+	if ok {
+		cached.lastUsed = time.Now()
+		d.images[unit.ID] = cached
+	}
+	d.m.Unlock()
+
+	if ok {
+		return KittyDisplayUnit{ReplacementText: cached.replacement}, nil
+	}
+
+	body, contentType, err := unit.Load()
+	if err != nil {
+		return KittyDisplayUnit{}, err
+	}
+
+	defer body.Close()
+
+	img, err := decodeFirstFrame(body, contentType)
+	if err != nil {
+		log.Logger.Err(err).Str("id", unit.ID).Str("type", contentType).Msg("failed to decode image")
+		return KittyDisplayUnit{}, err
+	}
+
+	replacement, err := d.render(img, unit.RightPadding)
+	if err != nil {
+		return KittyDisplayUnit{}, err
+	}
+
+	d.m.Lock()
+	d.images[unit.ID] = rasterImage{
+		replacement: replacement,
+		lastUsed:    time.Now(),
+	}
+	d.m.Unlock()
+
+	return KittyDisplayUnit{ReplacementText: replacement}, nil
+}
+
+func (d *rasterDisplayManager) render(img image.Image, rightPadding int) (string, error) {
+	if rightPadding > 0 {
+		img = addRightPadding(img, rightPadding)
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dx() == 0 || bounds.Dy() == 0 {
+		return "", fmt.Errorf("image has no pixels")
+	}
+
+	ratio := d.cellHeight / float32(bounds.Dy())
+	width := max(int(math.Round(float64(float32(bounds.Dx())*ratio))), 1)
+	height := max(int(math.Round(float64(d.cellHeight))), 1)
+	cols := max(int(math.Ceil(float64(float32(width)/d.cellWidth))), 1)
+
+	return d.encoder.encode(img, cols, width, height)
+}
+
+// CleanupOldImagesCommand forgets images not used for maxAge.
+// Inline images hold no state in the terminal, so no command is returned.
+func (d *rasterDisplayManager) CleanupOldImagesCommand(maxAge time.Duration) string {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	for id, img := range d.images {
+		if time.Since(img.lastUsed) > maxAge {
+			delete(d.images, id)
+		}
+	}
+
+	return ""
+}
+
+func (d *rasterDisplayManager) CleanupAllImagesCommand() string {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	clear(d.images)
+
+	return ""
+}
+
+// Close does nothing, raster images are only kept in memory.
+func (d *rasterDisplayManager) Close() error {
+	return nil
+}
+
+func decodeFirstFrame(r io.Reader, contentType string) (image.Image, error) {
+	switch contentType {
+	case "image/avif":
+		return avif.Decode(r)
+	case "image/webp":
+		// handles animated webp as well, which the x/image decoder does not
+		return awebp.Decode(r)
+	}
+
+	img, format, err := image.Decode(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s: %w", format, err)
+	}
+
+	return img, nil
+}
+
+func scaleImage(img image.Image, width, height int) *image.NRGBA {
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	xdraw.BiLinear.Scale(dst, dst.Bounds(), img, img.Bounds(), xdraw.Src, nil)
+	return dst
+}
+
+// inlineImage reserves cols cells for the image and draws seq over them.
+// The renderer attaches unknown escape sequences to the following cell, so the
+// cursor is moved back over the reserved cells before drawing and restored afterwards.
+func inlineImage(seq string, cols int) string {
+	return strings.Repeat(" ", cols) + "\x1b7" + fmt.Sprintf("\x1b[%dD", cols) + seq + "\x1b8"
+}
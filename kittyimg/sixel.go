@@ -0,0 +1,131 @@
+package kittyimg
+
+import (
+	"fmt"
+	"image"
+	"strings"
+)
+
+// sixelEncoder encodes images as DEC sixel graphics.
+// Colors are mapped onto the 216 color web safe palette, pixels with less than
+// half opacity are left transparent.
+type sixelEncoder struct{}
+
+func (sixelEncoder) encode(img image.Image, cols, width, height int) (string, error) {
+	scaled := scaleImage(img, width, height)
+
+	// palette index for each pixel, -1 if transparent
+	indices := make([]int, width*height)
+	var used [216]bool
+
+	for y := range height {
+		for x := range width {
+			off := scaled.PixOffset(x, y)
+			p := scaled.Pix[off : off+4 : off+4]
+
+			if p[3] < 128 {
+				indices[y*width+x] = -1
+				continue
+			}
+
+			c := sixelPaletteIndex(p[0], p[1], p[2])
+			indices[y*width+x] = c
+			used[c] = true
+		}
+	}
+
+	var b strings.Builder
+
+	// P2=1 keeps pixels not drawn transparent
+	b.WriteString("\x1bP0;1;0q")
+	fmt.Fprintf(&b, "\"1;1;%d;%d", width, height)
+
+	for c, isUsed := range used {
+		if !isUsed {
+			continue
+		}
+
+		r, g, bl := sixelPaletteColor(c)
+		fmt.Fprintf(&b, "#%d;2;%d;%d;%d", c, r, g, bl)
+	}
+
+	row := make([]byte, width)
+	for band := 0; band < height; band += 6 {
+		if band > 0 {
+			// graphics new line, move to the next band
+			b.WriteByte('-')
+		}
+
+		first := true
+
+		for c, isUsed := range used {
+			if !isUsed {
+				continue
+			}
+
+			var hasPixels bool
+			for x := range width {
+				var bits byte
+				for dy := 0; dy < 6 && band+dy < height; dy++ {
+					if indices[(band+dy)*width+x] == c {
+						bits |= 1 << dy
+					}
+				}
+
+				row[x] = '?' + bits
+				hasPixels = hasPixels || bits != 0
+			}
+
+			if !hasPixels {
+				continue
+			}
+
+			if !first {
+				// graphics carriage return, draw the next color over the same band
+				b.WriteByte('$')
+			}
+			first = false
+
+			fmt.Fprintf(&b, "#%d", c)
+			writeSixelRow(&b, row)
+		}
+	}
+
+	b.WriteString("\x1b\\")
+
+	return inlineImage(b.String(), cols), nil
+}
+
+// writeSixelRow writes row using the sixel repeat introducer for runs.
+// Trailing empty sixels are omitted.
+func writeSixelRow(b *strings.Builder, row []byte) {
+	row = []byte(strings.TrimRight(string(row), "?"))
+
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+
+		if n := j - i; n > 3 {
+			fmt.Fprintf(b, "!%d%c", n, row[i])
+		} else {
+			b.Write(row[i:j])
+		}
+
+		i = j
+	}
+}
+
+func sixelPaletteIndex(r, g, b byte) int {
+	q := func(v byte) int {
+		return (int(v) + 25) / 51
+	}
+
+	return q(r)*36 + q(g)*6 + q(b)
+}
+
+// sixelPaletteColor returns the color for a palette index in percent, as used by sixel color definitions.
+func sixelPaletteColor(c int) (int, int, int) {
+	return (c / 36) * 20, (c / 6 % 6) * 20, (c % 6) * 20
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/server"
+	"github.com/julez-dev/chatuino/twitch/twitchapi"
+	"github.com/julez-dev/chatuino/twitch/twitchauth"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v3"
+)
+
+var errNoChatuinoServer = errors.New("not available in local mode, use --embedded-server")
+
+// errLocalModeClientID is returned when local mode would use the client ID of the hosted Chatuino application. Twitch
+// only refreshes tokens without the client secret for public clients, so local mode needs an own public application.
+var errLocalModeClientID = errors.New("local mode needs the client ID of your own Twitch application with the client type public, set --client-id or CHATUINO_CLIENT_ID and add your accounts with the device login using it")
+
+// localServer stands in for the Chatuino server in local mode. The Twitch API is called with the user token of the
+// main account and tokens are refreshed with Twitch directly, which only works for accounts added with the device login.
+type localServer struct {
+	*twitchapi.API
+	auth    *twitchauth.Client
+	version string
+}
+
+func (s localServer) RefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
+	return s.auth.RefreshToken(ctx, refreshToken)
+}
+
+// checkLocalModeAccounts fails unless all accounts were added with the device login. The tokens of the browser login
+// and pasted tokens belong to the Chatuino server's application, Twitch only refreshes them with its client secret.
+func checkLocalModeAccounts(accounts []save.Account) error {
+	var (
+		others []string
+		found  bool
+	)
+
+	for _, acc := range accounts {
+		if acc.IsAnonymous {
+			continue
+		}
+
+		found = true
+		if acc.AuthMethod != save.AuthMethodDevice {
+			others = append(others, acc.DisplayName)
+		}
+	}
+
+	if !found {
+		return errors.New("local mode needs an account added with the device login in 'chatuino account', or use --embedded-server")
+	}
+
+	if len(others) > 0 {
+		return fmt.Errorf("local mode only works with accounts added with the device login, authorize %s again with the device login in 'chatuino account', or use --embedded-server", strings.Join(others, ", "))
+	}
+
+	return nil
+}
+
+// GetLinkPreview fails, fetching links from the own IP would reveal it to whoever posted them.
+func (s localServer) GetLinkPreview(context.Context, string) (server.LinkPreview, error) {
+	return server.LinkPreview{}, errNoChatuinoServer
+}
+
+// GetLatestVersion reports the running version, the update check needs the Chatuino server.
+func (s localServer) GetLatestVersion(context.Context) (string, error) {
+	return s.version, nil
+}
+
+// startEmbeddedServer runs the Chatuino server in-process on a loopback address and returns its URL.
+// It needs the client secret, so it only works with an own Twitch application.
+func startEmbeddedServer(ctx context.Context, command *cli.Command) (string, error) {
+	secret := command.String("client-secret")
+	if secret == "" {
+		return "", errors.New("the embedded server needs the client secret of your Twitch application, set --client-secret or CHATUINO_CLIENT_SECRET")
+	}
+
+	addr := command.String("embedded-server-addr")
+	if ap, err := netip.ParseAddrPort(addr); err != nil || !ap.Addr().IsLoopback() {
+		return "", fmt.Errorf("embedded server address %q has to be a loopback address like 127.0.0.1:8080", addr)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to listen for the embedded server: %w", err)
+	}
+
+	host := "http://" + ln.Addr().String()
+
+	api := server.New(log.Logger, server.Config{
+		HostAndPort:  ln.Addr().String(),
+		ClientID:     command.String("client-id"),
+		ClientSecret: secret,
+		RedirectURL:  host + "/auth/redirect",
+		HelixCache:   server.HelixCacheMemory,
+		Version:      Version,
+	}, http.DefaultClient)
+
+	go func() {
+		if err := api.Serve(ctx, ln); err != nil {
+			log.Logger.Err(err).Msg("embedded server stopped")
+		}
+	}()
+
+	log.Logger.Info().Str("host", host).Msg("started embedded server")
+
+	return host, nil
+}
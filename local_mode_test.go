@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/julez-dev/chatuino/save"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func TestStartEmbeddedServer(t *testing.T) {
+	t.Parallel()
+
+	run := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+
+		var (
+			host   string
+			runErr error
+		)
+
+		cmd := &cli.Command{
+			Name: "chatuino",
+			Flags: []cli.Flag{
+				&cli.StringFlag{Name: "client-id", Value: "test-client-id"},
+				&cli.StringFlag{Name: "client-secret"},
+				&cli.StringFlag{Name: "embedded-server-addr", Value: "127.0.0.1:0"},
+			},
+			Action: func(ctx context.Context, command *cli.Command) error {
+				host, runErr = startEmbeddedServer(t.Context(), command)
+				return nil
+			},
+		}
+
+		require.NoError(t, cmd.Run(context.Background(), append([]string{"chatuino"}, args...)))
+		return host, runErr
+	}
+
+	t.Run("needs the client secret", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := run(t)
+		require.ErrorContains(t, err, "client secret")
+	})
+
+	t.Run("only listens on loopback", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := run(t, "--client-secret", "secret", "--embedded-server-addr", "0.0.0.0:8080")
+		require.ErrorContains(t, err, "loopback")
+	})
+
+	t.Run("serves the API", func(t *testing.T) {
+		t.Parallel()
+
+		host, err := run(t, "--client-secret", "secret")
+		require.NoError(t, err)
+
+		resp, err := http.Get(host + "/internal/health")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestCheckLocalModeAccounts(t *testing.T) {
+	t.Parallel()
+
+	anonymous := save.Account{IsAnonymous: true, DisplayName: "justinfan123123"}
+	device := save.Account{DisplayName: "Device", AuthMethod: save.AuthMethodDevice}
+
+	require.NoError(t, checkLocalModeAccounts([]save.Account{anonymous, device}))
+	require.ErrorContains(t, checkLocalModeAccounts([]save.Account{anonymous}), "needs an account added with the device login")
+
+	err := checkLocalModeAccounts([]save.Account{
+		device,
+		{DisplayName: "Browser", AuthMethod: save.AuthMethodBrowser},
+		{DisplayName: "Old"},
+	})
+	require.ErrorContains(t, err, "authorize Browser, Old again with the device login")
+}
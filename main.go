@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"net/mail"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -22,11 +24,11 @@ import (
 	"github.com/julez-dev/chatuino/twitch/ffz"
 	"github.com/julez-dev/chatuino/twitch/recentmessage"
 	"github.com/julez-dev/chatuino/twitch/twitchapi"
+	"github.com/julez-dev/chatuino/twitch/twitchauth"
 	"github.com/julez-dev/chatuino/twitch/twitchirc"
 	"github.com/julez-dev/chatuino/wspool"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/afero"
-	"github.com/zalando/go-keyring"
 
 	tea "charm.land/bubbletea/v2"
 	"github.com/cli/browser"
@@ -34,6 +36,7 @@ import (
 	"github.com/julez-dev/chatuino/emote"
 	"github.com/julez-dev/chatuino/save"
 	"github.com/julez-dev/chatuino/server"
+	"github.com/julez-dev/chatuino/spellcheck"
 	"github.com/julez-dev/chatuino/twitch/seventv"
 	"github.com/julez-dev/chatuino/ui/mainui"
 	_ "github.com/mailru/easyjson"
@@ -95,6 +98,7 @@ chatuino --tab notification --tab mention --tab user1@streamer1`,
 			serverCMD,
 			cacheCMD,
 			contributorsCMD,
+			scriptCMD,
 		},
 		Flags: []cli.Flag{
 			&cli.StringFlag{
@@ -109,6 +113,27 @@ chatuino --tab notification --tab mention --tab user1@streamer1`,
 				Value:   "https://chatuino.net",
 				Sources: cli.EnvVars("CHATUINO_API_HOST"),
 			},
+			&cli.StringFlag{
+				Name:    "client-secret",
+				Usage:   "OAuth Client-Secret of your Twitch application, only needed for --embedded-server",
+				Sources: cli.EnvVars("CHATUINO_CLIENT_SECRET"),
+			},
+			&cli.BoolFlag{
+				Name:    "local",
+				Usage:   "Don't use the Chatuino server. The Twitch API is called with the token of your main account, which has to be added with the device login. Needs --client-id of your own public Twitch application",
+				Sources: cli.EnvVars("CHATUINO_LOCAL"),
+			},
+			&cli.BoolFlag{
+				Name:    "embedded-server",
+				Usage:   "Run the Chatuino server inside Chatuino on a loopback address instead of using --api-host. Needs --client-secret",
+				Sources: cli.EnvVars("CHATUINO_EMBEDDED_SERVER"),
+			},
+			&cli.StringFlag{
+				Name:    "embedded-server-addr",
+				Usage:   "Loopback address of the embedded server, a fixed port is needed for the browser login",
+				Value:   "127.0.0.1:0",
+				Sources: cli.EnvVars("CHATUINO_EMBEDDED_SERVER_ADDR"),
+			},
 			&cli.StringSliceFlag{
 				Name:  "tab",
 				Usage: "Tab to open on startup. Can be a channel name, user@channel, \"notification\", or \"mention\". Repeatable. When set, state is not loaded from or saved to disk.",
@@ -141,6 +166,17 @@ chatuino --tab notification --tab mention --tab user1@streamer1`,
 				Value:   false,
 				Sources: cli.EnvVars("CHATUINO_PLAIN_AUTH_STORAGE"),
 			},
+			&cli.BoolFlag{
+				Name:    "encrypted-auth-storage",
+				Usage:   "If your twitch authentication tokens should be stored in a file encrypted with a passphrase. The passphrase is read from --auth-passphrase-fd, " + authPassphraseEnv + " or prompted.",
+				Value:   false,
+				Sources: cli.EnvVars("CHATUINO_ENCRYPTED_AUTH_STORAGE"),
+			},
+			&cli.IntFlag{
+				Name:  "auth-passphrase-fd",
+				Usage: "File descriptor to read the passphrase of the encrypted auth storage from, e.g. 3 together with 3<passphrase-file",
+				Value: -1,
+			},
 		},
 		Before: beforeAction,
 		Action: func(ctx context.Context, command *cli.Command) error {
@@ -163,16 +199,53 @@ chatuino --tab notification --tab mention --tab user1@streamer1`,
 				return fmt.Errorf("failed to read keymap file: %w", err)
 			}
 
-			var keyringBackend keyring.Keyring
+			keyringBackend, err := newAuthStorage(command)
+			if err != nil {
+				return err
+			}
 
-			if command.Bool("plain-auth-storage") {
-				keyringBackend = save.NewPlainKeyringFallback(afero.NewOsFs())
-			} else {
-				keyringBackend = save.NewKeyringWrapper()
+			apiHost := command.String("api-host")
+			if command.Bool("embedded-server") {
+				apiHost, err = startEmbeddedServer(ctx, command)
+				if err != nil {
+					return err
+				}
+			}
+
+			// with the embedded server nothing goes to the hosted server either, it is used like any other server
+			localMode := command.Bool("local") && !command.Bool("embedded-server")
+			if localMode && settings.Security.CheckLinks {
+				log.Logger.Info().Msg("link checks need the Chatuino server, disabled in local mode")
+				settings.Security.CheckLinks = false
 			}
 
 			accountProvider := save.NewAccountProvider(keyringBackend)
-			serverAPI := server.NewClient(command.String("api-host"), http.DefaultClient)
+			serverAPI := server.NewClient(apiHost, http.DefaultClient)
+
+			var (
+				chatuinoServer mainui.ChatuinoServer    = serverAPI
+				tokenRefresher twitchapi.TokenRefresher = serverAPI
+				authClient     *twitchauth.Client
+			)
+
+			if localMode {
+				if !command.IsSet("client-id") {
+					return errLocalModeClientID
+				}
+
+				accounts, err := accountProvider.GetAllAccounts()
+				if err != nil {
+					return fmt.Errorf("failed to open accounts: %w", err)
+				}
+
+				if err := checkLocalModeAccounts(accounts); err != nil {
+					return err
+				}
+
+				authClient = twitchauth.NewClient(command.String("client-id"), http.DefaultClient)
+				tokenRefresher = authClient
+			}
+
 			stvAPI := seventv.NewAPI(http.DefaultClient)
 			bttvAPI := bttv.NewAPI(http.DefaultClient)
 			ffzAPI := ffz.NewAPI(http.DefaultClient)
@@ -182,6 +255,8 @@ chatuino --tab notification --tab mention --tab user1@streamer1`,
 			badgeCache := badge.NewCache(serverAPI)
 			appStateManager := save.NewAppStateManager(afero.NewOsFs())
 			channelHistoryManager := save.NewChannelHistoryManager(afero.NewOsFs())
+			emoteUsageManager := save.NewEmoteUsageManager(afero.NewOsFs())
+			inputHistoryManager := save.NewInputHistoryManager(afero.NewOsFs())
 
 			// message logger setup
 			db, err := openDB(false)
@@ -215,35 +290,64 @@ chatuino --tab notification --tab mention --tab user1@streamer1`,
 
 			go runChatLogger(messageLogger, messageLoggerChan, loggerWaitSync, settings.Moderation.StoreChatLogs)
 
+			// chat entries evicted from memory are spilled to a temporary db owned by this process,
+			// the message db is shared with other running Chatuino instances
+			scrollbackDB, cleanupScrollbackDB, err := openTempScrollbackDB()
+			if err != nil {
+				return fmt.Errorf("failed to open temporary scrollback db: %w", err)
+			}
+			defer cleanupScrollbackDB()
+
+			scrollback := messagelog.NewScrollback(scrollbackDB)
+			if err := scrollback.PrepareDatabase(); err != nil {
+				return fmt.Errorf("failed to migrate scrollback db: %w", err)
+			}
+
+			var spellChecker mainui.SpellChecker
+			if settings.Chat.SpellCheckDictionary != "" {
+				dictionary, err := spellcheck.Load(afero.NewOsFs(), settings.Chat.SpellCheckDictionary)
+				if err != nil {
+					return fmt.Errorf("failed to load spell check dictionary: %w", err)
+				}
+
+				log.Logger.Info().Int("words", dictionary.Len()).Str("path", settings.Chat.SpellCheckDictionary).Msg("loaded spell check dictionary")
+				spellChecker = dictionary
+			}
+
 			// If the user has provided an account we can use the users local authentication
 			// Instead of using Chatuino's server to handle requests for emote/badge fetching.
 			clients := make(map[string]mainui.APIClient)
 			if mainAccount, err := accountProvider.GetMainAccount(); err == nil {
-				ttvAPI, err := twitchapi.NewAPI(command.String("client-id"), twitchapi.WithUserAuthentication(accountProvider, serverAPI, mainAccount.ID))
+				ttvAPI, err := twitchapi.NewAPI(command.String("client-id"), twitchapi.WithUserAuthentication(accountProvider, tokenRefresher, mainAccount.ID))
 				if err == nil {
 					clients[mainAccount.ID] = ttvAPI
 					emoteCache = emote.NewCache(log.Logger, ttvAPI, stvAPI, bttvAPI, ffzAPI)
 					badgeCache = badge.NewCache(ttvAPI)
+
+					if localMode {
+						chatuinoServer = localServer{API: ttvAPI, auth: authClient, version: Version}
+					}
 				}
 			}
 
+			// anonymous accounts and users without accounts need the server's app access token
+			if _, ok := chatuinoServer.(localServer); localMode && !ok {
+				return errors.New("local mode needs a main account, mark one in 'chatuino account', or use --embedded-server")
+			}
+
 			var (
 				emoteReplacer  = emote.NewReplacer(http.DefaultClient, emoteCache, false, theme, nil)
 				badgeReplacer  = badge.NewReplacer(http.DefaultClient, badgeCache, false, theme, nil)
-				displayManager *kittyimg.DisplayManager
+				displayManager kittyimg.Backend
 			)
 
 			if settings.Chat.GraphicEmotes || settings.Chat.GraphicBadges {
-				if !hasImageSupport() {
-					return fmt.Errorf("graphical image support enabled but not available for this platform (unix & kitty terminal only)")
-				}
-
-				cellWidth, cellHeight, err := getTermCellWidthHeight()
+				displayManager, err = setupGraphicsBackend(settings.Chat)
 				if err != nil {
-					return fmt.Errorf("failed to get terminal size: %w", err)
+					return err
 				}
 
-				displayManager = kittyimg.NewDisplayManager(afero.NewOsFs(), cellWidth, cellHeight)
+				log.Logger.Info().Stringer("protocol", displayManager.Protocol()).Msg("using graphics protocol")
 
 				if settings.Chat.GraphicEmotes {
 					emoteReplacer = emote.NewReplacer(http.DefaultClient, emoteCache, true, theme, displayManager)
@@ -255,6 +359,10 @@ chatuino --tab notification --tab mention --tab user1@streamer1`,
 
 				defer func() {
 					io.WriteString(os.Stdout, displayManager.CleanupAllImagesCommand())
+
+					if err := displayManager.Close(); err != nil {
+						log.Logger.Err(err).Msg("failed to close graphics backend")
+					}
 				}()
 			}
 
@@ -266,7 +374,7 @@ chatuino --tab notification --tab mention --tab user1@streamer1`,
 				Version:              Version,
 				AppStateManager:      appStateManager,
 				Keymap:               keymap,
-				ServerAPI:            serverAPI,
+				ServerAPI:            chatuinoServer,
 				AccountProvider:      accountProvider,
 				EmoteCache:           emoteCache,
 				BadgeCache:           badgeCache,
@@ -275,9 +383,25 @@ chatuino --tab notification --tab mention --tab user1@streamer1`,
 				ImageDisplayManager:  displayManager,
 				RecentMessageService: recentMessageService,
 				MessageLogger:        messageLogger,
+				Scrollback:           scrollback,
 				Pool:                 pool,
 				APIUserClients:       clients,
 				ChannelHistory:       channelHistoryManager,
+				EmoteUsage:           emoteUsageManager,
+				BlockRules:           save.NewBlockRuleStore(afero.NewOsFs()),
+				InputHistory:         inputHistoryManager,
+				SpellChecker:         spellChecker,
+			}
+
+			if !settings.Scripts.Disabled {
+				scripts, err := loadScripts(settings.Scripts)
+				if err != nil {
+					return fmt.Errorf("failed to load scripts: %w", err)
+				}
+
+				if scripts.Len() > 0 {
+					deps.Scripts = scripts
+				}
 			}
 
 			// Fetch all Accounts
@@ -287,7 +411,7 @@ chatuino --tab notification --tab mention --tab user1@streamer1`,
 			}
 
 			// Migrate accounts that are missing LoginName (pre-existing installs).
-			if err := migrateAccountLoginNames(ctx, accounts, accountProvider, serverAPI); err != nil {
+			if err := migrateAccountLoginNames(ctx, accounts, accountProvider, chatuinoServer); err != nil {
 				log.Logger.Err(err).Msg("failed to migrate account login names")
 			}
 
@@ -305,12 +429,12 @@ chatuino --tab notification --tab mention --tab user1@streamer1`,
 				var api mainui.APIClient
 
 				if !acc.IsAnonymous {
-					api, err = twitchapi.NewAPI(command.String("client-id"), twitchapi.WithUserAuthentication(accountProvider, serverAPI, acc.ID))
+					api, err = twitchapi.NewAPI(command.String("client-id"), twitchapi.WithUserAuthentication(accountProvider, tokenRefresher, acc.ID))
 					if err != nil {
 						return fmt.Errorf("failed to build api client for %s: %w", acc.DisplayName, err)
 					}
 				} else {
-					api = serverAPI
+					api = chatuinoServer
 				}
 
 				clients[acc.ID] = api
@@ -361,6 +485,12 @@ chatuino --tab notification --tab mention --tab user1@streamer1`,
 						return fmt.Errorf("error while saving state: %w", err)
 					}
 				}
+
+				if history := final.InputHistorySnapshot(); history != nil {
+					if err := inputHistoryManager.SaveInputHistory(history); err != nil {
+						return fmt.Errorf("error while saving input history: %w", err)
+					}
+				}
 			}
 
 			close(messageLoggerChan)
@@ -379,6 +509,37 @@ chatuino --tab notification --tab mention --tab user1@streamer1`,
 	}
 }
 
+// setupGraphicsBackend creates the image backend for the configured protocol.
+// When no protocol is configured the protocol is detected based on the terminal.
+func setupGraphicsBackend(settings save.ChatSettings) (kittyimg.Backend, error) {
+	protocol, err := kittyimg.ParseProtocol(settings.GraphicsProtocol)
+	if err != nil {
+		return nil, err
+	}
+
+	if protocol == kittyimg.ProtocolNone {
+		protocol = kittyimg.DetectProtocol(os.LookupEnv)
+	}
+
+	if protocol == kittyimg.ProtocolNone {
+		return nil, fmt.Errorf("graphical image support enabled but no supported graphics protocol detected for this terminal, set chat.graphics_protocol to override")
+	}
+
+	cellWidth, cellHeight, err := getTermCellWidthHeight()
+	if err != nil && protocol != kittyimg.ProtocolHalfBlock {
+		return nil, fmt.Errorf("failed to get terminal size: %w", err)
+	}
+
+	index := newImageCacheIndex(settings.ImageCacheBudgetBytes())
+
+	backend, err := kittyimg.NewBackend(protocol, afero.NewOsFs(), cellWidth, cellHeight, index)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup %s graphics: %w", protocol, err)
+	}
+
+	return backend, nil
+}
+
 func openDB(readonly bool) (*sql.DB, error) {
 	var (
 		db  *sql.DB
@@ -401,6 +562,34 @@ func openDB(readonly bool) (*sql.DB, error) {
 	return db, nil
 }
 
+// openTempScrollbackDB opens a sqlite db in a temporary directory which is removed by the returned cleanup function.
+func openTempScrollbackDB() (*sql.DB, func(), error) {
+	dir, err := os.MkdirTemp("", "chatuino-scrollback-")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(dir, "scrollback.db")+"?_time_format=sqlite")
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+
+	db.SetMaxOpenConns(1)
+
+	cleanup := func() {
+		if err := db.Close(); err != nil {
+			log.Logger.Err(err).Msg("failed to close scrollback db connection")
+		}
+
+		if err := os.RemoveAll(dir); err != nil {
+			log.Logger.Err(err).Msg("failed to remove scrollback db")
+		}
+	}
+
+	return db, cleanup, nil
+}
+
 func runChatLogger(messageLogger *messagelog.BatchedMessageLogger, messageLoggerChan chan *twitchirc.PrivateMessage, loggerWaitSync chan struct{}, enabled bool) {
 	defer func() {
 		for range messageLoggerChan {
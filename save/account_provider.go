@@ -15,7 +15,10 @@ const (
 	keychainAccount = "account-save"
 )
 
-var ErrAccountNotFound = errors.New("account not found")
+var (
+	ErrAccountNotFound = errors.New("account not found")
+	ErrAccountsExist   = errors.New("the target storage already holds accounts")
+)
 
 var anonymousAccount = Account{
 	ID:          "anonymous-account",
@@ -27,15 +30,27 @@ var anonymousAccount = Account{
 	CreatedAt:   time.Now(),
 }
 
+// AuthMethod is how the tokens of an account were obtained. It decides who can refresh them: tokens of the device
+// login belong to a public client and are refreshed with Twitch directly, the others need the Chatuino server.
+type AuthMethod string
+
+const (
+	AuthMethodUnknown AuthMethod = "" // accounts created before the method was recorded
+	AuthMethodDevice  AuthMethod = "device"
+	AuthMethodBrowser AuthMethod = "browser"
+	AuthMethodPasted  AuthMethod = "pasted"
+)
+
 type Account struct {
-	ID           string    `json:"id"`
-	IsMain       bool      `json:"is_main"`
-	IsAnonymous  bool      `json:"-"`
-	LoginName    string    `json:"login_name"`
-	DisplayName  string    `json:"display_name"`
-	AccessToken  string    `json:"access_token"`
-	RefreshToken string    `json:"refresh_token"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID           string     `json:"id"`
+	IsMain       bool       `json:"is_main"`
+	IsAnonymous  bool       `json:"-"`
+	LoginName    string     `json:"login_name"`
+	DisplayName  string     `json:"display_name"`
+	AccessToken  string     `json:"access_token"`
+	RefreshToken string     `json:"refresh_token"`
+	AuthMethod   AuthMethod `json:"auth_method,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
 }
 
 type accountFile struct {
@@ -171,6 +186,23 @@ func (a AccountProvider) UpdateTokensFor(id, accessToken, refreshToken string) e
 	return nil
 }
 
+// UpdateAuthMethodFor records how the tokens of the account were obtained, after it was authorized again.
+func (a AccountProvider) UpdateAuthMethodFor(id string, method AuthMethod) error {
+	accounts, err := a.loadAccounts()
+	if err != nil {
+		return err
+	}
+
+	i := slices.IndexFunc(accounts, func(a Account) bool { return a.ID == id })
+	if i == -1 {
+		return ErrAccountNotFound
+	}
+
+	accounts[i].AuthMethod = method
+
+	return a.saveAccounts(accounts)
+}
+
 func (a AccountProvider) UpdateLoginNameFor(id, loginName string) error {
 	accounts, err := a.loadAccounts()
 	if err != nil {
@@ -265,3 +297,48 @@ func (a AccountProvider) saveAccounts(accounts []Account) error {
 
 	return nil
 }
+
+// MigrateAccounts moves the saved accounts from one keyring backend to another.
+// The accounts are removed from the source after they were written to the target, a target already holding
+// accounts is not overwritten. It returns false if the source holds no accounts.
+func MigrateAccounts(from, to keyring.Keyring) (bool, error) {
+	data, err := from.Get(keychainService, keychainAccount)
+	if err != nil {
+		if errors.Is(err, keyring.ErrNotFound) {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to read accounts: %w", err)
+	}
+
+	if data == "" {
+		return false, nil
+	}
+
+	var fileData accountFile
+	if err := json.Unmarshal([]byte(data), &fileData); err != nil {
+		return false, fmt.Errorf("failed to parse accounts: %w", err)
+	}
+
+	existing, err := to.Get(keychainService, keychainAccount)
+	if err != nil && !errors.Is(err, keyring.ErrNotFound) {
+		return false, fmt.Errorf("failed to read target storage: %w", err)
+	}
+
+	if existing != "" {
+		var existingData accountFile
+		if err := json.Unmarshal([]byte(existing), &existingData); err != nil || len(existingData.Accounts) > 0 {
+			return false, ErrAccountsExist
+		}
+	}
+
+	if err := to.Set(keychainService, keychainAccount, data); err != nil {
+		return false, fmt.Errorf("failed to write accounts: %w", err)
+	}
+
+	if err := from.Delete(keychainService, keychainAccount); err != nil {
+		return true, fmt.Errorf("accounts were copied but could not be removed from the old storage: %w", err)
+	}
+
+	return true, nil
+}
@@ -15,10 +15,18 @@ import (
 const (
 	chatuinoConfigDir = "chatuino"
 	stateFileName     = "state.json"
+	scriptsDirName    = "scripts"
 )
 
 type AppState struct {
-	Tabs []TabState `json:"tabs"`
+	Tabs  []TabState  `json:"tabs"`
+	Split *SplitState `json:"split,omitempty"`
+}
+
+// SplitState is the split view layout, Tabs holds the indices of the tabs shown in the split.
+type SplitState struct {
+	Vertical bool  `json:"vertical"`
+	Tabs     []int `json:"tabs"`
 }
 
 type TabState struct {
@@ -28,6 +36,7 @@ type TabState struct {
 	IsFocused     bool   `json:"is_focused"`
 	IdentityID    string `json:"identity_id"`
 	Kind          int    `json:"kind"`
+	Filter        string `json:"filter,omitempty"` // persistent message filter of combined chat tabs
 }
 
 type AppStateManager struct {
@@ -127,6 +136,101 @@ func openCreateConfigFile(fs afero.Fs, file string) (afero.File, error) {
 	return openCreateFile(fs, configDir, file)
 }
 
+func readConfigFile(fs afero.Fs, file string) ([]byte, error) {
+	f, err := openCreateConfigFile(fs, file)
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+func writeConfigFile(fs afero.Fs, file string, data []byte) error {
+	f, err := openCreateConfigFile(fs, file)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	_, err = f.Write(data)
+	return err
+}
+
+// replaceConfigFile writes data to a temporary file next to file and renames it over file once it is synced,
+// so a crash while writing leaves the previous content intact.
+func replaceConfigFile(fs afero.Fs, file string, data []byte) error {
+	tmp, err := openCreateConfigFile(fs, file+".tmp")
+	if err != nil {
+		return err
+	}
+
+	if err := writeSynced(tmp, data); err != nil {
+		_ = fs.Remove(tmp.Name())
+		return err
+	}
+
+	if err := fs.Rename(tmp.Name(), filepath.Join(filepath.Dir(tmp.Name()), file)); err != nil {
+		_ = fs.Remove(tmp.Name())
+		return err
+	}
+
+	return nil
+}
+
+func writeSynced(f afero.File, data []byte) error {
+	defer f.Close()
+
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+
+	if err := f.Sync(); err != nil {
+		return err
+	}
+
+	return f.Close()
+}
+
+// removeConfigFile removes a file of the config directory, a missing file is no error.
+func removeConfigFile(fs afero.Fs, file string) error {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return err
+	}
+
+	err = fs.Remove(filepath.Join(configDir, chatuinoConfigDir, file))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// ScriptsDir returns the directory user scripts are loaded from.
+func ScriptsDir() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, chatuinoConfigDir, scriptsDirName), nil
+}
+
 func openCreateDataFile(fs afero.Fs, file string) (afero.File, error) {
 	return openCreateFile(fs, xdg.DataHome, file)
 }
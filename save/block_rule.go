@@ -0,0 +1,291 @@
+package save
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// Block rule fields.
+const (
+	BlockFieldContent = "content"
+	BlockFieldUser    = "user"
+	BlockFieldBadge   = "badge"
+)
+
+// Block rule match types.
+const (
+	BlockMatchExact    = "exact"
+	BlockMatchContains = "contains"
+	BlockMatchGlob     = "glob"
+	BlockMatchRegex    = "regex"
+)
+
+// BlockRule hides messages where the selected field matches the pattern.
+type BlockRule struct {
+	Pattern  string     `yaml:"pattern"`
+	Field    string     `yaml:"field,omitempty"`    // content, user or badge, default: content
+	Match    string     `yaml:"match,omitempty"`    // exact, contains, glob or regex, default: contains for content, exact otherwise
+	Channels []string   `yaml:"channels,omitempty"` // channel logins the rule applies to, all channels when empty
+	Expires  *time.Time `yaml:"expires,omitempty"`  // the rule is ignored after this time, never expires when unset
+
+	re *regexp.Regexp // compiled glob or regex pattern
+}
+
+// NewBlockRule validates and compiles a rule.
+func NewBlockRule(rule BlockRule) (BlockRule, error) {
+	if err := rule.compile(); err != nil {
+		return BlockRule{}, err
+	}
+
+	return rule, nil
+}
+
+// FieldOrDefault returns the field the rule matches against.
+func (r BlockRule) FieldOrDefault() string {
+	if r.Field == "" {
+		return BlockFieldContent
+	}
+
+	return r.Field
+}
+
+// MatchOrDefault returns how the pattern is matched.
+func (r BlockRule) MatchOrDefault() string {
+	if r.Match != "" {
+		return r.Match
+	}
+
+	if r.FieldOrDefault() == BlockFieldContent {
+		return BlockMatchContains
+	}
+
+	return BlockMatchExact
+}
+
+// Expired reports whether the rule expired at now.
+func (r BlockRule) Expired(now time.Time) bool {
+	return r.Expires != nil && !now.Before(*r.Expires)
+}
+
+// AppliesTo reports whether the rule is active for a message in channel at now.
+func (r BlockRule) AppliesTo(channel string, now time.Time) bool {
+	if r.Expired(now) {
+		return false
+	}
+
+	if len(r.Channels) == 0 {
+		return true
+	}
+
+	channel = strings.TrimPrefix(channel, "#")
+	return slices.ContainsFunc(r.Channels, func(c string) bool {
+		return strings.EqualFold(strings.TrimPrefix(c, "#"), channel)
+	})
+}
+
+// Matches reports whether value matches the pattern of the rule. Exact, contains and glob matches ignore case,
+// regex patterns are case-sensitive unless they start with (?i).
+func (r BlockRule) Matches(value string) bool {
+	switch r.MatchOrDefault() {
+	case BlockMatchExact:
+		return strings.EqualFold(value, r.Pattern)
+	case BlockMatchContains:
+		return strings.Contains(strings.ToLower(value), strings.ToLower(r.Pattern))
+	}
+
+	re := r.re
+	if re == nil {
+		// rule was not created by NewBlockRule or loaded from disk
+		var err error
+		if re, err = r.compilePattern(); err != nil {
+			return false
+		}
+	}
+
+	return re.MatchString(value)
+}
+
+func (r *BlockRule) compile() error {
+	if r.Pattern == "" {
+		return fmt.Errorf("block rule pattern can't be empty")
+	}
+
+	if !slices.Contains([]string{BlockFieldContent, BlockFieldUser, BlockFieldBadge}, r.FieldOrDefault()) {
+		return fmt.Errorf("block rule field %q must be one of content, user or badge", r.Field)
+	}
+
+	if slices.Contains(r.Channels, "") {
+		return fmt.Errorf("block rule channel entry can't be empty string")
+	}
+
+	re, err := r.compilePattern()
+	if err != nil {
+		return err
+	}
+
+	r.re = re
+	return nil
+}
+
+func (r BlockRule) compilePattern() (*regexp.Regexp, error) {
+	switch r.MatchOrDefault() {
+	case BlockMatchExact, BlockMatchContains:
+		return nil, nil
+	case BlockMatchGlob:
+		return regexp.Compile(globToRegex(r.Pattern))
+	case BlockMatchRegex:
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("block rule pattern %q is not a valid regex: %w", r.Pattern, err)
+		}
+
+		return re, nil
+	}
+
+	return nil, fmt.Errorf("block rule match %q must be one of exact, contains, glob or regex", r.Match)
+}
+
+// globToRegex converts a glob, where * matches any text and ? matches a single character,
+// to a case-insensitive regex matching the whole value.
+func globToRegex(glob string) string {
+	var b strings.Builder
+	b.WriteString("(?is)^")
+
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	b.WriteString("$")
+	return b.String()
+}
+
+func (b *BlockSettings) compile() error {
+	for i := range b.Rules {
+		if err := b.Rules[i].compile(); err != nil {
+			return fmt.Errorf("block rule #%d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// BlockRuleStore writes block rules to the settings file.
+type BlockRuleStore struct {
+	mu sync.Mutex
+	fs afero.Fs
+}
+
+func NewBlockRuleStore(fs afero.Fs) *BlockRuleStore {
+	return &BlockRuleStore{fs: fs}
+}
+
+// SaveBlockRules replaces the block rules in the settings file. Other settings and comments are kept.
+func (s *BlockRuleStore) SaveBlockRules(rules []BlockRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := openCreateConfigFile(s.fs, settingsFileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	out, err := setBlockRules(data, rules)
+	if err != nil {
+		return err
+	}
+
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, bytes.NewReader(out))
+	return err
+}
+
+// setBlockRules sets block_settings.rules inside the settings YAML document.
+func setBlockRules(data []byte, rules []BlockRule) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse settings: %w", err)
+	}
+
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("settings must be a YAML mapping")
+	}
+
+	if rules == nil {
+		rules = []BlockRule{}
+	}
+
+	var rulesNode yaml.Node
+	if err := rulesNode.Encode(rules); err != nil {
+		return nil, err
+	}
+
+	blockNode := mappingValue(root, "block_settings")
+	if blockNode.Kind != yaml.MappingNode {
+		// block_settings was empty
+		*blockNode = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+
+	*mappingValue(blockNode, "rules") = rulesNode
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(&doc); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// mappingValue returns the value node of key inside a mapping node, adding the key if missing.
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+
+	return value
+}
@@ -0,0 +1,107 @@
+package save
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestBlockRule_Matches(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		rule  BlockRule
+		value string
+		want  bool
+	}{
+		{BlockRule{Pattern: "Spam"}, "buy spam now", true},
+		{BlockRule{Pattern: "spam", Match: BlockMatchExact}, "buy spam now", false},
+		{BlockRule{Pattern: "Bot", Field: BlockFieldUser}, "bot", true},
+		{BlockRule{Pattern: "Bot", Field: BlockFieldUser}, "bot123", false},
+		{BlockRule{Pattern: "*bot?", Match: BlockMatchGlob}, "SPAMBOT1", true},
+		{BlockRule{Pattern: "*bot?", Match: BlockMatchGlob}, "spambot12", false},
+		{BlockRule{Pattern: "a.b", Match: BlockMatchGlob}, "axb", false},
+		{BlockRule{Pattern: `^bot\d+$`, Match: BlockMatchRegex}, "bot42", true},
+		{BlockRule{Pattern: `^bot\d+$`, Match: BlockMatchRegex}, "BOT42", false},
+		{BlockRule{Pattern: `(?i)^bot\d+$`, Match: BlockMatchRegex}, "BOT42", true},
+	}
+
+	for _, c := range cases {
+		rule, err := NewBlockRule(c.rule)
+		require.NoError(t, err)
+		require.Equal(t, c.want, rule.Matches(c.value), "%+v matching %q", c.rule, c.value)
+
+		// rules that were not compiled still match
+		require.Equal(t, c.want, c.rule.Matches(c.value))
+	}
+}
+
+func TestBlockRule_AppliesTo(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	require.True(t, BlockRule{Pattern: "x"}.AppliesTo("any", now))
+	require.True(t, BlockRule{Pattern: "x", Channels: []string{"#Channel"}}.AppliesTo("channel", now))
+	require.False(t, BlockRule{Pattern: "x", Channels: []string{"channel"}}.AppliesTo("other", now))
+	require.True(t, BlockRule{Pattern: "x", Expires: &future}.AppliesTo("any", now))
+	require.False(t, BlockRule{Pattern: "x", Expires: &past}.AppliesTo("any", now))
+}
+
+func TestNewBlockRule_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, rule := range []BlockRule{
+		{},
+		{Pattern: "x", Field: "color"},
+		{Pattern: "x", Match: "fuzzy"},
+		{Pattern: "(", Match: BlockMatchRegex},
+		{Pattern: "x", Channels: []string{""}},
+	} {
+		_, err := NewBlockRule(rule)
+		require.Error(t, err, "%+v", rule)
+	}
+}
+
+func TestSetBlockRules(t *testing.T) {
+	t.Parallel()
+
+	input := `# my settings
+chat:
+  time_format: "15:04" # short
+block_settings:
+  users: [someone]
+`
+
+	out, err := setBlockRules([]byte(input), []BlockRule{{Pattern: "spam*", Match: BlockMatchGlob, Channels: []string{"channel"}}})
+	require.NoError(t, err)
+	require.Contains(t, string(out), "# my settings")
+	require.Contains(t, string(out), "# short")
+
+	settings := BuildDefaultSettings()
+	require.NoError(t, yaml.Unmarshal(out, &settings))
+	require.Equal(t, "15:04", settings.Chat.TimeFormat)
+	require.Equal(t, []string{"someone"}, settings.BlockSettings.Users)
+	require.Equal(t, []BlockRule{{Pattern: "spam*", Match: BlockMatchGlob, Channels: []string{"channel"}}}, settings.BlockSettings.Rules)
+
+	// replacing the rules keeps a single rules key
+	out, err = setBlockRules(out, nil)
+	require.NoError(t, err)
+
+	settings = BuildDefaultSettings()
+	require.NoError(t, yaml.Unmarshal(out, &settings))
+	require.Empty(t, settings.BlockSettings.Rules)
+
+	// empty settings file and empty block settings
+	for _, input := range []string{"", "block_settings:\n"} {
+		out, err = setBlockRules([]byte(input), []BlockRule{{Pattern: "x"}})
+		require.NoError(t, err)
+
+		settings = BuildDefaultSettings()
+		require.NoError(t, yaml.Unmarshal(out, &settings))
+		require.Len(t, settings.BlockSettings.Rules, 1)
+	}
+}
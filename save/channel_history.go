@@ -142,3 +142,86 @@ func sortHistoryDesc(entries []ChannelHistoryEntry) {
 		return b.VisitedAt.Compare(a.VisitedAt)
 	})
 }
+
+// importHistory adds the channels of an imported profile to the history and returns the number of channels afterwards.
+// ConflictKeep only imports into an empty history, ConflictOverwrite replaces it.
+func (m *ChannelHistoryManager) importHistory(imported []ChannelHistoryEntry, strategy ConflictStrategy) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := openCreateDataFile(m.fs, channelHistoryFileName)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return 0, err
+	}
+
+	var entries []ChannelHistoryEntry
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			syntaxErr := &json.SyntaxError{}
+			if !errors.As(err, &syntaxErr) {
+				return 0, err
+			}
+			entries = nil
+		}
+	}
+
+	switch {
+	case len(imported) == 0:
+		return len(entries), nil
+	case strategy == ConflictKeep && len(entries) > 0:
+		return len(entries), nil
+	case strategy == ConflictOverwrite:
+		entries = slices.Clone(imported)
+		sortHistoryDesc(entries)
+		if len(entries) > maxChannelHistory {
+			entries = entries[:maxChannelHistory]
+		}
+	default:
+		entries = mergeChannelHistory(entries, imported)
+	}
+
+	out, err := json.Marshal(entries)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := f.Truncate(0); err != nil {
+		return 0, err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	_, err = io.Copy(f, bytes.NewReader(out))
+	return len(entries), err
+}
+
+// mergeChannelHistory combines both histories keeping the latest visit of every channel, capped to maxChannelHistory.
+func mergeChannelHistory(local, imported []ChannelHistoryEntry) []ChannelHistoryEntry {
+	latest := make(map[string]time.Time, len(local)+len(imported))
+	for _, e := range slices.Concat(local, imported) {
+		if t, ok := latest[e.ChannelLogin]; !ok || e.VisitedAt.After(t) {
+			latest[e.ChannelLogin] = e.VisitedAt
+		}
+	}
+
+	merged := make([]ChannelHistoryEntry, 0, len(latest))
+	for login, visited := range latest {
+		merged = append(merged, ChannelHistoryEntry{ChannelLogin: login, VisitedAt: visited})
+	}
+
+	sortHistoryDesc(merged)
+
+	if len(merged) > maxChannelHistory {
+		merged = merged[:maxChannelHistory]
+	}
+
+	return merged
+}
@@ -0,0 +1,175 @@
+package save
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	emoteUsageFileName = "emote_usage.json"
+	maxEmoteUsage      = 500
+
+	// emoteUsageHalfLife is the time after which a use counts half as much when ranking emotes.
+	emoteUsageHalfLife = time.Hour * 24 * 7
+)
+
+// EmoteUsageEntry records how often and when an emote was sent by the user.
+type EmoteUsageEntry struct {
+	Text     string         `json:"text"`
+	Count    int            `json:"count"`
+	LastUsed time.Time      `json:"last_used"`
+	Channels map[string]int `json:"channels"` // channel id:count
+}
+
+// Score ranks the entry by frequency and recency, uses lose half of their weight every week.
+// When channelID is not empty, uses in that channel count twice.
+func (e EmoteUsageEntry) Score(channelID string, now time.Time) float64 {
+	count := float64(e.Count)
+	if channelID != "" {
+		count += float64(e.Channels[channelID])
+	}
+
+	age := max(now.Sub(e.LastUsed), 0)
+	return count * math.Pow(0.5, float64(age)/float64(emoteUsageHalfLife))
+}
+
+// EmoteUsageManager persists the emotes sent by the user to a JSON file.
+type EmoteUsageManager struct {
+	mu  sync.Mutex
+	fs  afero.Fs
+	now func() time.Time
+}
+
+func NewEmoteUsageManager(fs afero.Fs) *EmoteUsageManager {
+	return &EmoteUsageManager{fs: fs, now: time.Now}
+}
+
+// LoadUsage reads the emote usage from disk, sorted by score.
+func (m *EmoteUsageManager) LoadUsage() ([]EmoteUsageEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := openCreateDataFile(m.fs, emoteUsageFileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entries, err := readEmoteUsage(f)
+	if err != nil {
+		return nil, err
+	}
+
+	sortEmoteUsage(entries, m.now())
+	return entries, nil
+}
+
+// RecordUsage increments the usage of each emote sent in channelID.
+// The usage is capped at maxEmoteUsage entries, dropping the lowest ranked emotes.
+func (m *EmoteUsageManager) RecordUsage(channelID string, emotes []string) error {
+	if len(emotes) == 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := openCreateDataFile(m.fs, emoteUsageFileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	entries, err := readEmoteUsage(f)
+	if err != nil {
+		return err
+	}
+
+	now := m.now()
+	for _, text := range emotes {
+		i := slices.IndexFunc(entries, func(e EmoteUsageEntry) bool {
+			return e.Text == text
+		})
+
+		if i == -1 {
+			entries = append(entries, EmoteUsageEntry{Text: text})
+			i = len(entries) - 1
+		}
+
+		if entries[i].Channels == nil {
+			entries[i].Channels = map[string]int{}
+		}
+
+		entries[i].Count++
+		entries[i].Channels[channelID]++
+		entries[i].LastUsed = now
+	}
+
+	sortEmoteUsage(entries, now)
+
+	if len(entries) > maxEmoteUsage {
+		entries = entries[:maxEmoteUsage]
+	}
+
+	out, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, bytes.NewReader(out))
+	return err
+}
+
+func readEmoteUsage(f afero.File) ([]EmoteUsageEntry, error) {
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var entries []EmoteUsageEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		syntaxErr := &json.SyntaxError{}
+		if errors.As(err, &syntaxErr) {
+			// corrupted file, start fresh
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+func sortEmoteUsage(entries []EmoteUsageEntry, now time.Time) {
+	slices.SortFunc(entries, func(a, b EmoteUsageEntry) int {
+		// descending: higher score first, more recent first on ties
+		sa, sb := a.Score("", now), b.Score("", now)
+		switch {
+		case sa > sb:
+			return -1
+		case sa < sb:
+			return 1
+		}
+
+		return b.LastUsed.Compare(a.LastUsed)
+	})
+}
@@ -0,0 +1,50 @@
+package save
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmoteUsageEntry_Score(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	entry := EmoteUsageEntry{
+		Text:     "KEKW",
+		Count:    4,
+		LastUsed: now,
+		Channels: map[string]int{"channel-a": 2},
+	}
+
+	require.InDelta(t, 4, entry.Score("", now), 0.001)
+	require.InDelta(t, 6, entry.Score("channel-a", now), 0.001)
+	require.InDelta(t, 4, entry.Score("channel-b", now), 0.001)
+
+	// uses lose half of their weight every week
+	require.InDelta(t, 2, entry.Score("", now.Add(emoteUsageHalfLife)), 0.001)
+	require.InDelta(t, 1, entry.Score("", now.Add(emoteUsageHalfLife*2)), 0.001)
+}
+
+func TestSortEmoteUsage(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	entries := []EmoteUsageEntry{
+		{Text: "old-but-frequent", Count: 10, LastUsed: now.Add(-emoteUsageHalfLife * 4)},
+		{Text: "recent", Count: 2, LastUsed: now.Add(-time.Hour)},
+		{Text: "less-recent", Count: 2, LastUsed: now.Add(-time.Hour * 2)},
+		{Text: "most-recent", Count: 1, LastUsed: now},
+		{Text: "frequent", Count: 20, LastUsed: now.Add(-time.Hour * 24)},
+	}
+
+	sortEmoteUsage(entries, now)
+
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Text)
+	}
+
+	require.Equal(t, []string{"frequent", "recent", "less-recent", "most-recent", "old-but-frequent"}, got)
+}
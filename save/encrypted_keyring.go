@@ -0,0 +1,291 @@
+package save
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/spf13/afero"
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/argon2"
+)
+
+// enforce that EncryptedKeyring implements the Keyring interface
+var _ keyring.Keyring = &EncryptedKeyring{}
+
+const (
+	encryptedAuthFile    = "accounts.enc"
+	encryptedAuthVersion = 1
+	encryptedAuthKeyLen  = 32 // AES-256
+	encryptedAuthSaltLen = 16
+)
+
+var (
+	ErrEmptyPassphrase = errors.New("the passphrase must not be empty")
+	ErrWrongPassphrase = errors.New("wrong passphrase or corrupted file")
+)
+
+// argon2Params are the argon2id parameters deriving the key from the passphrase.
+// They are stored in the file, so they can be raised later without breaking existing files.
+type argon2Params struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"` // KiB
+	Threads uint8  `json:"threads"`
+}
+
+// recommended by RFC 9106 for memory constrained environments
+var defaultArgon2Params = argon2Params{
+	Time:    3,
+	Memory:  64 * 1024,
+	Threads: 4,
+}
+
+// encryptedAuthEnvelope is the content of the encrypted account file.
+type encryptedAuthEnvelope struct {
+	Version int          `json:"version"`
+	KDF     string       `json:"kdf"`
+	Params  argon2Params `json:"params"`
+	Salt    []byte       `json:"salt"`
+	Nonce   []byte       `json:"nonce"`
+	Data    []byte       `json:"data"`
+}
+
+// EncryptedKeyring stores the accounts in a file encrypted with AES-256-GCM, the key is derived from a passphrase with argon2id.
+// Like PlainKeyringFallback it ignores service and user, the file only holds the account save.
+type EncryptedKeyring struct {
+	m          *sync.RWMutex
+	fs         afero.Fs
+	passphrase []byte
+	params     argon2Params
+
+	// the derived key is cached, argon2id is slow by design
+	salt, key []byte
+}
+
+func NewEncryptedKeyring(fs afero.Fs, passphrase string) (*EncryptedKeyring, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+
+	return &EncryptedKeyring{
+		m:          &sync.RWMutex{},
+		fs:         fs,
+		passphrase: []byte(passphrase),
+		params:     defaultArgon2Params,
+	}, nil
+}
+
+// HasEncryptedAccounts reports if the encrypted account file was already written.
+func HasEncryptedAccounts(fs afero.Fs) (bool, error) {
+	f, err := openCreateConfigFile(fs, encryptedAuthFile)
+	if err != nil {
+		return false, err
+	}
+
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+
+	return info.Size() > 0, nil
+}
+
+func (e *EncryptedKeyring) Set(_, _, jsonData string) error {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	if e.key == nil {
+		envelope, err := e.readEnvelope()
+		switch {
+		case errors.Is(err, keyring.ErrNotFound):
+			salt := make([]byte, encryptedAuthSaltLen)
+			if _, err := rand.Read(salt); err != nil {
+				return err
+			}
+
+			e.salt, e.key = salt, deriveKey(e.passphrase, salt, e.params)
+		case err != nil:
+			return err
+		default:
+			// an existing file is only replaced if the passphrase can decrypt it
+			if _, err := e.unlock(envelope); err != nil {
+				return err
+			}
+		}
+	}
+
+	envelope, err := sealEnvelope(e.key, e.salt, e.params, []byte(jsonData))
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	// a partially written file can't be decrypted at all, replace it only once the new one is complete
+	return replaceConfigFile(e.fs, encryptedAuthFile, data)
+}
+
+func (e *EncryptedKeyring) Get(_, _ string) (string, error) {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	envelope, err := e.readEnvelope()
+	if err != nil {
+		return "", err
+	}
+
+	plain, err := e.unlock(envelope)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plain), nil
+}
+
+// readEnvelope reads the encrypted account file, keyring.ErrNotFound is returned if it wasn't written yet.
+func (e *EncryptedKeyring) readEnvelope() (encryptedAuthEnvelope, error) {
+	f, err := openCreateConfigFile(e.fs, encryptedAuthFile)
+	if err != nil {
+		return encryptedAuthEnvelope{}, err
+	}
+
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return encryptedAuthEnvelope{}, err
+	}
+
+	if len(data) == 0 {
+		return encryptedAuthEnvelope{}, keyring.ErrNotFound
+	}
+
+	var envelope encryptedAuthEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return encryptedAuthEnvelope{}, fmt.Errorf("failed to parse encrypted account file: %w", err)
+	}
+
+	if envelope.Version != encryptedAuthVersion || envelope.KDF != "argon2id" {
+		return encryptedAuthEnvelope{}, fmt.Errorf("unsupported encrypted account file version %d (%s)", envelope.Version, envelope.KDF)
+	}
+
+	return envelope, nil
+}
+
+// unlock decrypts the envelope and caches the key on success.
+func (e *EncryptedKeyring) unlock(envelope encryptedAuthEnvelope) ([]byte, error) {
+	key := e.key
+	if key == nil || string(e.salt) != string(envelope.Salt) || e.params != envelope.Params {
+		key = deriveKey(e.passphrase, envelope.Salt, envelope.Params)
+	}
+
+	plain, err := openEnvelope(key, envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	// writes keep the salt and parameters of the existing file
+	e.salt, e.key, e.params = envelope.Salt, key, envelope.Params
+
+	return plain, nil
+}
+
+// Delete removes the encrypted account file.
+func (e *EncryptedKeyring) Delete(_, _ string) error {
+	e.m.Lock()
+	defer e.m.Unlock()
+
+	return removeConfigFile(e.fs, encryptedAuthFile)
+}
+
+func (e *EncryptedKeyring) DeleteAll(_ string) error {
+	return e.Delete("", "")
+}
+
+func deriveKey(passphrase, salt []byte, params argon2Params) []byte {
+	return argon2.IDKey(passphrase, salt, params.Time, params.Memory, params.Threads, encryptedAuthKeyLen)
+}
+
+func sealEnvelope(key, salt []byte, params argon2Params, plain []byte) (encryptedAuthEnvelope, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return encryptedAuthEnvelope{}, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return encryptedAuthEnvelope{}, err
+	}
+
+	envelope := encryptedAuthEnvelope{
+		Version: encryptedAuthVersion,
+		KDF:     "argon2id",
+		Params:  params,
+		Salt:    salt,
+		Nonce:   nonce,
+	}
+
+	// the header is authenticated, changing the parameters or salt fails the decryption
+	envelope.Data = gcm.Seal(nil, nonce, plain, envelope.additionalData())
+
+	return envelope, nil
+}
+
+func openEnvelope(key []byte, envelope encryptedAuthEnvelope) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(envelope.Nonce) != gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+
+	plain, err := gcm.Open(nil, envelope.Nonce, envelope.Data, envelope.additionalData())
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	return plain, nil
+}
+
+func (e encryptedAuthEnvelope) additionalData() []byte {
+	return fmt.Appendf(nil, "chatuino:%d:%s:%d:%d:%d:%x", e.Version, e.KDF, e.Params.Time, e.Params.Memory, e.Params.Threads, e.Salt)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// encryptWithPassphrase encrypts plain with a key derived from the passphrase and a new salt.
+func encryptWithPassphrase(passphrase []byte, params argon2Params, plain []byte) (encryptedAuthEnvelope, error) {
+	salt := make([]byte, encryptedAuthSaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return encryptedAuthEnvelope{}, err
+	}
+
+	return sealEnvelope(deriveKey(passphrase, salt, params), salt, params, plain)
+}
+
+func decryptWithPassphrase(passphrase []byte, envelope encryptedAuthEnvelope) ([]byte, error) {
+	if envelope.Version != encryptedAuthVersion || envelope.KDF != "argon2id" {
+		return nil, fmt.Errorf("unsupported encryption version %d (%s)", envelope.Version, envelope.KDF)
+	}
+
+	return openEnvelope(deriveKey(passphrase, envelope.Salt, envelope.Params), envelope)
+}
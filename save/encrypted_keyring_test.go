@@ -0,0 +1,150 @@
+package save
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+// cheap parameters, the defaults take too long for tests
+var testArgon2Params = argon2Params{Time: 1, Memory: 64, Threads: 1}
+
+func TestSealOpenEnvelope(t *testing.T) {
+	t.Parallel()
+
+	salt := []byte("0123456789abcdef")
+	key := deriveKey([]byte("correct horse"), salt, testArgon2Params)
+
+	envelope, err := sealEnvelope(key, salt, testArgon2Params, []byte(`{"accounts":[]}`))
+	require.NoError(t, err)
+	require.NotContains(t, string(envelope.Data), "accounts")
+
+	plain, err := openEnvelope(key, envelope)
+	require.NoError(t, err)
+	require.Equal(t, `{"accounts":[]}`, string(plain))
+
+	// wrong passphrase
+	_, err = openEnvelope(deriveKey([]byte("battery staple"), salt, testArgon2Params), envelope)
+	require.ErrorIs(t, err, ErrWrongPassphrase)
+
+	// the parameters are authenticated
+	tampered := envelope
+	tampered.Params.Time = 2
+	_, err = openEnvelope(key, tampered)
+	require.ErrorIs(t, err, ErrWrongPassphrase)
+
+	// every write uses a new nonce
+	again, err := sealEnvelope(key, salt, testArgon2Params, []byte(`{"accounts":[]}`))
+	require.NoError(t, err)
+	require.NotEqual(t, envelope.Nonce, again.Nonce)
+}
+
+func TestNewEncryptedKeyring(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewEncryptedKeyring(nil, "")
+	require.ErrorIs(t, err, ErrEmptyPassphrase)
+}
+
+func TestEncryptedKeyring_SetChecksExistingFile(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+
+	first, err := NewEncryptedKeyring(fs, "correct horse")
+	require.NoError(t, err)
+	first.params = testArgon2Params
+	require.NoError(t, first.Set("", "", `{"accounts":[{"id":"1"}]}`))
+
+	// a new instance with the wrong passphrase must not replace the file
+	wrong, err := NewEncryptedKeyring(fs, "battery staple")
+	require.NoError(t, err)
+	wrong.params = testArgon2Params
+	require.ErrorIs(t, wrong.Set("", "", `{"accounts":[]}`), ErrWrongPassphrase)
+
+	// a new instance with the right passphrase keeps the salt of the file
+	second, err := NewEncryptedKeyring(fs, "correct horse")
+	require.NoError(t, err)
+	second.params = testArgon2Params
+	require.NoError(t, second.Set("", "", `{"accounts":[{"id":"2"}]}`))
+	require.Equal(t, first.salt, second.salt)
+
+	data, err := first.Get("", "")
+	require.NoError(t, err)
+	require.Equal(t, `{"accounts":[{"id":"2"}]}`, data)
+
+	// the file is replaced by renaming the temporary file
+	configDir, err := os.UserConfigDir()
+	require.NoError(t, err)
+
+	exists, err := afero.Exists(fs, filepath.Join(configDir, chatuinoConfigDir, encryptedAuthFile+".tmp"))
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func TestMigrateAccounts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("moves accounts", func(t *testing.T) {
+		t.Parallel()
+
+		from := memKeyring{keychainAccount: `{"accounts":[{"id":"1"}]}`}
+		to := memKeyring{}
+
+		migrated, err := MigrateAccounts(from, to)
+		require.NoError(t, err)
+		require.True(t, migrated)
+		require.Equal(t, `{"accounts":[{"id":"1"}]}`, to[keychainAccount])
+		require.NotContains(t, from, keychainAccount)
+	})
+
+	t.Run("nothing to migrate", func(t *testing.T) {
+		t.Parallel()
+
+		migrated, err := MigrateAccounts(memKeyring{}, memKeyring{})
+		require.NoError(t, err)
+		require.False(t, migrated)
+	})
+
+	t.Run("keeps existing accounts", func(t *testing.T) {
+		t.Parallel()
+
+		from := memKeyring{keychainAccount: `{"accounts":[{"id":"1"}]}`}
+		to := memKeyring{keychainAccount: `{"accounts":[{"id":"2"}]}`}
+
+		_, err := MigrateAccounts(from, to)
+		require.ErrorIs(t, err, ErrAccountsExist)
+		require.Equal(t, `{"accounts":[{"id":"2"}]}`, to[keychainAccount])
+		require.Contains(t, from, keychainAccount)
+	})
+}
+
+type memKeyring map[string]string
+
+func (m memKeyring) Set(_, user, password string) error {
+	m[user] = password
+	return nil
+}
+
+func (m memKeyring) Get(_, user string) (string, error) {
+	v, ok := m[user]
+	if !ok {
+		return "", keyring.ErrNotFound
+	}
+
+	return v, nil
+}
+
+func (m memKeyring) Delete(_, user string) error {
+	delete(m, user)
+	return nil
+}
+
+func (m memKeyring) DeleteAll(_ string) error {
+	clear(m)
+	return nil
+}
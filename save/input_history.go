@@ -0,0 +1,88 @@
+package save
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/spf13/afero"
+)
+
+const inputHistoryFileName = "input_history.json"
+
+// InputHistory is the sent inputs and the unsent draft of the message input of a tab.
+type InputHistory struct {
+	Entries []string `json:"entries"` // oldest first
+	Draft   string   `json:"draft,omitempty"`
+}
+
+// InputHistoryManager persists the message input history of all tabs, keyed by tab, to a JSON file.
+type InputHistoryManager struct {
+	mu sync.Mutex
+	fs afero.Fs
+}
+
+func NewInputHistoryManager(fs afero.Fs) *InputHistoryManager {
+	return &InputHistoryManager{fs: fs}
+}
+
+func (m *InputHistoryManager) LoadInputHistory() (map[string]InputHistory, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := openCreateDataFile(m.fs, inputHistoryFileName)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	history := map[string]InputHistory{}
+	if len(data) == 0 {
+		return history, nil
+	}
+
+	if err := json.Unmarshal(data, &history); err != nil {
+		syntaxErr := &json.SyntaxError{}
+		if errors.As(err, &syntaxErr) {
+			// corrupted file, start fresh
+			return map[string]InputHistory{}, nil
+		}
+		return nil, err
+	}
+
+	return history, nil
+}
+
+func (m *InputHistoryManager) SaveInputHistory(history map[string]InputHistory) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := openCreateDataFile(m.fs, inputHistoryFileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	data, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+
+	if err := f.Truncate(0); err != nil {
+		return err
+	}
+
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(f, bytes.NewReader(data))
+	return err
+}
@@ -23,6 +23,8 @@ type KeyMap struct {
 	// General
 	Up      key.Binding `yaml:"up"`
 	Down    key.Binding `yaml:"down"`
+	Left    key.Binding `yaml:"left"`
+	Right   key.Binding `yaml:"right"`
 	Escape  key.Binding `yaml:"escape"`
 	Confirm key.Binding `yaml:"confirm"`
 	Help    key.Binding `yaml:"help"`
@@ -34,10 +36,18 @@ type KeyMap struct {
 	CloseTab   key.Binding `yaml:"close_tab"`
 	DumpScreen key.Binding `yaml:"dump_screen"` // used by lists, and join input type switch
 
+	ConnectionStatus key.Binding `yaml:"connection_status"`
+
 	// Tab Binds
 	Next     key.Binding `yaml:"next"`
 	Previous key.Binding `yaml:"previous"`
 
+	// Split View
+	SplitView        key.Binding `yaml:"split_view"`
+	SplitOrientation key.Binding `yaml:"split_orientation"`
+	NextPane         key.Binding `yaml:"next_pane"`
+	PreviousPane     key.Binding `yaml:"previous_pane"`
+
 	// Quick Join
 	QuickJoin key.Binding `yaml:"quick_join"`
 
@@ -53,9 +63,13 @@ type KeyMap struct {
 	CopyMessage  key.Binding `yaml:"copy_message"`
 	SearchMode   key.Binding `yaml:"search_mode"`
 	QuickSent    key.Binding `yaml:"quick_sent"`
+	EmotePicker  key.Binding `yaml:"emote_picker"`
+	Reply        key.Binding `yaml:"reply"`
+	ShowThread   key.Binding `yaml:"show_thread"`
 
 	// Account Binds
-	MarkLeader key.Binding `yaml:"mark_leader"`
+	MarkLeader  key.Binding `yaml:"mark_leader"`
+	Reauthorize key.Binding `yaml:"reauthorize"`
 }
 
 func (c *KeyMap) MarshalYAML() (interface{}, error) {
@@ -117,6 +131,14 @@ func BuildDefaultKeyMap() KeyMap {
 			key.WithKeys("down", "j"),
 			key.WithHelp("↓/j", "down"),
 		),
+		Left: key.NewBinding(
+			key.WithKeys("left", "h"),
+			key.WithHelp("←/h", "left"),
+		),
+		Right: key.NewBinding(
+			key.WithKeys("right", "l"),
+			key.WithHelp("→/l", "right"),
+		),
 		Escape: key.NewBinding(
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "escape"),
@@ -149,6 +171,10 @@ func BuildDefaultKeyMap() KeyMap {
 			key.WithKeys("ctrl+alt+d"),
 			key.WithHelp("ctrl+alt+d", "dump screen"),
 		),
+		ConnectionStatus: key.NewBinding(
+			key.WithKeys("alt+s"),
+			key.WithHelp("alt+s", "show connection status"),
+		),
 		QuickJoin: key.NewBinding(
 			key.WithKeys("ctrl+j"),
 			key.WithHelp("ctrl+j", "quick join channel"),
@@ -161,6 +187,22 @@ func BuildDefaultKeyMap() KeyMap {
 			key.WithKeys("shift+tab"),
 			key.WithHelp("shift+tab", "previous item"),
 		),
+		SplitView: key.NewBinding(
+			key.WithKeys("alt+x"),
+			key.WithHelp("alt+x", "add/remove tab to/from split view"),
+		),
+		SplitOrientation: key.NewBinding(
+			key.WithKeys("alt+o"),
+			key.WithHelp("alt+o", "tile split view horizontally/vertically"),
+		),
+		NextPane: key.NewBinding(
+			key.WithKeys("alt+right", "alt+l"),
+			key.WithHelp("alt+→/alt+l", "focus next split pane"),
+		),
+		PreviousPane: key.NewBinding(
+			key.WithKeys("alt+left", "alt+h"),
+			key.WithHelp("alt+←/alt+h", "focus previous split pane"),
+		),
 		InsertMode: key.NewBinding(
 			key.WithKeys("i", "I"),
 			key.WithHelp("i", "insert mode"),
@@ -181,6 +223,10 @@ func BuildDefaultKeyMap() KeyMap {
 			key.WithKeys("m"),
 			key.WithHelp("m", "mark account as main account"),
 		),
+		Reauthorize: key.NewBinding(
+			key.WithKeys("a"),
+			key.WithHelp("a", "authorize account again"),
+		),
 		GoToTop: key.NewBinding(
 			key.WithKeys("t"),
 			key.WithHelp("t", "go to top"),
@@ -209,6 +255,18 @@ func BuildDefaultKeyMap() KeyMap {
 			key.WithKeys("alt+enter"),
 			key.WithHelp("alt+enter", "send message but stay in insert mode"),
 		),
+		EmotePicker: key.NewBinding(
+			key.WithKeys("alt+e"),
+			key.WithHelp("alt+e", "open emote picker"),
+		),
+		Reply: key.NewBinding(
+			key.WithKeys("alt+r"),
+			key.WithHelp("alt+r", "reply to selected message"),
+		),
+		ShowThread: key.NewBinding(
+			key.WithKeys("alt+v"),
+			key.WithHelp("alt+v", "show reply thread of selected message"),
+		),
 	}
 }
 
@@ -171,6 +171,36 @@ func (b *BatchedMessageLogger) MessagesFromUserInChannel(username string, broadc
 	return b.scanRows(rows)
 }
 
+// RecentMessagesInChannel returns the latest messages logged for a channel, newest first.
+func (b *BatchedMessageLogger) RecentMessagesInChannel(broadcasterChannel string, limit int) ([]LogEntry, error) {
+	query := `SELECT id, broadcast_id, user_id, broadcast_channel, sent_at, sender_display, payload FROM messages WHERE broadcast_channel = ? ORDER BY sent_at DESC LIMIT ?`
+	rows, err := b.roDB.Query(query, broadcasterChannel, limit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return []LogEntry{}, nil
+		}
+
+		return nil, err
+	}
+
+	return b.scanRows(rows)
+}
+
+// MessagesInChannelBefore returns the latest messages logged for a channel which were sent before the given time, newest first.
+func (b *BatchedMessageLogger) MessagesInChannelBefore(broadcasterChannel string, before time.Time, limit int) ([]LogEntry, error) {
+	query := `SELECT id, broadcast_id, user_id, broadcast_channel, sent_at, sender_display, payload FROM messages WHERE broadcast_channel = ? AND sent_at < ? ORDER BY sent_at DESC LIMIT ?`
+	rows, err := b.roDB.Query(query, broadcasterChannel, before, limit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return []LogEntry{}, nil
+		}
+
+		return nil, err
+	}
+
+	return b.scanRows(rows)
+}
+
 func (b *BatchedMessageLogger) scanRows(rows *sql.Rows) ([]LogEntry, error) {
 	defer rows.Close()
 
@@ -129,3 +129,50 @@ func TestBatchedMessageLogger_createLogEntries(t *testing.T) {
 		require.Nil(t, err)
 	})
 }
+
+func TestBatchedMessageLogger_RecentMessagesInChannel(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "broadcast_id", "user_id", "broadcast_channel", "sent_at", "sender_display", "payload"}).
+		AddRow("second", 1, 2, "channel", "2025-01-02 10:00:00+00:00", "sender", []byte(`{"message":"KEKW KEKW"}`)).
+		AddRow("first", 1, 2, "channel", "2025-01-01 10:00:00+00:00", "sender", []byte(`{"message":"Kappa"}`))
+
+	sqlMock.ExpectQuery("SELECT (.+) FROM messages WHERE broadcast_channel = \\? ORDER BY sent_at DESC LIMIT \\?").
+		WithArgs("channel", 50).
+		WillReturnRows(rows)
+
+	messageLogger := NewBatchedMessageLogger(zerolog.Nop(), db, db, nil, nil)
+	entries, err := messageLogger.RecentMessagesInChannel("channel", 50)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "second", entries[0].ID)
+	require.Equal(t, "KEKW KEKW", entries[0].PrivateMessage.Message)
+	require.Equal(t, "Kappa", entries[1].PrivateMessage.Message)
+	require.NoError(t, sqlMock.ExpectationsWereMet())
+}
+
+func TestBatchedMessageLogger_MessagesInChannelBefore(t *testing.T) {
+	db, sqlMock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	before := time.Date(2025, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"id", "broadcast_id", "user_id", "broadcast_channel", "sent_at", "sender_display", "payload"}).
+		AddRow("second", 1, 2, "channel", "2025-01-02 10:00:00+00:00", "sender", []byte(`{"message":"KEKW"}`)).
+		AddRow("first", 1, 2, "channel", "2025-01-01 10:00:00+00:00", "sender", []byte(`{"message":"Kappa"}`))
+
+	sqlMock.ExpectQuery("SELECT (.+) FROM messages WHERE broadcast_channel = \\? AND sent_at < \\? ORDER BY sent_at DESC LIMIT \\?").
+		WithArgs("channel", before, 100).
+		WillReturnRows(rows)
+
+	messageLogger := NewBatchedMessageLogger(zerolog.Nop(), db, db, nil, nil)
+	entries, err := messageLogger.MessagesInChannelBefore("channel", before, 100)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "second", entries[0].ID)
+	require.Equal(t, "first", entries[1].ID)
+	require.NoError(t, sqlMock.ExpectationsWereMet())
+}
@@ -0,0 +1,216 @@
+package messagelog
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+)
+
+const scrollbackMigration = `BEGIN;
+CREATE TABLE IF NOT EXISTS scrollback (
+	seq INTEGER PRIMARY KEY AUTOINCREMENT,
+	buffer TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	deleted INTEGER NOT NULL,
+	payload BLOB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS scrollback_buffer_idx ON scrollback (buffer, seq);
+COMMIT;`
+
+// ScrollbackEntry is a chat message evicted from the in-memory chat buffer.
+type ScrollbackEntry struct {
+	Message   twitchirc.IRCer
+	IsDeleted bool
+}
+
+// ScrollbackError is an error shown in chat which was restored from the scrollback.
+type ScrollbackError struct {
+	Message string
+}
+
+func (e *ScrollbackError) Error() string {
+	return e.Message
+}
+
+func (e *ScrollbackError) IRC() string {
+	return ""
+}
+
+// Scrollback stores chat messages evicted from memory for the current session, so they can be paged back in.
+// Each chat buffer is a stack, popping returns the entries spilled last. The buffers are not separated by
+// process, so the database must not be shared with other Chatuino instances.
+type Scrollback struct {
+	db DB
+}
+
+func NewScrollback(db DB) *Scrollback {
+	return &Scrollback{db: db}
+}
+
+// PrepareDatabase creates the scrollback table.
+func (s *Scrollback) PrepareDatabase() error {
+	if _, err := s.db.Exec(scrollbackMigration); err != nil {
+		return fmt.Errorf("failed running scrollback migration: %w", err)
+	}
+
+	return nil
+}
+
+// Push stores entries, ordered oldest first, on top of the buffer.
+func (s *Scrollback) Push(buffer string, entries []ScrollbackEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	query := `INSERT INTO scrollback (buffer, kind, deleted, payload) VALUES %s`
+
+	valueStrings := make([]string, 0, len(entries))
+	valueArgs := make([]any, 0, len(entries)*4) // 4 args per row
+	for _, entry := range entries {
+		kind, payload, err := marshalScrollbackMessage(entry.Message)
+		if err != nil {
+			return err
+		}
+
+		valueStrings = append(valueStrings, "(?, ?, ?, ?)")
+		valueArgs = append(valueArgs, buffer, kind, entry.IsDeleted, payload)
+	}
+
+	query = fmt.Sprintf(query, strings.Join(valueStrings, ","))
+
+	if _, err := s.db.Exec(query, valueArgs...); err != nil {
+		return fmt.Errorf("failed inserting scrollback: %w", err)
+	}
+
+	return nil
+}
+
+// Pop removes up to limit entries from the top of the buffer and returns them oldest first.
+func (s *Scrollback) Pop(buffer string, limit int) ([]ScrollbackEntry, error) {
+	rows, err := s.db.Query(`SELECT seq, kind, deleted, payload FROM scrollback WHERE buffer = ? ORDER BY seq DESC LIMIT ?`, buffer, limit)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+	defer rows.Close()
+
+	var (
+		entries []ScrollbackEntry
+		minSeq  int64
+	)
+
+	for rows.Next() {
+		var (
+			seq     int64
+			kind    string
+			deleted bool
+			payload []byte
+		)
+
+		if err := rows.Scan(&seq, &kind, &deleted, &payload); err != nil {
+			return nil, err
+		}
+
+		msg, err := unmarshalScrollbackMessage(kind, payload)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, ScrollbackEntry{Message: msg, IsDeleted: deleted})
+		minSeq = seq
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	if _, err := s.db.Exec(`DELETE FROM scrollback WHERE buffer = ? AND seq >= ?`, buffer, minSeq); err != nil {
+		return nil, fmt.Errorf("failed deleting popped scrollback: %w", err)
+	}
+
+	slices.Reverse(entries)
+	return entries, nil
+}
+
+// Drop removes all entries of the buffer.
+func (s *Scrollback) Drop(buffer string) error {
+	if _, err := s.db.Exec(`DELETE FROM scrollback WHERE buffer = ?`, buffer); err != nil {
+		return fmt.Errorf("failed deleting scrollback: %w", err)
+	}
+
+	return nil
+}
+
+func marshalScrollbackMessage(msg twitchirc.IRCer) (string, []byte, error) {
+	var kind string
+	switch m := msg.(type) {
+	case *twitchirc.PrivateMessage:
+		kind = "privmsg"
+	case *twitchirc.Notice:
+		kind = "notice"
+	case *twitchirc.ClearChat:
+		kind = "clearchat"
+	case *twitchirc.ClearMessage:
+		kind = "clearmsg"
+	case *twitchirc.SubMessage:
+		kind = "sub"
+	case *twitchirc.SubGiftMessage:
+		kind = "subgift"
+	case *twitchirc.AnnouncementMessage:
+		kind = "announcement"
+	case error:
+		payload, err := json.Marshal(ScrollbackError{Message: m.Error()})
+		return "error", payload, err
+	default:
+		return "", nil, fmt.Errorf("unsupported scrollback message type %T", msg)
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to marshal %s scrollback message: %w", kind, err)
+	}
+
+	return kind, payload, nil
+}
+
+func unmarshalScrollbackMessage(kind string, payload []byte) (twitchirc.IRCer, error) {
+	var msg twitchirc.IRCer
+	switch kind {
+	case "privmsg":
+		msg = &twitchirc.PrivateMessage{}
+	case "notice":
+		msg = &twitchirc.Notice{}
+	case "clearchat":
+		msg = &twitchirc.ClearChat{}
+	case "clearmsg":
+		msg = &twitchirc.ClearMessage{}
+	case "sub":
+		msg = &twitchirc.SubMessage{}
+	case "subgift":
+		msg = &twitchirc.SubGiftMessage{}
+	case "announcement":
+		msg = &twitchirc.AnnouncementMessage{}
+	case "error":
+		msg = &ScrollbackError{}
+	default:
+		return nil, fmt.Errorf("unknown scrollback message kind %q", kind)
+	}
+
+	if err := json.Unmarshal(payload, msg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal %s scrollback message: %w", kind, err)
+	}
+
+	return msg, nil
+}
@@ -0,0 +1,70 @@
+package messagelog
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+func newTestScrollback(t *testing.T) *Scrollback {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", "file::memory:?_time_format=sqlite")
+	require.NoError(t, err)
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	s := NewScrollback(db)
+	require.NoError(t, s.PrepareDatabase())
+
+	return s
+}
+
+func TestScrollback_PushPop(t *testing.T) {
+	t.Parallel()
+
+	s := newTestScrollback(t)
+	now := time.Now().UTC().Truncate(time.Second)
+
+	require.NoError(t, s.Push("tab-1", []ScrollbackEntry{
+		{Message: &twitchirc.PrivateMessage{ID: "1", Message: "first", TMISentTS: now}},
+		{Message: &twitchirc.Notice{Message: "notice", FakeTimestamp: now}},
+	}))
+	require.NoError(t, s.Push("tab-2", []ScrollbackEntry{
+		{Message: &twitchirc.PrivateMessage{ID: "other"}},
+	}))
+	require.NoError(t, s.Push("tab-1", []ScrollbackEntry{
+		{Message: &twitchirc.PrivateMessage{ID: "2", Message: "deleted"}, IsDeleted: true},
+		{Message: &ScrollbackError{Message: "connection lost"}},
+		{Message: &twitchirc.SubMessage{Message: "sub", UserNotice: twitchirc.UserNotice{DisplayName: "subber"}}},
+	}))
+
+	// newest spilled entries come first, returned oldest first
+	entries, err := s.Pop("tab-1", 3)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, "2", entries[0].Message.(*twitchirc.PrivateMessage).ID)
+	require.True(t, entries[0].IsDeleted)
+	require.EqualError(t, entries[1].Message.(error), "connection lost")
+	require.Equal(t, "subber", entries[2].Message.(*twitchirc.SubMessage).DisplayName)
+
+	entries, err = s.Pop("tab-1", 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, &twitchirc.PrivateMessage{ID: "1", Message: "first", TMISentTS: now}, entries[0].Message)
+	require.Equal(t, "notice", entries[1].Message.(*twitchirc.Notice).Message)
+	require.False(t, entries[1].IsDeleted)
+
+	entries, err = s.Pop("tab-1", 10)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+
+	require.NoError(t, s.Drop("tab-2"))
+	entries, err = s.Pop("tab-2", 10)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
@@ -33,12 +33,16 @@ func (p *PlainKeyringFallback) Get(_, _ string) (string, error) {
 	return p.read()
 }
 
+// Delete removes the plain text account file.
 func (p *PlainKeyringFallback) Delete(_, _ string) error {
-	return nil
+	p.m.Lock()
+	defer p.m.Unlock()
+
+	return removeConfigFile(p.fs, plainAuthFile)
 }
 
 func (p *PlainKeyringFallback) DeleteAll(_ string) error {
-	return nil
+	return p.Delete("", "")
 }
 
 func (p *PlainKeyringFallback) read() (string, error) {
@@ -0,0 +1,378 @@
+package save
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	profileFormat  = "chatuino-profile"
+	profileVersion = 1
+)
+
+// profileConfigFiles are the config files bundled in a profile.
+var profileConfigFiles = []string{settingsFileName, themeFileName, keyMapFileName}
+
+// ConflictStrategy decides what happens with accounts, config files and the channel history which exist locally and in an imported profile.
+type ConflictStrategy string
+
+const (
+	// ConflictKeep leaves existing data untouched, only missing data is added.
+	ConflictKeep ConflictStrategy = "keep"
+	// ConflictOverwrite replaces existing data with the profile's.
+	ConflictOverwrite ConflictStrategy = "overwrite"
+	// ConflictMerge combines both, local values win where both set the same value.
+	ConflictMerge ConflictStrategy = "merge"
+)
+
+var (
+	ErrProfileTokensUnencrypted = errors.New("profiles including tokens have to be encrypted with a passphrase")
+	ErrInvalidProfile           = errors.New("not a Chatuino profile")
+)
+
+// Profile bundles the accounts, config files and channel history to set up Chatuino on another machine.
+type Profile struct {
+	CreatedAt      time.Time             `json:"created_at"`
+	IncludesTokens bool                  `json:"includes_tokens"`
+	Accounts       []Account             `json:"accounts"`
+	ConfigFiles    map[string]string     `json:"config_files"` // file name -> content
+	ChannelHistory []ChannelHistoryEntry `json:"channel_history"`
+}
+
+// profileArchive is the file written by WriteProfile, it either holds the profile or the encrypted profile.
+type profileArchive struct {
+	Format    string                 `json:"format"`
+	Version   int                    `json:"version"`
+	Profile   *Profile               `json:"profile,omitempty"`
+	Encrypted *encryptedAuthEnvelope `json:"encrypted,omitempty"`
+}
+
+// ProfileImportResult lists what ImportProfile changed, accounts are listed by display name.
+type ProfileImportResult struct {
+	AccountsAdded         []string
+	AccountsUpdated       []string
+	AccountsKept          []string
+	AccountsWithoutTokens []string // need to be authorized again
+	ConfigFilesWritten    []string
+	ConfigFilesKept       []string
+	Channels              int // channels in the history after the import
+}
+
+// ExportProfile collects the profile of this machine. Without includeTokens the accounts are exported without their tokens.
+func ExportProfile(fs afero.Fs, accounts AccountProvider, history *ChannelHistoryManager, includeTokens bool) (Profile, error) {
+	all, err := accounts.GetAllAccounts()
+	if err != nil {
+		return Profile{}, fmt.Errorf("failed to read accounts: %w", err)
+	}
+
+	p := Profile{
+		CreatedAt:      time.Now(),
+		IncludesTokens: includeTokens,
+		ConfigFiles:    map[string]string{},
+	}
+
+	for _, acc := range all {
+		if acc.IsAnonymous {
+			continue
+		}
+
+		if !includeTokens {
+			acc.AccessToken, acc.RefreshToken = "", ""
+		}
+
+		p.Accounts = append(p.Accounts, acc)
+	}
+
+	for _, name := range profileConfigFiles {
+		data, err := readConfigFile(fs, name)
+		if err != nil {
+			return Profile{}, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		if len(data) > 0 {
+			p.ConfigFiles[name] = string(data)
+		}
+	}
+
+	p.ChannelHistory, err = history.LoadHistory()
+	if err != nil {
+		return Profile{}, fmt.Errorf("failed to read channel history: %w", err)
+	}
+
+	return p, nil
+}
+
+// WriteProfile writes the profile archive to w. With a passphrase the profile is encrypted, profiles including tokens require one.
+func WriteProfile(w io.Writer, p Profile, passphrase string) error {
+	return writeProfile(w, p, passphrase, defaultArgon2Params)
+}
+
+func writeProfile(w io.Writer, p Profile, passphrase string, params argon2Params) error {
+	if p.IncludesTokens && passphrase == "" {
+		return ErrProfileTokensUnencrypted
+	}
+
+	archive := profileArchive{
+		Format:  profileFormat,
+		Version: profileVersion,
+	}
+
+	if passphrase == "" {
+		archive.Profile = &p
+	} else {
+		data, err := json.Marshal(p)
+		if err != nil {
+			return err
+		}
+
+		envelope, err := encryptWithPassphrase([]byte(passphrase), params, data)
+		if err != nil {
+			return err
+		}
+
+		archive.Encrypted = &envelope
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(archive)
+}
+
+// ReadProfile reads a profile archive. passphrase is only called for encrypted profiles.
+func ReadProfile(r io.Reader, passphrase func() (string, error)) (Profile, error) {
+	var archive profileArchive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return Profile{}, fmt.Errorf("%w: %w", ErrInvalidProfile, err)
+	}
+
+	if archive.Format != profileFormat {
+		return Profile{}, ErrInvalidProfile
+	}
+
+	if archive.Version > profileVersion {
+		return Profile{}, fmt.Errorf("the profile has version %d and was created by a newer Chatuino, update Chatuino to import it", archive.Version)
+	}
+
+	switch {
+	case archive.Encrypted != nil:
+		pass, err := passphrase()
+		if err != nil {
+			return Profile{}, err
+		}
+
+		data, err := decryptWithPassphrase([]byte(pass), *archive.Encrypted)
+		if err != nil {
+			return Profile{}, err
+		}
+
+		var p Profile
+		if err := json.Unmarshal(data, &p); err != nil {
+			return Profile{}, fmt.Errorf("%w: %w", ErrInvalidProfile, err)
+		}
+
+		return p, nil
+	case archive.Profile != nil:
+		return *archive.Profile, nil
+	default:
+		return Profile{}, ErrInvalidProfile
+	}
+}
+
+// ImportProfile applies the profile to this machine, resolving conflicts with the strategy.
+// The config files are checked before anything is written, a profile with invalid settings changes nothing.
+func ImportProfile(fs afero.Fs, accounts AccountProvider, history *ChannelHistoryManager, p Profile, strategy ConflictStrategy) (ProfileImportResult, error) {
+	var result ProfileImportResult
+
+	if !slices.Contains([]ConflictStrategy{ConflictKeep, ConflictOverwrite, ConflictMerge}, strategy) {
+		return result, fmt.Errorf("unknown conflict strategy %q, use keep, overwrite or merge", strategy)
+	}
+
+	files := map[string][]byte{}
+	for _, name := range profileConfigFiles {
+		imported, ok := p.ConfigFiles[name]
+		if !ok {
+			continue
+		}
+
+		local, err := readConfigFile(fs, name)
+		if err != nil {
+			return result, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		data, changed, err := resolveConfigFile(local, []byte(imported), strategy)
+		if err != nil {
+			return result, fmt.Errorf("failed to import %s: %w", name, err)
+		}
+
+		if !changed {
+			result.ConfigFilesKept = append(result.ConfigFilesKept, name)
+			continue
+		}
+
+		if name == settingsFileName {
+			if err := checkSettings(data); err != nil {
+				return result, fmt.Errorf("the imported %s is invalid: %w", name, err)
+			}
+		}
+
+		files[name] = data
+	}
+
+	local, err := accounts.loadAccounts()
+	if err != nil {
+		return result, fmt.Errorf("failed to read accounts: %w", err)
+	}
+
+	merged := mergeAccounts(local, p.Accounts, strategy, &result)
+	if err := accounts.saveAccounts(merged); err != nil {
+		return result, fmt.Errorf("failed to save accounts: %w", err)
+	}
+
+	for _, name := range profileConfigFiles {
+		data, ok := files[name]
+		if !ok {
+			continue
+		}
+
+		if err := writeConfigFile(fs, name, data); err != nil {
+			return result, fmt.Errorf("failed to write %s: %w", name, err)
+		}
+
+		result.ConfigFilesWritten = append(result.ConfigFilesWritten, name)
+	}
+
+	result.Channels, err = history.importHistory(p.ChannelHistory, strategy)
+	if err != nil {
+		return result, fmt.Errorf("failed to import channel history: %w", err)
+	}
+
+	return result, nil
+}
+
+// mergeAccounts adds the imported accounts to the local ones. With ConflictMerge existing accounts only take tokens
+// from the profile if they have none, with ConflictOverwrite they take names and tokens. The auth method always travels
+// with the tokens. The local main account stays main.
+func mergeAccounts(local, imported []Account, strategy ConflictStrategy, result *ProfileImportResult) []Account {
+	merged := slices.DeleteFunc(slices.Clone(local), func(a Account) bool { return a.IsAnonymous })
+	hasMain := slices.ContainsFunc(merged, func(a Account) bool { return a.IsMain })
+
+	for _, acc := range imported {
+		if acc.IsAnonymous {
+			continue
+		}
+
+		hasTokens := acc.AccessToken != "" && acc.RefreshToken != ""
+
+		i := slices.IndexFunc(merged, func(a Account) bool { return a.ID == acc.ID })
+		if i == -1 {
+			acc.IsMain = acc.IsMain && !hasMain && hasTokens
+			hasMain = hasMain || acc.IsMain
+			merged = append(merged, acc)
+
+			result.AccountsAdded = append(result.AccountsAdded, acc.DisplayName)
+			if !hasTokens {
+				result.AccountsWithoutTokens = append(result.AccountsWithoutTokens, acc.DisplayName)
+			}
+
+			continue
+		}
+
+		existing := &merged[i]
+
+		switch {
+		case strategy == ConflictOverwrite:
+			existing.LoginName, existing.DisplayName = acc.LoginName, acc.DisplayName
+			if hasTokens {
+				existing.AccessToken, existing.RefreshToken = acc.AccessToken, acc.RefreshToken
+				existing.AuthMethod = acc.AuthMethod
+			}
+		case strategy == ConflictMerge && existing.AccessToken == "" && hasTokens:
+			existing.AccessToken, existing.RefreshToken = acc.AccessToken, acc.RefreshToken
+			existing.AuthMethod = acc.AuthMethod
+		default:
+			result.AccountsKept = append(result.AccountsKept, existing.DisplayName)
+			continue
+		}
+
+		result.AccountsUpdated = append(result.AccountsUpdated, existing.DisplayName)
+	}
+
+	return merged
+}
+
+// resolveConfigFile returns the content of a config file after the import and if it changed.
+func resolveConfigFile(local, imported []byte, strategy ConflictStrategy) ([]byte, bool, error) {
+	if len(local) == 0 || strategy == ConflictOverwrite {
+		return imported, string(local) != string(imported), nil
+	}
+
+	if strategy == ConflictKeep {
+		return local, false, nil
+	}
+
+	var localValues, importedValues map[string]any
+	if err := yaml.Unmarshal(local, &localValues); err != nil {
+		return nil, false, fmt.Errorf("failed to parse local file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(imported, &importedValues); err != nil {
+		return nil, false, fmt.Errorf("failed to parse imported file: %w", err)
+	}
+
+	if !mergeYAMLMaps(localValues, importedValues) {
+		return local, false, nil
+	}
+
+	data, err := yaml.Marshal(localValues)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return data, true, nil
+}
+
+// mergeYAMLMaps adds the keys of src missing in dst, nested maps are merged. It reports if dst changed.
+func mergeYAMLMaps(dst, src map[string]any) bool {
+	changed := false
+
+	for k, v := range src {
+		existing, ok := dst[k]
+		if !ok {
+			dst[k] = v
+			changed = true
+			continue
+		}
+
+		existingMap, ok := existing.(map[string]any)
+		if !ok {
+			continue
+		}
+
+		if srcMap, ok := v.(map[string]any); ok && mergeYAMLMaps(existingMap, srcMap) {
+			changed = true
+		}
+	}
+
+	return changed
+}
+
+func checkSettings(data []byte) error {
+	settings := BuildDefaultSettings()
+	if err := yaml.Unmarshal(data, &settings); err != nil {
+		return err
+	}
+
+	if err := settings.validate(); err != nil {
+		return err
+	}
+
+	return settings.BlockSettings.compile()
+}
@@ -0,0 +1,200 @@
+package save
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestProfileArchive(t *testing.T) {
+	t.Parallel()
+
+	p := Profile{
+		CreatedAt:      time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		IncludesTokens: true,
+		Accounts:       []Account{{ID: "1", DisplayName: "one", AccessToken: "access", RefreshToken: "refresh"}},
+		ConfigFiles:    map[string]string{settingsFileName: "chat:\n  timestamp_format: \"15:04\"\n"},
+		ChannelHistory: []ChannelHistoryEntry{{ChannelLogin: "streamer", VisitedAt: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)}},
+	}
+
+	t.Run("tokens require a passphrase", func(t *testing.T) {
+		t.Parallel()
+
+		require.ErrorIs(t, writeProfile(&bytes.Buffer{}, p, "", testArgon2Params), ErrProfileTokensUnencrypted)
+	})
+
+	t.Run("encrypted", func(t *testing.T) {
+		t.Parallel()
+
+		var buf bytes.Buffer
+		require.NoError(t, writeProfile(&buf, p, "secret", testArgon2Params))
+		require.NotContains(t, buf.String(), "access")
+
+		read, err := ReadProfile(bytes.NewReader(buf.Bytes()), func() (string, error) { return "secret", nil })
+		require.NoError(t, err)
+		require.Equal(t, p, read)
+
+		_, err = ReadProfile(bytes.NewReader(buf.Bytes()), func() (string, error) { return "wrong", nil })
+		require.ErrorIs(t, err, ErrWrongPassphrase)
+	})
+
+	t.Run("plain without tokens", func(t *testing.T) {
+		t.Parallel()
+
+		plain := p
+		plain.IncludesTokens = false
+		plain.Accounts = []Account{{ID: "1", DisplayName: "one"}}
+
+		var buf bytes.Buffer
+		require.NoError(t, writeProfile(&buf, plain, "", testArgon2Params))
+
+		read, err := ReadProfile(&buf, func() (string, error) { return "", errors.New("not encrypted") })
+		require.NoError(t, err)
+		require.Equal(t, plain, read)
+	})
+
+	t.Run("rejects other files and newer versions", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := ReadProfile(bytes.NewBufferString(`{"accounts":[]}`), nil)
+		require.ErrorIs(t, err, ErrInvalidProfile)
+
+		_, err = ReadProfile(bytes.NewBufferString(`{"format":"chatuino-profile","version":99,"profile":{}}`), nil)
+		require.ErrorContains(t, err, "newer Chatuino")
+	})
+}
+
+func TestMergeAccounts(t *testing.T) {
+	t.Parallel()
+
+	local := []Account{
+		{ID: "1", DisplayName: "one", IsMain: true, AccessToken: "local-access", RefreshToken: "local-refresh", AuthMethod: AuthMethodBrowser},
+		{ID: "2", DisplayName: "two", AuthMethod: AuthMethodBrowser}, // imported earlier without tokens
+		anonymousAccount,
+	}
+
+	imported := []Account{
+		{ID: "1", DisplayName: "One", IsMain: true, AccessToken: "new-access", RefreshToken: "new-refresh", AuthMethod: AuthMethodDevice},
+		{ID: "2", DisplayName: "Two", AccessToken: "two-access", RefreshToken: "two-refresh", AuthMethod: AuthMethodDevice},
+		{ID: "3", DisplayName: "three"},
+	}
+
+	t.Run("keep", func(t *testing.T) {
+		t.Parallel()
+
+		var result ProfileImportResult
+		merged := mergeAccounts(local, imported, ConflictKeep, &result)
+
+		require.Len(t, merged, 3)
+		require.Equal(t, local[0], merged[0])
+		require.Equal(t, local[1], merged[1])
+		require.False(t, merged[2].IsMain)
+		require.Equal(t, []string{"three"}, result.AccountsAdded)
+		require.Equal(t, []string{"three"}, result.AccountsWithoutTokens)
+		require.Equal(t, []string{"one", "two"}, result.AccountsKept)
+	})
+
+	t.Run("merge fills in missing tokens", func(t *testing.T) {
+		t.Parallel()
+
+		var result ProfileImportResult
+		merged := mergeAccounts(local, imported, ConflictMerge, &result)
+
+		require.Equal(t, "local-access", merged[0].AccessToken)
+		require.Equal(t, AuthMethodBrowser, merged[0].AuthMethod)
+		require.Equal(t, "two-access", merged[1].AccessToken)
+		require.Equal(t, AuthMethodDevice, merged[1].AuthMethod)
+		require.Equal(t, []string{"two"}, result.AccountsUpdated)
+		require.Equal(t, []string{"one"}, result.AccountsKept)
+	})
+
+	t.Run("overwrite", func(t *testing.T) {
+		t.Parallel()
+
+		var result ProfileImportResult
+		merged := mergeAccounts(local, imported, ConflictOverwrite, &result)
+
+		require.Equal(t, "new-access", merged[0].AccessToken)
+		require.Equal(t, AuthMethodDevice, merged[0].AuthMethod)
+		require.Equal(t, "One", merged[0].DisplayName)
+		require.True(t, merged[0].IsMain)
+		require.Equal(t, "two-access", merged[1].AccessToken)
+		require.Equal(t, AuthMethodDevice, merged[1].AuthMethod)
+		require.Equal(t, []string{"One", "Two"}, result.AccountsUpdated)
+	})
+
+	t.Run("main account of the profile", func(t *testing.T) {
+		t.Parallel()
+
+		var result ProfileImportResult
+		merged := mergeAccounts(nil, imported, ConflictKeep, &result)
+
+		require.True(t, merged[0].IsMain)
+		require.False(t, merged[1].IsMain)
+	})
+}
+
+func TestResolveConfigFile(t *testing.T) {
+	t.Parallel()
+
+	local := []byte("chat:\n  timestamp_format: \"15:04\"\n")
+	imported := []byte("chat:\n  timestamp_format: \"15:04:05\"\n  spell_check_dictionary: /usr/share/hunspell/en_US\nmoderation:\n  store_chat_logs: true\n")
+
+	data, changed, err := resolveConfigFile(local, imported, ConflictKeep)
+	require.NoError(t, err)
+	require.False(t, changed)
+	require.Equal(t, local, data)
+
+	data, changed, err = resolveConfigFile(nil, imported, ConflictKeep)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, imported, data)
+
+	data, changed, err = resolveConfigFile(local, imported, ConflictOverwrite)
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.Equal(t, imported, data)
+
+	data, changed, err = resolveConfigFile(local, imported, ConflictMerge)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	var merged map[string]any
+	require.NoError(t, yaml.Unmarshal(data, &merged))
+	require.Equal(t, map[string]any{
+		"chat": map[string]any{
+			"timestamp_format":       "15:04",
+			"spell_check_dictionary": "/usr/share/hunspell/en_US",
+		},
+		"moderation": map[string]any{"store_chat_logs": true},
+	}, merged)
+
+	_, changed, err = resolveConfigFile(imported, local, ConflictMerge)
+	require.NoError(t, err)
+	require.False(t, changed)
+}
+
+func TestMergeChannelHistory(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	local := []ChannelHistoryEntry{
+		{ChannelLogin: "a", VisitedAt: now.Add(-time.Hour)},
+		{ChannelLogin: "b", VisitedAt: now.Add(-3 * time.Hour)},
+	}
+	imported := []ChannelHistoryEntry{
+		{ChannelLogin: "b", VisitedAt: now},
+		{ChannelLogin: "a", VisitedAt: now.Add(-2 * time.Hour)},
+		{ChannelLogin: "c", VisitedAt: now.Add(-4 * time.Hour)},
+	}
+
+	require.Equal(t, []ChannelHistoryEntry{
+		{ChannelLogin: "b", VisitedAt: now},
+		{ChannelLogin: "a", VisitedAt: now.Add(-time.Hour)},
+		{ChannelLogin: "c", VisitedAt: now.Add(-4 * time.Hour)},
+	}, mergeChannelHistory(local, imported))
+}
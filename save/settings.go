@@ -5,7 +5,9 @@ import (
 	"io"
 	"slices"
 	"strings"
+	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/julez-dev/chatuino/command"
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
@@ -13,6 +15,9 @@ import (
 
 const (
 	settingsFileName = "settings.yaml"
+
+	defaultScrollbackSize = 1000
+	minScrollbackSize     = 100
 )
 
 type Settings struct {
@@ -22,6 +27,7 @@ type Settings struct {
 	CustomCommands  []CustomCommand    `yaml:"custom_commands"`
 	BlockSettings   BlockSettings      `yaml:"block_settings"`
 	Security        SecuritySettings   `yaml:"security"`
+	Scripts         ScriptSettings     `yaml:"scripts"`
 }
 
 type ModerationSettings struct {
@@ -33,22 +39,36 @@ type ModerationSettings struct {
 type ChatSettings struct {
 	GraphicBadges              bool   `yaml:"graphic_badges"`
 	GraphicEmotes              bool   `yaml:"graphic_emotes"`
+	GraphicsProtocol           string `yaml:"graphics_protocol"` // auto, kitty, sixel, iterm2 or halfblock, default: auto
+	ImageCacheSize             string `yaml:"image_cache_size"`  // max disk size of converted images, e.g. "512MB"; "0" disables the limit, default: "512MB"
 	DisableBadges              bool   `yaml:"disable_badges"`
 	DisablePaddingWrappedLines bool   `yaml:"disable_padding_wrapped_lines"`
 	SmoothScroll               bool   `yaml:"smooth_scroll"`
+	ScrollbackSize             int    `yaml:"scrollback_size"`          // messages kept in memory per chat, older messages are moved to disk, default: 1000
 	TimeFormat                 string `yaml:"time_format"`              // Go time format string, default: "15:04:05"
 	UserInspectTimeFormat      string `yaml:"user_inspect_time_format"` // Go time format string, default: "2006-01-02 15:04:05"
+	SpellCheckDictionary       string `yaml:"spell_check_dictionary"`   // path to a Hunspell .dic file, empty disables spell checking
 }
 
 type BlockSettings struct {
-	Users []string `yaml:"users"`
-	Words []string `yaml:"words"`
+	Users              []string    `yaml:"users"`
+	Words              []string    `yaml:"words"`
+	Rules              []BlockRule `yaml:"rules"`
+	ImportTwitchBlocks bool        `yaml:"import_twitch_blocks"` // hide users blocked on Twitch by one of the accounts, default: false
+
+	// TwitchUsers are the logins blocked on Twitch, imported at startup and changed by /twitchblock and /twitchunblock
+	TwitchUsers []string `yaml:"-"`
 }
 
 type SecuritySettings struct {
 	CheckLinks bool `yaml:"check_links"`
 }
 
+type ScriptSettings struct {
+	Disabled   bool   `yaml:"disabled"`    // don't load scripts from the scripts directory
+	TimeBudget string `yaml:"time_budget"` // Go duration a script may run per message, default: "10ms"
+}
+
 type CustomCommand struct {
 	Trigger     string `yaml:"trigger"`
 	Replacement string `yaml:"replacement"`
@@ -65,6 +85,8 @@ func BuildDefaultSettings() Settings {
 		Chat: ChatSettings{
 			TimeFormat:            "15:04:05",
 			UserInspectTimeFormat: "2006-01-02 15:04:05",
+			ImageCacheSize:        "512MB",
+			ScrollbackSize:        defaultScrollbackSize,
 		},
 	}
 }
@@ -87,6 +109,14 @@ func (s Settings) validate() error {
 		}
 	}
 
+	if !slices.Contains([]string{"", "auto", "kitty", "sixel", "iterm2", "halfblock"}, s.Chat.GraphicsProtocol) {
+		return fmt.Errorf("chat graphics protocol %q must be one of auto, kitty, sixel, iterm2 or halfblock", s.Chat.GraphicsProtocol)
+	}
+
+	if _, err := humanize.ParseBytes(s.Chat.ImageCacheSize); err != nil {
+		return fmt.Errorf("chat image cache size %q is invalid: %w", s.Chat.ImageCacheSize, err)
+	}
+
 	if slices.Contains(s.BlockSettings.Users, "") {
 		return fmt.Errorf("block settings user entry can't be empty string")
 	}
@@ -95,9 +125,44 @@ func (s Settings) validate() error {
 		return fmt.Errorf("block settings word entry can't be empty string")
 	}
 
+	if s.Scripts.TimeBudget != "" {
+		if d, err := time.ParseDuration(s.Scripts.TimeBudget); err != nil || d <= 0 {
+			return fmt.Errorf("scripts time budget %q must be a positive duration like 10ms", s.Scripts.TimeBudget)
+		}
+	}
+
 	return nil
 }
 
+// ScrollbackLimit returns the number of messages kept in memory per chat, falling back to the default when unset.
+func (c ChatSettings) ScrollbackLimit() int {
+	if c.ScrollbackSize <= 0 {
+		return defaultScrollbackSize
+	}
+
+	return max(c.ScrollbackSize, minScrollbackSize)
+}
+
+// Budget returns the time a script may run per message, 0 when unset or invalid so the script engine uses its default.
+func (s ScriptSettings) Budget() time.Duration {
+	d, err := time.ParseDuration(s.TimeBudget)
+	if err != nil || d <= 0 {
+		return 0
+	}
+
+	return d
+}
+
+// ImageCacheBudgetBytes returns the configured image cache size in bytes, 0 means unlimited.
+func (c ChatSettings) ImageCacheBudgetBytes() int64 {
+	size, err := humanize.ParseBytes(c.ImageCacheSize)
+	if err != nil {
+		return 0
+	}
+
+	return int64(size)
+}
+
 func (s Settings) BuildCustomSuggestionMap() map[string]string {
 	m := make(map[string]string, len(s.CustomCommands))
 	for _, c := range s.CustomCommands {
@@ -138,5 +203,9 @@ func SettingsFromDisk() (Settings, error) {
 		return Settings{}, err
 	}
 
+	if err := settings.BlockSettings.compile(); err != nil {
+		return Settings{}, err
+	}
+
 	return settings, nil
 }
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+
+	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/script"
+	"github.com/spf13/afero"
+	"github.com/urfave/cli/v3"
+)
+
+func loadScripts(settings save.ScriptSettings) (*script.Engine, error) {
+	dir, err := save.ScriptsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	return script.LoadDir(afero.NewOsFs(), dir, settings.Budget())
+}
+
+var scriptCMD = &cli.Command{
+	Name:        "script",
+	Description: "Manage user scripts, which filter chat messages and trigger actions",
+	Usage:       "Manage user scripts",
+	Commands: []*cli.Command{
+		{
+			Name:        "test",
+			Description: "Run the fixtures of all scripts. The fixtures of <name>.star are read from <name>_test.yaml",
+			Usage:       "Run script fixtures",
+			Action: func(_ context.Context, _ *cli.Command) error {
+				settings, err := save.SettingsFromDisk()
+				if err != nil {
+					return fmt.Errorf("failed to read settings file: %w", err)
+				}
+
+				dir, err := save.ScriptsDir()
+				if err != nil {
+					return err
+				}
+
+				fsys := afero.NewOsFs()
+				paths, err := afero.Glob(fsys, filepath.Join(dir, "*"+script.Extension))
+				if err != nil {
+					return err
+				}
+
+				if len(paths) == 0 {
+					fmt.Printf("No scripts found in %s\n", dir)
+					return nil
+				}
+
+				var failed int
+				for _, path := range paths {
+					src, err := afero.ReadFile(fsys, path)
+					if err != nil {
+						return err
+					}
+
+					s, err := script.Compile(filepath.Base(path), string(src))
+					if err != nil {
+						fmt.Printf("FAIL %s\n  %s\n", filepath.Base(path), err)
+						failed++
+						continue
+					}
+
+					fixtures, err := script.LoadFixtures(fsys, script.FixturePath(path))
+					if err != nil {
+						if errors.Is(err, fs.ErrNotExist) {
+							fmt.Printf("SKIP %s (no fixtures)\n", s.Name)
+							continue
+						}
+
+						return err
+					}
+
+					errs := s.Test(fixtures, settings.Scripts.Budget())
+					if len(errs) == 0 {
+						fmt.Printf("PASS %s (%d fixtures)\n", s.Name, len(fixtures))
+						continue
+					}
+
+					failed++
+					fmt.Printf("FAIL %s\n", s.Name)
+					for _, err := range errs {
+						fmt.Printf("  %s\n", err)
+					}
+				}
+
+				if failed > 0 {
+					return fmt.Errorf("%d scripts failed", failed)
+				}
+
+				return nil
+			},
+		},
+	},
+}
@@ -0,0 +1,80 @@
+package script
+
+import (
+	"fmt"
+	"strconv"
+
+	"go.starlark.net/starlark"
+)
+
+type actionKind string
+
+const (
+	actionHide      actionKind = "hide"
+	actionHighlight actionKind = "highlight"
+	actionRewrite   actionKind = "rewrite"
+	actionRoute     actionKind = "route"
+	actionCommand   actionKind = "command"
+)
+
+const defaultHighlightColor = "#ffd700"
+
+// predeclared are the builtins available to scripts, each returns an action.
+var predeclared = starlark.StringDict{
+	"hide":      starlark.NewBuiltin("hide", hideBuiltin),
+	"highlight": starlark.NewBuiltin("highlight", highlightBuiltin),
+	"rewrite":   starlark.NewBuiltin("rewrite", valueBuiltin(actionRewrite, "text")),
+	"route":     starlark.NewBuiltin("route", valueBuiltin(actionRoute, "channel")),
+	"command":   starlark.NewBuiltin("command", valueBuiltin(actionCommand, "text")),
+}
+
+// action is returned by the builtins and tells the engine what to do with the message.
+type action struct {
+	kind  actionKind
+	value string
+}
+
+func (a *action) String() string {
+	if a.value == "" {
+		return string(a.kind) + "()"
+	}
+
+	return string(a.kind) + "(" + strconv.Quote(a.value) + ")"
+}
+
+func (a *action) Type() string          { return "action" }
+func (a *action) Freeze()               {}
+func (a *action) Truth() starlark.Bool  { return starlark.True }
+func (a *action) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: action") }
+
+func hideBuiltin(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+
+	return &action{kind: actionHide}, nil
+}
+
+func highlightBuiltin(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	color := defaultHighlightColor
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "color?", &color); err != nil {
+		return nil, err
+	}
+
+	return &action{kind: actionHighlight, value: color}, nil
+}
+
+func valueBuiltin(kind actionKind, param string) func(*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple) (starlark.Value, error) {
+	return func(_ *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var value string
+		if err := starlark.UnpackArgs(b.Name(), args, kwargs, param, &value); err != nil {
+			return nil, err
+		}
+
+		if value == "" {
+			return nil, fmt.Errorf("%s: %s must not be empty", b.Name(), param)
+		}
+
+		return &action{kind: kind, value: value}, nil
+	}
+}
@@ -0,0 +1,270 @@
+// Package script runs user provided Starlark scripts for chat messages.
+// Scripts can hide, highlight, rewrite or route messages and trigger chat commands.
+//
+// A script defines an on_message function, which receives the message and returns
+// None, an action or a list of actions:
+//
+//	def on_message(msg):
+//	    if "buy followers" in msg.text.lower():
+//	        return [hide(), command("/timeout " + msg.user + " 600 spam")]
+//
+// Scripts are sandboxed: Starlark has no access to the file system, network or clock and
+// loading other modules is not supported. Each call is limited by a time budget and a step limit.
+package script
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/afero"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+	"go.starlark.net/syntax"
+)
+
+const (
+	// Extension is the file extension of scripts loaded from the scripts directory.
+	Extension = ".star"
+
+	// DefaultBudget is the time a single script may take for one message.
+	DefaultBudget = 10 * time.Millisecond
+
+	maxExecutionSteps = 1_000_000
+	entrypoint        = "on_message"
+)
+
+// Message is the chat message passed to scripts.
+type Message struct {
+	ID           string   `yaml:"id"`
+	Channel      string   `yaml:"channel"`
+	User         string   `yaml:"user"` // login name of the sender
+	DisplayName  string   `yaml:"display_name"`
+	Text         string   `yaml:"text"`
+	Badges       []string `yaml:"badges"`
+	Mod          bool     `yaml:"mod"`
+	Subscriber   bool     `yaml:"subscriber"`
+	VIP          bool     `yaml:"vip"`
+	FirstMessage bool     `yaml:"first_message"`
+}
+
+// Result is the combined outcome of all scripts for a message.
+type Result struct {
+	Hide      bool     `yaml:"hide"`
+	Highlight string   `yaml:"highlight"` // color the message is highlighted with, empty if not highlighted
+	Text      string   `yaml:"text"`      // rewritten message text, empty if unchanged
+	Routes    []string `yaml:"routes"`    // channels of the tabs the message is additionally shown in
+	Commands  []string `yaml:"commands"`  // chat commands run in the channel of the message
+}
+
+// Script is a compiled user script.
+type Script struct {
+	Name      string
+	onMessage starlark.Callable
+}
+
+// Engine runs scripts in order of their names.
+type Engine struct {
+	scripts []Script
+	budget  time.Duration
+}
+
+func New(budget time.Duration, scripts ...Script) *Engine {
+	if budget <= 0 {
+		budget = DefaultBudget
+	}
+
+	slices.SortFunc(scripts, func(a, b Script) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return &Engine{scripts: scripts, budget: budget}
+}
+
+// LoadDir compiles all scripts inside dir. A missing directory results in an engine without scripts.
+func LoadDir(fsys afero.Fs, dir string, budget time.Duration) (*Engine, error) {
+	entries, err := afero.ReadDir(fsys, dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return New(budget), nil
+		}
+
+		return nil, err
+	}
+
+	var (
+		scripts []Script
+		errs    []error
+	)
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != Extension {
+			continue
+		}
+
+		src, err := afero.ReadFile(fsys, filepath.Join(dir, entry.Name()))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		s, err := Compile(entry.Name(), string(src))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		scripts = append(scripts, s)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return New(budget, scripts...), nil
+}
+
+// Compile executes the top level of a script and looks up its on_message function.
+func Compile(name string, src string) (Script, error) {
+	thread := newThread(name)
+
+	globals, err := starlark.ExecFileOptions(&syntax.FileOptions{}, thread, name, src, predeclared)
+	if err != nil {
+		return Script{}, fmt.Errorf("failed to compile script %s: %w", name, err)
+	}
+
+	fn, ok := globals[entrypoint].(starlark.Callable)
+	if !ok {
+		return Script{}, fmt.Errorf("script %s does not define the %s function", name, entrypoint)
+	}
+
+	return Script{Name: name, onMessage: fn}, nil
+}
+
+// Len returns the number of loaded scripts.
+func (e *Engine) Len() int {
+	return len(e.scripts)
+}
+
+// Run passes the message through all scripts. Each script sees the text rewritten by the scripts before it.
+// A hidden message is not passed to the remaining scripts. Scripts that fail are skipped, their errors are returned
+// alongside the result of the other scripts.
+func (e *Engine) Run(msg Message) (Result, error) {
+	var (
+		result Result
+		errs   []error
+	)
+
+	for _, s := range e.scripts {
+		actions, err := s.call(msg, e.budget)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		for _, a := range actions {
+			switch a.kind {
+			case actionHide:
+				result.Hide = true
+			case actionHighlight:
+				result.Highlight = a.value
+			case actionRewrite:
+				result.Text = a.value
+				msg.Text = a.value
+			case actionRoute:
+				result.Routes = append(result.Routes, strings.ToLower(strings.TrimPrefix(a.value, "#")))
+			case actionCommand:
+				result.Commands = append(result.Commands, a.value)
+			}
+		}
+
+		if result.Hide {
+			break
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+func (s Script) call(msg Message, budget time.Duration) ([]*action, error) {
+	thread := newThread(s.Name)
+
+	timer := time.AfterFunc(budget, func() {
+		thread.Cancel("time budget exceeded")
+	})
+	defer timer.Stop()
+
+	v, err := starlark.Call(thread, s.onMessage, starlark.Tuple{messageValue(msg)}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("script %s failed: %w", s.Name, err)
+	}
+
+	actions, err := toActions(v)
+	if err != nil {
+		return nil, fmt.Errorf("script %s: %w", s.Name, err)
+	}
+
+	return actions, nil
+}
+
+func newThread(name string) *starlark.Thread {
+	thread := &starlark.Thread{
+		Name: name,
+		Print: func(_ *starlark.Thread, msg string) {
+			log.Logger.Info().Str("script", name).Msg(msg)
+		},
+		// Load is not set, scripts can't load other modules
+	}
+	thread.SetMaxExecutionSteps(maxExecutionSteps)
+
+	return thread
+}
+
+func messageValue(msg Message) starlark.Value {
+	badges := make([]starlark.Value, 0, len(msg.Badges))
+	for _, b := range msg.Badges {
+		badges = append(badges, starlark.String(b))
+	}
+
+	return starlarkstruct.FromStringDict(starlarkstruct.Default, starlark.StringDict{
+		"id":           starlark.String(msg.ID),
+		"channel":      starlark.String(msg.Channel),
+		"user":         starlark.String(msg.User),
+		"display_name": starlark.String(msg.DisplayName),
+		"text":         starlark.String(msg.Text),
+		"badges":       starlark.Tuple(badges),
+		"is_mod":       starlark.Bool(msg.Mod),
+		"is_sub":       starlark.Bool(msg.Subscriber),
+		"is_vip":       starlark.Bool(msg.VIP),
+		"is_first":     starlark.Bool(msg.FirstMessage),
+	})
+}
+
+func toActions(v starlark.Value) ([]*action, error) {
+	switch v := v.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case *action:
+		return []*action{v}, nil
+	case *starlark.List, starlark.Tuple:
+		seq := v.(starlark.Indexable)
+		actions := make([]*action, 0, seq.Len())
+		for i := range seq.Len() {
+			a, ok := seq.Index(i).(*action)
+			if !ok {
+				return nil, fmt.Errorf("%s returned a %s inside the list of actions", entrypoint, seq.Index(i).Type())
+			}
+
+			actions = append(actions, a)
+		}
+
+		return actions, nil
+	}
+
+	return nil, fmt.Errorf("%s must return None, an action or a list of actions, got %s", entrypoint, v.Type())
+}
@@ -0,0 +1,153 @@
+package script
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+func mustCompile(t *testing.T, name, src string) Script {
+	t.Helper()
+
+	s, err := Compile(name, src)
+	require.NoError(t, err)
+
+	return s
+}
+
+func TestCompile(t *testing.T) {
+	t.Parallel()
+
+	_, err := Compile("missing.star", `x = 1`)
+	require.ErrorContains(t, err, "does not define the on_message function")
+
+	_, err = Compile("syntax.star", `def on_message(msg)`)
+	require.Error(t, err)
+
+	// loading other modules is not allowed
+	_, err = Compile("load.star", `load("other.star", "x")
+def on_message(msg):
+    return None`)
+	require.Error(t, err)
+}
+
+func TestEngine_Run(t *testing.T) {
+	t.Parallel()
+
+	spam := mustCompile(t, "a_spam.star", `
+def on_message(msg):
+    if "buy followers" in msg.text.lower():
+        return [hide(), command("/timeout " + msg.user + " 600 spam")]
+`)
+
+	rewrite := mustCompile(t, "b_rewrite.star", `
+def on_message(msg):
+    if msg.is_mod:
+        return [highlight("#ff0000"), rewrite(msg.text.upper())]
+`)
+
+	route := mustCompile(t, "c_route.star", `
+def on_message(msg):
+    if msg.text.startswith("HELLO"):
+        return route("#Other")
+`)
+
+	e := New(time.Second, route, rewrite, spam)
+
+	res, err := e.Run(Message{User: "spammer", Text: "Buy followers now"})
+	require.NoError(t, err)
+	require.Equal(t, Result{Hide: true, Commands: []string{"/timeout spammer 600 spam"}}, res)
+
+	// rewritten text is passed to the following scripts
+	res, err = e.Run(Message{User: "mod", Text: "hello", Mod: true})
+	require.NoError(t, err)
+	require.Equal(t, Result{Highlight: "#ff0000", Text: "HELLO", Routes: []string{"other"}}, res)
+
+	res, err = e.Run(Message{Text: "hello"})
+	require.NoError(t, err)
+	require.Equal(t, Result{}, res)
+}
+
+func TestEngine_RunErrors(t *testing.T) {
+	t.Parallel()
+
+	loop := mustCompile(t, "loop.star", `
+def on_message(msg):
+    for i in range(100000000):
+        pass
+`)
+
+	invalid := mustCompile(t, "invalid.star", `
+def on_message(msg):
+    return "hide"
+`)
+
+	highlight := mustCompile(t, "highlight.star", `
+def on_message(msg):
+    return highlight()
+`)
+
+	e := New(50*time.Millisecond, loop, invalid, highlight)
+
+	// failing scripts are skipped
+	res, err := e.Run(Message{Text: "hello"})
+	require.ErrorContains(t, err, "loop.star")
+	require.ErrorContains(t, err, "invalid.star")
+	require.Equal(t, Result{Highlight: defaultHighlightColor}, res)
+}
+
+func TestLoadDir(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "scripts/hide.star", []byte("def on_message(msg):\n    return hide()\n"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "scripts/hide_test.yaml", []byte("- name: hidden\n  message:\n    text: hi\n  expect:\n    hide: true\n"), 0o644))
+
+	e, err := LoadDir(fs, "scripts", 0)
+	require.NoError(t, err)
+	require.Equal(t, 1, e.Len())
+
+	e, err = LoadDir(fs, "missing", 0)
+	require.NoError(t, err)
+	require.Equal(t, 0, e.Len())
+
+	require.NoError(t, afero.WriteFile(fs, "scripts/broken.star", []byte("def on_message(msg)"), 0o644))
+	_, err = LoadDir(fs, "scripts", 0)
+	require.ErrorContains(t, err, "broken.star")
+}
+
+func TestScript_Test(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "spam_test.yaml", []byte(`
+- name: spam is hidden
+  message:
+    user: spammer
+    text: buy followers
+  expect:
+    hide: true
+    commands: ["/timeout spammer 600"]
+- name: wrong expectation
+  message:
+    text: hello
+  expect:
+    hide: true
+`), 0o644))
+
+	fixtures, err := LoadFixtures(fs, FixturePath("spam.star"))
+	require.NoError(t, err)
+	require.Len(t, fixtures, 2)
+
+	s := mustCompile(t, "spam.star", `
+def on_message(msg):
+    if "followers" in msg.text:
+        return [hide(), command("/timeout " + msg.user + " 600")]
+`)
+
+	errs := s.Test(fixtures, time.Second)
+	require.Len(t, errs, 1)
+	require.ErrorContains(t, errs[0], "wrong expectation")
+}
@@ -0,0 +1,76 @@
+package script
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// FixtureSuffix replaces the script extension to find the fixture file of a script,
+// the fixtures of spam.star are read from spam_test.yaml.
+const FixtureSuffix = "_test.yaml"
+
+// Fixture is a message and the result a script is expected to produce for it.
+type Fixture struct {
+	Name    string  `yaml:"name"`
+	Message Message `yaml:"message"`
+	Expect  Result  `yaml:"expect"`
+}
+
+// FixturePath returns the path of the fixture file belonging to the script path.
+func FixturePath(scriptPath string) string {
+	return strings.TrimSuffix(scriptPath, Extension) + FixtureSuffix
+}
+
+// LoadFixtures reads the fixtures from a YAML file containing a list of fixtures.
+func LoadFixtures(fsys afero.Fs, path string) ([]Fixture, error) {
+	data, err := afero.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures []Fixture
+	if err := yaml.Unmarshal(data, &fixtures); err != nil {
+		return nil, fmt.Errorf("failed to parse fixtures %s: %w", path, err)
+	}
+
+	return fixtures, nil
+}
+
+// Test runs the fixtures against the script alone and returns an error for each fixture that did not produce
+// the expected result.
+func (s Script) Test(fixtures []Fixture, budget time.Duration) []error {
+	engine := New(budget, s)
+
+	var errs []error
+	for i, f := range fixtures {
+		name := f.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i+1)
+		}
+
+		got, err := engine.Run(f.Message)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fixture %s: %w", name, err))
+			continue
+		}
+
+		if !resultsEqual(got, f.Expect) {
+			errs = append(errs, fmt.Errorf("fixture %s: expected %+v, got %+v", name, f.Expect, got))
+		}
+	}
+
+	return errs
+}
+
+func resultsEqual(a, b Result) bool {
+	return a.Hide == b.Hide &&
+		a.Highlight == b.Highlight &&
+		a.Text == b.Text &&
+		slices.Equal(a.Routes, b.Routes) &&
+		slices.Equal(a.Commands, b.Commands)
+}
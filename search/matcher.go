@@ -153,6 +153,32 @@ func (m *PropertyMatcher) Match(msg *twitchirc.PrivateMessage) bool {
 	}
 }
 
+// ThreadMatcher matches the message starting a reply thread and all replies in it.
+type ThreadMatcher struct {
+	id string
+}
+
+func NewThreadMatcher(id string) *ThreadMatcher {
+	return &ThreadMatcher{id: id}
+}
+
+func (m *ThreadMatcher) Match(msg *twitchirc.PrivateMessage) bool {
+	return msg.ID == m.id || msg.ThreadParentMsgID == m.id || msg.ParentMsgID == m.id
+}
+
+// ChannelMatcher matches messages sent in a channel, compared case-insensitively against the channel login.
+type ChannelMatcher struct {
+	channel string
+}
+
+func NewChannelMatcher(channel string) *ChannelMatcher {
+	return &ChannelMatcher{channel: strings.ToLower(strings.TrimPrefix(channel, "#"))}
+}
+
+func (m *ChannelMatcher) Match(msg *twitchirc.PrivateMessage) bool {
+	return strings.EqualFold(msg.ChannelUserName, m.channel)
+}
+
 // AndMatcher requires all child matchers to match. Short-circuits on first failure.
 type AndMatcher struct {
 	Matchers []Matcher
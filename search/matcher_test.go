@@ -244,6 +244,19 @@ func TestPropertyMatcher(t *testing.T) {
 	})
 }
 
+func TestThreadMatcher(t *testing.T) {
+	t.Parallel()
+
+	m := NewThreadMatcher("root")
+
+	require.True(t, m.Match(&twitchirc.PrivateMessage{ID: "root"}), "thread parent")
+	require.True(t, m.Match(&twitchirc.PrivateMessage{ID: "a", ParentMsgID: "root", ThreadParentMsgID: "root"}), "direct reply")
+	require.True(t, m.Match(&twitchirc.PrivateMessage{ID: "b", ParentMsgID: "a", ThreadParentMsgID: "root"}), "nested reply")
+	require.True(t, m.Match(&twitchirc.PrivateMessage{ID: "c", ParentMsgID: "root"}), "reply without thread tag")
+	require.False(t, m.Match(&twitchirc.PrivateMessage{ID: "d", ParentMsgID: "other", ThreadParentMsgID: "other"}))
+	require.False(t, m.Match(&twitchirc.PrivateMessage{ID: "e"}))
+}
+
 func TestAndMatcher(t *testing.T) {
 	t.Parallel()
 
@@ -287,3 +300,12 @@ func TestNotMatcher(t *testing.T) {
 		require.True(t, m.Match(msg("user", "goodbye")))
 	})
 }
+
+func TestChannelMatcher(t *testing.T) {
+	t.Parallel()
+
+	m := NewChannelMatcher("#Julez")
+
+	require.True(t, m.Match(&twitchirc.PrivateMessage{ChannelUserName: "julez"}))
+	require.False(t, m.Match(&twitchirc.PrivateMessage{ChannelUserName: "julez_dev"}))
+}
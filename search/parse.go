@@ -16,6 +16,9 @@ import (
 //	from:term           alias for user:
 //	badge:name          badge name substring
 //	is:mod|sub|vip|first boolean property
+//	thread:id           message id of a reply thread
+//	channel:name        channel the message was sent in
+//	in:name             alias for channel:
 //	regex:pattern       regex on content + username
 //	/pattern/           shorthand for regex:
 //	-prefix:value       negation
@@ -245,6 +248,10 @@ func tokenToMatcher(tok token) (Matcher, error) {
 		}
 
 		m = rm
+	case tok.prefix == "thread":
+		m = NewThreadMatcher(tok.value)
+	case tok.prefix == "channel" || tok.prefix == "in":
+		m = NewChannelMatcher(tok.value)
 	case tok.prefix == "is":
 		pm, err := parsePropertyMatcher(tok.value)
 		if err != nil {
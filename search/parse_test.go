@@ -29,6 +29,9 @@ func TestParse_Structure(t *testing.T) {
 		{name: "is:sub", query: "is:sub", wantType: "*search.PropertyMatcher"},
 		{name: "is:vip", query: "is:vip", wantType: "*search.PropertyMatcher"},
 		{name: "is:first", query: "is:first", wantType: "*search.PropertyMatcher"},
+		{name: "thread prefix", query: "thread:abc", wantType: "*search.ThreadMatcher"},
+		{name: "channel prefix", query: "channel:julez", wantType: "*search.ChannelMatcher"},
+		{name: "in prefix alias", query: "in:julez", wantType: "*search.ChannelMatcher"},
 		{name: "regex prefix", query: "regex:hel+o", wantType: "*search.RegexMatcher"},
 		{name: "regex slash syntax", query: "/hel+o/", wantType: "*search.RegexMatcher"},
 		{name: "negated", query: "-user:bot", wantType: "*search.NotMatcher"},
@@ -283,6 +286,10 @@ func typeNameOf(m Matcher) string {
 		return "*search.BadgeMatcher"
 	case *PropertyMatcher:
 		return "*search.PropertyMatcher"
+	case *ThreadMatcher:
+		return "*search.ThreadMatcher"
+	case *ChannelMatcher:
+		return "*search.ChannelMatcher"
 	case *AndMatcher:
 		return "*search.AndMatcher"
 	case *NotMatcher:
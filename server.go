@@ -3,8 +3,11 @@ package main
 import (
 	"context"
 	"net/http"
+	"os"
+	"slices"
 
 	"github.com/julez-dev/chatuino/server"
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v3"
 )
@@ -15,6 +18,11 @@ var serverCMD = &cli.Command{
 	Description: "Starts the chatuino which is responsible for proxying requests to the twitch API " +
 		"which require an app access token",
 	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:    "config",
+			Usage:   "Path to a YAML config file. Flags and environment variables take precedence over it",
+			Sources: cli.EnvVars("CHATUINO_SERVER_CONFIG"),
+		},
 		&cli.StringFlag{
 			Name:    "addr",
 			Usage:   "The address the server should listen at",
@@ -28,16 +36,14 @@ var serverCMD = &cli.Command{
 			Sources: cli.EnvVars("CHATUINO_REDIRECT_URL"),
 		},
 		&cli.StringFlag{
-			Name:     "client-id",
-			Usage:    "OAuth Client-ID",
-			Sources:  cli.EnvVars("CHATUINO_CLIENT_ID"),
-			Required: true,
+			Name:    "client-id",
+			Usage:   "OAuth Client-ID",
+			Sources: cli.EnvVars("CHATUINO_CLIENT_ID"),
 		},
 		&cli.StringFlag{
-			Name:     "client-secret",
-			Usage:    "OAuth Client-Secret",
-			Sources:  cli.EnvVars("CHATUINO_CLIENT_SECRET"),
-			Required: true,
+			Name:    "client-secret",
+			Usage:   "OAuth Client-Secret",
+			Sources: cli.EnvVars("CHATUINO_CLIENT_SECRET"),
 		},
 		&cli.StringFlag{
 			Name:    "redis-addr",
@@ -57,34 +63,58 @@ var serverCMD = &cli.Command{
 		},
 		&cli.BoolFlag{
 			Name:    "enable-ratelimit",
-			Usage:   "If proxy ratelimiting should be set",
+			Usage:   "If requests should be rate limited per client IP. Uses Redis if configured, in-memory limits otherwise",
+			Value:   true,
 			Sources: cli.EnvVars("CHATUINO_PROXY_RATELIMIT"),
 		},
+		&cli.StringSliceFlag{
+			Name:    "trusted-proxies",
+			Usage:   "CIDRs of reverse proxies whose X-Forwarded-For header is trusted to find the client IP, loopback only by default",
+			Value:   []string{"127.0.0.0/8", "::1/128"},
+			Sources: cli.EnvVars("CHATUINO_TRUSTED_PROXIES"),
+		},
+		&cli.StringFlag{
+			Name:    "internal-addr",
+			Usage:   "Listen address of /metrics, /internal/ready and /internal/cache, e.g. 127.0.0.1:9090",
+			Sources: cli.EnvVars("CHATUINO_INTERNAL_ADDR"),
+		},
+		&cli.StringFlag{
+			Name:    "internal-token",
+			Usage:   "Bearer token required by /metrics, /internal/ready and /internal/cache, serves them on the public address if no internal address is set",
+			Sources: cli.EnvVars("CHATUINO_INTERNAL_TOKEN"),
+		},
+		&cli.StringFlag{
+			Name:    "helix-cache",
+			Usage:   "Cache for Twitch API responses shared by all clients: memory, redis or off",
+			Value:   server.HelixCacheMemory,
+			Sources: cli.EnvVars("CHATUINO_HELIX_CACHE"),
+		},
+		&cli.IntFlag{
+			Name:    "helix-cache-entries",
+			Usage:   "Maximum number of responses kept by the in-memory cache",
+			Value:   1000,
+			Sources: cli.EnvVars("CHATUINO_HELIX_CACHE_ENTRIES"),
+		},
 	},
 	Action: func(ctx context.Context, command *cli.Command) error {
-		redisDB := command.Int("redis-db")
-		if redisDB < 0 || redisDB > 15 {
-			log.Warn().Int("db", redisDB).Msg("Invalid Redis DB number, using 0")
-			redisDB = 0
+		var file server.FileConfig
+		if path := command.String("config"); path != "" {
+			var err error
+			if file, err = server.LoadConfigFile(path); err != nil {
+				return err
+			}
 		}
 
-		api := server.New(
-			log.Logger,
-			server.Config{
-				HostAndPort:          command.String("addr"),
-				ClientID:             command.String("client-id"),
-				ClientSecret:         command.String("client-secret"),
-				RedirectURL:          command.String("redirect-url"),
-				EnableProxyRateLimit: command.Bool("enable-ratelimit"),
-				Version:              Version,
-				Redis: server.RedisConfig{
-					Addr:     command.String("redis-addr"),
-					Password: command.String("redis-password"),
-					DB:       redisDB,
-				},
-			},
-			http.DefaultClient,
-		)
+		if err := setupServerLogger(command, file.Log); err != nil {
+			return err
+		}
+
+		conf, err := serverConfig(command, file)
+		if err != nil {
+			return err
+		}
+
+		api := server.New(log.Logger, conf, http.DefaultClient)
 
 		if err := api.Launch(ctx); err != nil {
 			return err
@@ -93,3 +123,102 @@ var serverCMD = &cli.Command{
 		return nil
 	},
 }
+
+// serverConfig merges the config file with the flags, flags which are set explicitly or by environment variable win.
+func serverConfig(command *cli.Command, file server.FileConfig) (server.Config, error) {
+	str := func(flag, fileValue string) string {
+		if command.IsSet(flag) || fileValue == "" {
+			return command.String(flag)
+		}
+
+		return fileValue
+	}
+
+	// the first client of the file is the default client, unless the flags provide one
+	clients := file.Clients
+	clientID, clientSecret := command.String("client-id"), command.String("client-secret")
+	if !command.IsSet("client-id") && len(clients) > 0 {
+		clientID, clientSecret = clients[0].ID, clients[0].Secret
+		clients = clients[1:]
+	} else if command.IsSet("client-id") {
+		clients = slices.DeleteFunc(slices.Clone(clients), func(c server.TwitchClient) bool { return c.ID == clientID })
+	}
+
+	redisDB := command.Int("redis-db")
+	if !command.IsSet("redis-db") && file.Redis.DB != 0 {
+		redisDB = file.Redis.DB
+	}
+
+	if redisDB < 0 || redisDB > 15 {
+		log.Warn().Int("db", redisDB).Msg("Invalid Redis DB number, using 0")
+		redisDB = 0
+	}
+
+	proxies := command.StringSlice("trusted-proxies")
+	if !command.IsSet("trusted-proxies") && len(file.TrustedProxies) > 0 {
+		proxies = file.TrustedProxies
+	}
+
+	trustedProxies, err := server.ParseTrustedProxies(proxies)
+	if err != nil {
+		return server.Config{}, err
+	}
+
+	enableRateLimit := command.Bool("enable-ratelimit")
+	if !command.IsSet("enable-ratelimit") && file.RateLimit.Enabled != nil {
+		enableRateLimit = *file.RateLimit.Enabled
+	}
+
+	cacheEntries := command.Int("helix-cache-entries")
+	if !command.IsSet("helix-cache-entries") && file.Helix.CacheEntries != 0 {
+		cacheEntries = file.Helix.CacheEntries
+	}
+
+	return server.Config{
+		HostAndPort:          str("addr", file.Addr),
+		ClientID:             clientID,
+		ClientSecret:         clientSecret,
+		Clients:              clients,
+		RedirectURL:          str("redirect-url", file.RedirectURL),
+		EnableProxyRateLimit: enableRateLimit,
+		RateLimitPolicies:    file.RateLimit.RateLimitPolicies(),
+		TrustedProxies:       trustedProxies,
+		HelixCache:           str("helix-cache", file.Helix.Cache),
+		HelixCacheEntries:    cacheEntries,
+		HelixAllowedPaths:    file.Helix.AllowedPaths,
+		InternalAddr:         str("internal-addr", file.Internal.Addr),
+		InternalToken:        str("internal-token", file.Internal.Token),
+		TLS:                  file.TLS,
+		CORS:                 file.CORS,
+		Version:              Version,
+		Redis: server.RedisConfig{
+			Addr:     str("redis-addr", file.Redis.Addr),
+			Password: str("redis-password", file.Redis.Password),
+			DB:       redisDB,
+		},
+	}, nil
+}
+
+// setupServerLogger applies the log section of the config file. The --log flags win, they only take the level from the file.
+func setupServerLogger(command *cli.Command, conf server.LogConfig) error {
+	if conf == (server.LogConfig{}) {
+		return nil
+	}
+
+	level, err := server.ParseLogLevel(conf.Level)
+	if err != nil {
+		return err
+	}
+
+	if !command.IsSet("log") {
+		if conf.HumanReadable {
+			log.Logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr}).With().Timestamp().Logger()
+		} else {
+			log.Logger = zerolog.New(os.Stderr).With().Timestamp().Logger()
+		}
+	}
+
+	log.Logger = log.Logger.Level(level)
+
+	return nil
+}
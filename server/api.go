@@ -1,21 +1,26 @@
 package server
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
+	"net/netip"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
+	"golang.org/x/crypto/acme/autocert"
 	"golang.org/x/sync/errgroup"
 )
 
 // RedisConfig holds Redis connection configuration
 type RedisConfig struct {
-	Addr     string
-	Password string
-	DB       int
+	Addr     string `yaml:"addr"`
+	Password string `yaml:"password"`
+	DB       int    `yaml:"db"`
 }
 
 type Config struct {
@@ -26,6 +31,27 @@ type Config struct {
 	Redis                RedisConfig
 	EnableProxyRateLimit bool
 	Version              string
+
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For header is used to find the client IP
+	TrustedProxies []netip.Prefix
+
+	// HelixCache is the backend caching Helix responses: memory (default), redis or off
+	HelixCache        string
+	HelixCacheEntries int // maximum entries of the memory cache
+
+	// Clients are further Twitch applications users can authenticate with, selected by their client ID
+	Clients []TwitchClient
+
+	// InternalAddr is the listen address of /metrics, /internal/ready and /internal/cache. If it is empty,
+	// they are only served on HostAndPort if InternalToken is set.
+	InternalAddr string
+	// InternalToken is the bearer token required by the internal endpoints
+	InternalToken string
+
+	TLS               TLSConfig
+	CORS              CORSConfig
+	HelixAllowedPaths []string                   // extend allowedHelixPaths
+	RateLimitPolicies map[string]RateLimitPolicy // override the default policies by name
 }
 
 type API struct {
@@ -34,19 +60,58 @@ type API struct {
 	client             *http.Client
 	helixTokenProvider *HelixTokenProvider
 	redisClient        *redis.Client
+	responseCache      ResponseCache
+	linkPreviewCache   ResponseCache // independent of the Helix cache, the previews must not evict Helix responses
+	cacheStats         helixCacheStats
+	metrics            *Metrics
+	tokenURL           string // configurable for testing
 }
 
 func New(logger zerolog.Logger, config Config, client *http.Client) *API {
-	return &API{
+	a := &API{
 		logger:             logger,
 		conf:               config,
 		client:             client,
 		helixTokenProvider: NewHelixTokenProvider(client, config.ClientID, config.ClientSecret),
+		metrics:            NewMetrics(),
+		tokenURL:           defaultTokenURL,
 	}
+
+	a.helixTokenProvider.metrics = a.metrics
+	a.metrics.registerCacheStats(&a.cacheStats)
+
+	return a
 }
 
 func (a *API) Launch(ctx context.Context) error {
-	if a.conf.EnableProxyRateLimit {
+	if err := a.conf.Validate(); err != nil {
+		return fmt.Errorf("invalid server config:\n%w", err)
+	}
+
+	ln, err := net.Listen("tcp", a.conf.HostAndPort)
+	if err != nil {
+		return err
+	}
+
+	return a.serve(ctx, ln)
+}
+
+// Serve runs the server on the listener until ctx is done, e.g. on a loopback port when the server is embedded in the client.
+// The listener is closed when Serve returns.
+func (a *API) Serve(ctx context.Context, ln net.Listener) error {
+	if err := a.conf.Validate(); err != nil {
+		ln.Close()
+		return fmt.Errorf("invalid server config:\n%w", err)
+	}
+
+	return a.serve(ctx, ln)
+}
+
+func (a *API) serve(ctx context.Context, ln net.Listener) error {
+	defer ln.Close()
+
+	useRedisCache := a.conf.HelixCache == HelixCacheRedis
+	if (a.conf.EnableProxyRateLimit || useRedisCache) && a.conf.Redis.Addr != "" {
 		client, err := a.initRedisClient(ctx)
 		if err != nil {
 			return err
@@ -54,6 +119,20 @@ func (a *API) Launch(ctx context.Context) error {
 		a.redisClient = client
 	}
 
+	switch a.conf.HelixCache {
+	case HelixCacheRedis:
+		a.responseCache = NewRedisResponseCache(a.redisClient)
+	case HelixCacheDisabled:
+	default:
+		a.responseCache = NewMemoryResponseCache(a.conf.HelixCacheEntries)
+	}
+
+	if a.redisClient != nil {
+		a.linkPreviewCache = NewRedisResponseCache(a.redisClient)
+	} else {
+		a.linkPreviewCache = NewMemoryResponseCache(linkPreviewCacheEntries)
+	}
+
 	httpSrv := &http.Server{
 		Addr:           a.conf.HostAndPort,
 		WriteTimeout:   time.Second * 15,
@@ -69,13 +148,69 @@ func (a *API) Launch(ctx context.Context) error {
 
 	wg, ctx := errgroup.WithContext(ctx)
 
+	// the internal server keeps the metrics and dependency checks off the public address
+	var internalSrv *http.Server
+	if a.conf.InternalAddr != "" {
+		internalSrv = &http.Server{
+			Addr:           a.conf.InternalAddr,
+			WriteTimeout:   time.Second * 15,
+			ReadTimeout:    time.Second * 15,
+			IdleTimeout:    time.Second * 60,
+			MaxHeaderBytes: 2 * 1024,
+			Handler:        internalRouter(a.logger, a),
+		}
+
+		wg.Go(func() error {
+			a.logger.Info().Str("addr", internalSrv.Addr).Msg("starting internal http server")
+
+			if err := internalSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+
+			return nil
+		})
+	}
+
+	// the challenge server only runs with autocert, it answers Let's Encrypt's HTTP-01 challenges
+	var challengeSrv *http.Server
+	if len(a.conf.TLS.Autocert.Domains) > 0 {
+		manager := a.autocertManager()
+		httpSrv.TLSConfig = manager.TLSConfig()
+
+		challengeSrv = &http.Server{
+			Addr:         cmp.Or(a.conf.TLS.Autocert.HTTPAddr, defaultAutocertHTTPAddr),
+			ReadTimeout:  time.Second * 15,
+			WriteTimeout: time.Second * 15,
+			Handler:      manager.HTTPHandler(nil),
+		}
+
+		wg.Go(func() error {
+			a.logger.Info().Str("addr", challengeSrv.Addr).Msg("starting autocert challenge server")
+
+			if err := challengeSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+
+			return nil
+		})
+	}
+
 	wg.Go(func() error {
 		a.logger.Info().
-			Str("addr", httpSrv.Addr).
+			Str("addr", ln.Addr().String()).
 			Str("redirect-url", a.conf.RedirectURL).
+			Bool("tls", a.conf.TLS.Enabled()).
 			Msg("starting http server")
 
-		if err := httpSrv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		var err error
+		if a.conf.TLS.Enabled() {
+			// with autocert the certificates come from TLSConfig, the file names are empty
+			err = httpSrv.ServeTLS(ln, a.conf.TLS.CertFile, a.conf.TLS.KeyFile)
+		} else {
+			err = httpSrv.Serve(ln)
+		}
+
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
 			return err
 		}
 
@@ -92,6 +227,18 @@ func (a *API) Launch(ctx context.Context) error {
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Second*15)
 		defer cancel()
 
+		if challengeSrv != nil {
+			if err := challengeSrv.Shutdown(shutdownCtx); err != nil {
+				return err
+			}
+		}
+
+		if internalSrv != nil {
+			if err := internalSrv.Shutdown(shutdownCtx); err != nil {
+				return err
+			}
+		}
+
 		if err := httpSrv.Shutdown(shutdownCtx); err != nil {
 			return err
 		}
@@ -108,6 +255,52 @@ func (a *API) Launch(ctx context.Context) error {
 	return nil
 }
 
+// rateLimitBackend returns the backend of the rate limiter, Redis if configured or else the in-memory limiter.
+func (a *API) rateLimitBackend() RateLimitBackend {
+	switch {
+	case !a.conf.EnableProxyRateLimit:
+		return nil
+	case a.redisClient != nil:
+		return NewRedisRateLimitBackend(a.redisClient)
+	default:
+		return NewMemoryRateLimitBackend()
+	}
+}
+
+// autocertManager obtains and renews the certificates of the configured domains from Let's Encrypt.
+func (a *API) autocertManager() *autocert.Manager {
+	return &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		HostPolicy: autocert.HostWhitelist(a.conf.TLS.Autocert.Domains...),
+		Cache:      autocert.DirCache(cmp.Or(a.conf.TLS.Autocert.CacheDir, defaultAutocertCacheDir)),
+		Email:      a.conf.TLS.Autocert.Email,
+	}
+}
+
+// rateLimitPolicy returns the configured override of the policy, if any.
+func (a *API) rateLimitPolicy(policy RateLimitPolicy) RateLimitPolicy {
+	if override, ok := a.conf.RateLimitPolicies[policy.Name]; ok {
+		return override
+	}
+
+	return policy
+}
+
+// twitchClient returns the client with the ID, the default client if id is empty.
+func (a *API) twitchClient(id string) (TwitchClient, bool) {
+	if id == "" || id == a.conf.ClientID {
+		return TwitchClient{ID: a.conf.ClientID, Secret: a.conf.ClientSecret}, true
+	}
+
+	for _, c := range a.conf.Clients {
+		if c.ID == id {
+			return c, true
+		}
+	}
+
+	return TwitchClient{}, false
+}
+
 func (a *API) initRedisClient(ctx context.Context) (*redis.Client, error) {
 	client := redis.NewClient(&redis.Options{
 		Addr:     a.conf.Redis.Addr,
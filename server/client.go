@@ -145,6 +145,11 @@ func (c *Client) CheckLink(ctx context.Context, targetURL string) (CheckLinkResp
 	return data, nil
 }
 
+// GetLinkPreview returns title, description and media info of the page behind targetURL.
+func (c *Client) GetLinkPreview(ctx context.Context, targetURL string) (LinkPreview, error) {
+	return do[LinkPreview](ctx, c, fmt.Sprintf("%s/proxy/link_preview?target=%s", c.baseURL, url.QueryEscape(targetURL)))
+}
+
 // Version methods
 
 func (c *Client) GetLatestVersion(ctx context.Context) (string, error) {
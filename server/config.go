@@ -0,0 +1,269 @@
+package server
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+)
+
+// TwitchClient is a registered Twitch application the server can authenticate users for.
+type TwitchClient struct {
+	ID     string `yaml:"id"`
+	Secret string `yaml:"secret"`
+}
+
+// CORSConfig lists the origins browsers may call the server from. "*" allows any origin.
+type CORSConfig struct {
+	AllowedOrigins []string `yaml:"allowed_origins"`
+}
+
+// TLSConfig makes the server serve HTTPS itself, either with a certificate from disk or one obtained from Let's Encrypt.
+type TLSConfig struct {
+	CertFile string         `yaml:"cert_file"`
+	KeyFile  string         `yaml:"key_file"`
+	Autocert AutocertConfig `yaml:"autocert"`
+}
+
+// Enabled reports if the server serves HTTPS.
+func (c TLSConfig) Enabled() bool {
+	return c.CertFile != "" || c.KeyFile != "" || len(c.Autocert.Domains) > 0
+}
+
+// AutocertConfig obtains certificates from Let's Encrypt for Domains. The HTTP-01 challenge is answered on HTTPAddr,
+// which has to be reachable on port 80 from the internet.
+type AutocertConfig struct {
+	Domains  []string `yaml:"domains"`
+	Email    string   `yaml:"email"`
+	CacheDir string   `yaml:"cache_dir"`
+	HTTPAddr string   `yaml:"http_addr"`
+}
+
+const (
+	defaultAutocertCacheDir = "autocert"
+	defaultAutocertHTTPAddr = ":80"
+)
+
+// FileConfig is the server configuration file. Flags and environment variables take precedence over it.
+type FileConfig struct {
+	Addr           string              `yaml:"addr"`
+	RedirectURL    string              `yaml:"redirect_url"`
+	Clients        []TwitchClient      `yaml:"clients"`
+	Redis          RedisConfig         `yaml:"redis"`
+	TrustedProxies []string            `yaml:"trusted_proxies"`
+	CORS           CORSConfig          `yaml:"cors"`
+	TLS            TLSConfig           `yaml:"tls"`
+	Helix          HelixFileConfig     `yaml:"helix"`
+	RateLimit      RateLimitFileConfig `yaml:"ratelimit"`
+	Log            LogConfig           `yaml:"log"`
+	Internal       InternalConfig      `yaml:"internal"`
+}
+
+// InternalConfig serves /metrics, /internal/ready and /internal/cache on their own address or behind a bearer token.
+type InternalConfig struct {
+	Addr  string `yaml:"addr"`
+	Token string `yaml:"token"`
+}
+
+type HelixFileConfig struct {
+	// AllowedPaths are Helix paths proxied in addition to the built-in allowlist, e.g. "channels/followers"
+	AllowedPaths []string `yaml:"allowed_paths"`
+	Cache        string   `yaml:"cache"`
+	CacheEntries int      `yaml:"cache_entries"`
+}
+
+type RateLimitFileConfig struct {
+	Enabled *bool `yaml:"enabled"`
+	// Policies override the limits of the ttv, link_check and auth policies
+	Policies map[string]RateLimitWindow `yaml:"policies"`
+}
+
+type RateLimitWindow struct {
+	Limit  int           `yaml:"limit"`
+	Window time.Duration `yaml:"window"`
+}
+
+type LogConfig struct {
+	Level         string `yaml:"level"`
+	HumanReadable bool   `yaml:"human_readable"`
+}
+
+// LoadConfigFile reads the configuration file at path. Unknown keys are rejected, so typos don't go unnoticed.
+func LoadConfigFile(path string) (FileConfig, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return FileConfig{}, fmt.Errorf("open server config: %w", err)
+	}
+	defer f.Close()
+
+	return parseConfigFile(f)
+}
+
+func parseConfigFile(r io.Reader) (FileConfig, error) {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+
+	var conf FileConfig
+	if err := dec.Decode(&conf); err != nil && !errors.Is(err, io.EOF) {
+		return FileConfig{}, fmt.Errorf("parse server config: %w", err)
+	}
+
+	if err := conf.Log.validate(); err != nil {
+		return FileConfig{}, err
+	}
+
+	return conf, nil
+}
+
+// ParseLogLevel returns the zerolog level, empty is info.
+func ParseLogLevel(level string) (zerolog.Level, error) {
+	if level == "" {
+		return zerolog.InfoLevel, nil
+	}
+
+	lvl, err := zerolog.ParseLevel(strings.ToLower(level))
+	if err != nil || lvl == zerolog.NoLevel {
+		return zerolog.NoLevel, fmt.Errorf("log.level: unknown level %q, use trace, debug, info, warn or error", level)
+	}
+
+	return lvl, nil
+}
+
+func (c LogConfig) validate() error {
+	_, err := ParseLogLevel(c.Level)
+	return err
+}
+
+// RateLimitPolicies converts the configured policy overrides, the names are checked by Config.Validate.
+func (c RateLimitFileConfig) RateLimitPolicies() map[string]RateLimitPolicy {
+	if len(c.Policies) == 0 {
+		return nil
+	}
+
+	policies := make(map[string]RateLimitPolicy, len(c.Policies))
+	for name, p := range c.Policies {
+		policies[name] = RateLimitPolicy{Name: name, Limit: p.Limit, Window: p.Window}
+	}
+
+	return policies
+}
+
+// helixPathPattern matches Helix paths like "chat/emotes/global"
+var helixPathPattern = regexp.MustCompile(`^[a-z0-9_]+(/[a-z0-9_]+)*$`)
+
+// Validate checks the configuration before the server starts and reports all problems at once.
+func (c Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if _, _, err := net.SplitHostPort(c.HostAndPort); err != nil {
+		fail("addr: %q is not a valid listen address: %w", c.HostAndPort, err)
+	}
+
+	if c.InternalAddr != "" {
+		if _, _, err := net.SplitHostPort(c.InternalAddr); err != nil {
+			fail("internal.addr: %q is not a valid listen address: %w", c.InternalAddr, err)
+		} else if c.InternalAddr == c.HostAndPort {
+			fail("internal.addr: has to differ from addr")
+		}
+	}
+
+	if c.ClientID == "" || c.ClientSecret == "" {
+		fail("clients: a Twitch client ID and secret are required")
+	}
+
+	seen := map[string]bool{c.ClientID: true}
+	for i, client := range c.Clients {
+		switch {
+		case client.ID == "" || client.Secret == "":
+			fail("clients[%d]: id and secret are required", i)
+		case seen[client.ID]:
+			fail("clients[%d]: client %q is configured twice", i, client.ID)
+		}
+
+		seen[client.ID] = true
+	}
+
+	if u, err := url.Parse(c.RedirectURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fail("redirect_url: %q has to be an absolute http or https URL", c.RedirectURL)
+	}
+
+	errs = append(errs, c.TLS.validate()...)
+
+	for _, origin := range c.CORS.AllowedOrigins {
+		if origin == "*" {
+			continue
+		}
+
+		if u, err := url.Parse(origin); err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+			fail("cors.allowed_origins: %q is not an origin like https://example.com", origin)
+		}
+	}
+
+	for _, p := range c.HelixAllowedPaths {
+		if !helixPathPattern.MatchString(strings.Trim(p, "/")) {
+			fail("helix.allowed_paths: %q is not a Helix path like chat/emotes", p)
+		}
+	}
+
+	switch c.HelixCache {
+	case "", HelixCacheMemory, HelixCacheDisabled:
+	case HelixCacheRedis:
+		if c.Redis.Addr == "" {
+			fail("helix.cache: the redis cache requires a redis address")
+		}
+	default:
+		fail("helix.cache: unknown cache %q, use memory, redis or off", c.HelixCache)
+	}
+
+	known := []string{ttvRateLimit.Name, linkCheckRateLimit.Name, authRateLimit.Name}
+	for name, p := range c.RateLimitPolicies {
+		if !slices.Contains(known, name) {
+			fail("ratelimit.policies: unknown policy %q, use %s", name, strings.Join(known, ", "))
+			continue
+		}
+
+		if p.Limit <= 0 || p.Window <= 0 {
+			fail("ratelimit.policies.%s: limit and window have to be positive", name)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (c TLSConfig) validate() []error {
+	var errs []error
+
+	useCert := c.CertFile != "" || c.KeyFile != ""
+	useAutocert := len(c.Autocert.Domains) > 0
+
+	switch {
+	case useCert && useAutocert:
+		errs = append(errs, errors.New("tls: use either cert_file and key_file or autocert, not both"))
+	case useCert:
+		if c.CertFile == "" || c.KeyFile == "" {
+			errs = append(errs, errors.New("tls: cert_file and key_file are both required"))
+			break
+		}
+
+		if _, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile); err != nil {
+			errs = append(errs, fmt.Errorf("tls: could not load certificate: %w", err))
+		}
+	case c.Autocert.Email != "" || c.Autocert.CacheDir != "" || c.Autocert.HTTPAddr != "":
+		errs = append(errs, errors.New("tls.autocert: domains are required"))
+	}
+
+	return errs
+}
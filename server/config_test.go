@@ -0,0 +1,161 @@
+package server
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfigFile(t *testing.T) {
+	t.Parallel()
+
+	t.Run("full config", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := parseConfigFile(strings.NewReader(`
+addr: ":9000"
+redirect_url: https://chat.example.com/auth/redirect
+clients:
+  - id: main
+    secret: main-secret
+  - id: second
+    secret: second-secret
+redis:
+  addr: localhost:6379
+  db: 2
+cors:
+  allowed_origins: [https://chat.example.com]
+tls:
+  autocert:
+    domains: [chat.example.com]
+    email: admin@example.com
+helix:
+  allowed_paths: [channels/followers]
+  cache: redis
+ratelimit:
+  enabled: false
+  policies:
+    ttv: {limit: 200, window: 30s}
+log:
+  level: warn
+  human_readable: true
+internal:
+  addr: 127.0.0.1:9090
+  token: secret
+`))
+		require.NoError(t, err)
+
+		require.Equal(t, ":9000", conf.Addr)
+		require.Equal(t, []TwitchClient{{ID: "main", Secret: "main-secret"}, {ID: "second", Secret: "second-secret"}}, conf.Clients)
+		require.Equal(t, RedisConfig{Addr: "localhost:6379", DB: 2}, conf.Redis)
+		require.Equal(t, []string{"chat.example.com"}, conf.TLS.Autocert.Domains)
+		require.True(t, conf.TLS.Enabled())
+		require.False(t, *conf.RateLimit.Enabled)
+		require.Equal(t, map[string]RateLimitPolicy{
+			"ttv": {Name: "ttv", Limit: 200, Window: 30 * time.Second},
+		}, conf.RateLimit.RateLimitPolicies())
+		require.Equal(t, LogConfig{Level: "warn", HumanReadable: true}, conf.Log)
+		require.Equal(t, InternalConfig{Addr: "127.0.0.1:9090", Token: "secret"}, conf.Internal)
+	})
+
+	t.Run("empty file", func(t *testing.T) {
+		t.Parallel()
+
+		conf, err := parseConfigFile(strings.NewReader(""))
+		require.NoError(t, err)
+		require.Equal(t, FileConfig{}, conf)
+	})
+
+	t.Run("rejects unknown keys", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parseConfigFile(strings.NewReader("adress: \":9000\"\n"))
+		require.ErrorContains(t, err, "field adress not found")
+	})
+
+	t.Run("rejects unknown log level", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := parseConfigFile(strings.NewReader("log:\n  level: loud\n"))
+		require.ErrorContains(t, err, `log.level: unknown level "loud"`)
+	})
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := Config{
+		HostAndPort:  ":8080",
+		ClientID:     "id",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/auth/redirect",
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		t.Parallel()
+
+		require.NoError(t, valid.Validate())
+	})
+
+	t.Run("reports all problems", func(t *testing.T) {
+		t.Parallel()
+
+		conf := valid
+		conf.ClientSecret = ""
+		conf.Clients = []TwitchClient{{ID: "id", Secret: "other"}}
+		conf.RedirectURL = "/auth/redirect"
+		conf.TLS = TLSConfig{CertFile: "cert.pem", Autocert: AutocertConfig{Domains: []string{"example.com"}}}
+		conf.CORS.AllowedOrigins = []string{"https://example.com/app"}
+		conf.HelixAllowedPaths = []string{"../oauth2"}
+		conf.HelixCache = HelixCacheRedis
+		conf.RateLimitPolicies = map[string]RateLimitPolicy{"ttv": {Name: "ttv"}, "other": {Name: "other", Limit: 1, Window: time.Second}}
+		conf.InternalAddr = ":8080"
+
+		err := conf.Validate()
+		require.Error(t, err)
+
+		for _, want := range []string{
+			"internal.addr: has to differ from addr",
+			"clients: a Twitch client ID and secret are required",
+			`clients[0]: client "id" is configured twice`,
+			"redirect_url:",
+			"tls: use either cert_file and key_file or autocert",
+			"cors.allowed_origins:",
+			"helix.allowed_paths:",
+			"helix.cache: the redis cache requires a redis address",
+			"ratelimit.policies.ttv: limit and window have to be positive",
+			`ratelimit.policies: unknown policy "other"`,
+		} {
+			require.ErrorContains(t, err, want)
+		}
+	})
+
+	t.Run("certificate files have to exist", func(t *testing.T) {
+		t.Parallel()
+
+		conf := valid
+		conf.TLS = TLSConfig{CertFile: "missing.pem", KeyFile: "missing-key.pem"}
+
+		require.ErrorContains(t, conf.Validate(), "tls: could not load certificate")
+	})
+}
+
+func TestTwitchClient(t *testing.T) {
+	t.Parallel()
+
+	api := createTestAPI(t)
+	api.conf.Clients = []TwitchClient{{ID: "second", Secret: "second-secret"}}
+
+	client, ok := api.twitchClient("")
+	require.True(t, ok)
+	require.Equal(t, TwitchClient{ID: "test-client-id", Secret: "test-client-secret"}, client)
+
+	client, ok = api.twitchClient("second")
+	require.True(t, ok)
+	require.Equal(t, "second-secret", client.Secret)
+
+	_, ok = api.twitchClient("unknown")
+	require.False(t, ok)
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORSMiddleware(t *testing.T) {
+	t.Parallel()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	do := func(h http.Handler, method, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/ttv/users", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("allowed origin", func(t *testing.T) {
+		t.Parallel()
+
+		h := corsMiddleware([]string{"https://chat.example.com"})(next)
+
+		rec := do(h, http.MethodGet, "https://chat.example.com")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "https://chat.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+
+		rec = do(h, http.MethodOptions, "https://chat.example.com")
+		require.Equal(t, http.StatusNoContent, rec.Code)
+		require.Contains(t, rec.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+	})
+
+	t.Run("other origin", func(t *testing.T) {
+		t.Parallel()
+
+		h := corsMiddleware([]string{"https://chat.example.com"})(next)
+
+		rec := do(h, http.MethodGet, "https://evil.example.com")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("any origin", func(t *testing.T) {
+		t.Parallel()
+
+		h := corsMiddleware([]string{"*"})(next)
+
+		rec := do(h, http.MethodGet, "https://other.example.com")
+		require.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("disabled without origins", func(t *testing.T) {
+		t.Parallel()
+
+		h := corsMiddleware(nil)(next)
+
+		rec := do(h, http.MethodGet, "https://chat.example.com")
+		require.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
@@ -1,23 +1,48 @@
 package server
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"html/template"
 	"io"
 	"net/http"
 	"net/url"
 	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"github.com/rs/zerolog"
 )
 
 var scopes = [...]string{
 	"chat:read", "chat:edit", "channel:moderate", "moderator:read:chat_settings", "moderation:read", "user:read:chat", "moderator:manage:banned_users",
 	"moderator:manage:unban_requests", "user:read:follows", "channel:manage:polls", "channel:read:ads", "moderator:read:followers", "clips:edit", "moderator:manage:announcements",
-	"channel:manage:broadcast", "user:read:emotes", "moderator:manage:chat_messages", "user:write:chat",
+	"channel:manage:broadcast", "user:read:emotes", "moderator:manage:chat_messages", "user:write:chat", "user:read:blocked_users",
+	"user:manage:blocked_users",
+}
+
+// Scopes returns the OAuth scopes Chatuino requests for user accounts.
+func Scopes() []string {
+	return slices.Clone(scopes[:])
+}
+
+// MissingScopes returns the scopes Chatuino requests which were not granted. Accounts created before a
+// feature needing a new scope was added have to be authorized again.
+func MissingScopes(granted []string) []string {
+	var missing []string
+	for _, s := range scopes {
+		if !slices.Contains(granted, s) {
+			missing = append(missing, s)
+		}
+	}
+
+	return missing
 }
 
 type tokenPair struct {
@@ -29,6 +54,13 @@ func (a *API) handleAuthStart() http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger := a.getLoggerFrom(r.Context())
 
+		client, ok := a.twitchClient(r.URL.Query().Get("client_id"))
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Unknown client_id"))
+			return
+		}
+
 		state, err := randomString(10)
 		if err != nil {
 			logger.Err(err).Msg("could not generate random state string")
@@ -37,7 +69,7 @@ func (a *API) handleAuthStart() http.HandlerFunc {
 		}
 
 		val := url.Values{}
-		val.Set("client_id", a.conf.ClientID)
+		val.Set("client_id", client.ID)
 		val.Set("force_verify", "false")
 		val.Set("redirect_uri", a.conf.RedirectURL)
 		val.Set("response_type", "code")
@@ -59,11 +91,65 @@ func (a *API) handleAuthStart() http.HandlerFunc {
 			Path:     "/auth/redirect",
 		})
 
+		// the redirect has to exchange the code with the client the user authorized, no cookie means the default client
+		if client.ID != a.conf.ClientID {
+			http.SetCookie(w, &http.Cookie{
+				Name:     "chatuino_client",
+				Value:    client.ID,
+				MaxAge:   int((time.Minute * 5).Seconds()),
+				HttpOnly: true,
+				Path:     "/auth/redirect",
+			})
+		}
+
+		// the account UI listens on a loopback port to receive the tokens without pasting them
+		if port := r.URL.Query().Get("loopback_port"); port != "" {
+			loopbackState := r.URL.Query().Get("loopback_state")
+			if !validLoopback(port, loopbackState) {
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte("Invalid loopback_port or loopback_state"))
+				return
+			}
+
+			http.SetCookie(w, &http.Cookie{
+				Name:     "chatuino_loopback",
+				Value:    port + ":" + loopbackState,
+				MaxAge:   int((time.Minute * 5).Seconds()),
+				HttpOnly: true,
+				Path:     "/auth/redirect",
+			})
+		}
+
 		w.Header().Set("Location", u.String())
 		w.WriteHeader(http.StatusFound)
 	})
 }
 
+// loopbackForm hands the tokens of a browser login to the loopback listener of Chatuino.
+type loopbackForm struct {
+	Action       string
+	State        string
+	AccessToken  string
+	RefreshToken string
+	Scope        string
+}
+
+var loopbackFormTemplate = template.Must(template.New("loopback").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Chatuino Login</title></head>
+<body>
+<form id="login" method="POST" action="{{.Action}}">
+<input type="hidden" name="state" value="{{.State}}">
+<input type="hidden" name="access_token" value="{{.AccessToken}}">
+<input type="hidden" name="refresh_token" value="{{.RefreshToken}}">
+<input type="hidden" name="scope" value="{{.Scope}}">
+<noscript><button type="submit">Continue to Chatuino</button></noscript>
+</form>
+<script>document.getElementById("login").submit();</script>
+</body>
+</html>
+`))
+
 func (a *API) handleAuthRedirect() http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger := a.getLoggerFrom(r.Context())
@@ -114,15 +200,28 @@ func (a *API) handleAuthRedirect() http.HandlerFunc {
 			Expires: time.Now().Add(-24 * time.Hour),
 		})
 
+		var clientID string
+		if cookie, err := r.Cookie("chatuino_client"); err == nil {
+			clientID = cookie.Value
+		}
+
+		client, ok := a.twitchClient(clientID)
+		if !ok {
+			logger.Error().Str("client_id", clientID).Msg("client cookie contains unknown client")
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Unknown client"))
+			return
+		}
+
 		// request token + refresh token
 		formVal := url.Values{}
-		formVal.Set("client_id", a.conf.ClientID)
-		formVal.Set("client_secret", a.conf.ClientSecret)
+		formVal.Set("client_id", client.ID)
+		formVal.Set("client_secret", client.Secret)
 		formVal.Set("code", values.Get("code"))
 		formVal.Set("grant_type", "authorization_code")
 		formVal.Set("redirect_uri", a.conf.RedirectURL)
 
-		req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, "https://id.twitch.tv/oauth2/token", strings.NewReader(formVal.Encode()))
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, a.tokenURL, strings.NewReader(formVal.Encode()))
 		if err != nil {
 			logger.Err(err).Msg("could not create new http.Request")
 			w.WriteHeader(http.StatusInternalServerError)
@@ -160,6 +259,33 @@ func (a *API) handleAuthRedirect() http.HandlerFunc {
 			return
 		}
 
+		if cookie, err := r.Cookie("chatuino_loopback"); err == nil {
+			port, loopbackState, _ := strings.Cut(cookie.Value, ":")
+			if validLoopback(port, loopbackState) {
+				http.SetCookie(w, &http.Cookie{
+					Name:    "chatuino_loopback",
+					Expires: time.Now().Add(-24 * time.Hour),
+				})
+
+				// the tokens are posted instead of redirected to, so they don't end up in the browser history or request logs
+				w.Header().Set("Content-Type", "text/html; charset=utf-8")
+				w.Header().Set("Cache-Control", "no-store")
+				w.Header().Set("Referrer-Policy", "no-referrer")
+
+				err := loopbackFormTemplate.Execute(w, loopbackForm{
+					Action:       "http://127.0.0.1:" + port + "/callback",
+					State:        loopbackState,
+					AccessToken:  tokenData.AccessToken,
+					RefreshToken: tokenData.RefreshToken,
+					Scope:        values.Get("scope"),
+				})
+				if err != nil {
+					logger.Err(err).Msg("could not write loopback form")
+				}
+				return
+			}
+		}
+
 		w.WriteHeader(resp.StatusCode)
 		fmt.Fprintf(w, "Paste this in Chatuinos account prompt:\n%s%%%s", tokenData.AccessToken, tokenData.RefreshToken)
 	})
@@ -176,9 +302,16 @@ func (a *API) handleAuthRevoke() http.HandlerFunc {
 			return
 		}
 
+		client, ok := a.twitchClient(r.Header.Get("Client-Id"))
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Unknown Client-Id"))
+			return
+		}
+
 		token := splits[1]
 		formVal := url.Values{}
-		formVal.Set("client_id", a.conf.ClientID)
+		formVal.Set("client_id", client.ID)
 		formVal.Set("token", token)
 
 		req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, "https://id.twitch.tv/oauth2/revoke", strings.NewReader(formVal.Encode()))
@@ -221,14 +354,21 @@ func (a *API) handleAuthRefresh() http.HandlerFunc {
 			return
 		}
 
+		client, ok := a.twitchClient(r.Header.Get("Client-Id"))
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte("Unknown Client-Id"))
+			return
+		}
+
 		refreshToken := splits[1]
 		formVal := url.Values{}
-		formVal.Set("client_id", a.conf.ClientID)
-		formVal.Set("client_secret", a.conf.ClientSecret)
+		formVal.Set("client_id", client.ID)
+		formVal.Set("client_secret", client.Secret)
 		formVal.Set("grant_type", "refresh_token")
 		formVal.Set("refresh_token", refreshToken)
 
-		req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, "https://id.twitch.tv/oauth2/token", strings.NewReader(formVal.Encode()))
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodPost, a.tokenURL, strings.NewReader(formVal.Encode()))
 		if err != nil {
 			logger.Err(err).Msg("could not create new http.Request")
 			w.WriteHeader(http.StatusInternalServerError)
@@ -259,6 +399,97 @@ func (a *API) handleGetHealth() http.HandlerFunc {
 	})
 }
 
+// CacheStatsResponse is the JSON response for the /internal/cache endpoint.
+type CacheStatsResponse struct {
+	Enabled     bool  `json:"enabled"`
+	Hits        int64 `json:"hits"`
+	Misses      int64 `json:"misses"`
+	NotModified int64 `json:"not_modified"`
+}
+
+func (a *API) handleGetCacheStats() http.HandlerFunc {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(CacheStatsResponse{
+			Enabled:     a.responseCache != nil,
+			Hits:        a.cacheStats.hits.Load(),
+			Misses:      a.cacheStats.misses.Load(),
+			NotModified: a.cacheStats.notModified.Load(),
+		})
+	})
+}
+
+const (
+	// readyCheckTimeout bounds the dependency checks of the /internal/ready endpoint
+	readyCheckTimeout = 5 * time.Second
+	// readyCheckInterval limits how often the dependencies are checked, probes in between get the last result
+	readyCheckInterval = 10 * time.Second
+)
+
+// ReadyResponse is the JSON response for the /internal/ready endpoint.
+// Checks maps each dependency to "ok" or "failed", the errors are only logged.
+type ReadyResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// handleGetReady reports if the server can serve requests: Redis is reachable, if configured, and a valid app access token is available.
+func (a *API) handleGetReady() http.HandlerFunc {
+	var (
+		mu        sync.Mutex
+		checkedAt time.Time
+		last      ReadyResponse
+		status    int
+	)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		logger := a.getLoggerFrom(r.Context())
+
+		mu.Lock()
+		if time.Since(checkedAt) >= readyCheckInterval {
+			// a cancelled probe must not be cached as a failed check
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), readyCheckTimeout)
+			last, status = a.checkReady(ctx, logger)
+			cancel()
+
+			checkedAt = time.Now()
+		}
+		resp, code := last, status
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(resp)
+	})
+}
+
+func (a *API) checkReady(ctx context.Context, logger zerolog.Logger) (ReadyResponse, int) {
+	checks := map[string]error{
+		"app_token": a.helixTokenProvider.CheckToken(ctx),
+	}
+
+	if a.redisClient != nil {
+		checks["redis"] = a.redisClient.Ping(ctx).Err()
+	}
+
+	resp := ReadyResponse{Status: "ready", Checks: map[string]string{}}
+	status := http.StatusOK
+
+	for name, err := range checks {
+		if err != nil {
+			logger.Warn().Err(err).Str("check", name).Msg("readiness check failed")
+			resp.Checks[name] = "failed"
+			resp.Status = "not ready"
+			status = http.StatusServiceUnavailable
+			continue
+		}
+
+		resp.Checks[name] = "ok"
+	}
+
+	return resp, status
+}
+
 const installScriptURL = "https://raw.githubusercontent.com/julez-dev/chatuino/main/install/install.sh"
 
 // VersionResponse is the JSON response for the /version endpoint.
@@ -279,6 +510,21 @@ func (a *API) handleInstallScript() http.HandlerFunc {
 	})
 }
 
+// validLoopback reports whether the loopback port and state sent by the account UI are safe to redirect to.
+func validLoopback(port, state string) bool {
+	p, err := strconv.Atoi(port)
+	if err != nil || p < 1024 || p > 65535 || strconv.Itoa(p) != port {
+		return false
+	}
+
+	if len(state) < 16 || len(state) > 64 {
+		return false
+	}
+
+	_, err = hex.DecodeString(state)
+	return err == nil
+}
+
 func randomString(n int) (string, error) {
 	b := make([]byte, n)
 
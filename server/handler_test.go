@@ -4,6 +4,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -48,3 +52,206 @@ func TestHandleGetVersion(t *testing.T) {
 		require.Empty(t, resp.Version)
 	})
 }
+
+func TestHandleAuthLoopback(t *testing.T) {
+	t.Parallel()
+
+	const loopbackState = "0123456789abcdef0123456789abcdef"
+
+	t.Run("start rejects invalid loopback", func(t *testing.T) {
+		t.Parallel()
+
+		api := createTestAPI(t)
+
+		for _, query := range []string{"loopback_port=80&loopback_state=" + loopbackState, "loopback_port=8080&loopback_state=short", "loopback_port=08080&loopback_state=" + loopbackState} {
+			req := httptest.NewRequest(http.MethodGet, "/auth/start?"+query, nil)
+			rec := httptest.NewRecorder()
+			api.handleAuthStart().ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+	})
+
+	t.Run("redirect posts tokens to loopback", func(t *testing.T) {
+		t.Parallel()
+
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, r.ParseForm())
+			require.Equal(t, "authorization_code", r.Form.Get("grant_type"))
+			require.Equal(t, "the-code", r.Form.Get("code"))
+
+			w.Write([]byte(`{"access_token":"access","refresh_token":"refresh"}`))
+		}))
+		defer tokenServer.Close()
+
+		api := createTestAPI(t)
+		api.tokenURL = tokenServer.URL
+
+		startReq := httptest.NewRequest(http.MethodGet, "/auth/start?loopback_port=49152&loopback_state="+loopbackState, nil)
+		startRec := httptest.NewRecorder()
+		api.handleAuthStart().ServeHTTP(startRec, startReq)
+		require.Equal(t, http.StatusFound, startRec.Code)
+
+		cookies := startRec.Result().Cookies()
+		require.Len(t, cookies, 2)
+
+		query := url.Values{}
+		query.Set("code", "the-code")
+		query.Set("scope", strings.Join(scopes[:], " "))
+		query.Set("state", cookies[0].Value)
+
+		req := httptest.NewRequest(http.MethodGet, "/auth/redirect?"+query.Encode(), nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+
+		rec := httptest.NewRecorder()
+		api.handleAuthRedirect().ServeHTTP(rec, req)
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Empty(t, rec.Header().Get("Location"), "tokens must not be part of a URL")
+		require.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+
+		body := rec.Body.String()
+		require.Contains(t, body, `method="POST" action="http://127.0.0.1:49152/callback"`)
+		require.Contains(t, body, `name="state" value="`+loopbackState+`"`)
+		require.Contains(t, body, `name="access_token" value="access"`)
+		require.Contains(t, body, `name="refresh_token" value="refresh"`)
+	})
+}
+
+func TestMissingScopes(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, MissingScopes(Scopes()))
+
+	granted := slices.DeleteFunc(Scopes(), func(s string) bool { return s == "user:read:blocked_users" })
+	require.Equal(t, []string{"user:read:blocked_users"}, MissingScopes(append(granted, "unknown:scope")))
+}
+
+func TestHandleGetReady(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ready with a valid app token", func(t *testing.T) {
+		t.Parallel()
+
+		validate := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer validate.Close()
+
+		api := createTestAPI(t)
+		api.helixTokenProvider.validateURL = validate.URL
+
+		rec := httptest.NewRecorder()
+		api.handleGetReady().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/internal/ready", nil))
+
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp ReadyResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		require.Equal(t, ReadyResponse{Status: "ready", Checks: map[string]string{"app_token": "ok"}}, resp)
+	})
+
+	t.Run("not ready without app token", func(t *testing.T) {
+		t.Parallel()
+
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer tokenServer.Close()
+
+		api := createTestAPI(t)
+		api.helixTokenProvider.token = ""
+		api.helixTokenProvider.tokenURL = tokenServer.URL
+
+		rec := httptest.NewRecorder()
+		api.handleGetReady().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/internal/ready", nil))
+
+		require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+		var resp ReadyResponse
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		require.Equal(t, "not ready", resp.Status)
+		require.Equal(t, "failed", resp.Checks["app_token"], "errors are not exposed")
+	})
+
+	t.Run("checks are cached between probes", func(t *testing.T) {
+		t.Parallel()
+
+		var requests atomic.Int32
+		tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			w.WriteHeader(http.StatusBadRequest)
+		}))
+		defer tokenServer.Close()
+
+		api := createTestAPI(t)
+		api.helixTokenProvider.token = ""
+		api.helixTokenProvider.tokenURL = tokenServer.URL
+
+		handler := api.handleGetReady()
+		for range 3 {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/internal/ready", nil))
+			require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		}
+
+		require.EqualValues(t, 1, requests.Load())
+	})
+}
+
+func TestInternalRoutes(t *testing.T) {
+	t.Parallel()
+
+	get := func(h http.Handler, path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	t.Run("not public by default", func(t *testing.T) {
+		t.Parallel()
+
+		api := createTestAPI(t)
+		api.metrics = NewMetrics()
+		r := router(api.logger, api)
+
+		require.Equal(t, http.StatusOK, get(r, "/internal/health", ""))
+		require.NotEqual(t, http.StatusOK, get(r, "/internal/cache", ""))
+		require.NotEqual(t, http.StatusOK, get(r, "/metrics", ""))
+	})
+
+	t.Run("public behind token", func(t *testing.T) {
+		t.Parallel()
+
+		api := createTestAPI(t)
+		api.metrics = NewMetrics()
+		api.conf.InternalToken = "secret"
+		r := router(api.logger, api)
+
+		require.Equal(t, http.StatusUnauthorized, get(r, "/internal/cache", ""))
+		require.Equal(t, http.StatusUnauthorized, get(r, "/metrics", "wrong"))
+		require.Equal(t, http.StatusOK, get(r, "/internal/cache", "secret"))
+		require.Equal(t, http.StatusOK, get(r, "/metrics", "secret"))
+	})
+
+	t.Run("separate address", func(t *testing.T) {
+		t.Parallel()
+
+		api := createTestAPI(t)
+		api.conf.InternalAddr = "127.0.0.1:9090"
+		api.conf.InternalToken = "secret"
+
+		require.NotEqual(t, http.StatusOK, get(router(api.logger, api), "/internal/cache", "secret"))
+
+		internal := internalRouter(api.logger, api)
+		require.Equal(t, http.StatusOK, get(internal, "/internal/health", ""))
+		require.Equal(t, http.StatusUnauthorized, get(internal, "/internal/cache", ""))
+		require.Equal(t, http.StatusOK, get(internal, "/internal/cache", "secret"))
+	})
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"maps"
 	"net/http"
 	"strings"
 )
@@ -22,16 +23,28 @@ var allowedHelixPaths = map[string]struct{}{
 // (after stripping the /ttv prefix) is in the allowlist. Returns 403 Forbidden
 // for non-allowlisted paths.
 func HelixAllowlistMiddleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		path := extractHelixPath(r.URL.Path)
+	return NewHelixAllowlistMiddleware(nil)(next)
+}
+
+// NewHelixAllowlistMiddleware is HelixAllowlistMiddleware with extraPaths allowed in addition to allowedHelixPaths.
+func NewHelixAllowlistMiddleware(extraPaths []string) func(http.Handler) http.Handler {
+	allowed := maps.Clone(allowedHelixPaths)
+	for _, p := range extraPaths {
+		allowed[strings.Trim(p, "/")] = struct{}{}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path := extractHelixPath(r.URL.Path)
 
-		if !isPathAllowed(path) {
-			w.WriteHeader(http.StatusForbidden)
-			return
-		}
+			if _, ok := allowed[path]; !ok {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
 
-		next.ServeHTTP(w, r)
-	})
+			next.ServeHTTP(w, r)
+		})
+	}
 }
 
 // extractHelixPath strips the /ttv/ prefix from the path.
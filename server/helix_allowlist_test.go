@@ -165,3 +165,24 @@ func TestHelixAllowlistMiddleware(t *testing.T) {
 		})
 	}
 }
+
+func TestNewHelixAllowlistMiddleware(t *testing.T) {
+	t.Parallel()
+
+	handler := NewHelixAllowlistMiddleware([]string{"/channels/followers/"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	for path, want := range map[string]int{
+		"/ttv/channels/followers": http.StatusOK,
+		"/ttv/chat/emotes/global": http.StatusOK,
+		"/ttv/channels":           http.StatusForbidden,
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, want, rec.Code, path)
+	}
+
+	// the extra paths don't leak into the default allowlist
+	require.False(t, isPathAllowed("channels/followers"))
+}
@@ -0,0 +1,260 @@
+package server
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Helix cache backends selectable with Config.HelixCache
+const (
+	HelixCacheMemory   = "memory"
+	HelixCacheRedis    = "redis"
+	HelixCacheDisabled = "off"
+)
+
+const defaultHelixCacheEntries = 1000
+
+// helixCacheTTLs are the cache durations per Helix path. Global emotes and badges rarely change,
+// channel data changes more often and stream status has to be fresh.
+var helixCacheTTLs = map[string]time.Duration{
+	"chat/emotes/global": time.Hour,
+	"chat/badges/global": time.Hour,
+	"chat/emotes":        10 * time.Minute,
+	"chat/badges":        10 * time.Minute,
+	"users":              10 * time.Minute,
+	"chat/settings":      30 * time.Second,
+	"streams":            30 * time.Second,
+}
+
+// CachedResponse is a successful Helix response stored in the cache.
+type CachedResponse struct {
+	ContentType string    `json:"content_type"`
+	Body        []byte    `json:"body"`
+	ETag        string    `json:"etag"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// ResponseCache stores Helix responses shared by all clients.
+type ResponseCache interface {
+	Get(ctx context.Context, key string) (CachedResponse, bool, error)
+	Set(ctx context.Context, key string, resp CachedResponse) error
+}
+
+// helixCacheStats counts how requests to cacheable endpoints were answered.
+type helixCacheStats struct {
+	hits        atomic.Int64
+	misses      atomic.Int64
+	notModified atomic.Int64 // hits answered with 304 Not Modified
+}
+
+// helixCacheKey builds the key from the Helix path and the query, the query is sorted so the parameter order doesn't matter.
+func helixCacheKey(path string, query url.Values) string {
+	return "helixcache:" + path + "?" + query.Encode()
+}
+
+// newCachedResponse returns the cache entry of a response body, the ETag is derived from the body.
+func newCachedResponse(contentType string, body []byte, ttl time.Duration) CachedResponse {
+	sum := sha256.Sum256(body)
+
+	return CachedResponse{
+		ContentType: contentType,
+		Body:        body,
+		ETag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+		ExpiresAt:   time.Now().Add(ttl),
+	}
+}
+
+// writeCachedResponse writes the response, or 304 Not Modified if the client already has it. It reports if 304 was sent.
+func writeCachedResponse(w http.ResponseWriter, r *http.Request, resp CachedResponse, cacheStatus string) bool {
+	maxAge := max(int(time.Until(resp.ExpiresAt).Seconds()), 0)
+
+	w.Header().Set("ETag", resp.ETag)
+	w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(maxAge))
+	w.Header().Set("X-Cache", cacheStatus)
+
+	if etagMatches(r.Header.Get("If-None-Match"), resp.ETag) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+
+	if resp.ContentType != "" {
+		w.Header().Set("Content-Type", resp.ContentType)
+	}
+
+	w.Header().Set("Content-Length", strconv.Itoa(len(resp.Body)))
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(resp.Body)
+
+	return false
+}
+
+// etagMatches reports if the If-None-Match header contains the ETag, weak comparison like RFC 9110 requires.
+func etagMatches(header, etag string) bool {
+	if header == "" {
+		return false
+	}
+
+	for _, candidate := range splitETags(header) {
+		if candidate == "*" || trimWeak(candidate) == trimWeak(etag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func splitETags(header string) []string {
+	var (
+		tags    []string
+		current []byte
+		quoted  bool
+	)
+
+	for i := 0; i < len(header); i++ {
+		c := header[i]
+
+		switch {
+		case c == '"':
+			quoted = !quoted
+			current = append(current, c)
+		case c == ',' && !quoted:
+			tags = append(tags, string(current))
+			current = current[:0]
+		case (c == ' ' || c == '\t') && !quoted:
+		default:
+			current = append(current, c)
+		}
+	}
+
+	return append(tags, string(current))
+}
+
+func trimWeak(etag string) string {
+	if len(etag) > 2 && etag[:2] == "W/" {
+		return etag[2:]
+	}
+
+	return etag
+}
+
+// MemoryResponseCache is an in-process LRU cache with a maximum number of entries.
+type MemoryResponseCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front is the most recently used
+	entries    map[string]*list.Element
+	now        func() time.Time // configurable for testing
+}
+
+type memoryCacheEntry struct {
+	key  string
+	resp CachedResponse
+}
+
+func NewMemoryResponseCache(maxEntries int) *MemoryResponseCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultHelixCacheEntries
+	}
+
+	return &MemoryResponseCache{
+		maxEntries: maxEntries,
+		order:      list.New(),
+		entries:    map[string]*list.Element{},
+		now:        time.Now,
+	}
+}
+
+func (c *MemoryResponseCache) Get(_ context.Context, key string) (CachedResponse, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return CachedResponse{}, false, nil
+	}
+
+	entry := elem.Value.(*memoryCacheEntry)
+	if !c.now().Before(entry.resp.ExpiresAt) {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return CachedResponse{}, false, nil
+	}
+
+	c.order.MoveToFront(elem)
+
+	return entry.resp, true, nil
+}
+
+func (c *MemoryResponseCache) Set(_ context.Context, key string, resp CachedResponse) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*memoryCacheEntry).resp = resp
+		c.order.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryCacheEntry{key: key, resp: resp})
+
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*memoryCacheEntry).key)
+	}
+
+	return nil
+}
+
+// RedisResponseCache stores the responses in Redis, shared by all server instances. Redis expires the entries.
+type RedisResponseCache struct {
+	client *redis.Client
+}
+
+func NewRedisResponseCache(client *redis.Client) *RedisResponseCache {
+	return &RedisResponseCache{client: client}
+}
+
+func (c *RedisResponseCache) Get(ctx context.Context, key string) (CachedResponse, bool, error) {
+	data, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return CachedResponse{}, false, nil
+		}
+
+		return CachedResponse{}, false, err
+	}
+
+	var resp CachedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return CachedResponse{}, false, err
+	}
+
+	return resp, true, nil
+}
+
+func (c *RedisResponseCache) Set(ctx context.Context, key string, resp CachedResponse) error {
+	ttl := time.Until(resp.ExpiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	return c.client.Set(ctx, key, data, ttl).Err()
+}
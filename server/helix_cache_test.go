@@ -0,0 +1,148 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryResponseCache(t *testing.T) {
+	t.Parallel()
+
+	t.Run("evicts least recently used", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		cache := NewMemoryResponseCache(2)
+		resp := newCachedResponse("application/json", []byte(`{}`), time.Minute)
+
+		require.NoError(t, cache.Set(ctx, "a", resp))
+		require.NoError(t, cache.Set(ctx, "b", resp))
+
+		_, ok, _ := cache.Get(ctx, "a")
+		require.True(t, ok)
+
+		require.NoError(t, cache.Set(ctx, "c", resp))
+
+		_, ok, _ = cache.Get(ctx, "b")
+		require.False(t, ok, "b was used least recently")
+
+		_, ok, _ = cache.Get(ctx, "a")
+		require.True(t, ok)
+		_, ok, _ = cache.Get(ctx, "c")
+		require.True(t, ok)
+	})
+
+	t.Run("expires entries", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		now := time.Now()
+		cache := NewMemoryResponseCache(10)
+		cache.now = func() time.Time { return now }
+
+		require.NoError(t, cache.Set(ctx, "a", CachedResponse{ExpiresAt: now.Add(time.Minute)}))
+
+		_, ok, _ := cache.Get(ctx, "a")
+		require.True(t, ok)
+
+		now = now.Add(time.Minute)
+		_, ok, _ = cache.Get(ctx, "a")
+		require.False(t, ok)
+	})
+}
+
+func TestHelixCacheKey(t *testing.T) {
+	t.Parallel()
+
+	a := helixCacheKey("users", url.Values{"login": {"a"}, "id": {"1"}})
+	b := helixCacheKey("users", url.Values{"id": {"1"}, "login": {"a"}})
+
+	require.Equal(t, a, b)
+	require.NotEqual(t, a, helixCacheKey("users", url.Values{"id": {"2"}, "login": {"a"}}))
+}
+
+func TestETagMatches(t *testing.T) {
+	t.Parallel()
+
+	require.True(t, etagMatches(`"abc"`, `"abc"`))
+	require.True(t, etagMatches(`W/"abc"`, `"abc"`))
+	require.True(t, etagMatches(`"x", "abc"`, `"abc"`))
+	require.True(t, etagMatches(`*`, `"abc"`))
+	require.False(t, etagMatches(`"a,bc"`, `"abc"`))
+	require.False(t, etagMatches(``, `"abc"`))
+}
+
+func TestHelixProxyCache(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int64
+	mockTwitch := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		if r.URL.Query().Get("broadcaster_id") == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":[]}`))
+	}))
+	defer mockTwitch.Close()
+
+	api := createTestAPI(t)
+	api.responseCache = NewMemoryResponseCache(10)
+	target, err := url.Parse(mockTwitch.URL)
+	require.NoError(t, err)
+	handler := api.helixProxyHandlerWithTarget(target)
+
+	do := func(path, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	rec := do("/ttv/chat/emotes/global", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	require.Equal(t, `{"data":[]}`, rec.Body.String())
+	etag := rec.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	rec = do("/ttv/chat/emotes/global", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	require.Equal(t, `{"data":[]}`, rec.Body.String())
+
+	rec = do("/ttv/chat/emotes/global", etag)
+	require.Equal(t, http.StatusNotModified, rec.Code)
+	require.Empty(t, rec.Body.String())
+
+	require.Equal(t, int64(1), calls.Load())
+
+	// errors are not cached
+	require.Equal(t, http.StatusNotFound, do("/ttv/chat/emotes?broadcaster_id=missing", "").Code)
+	require.Equal(t, http.StatusNotFound, do("/ttv/chat/emotes?broadcaster_id=missing", "").Code)
+	require.Equal(t, int64(3), calls.Load())
+
+	// endpoints without TTL are not cached
+	do("/ttv/search/channels?query=a", "")
+	do("/ttv/search/channels?query=a", "")
+	require.Equal(t, int64(5), calls.Load())
+
+	require.Equal(t, int64(2), api.cacheStats.hits.Load())
+	require.Equal(t, int64(3), api.cacheStats.misses.Load())
+	require.Equal(t, int64(1), api.cacheStats.notModified.Load())
+}
@@ -1,14 +1,21 @@
 package server
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/url"
+	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 // DefaultHelixBaseURL is the default Twitch Helix API base URL.
 const DefaultHelixBaseURL = "https://api.twitch.tv"
 
+// helixCacheFetchTimeout limits requests to Twitch shared by multiple clients.
+const helixCacheFetchTimeout = 15 * time.Second
+
 // handleHelixProxy returns an http.HandlerFunc that forwards requests to the Twitch Helix API.
 // It rewrites /ttv/* paths to /helix/*, uses custom transport to inject auth headers,
 // and copies the response back to the client.
@@ -26,10 +33,16 @@ func (a *API) handleHelixProxy() http.HandlerFunc {
 // This is used by handleHelixProxy and can be used in tests.
 func (a *API) helixProxyHandlerWithTarget(target *url.URL) http.HandlerFunc {
 	// Create HTTP client with custom transport that injects auth headers
+	transport := newHelixRetryTransport(http.DefaultTransport, a.helixTokenProvider, a.conf.ClientID)
+	transport.metrics = a.metrics
+
 	client := &http.Client{
-		Transport: newHelixRetryTransport(http.DefaultTransport, a.helixTokenProvider, a.conf.ClientID),
+		Transport: transport,
 	}
 
+	cache := a.responseCache
+	var group singleflight.Group
+
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Only allow GET requests (all allowlisted endpoints are read-only)
 		if r.Method != http.MethodGet {
@@ -44,6 +57,12 @@ func (a *API) helixProxyHandlerWithTarget(target *url.URL) http.HandlerFunc {
 			RawQuery: r.URL.RawQuery,
 		})
 
+		// Responses of cacheable endpoints are shared by all clients
+		if ttl, ok := helixCacheTTLs[helixPath]; ok && cache != nil {
+			a.serveCachedHelix(w, r, cache, &group, client, helixPath, targetURL.String(), ttl)
+			return
+		}
+
 		// Create new request to Twitch (no body needed for GET)
 		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, targetURL.String(), nil)
 		if err != nil {
@@ -83,3 +102,94 @@ func (a *API) helixProxyHandlerWithTarget(target *url.URL) http.HandlerFunc {
 		io.Copy(w, resp.Body)
 	}
 }
+
+// serveCachedHelix answers from the cache or fetches the response from Twitch and caches it.
+// Concurrent misses of the same key share one request to Twitch.
+func (a *API) serveCachedHelix(w http.ResponseWriter, r *http.Request, cache ResponseCache, group *singleflight.Group, client *http.Client, helixPath, targetURL string, ttl time.Duration) {
+	logger := a.getLoggerFrom(r.Context())
+	key := helixCacheKey(helixPath, r.URL.Query())
+
+	cached, ok, err := cache.Get(r.Context(), key)
+	if err != nil {
+		logger.Warn().Err(err).Msg("failed to read helix cache")
+	}
+
+	if ok {
+		a.cacheStats.hits.Add(1)
+		if writeCachedResponse(w, r, cached, "HIT") {
+			a.cacheStats.notModified.Add(1)
+		}
+		return
+	}
+
+	a.cacheStats.misses.Add(1)
+
+	// the request outlives a client going away, the other waiting clients still need the response
+	v, err, _ := group.Do(key, func() (any, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), helixCacheFetchTimeout)
+		defer cancel()
+
+		return fetchHelixResponse(ctx, client, targetURL, ttl)
+	})
+	if err != nil {
+		logger.Err(err).Str("url", targetURL).Msg("proxy request failed")
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+		return
+	}
+
+	resp := v.(helixResponse)
+
+	// only successful responses are cached, errors are passed through like uncached responses
+	if resp.statusCode != http.StatusOK {
+		if resp.statusCode == http.StatusTooManyRequests && resp.rateLimitReset != "" {
+			w.Header().Set("Ratelimit-Reset", resp.rateLimitReset)
+		}
+
+		if resp.cached.ContentType != "" {
+			w.Header().Set("Content-Type", resp.cached.ContentType)
+		}
+
+		w.WriteHeader(resp.statusCode)
+		_, _ = w.Write(resp.cached.Body)
+		return
+	}
+
+	if err := cache.Set(r.Context(), key, resp.cached); err != nil {
+		logger.Warn().Err(err).Msg("failed to write helix cache")
+	}
+
+	if writeCachedResponse(w, r, resp.cached, "MISS") {
+		a.cacheStats.notModified.Add(1)
+	}
+}
+
+// helixResponse is a buffered Helix response of a cacheable endpoint.
+type helixResponse struct {
+	statusCode     int
+	rateLimitReset string
+	cached         CachedResponse
+}
+
+func fetchHelixResponse(ctx context.Context, client *http.Client, targetURL string, ttl time.Duration) (helixResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, targetURL, nil)
+	if err != nil {
+		return helixResponse{}, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return helixResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return helixResponse{}, err
+	}
+
+	return helixResponse{
+		statusCode:     resp.StatusCode,
+		rateLimitReset: resp.Header.Get("Ratelimit-Reset"),
+		cached:         newCachedResponse(resp.Header.Get("Content-Type"), body, ttl),
+	}, nil
+}
@@ -21,6 +21,7 @@ type helixRetryTransport struct {
 	base          http.RoundTripper
 	tokenProvider tokenProvider
 	clientID      string
+	metrics       *Metrics
 }
 
 // newHelixRetryTransport creates a new retry transport.
@@ -56,6 +57,7 @@ func (t *helixRetryTransport) RoundTrip(req *http.Request) (*http.Response, erro
 
 	// Got 401 - attempt token refresh and retry
 	t.tokenProvider.InvalidateToken()
+	t.metrics.observeHelixRetry()
 
 	// Second attempt with new token
 	respRetry, err := t.doAuthenticatedRequest(reqClone)
@@ -85,5 +87,12 @@ func (t *helixRetryTransport) doAuthenticatedRequest(req *http.Request) (*http.R
 	req.Header.Set("Client-Id", t.clientID)
 	req.Header.Set("Accept", "application/json")
 
-	return t.base.RoundTrip(req)
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.metrics.observeHelixUpstream(resp.StatusCode)
+
+	return resp, nil
 }
@@ -9,9 +9,16 @@ import (
 	"net/url"
 	"strings"
 	"sync"
+	"time"
 )
 
-const defaultTokenURL = "https://id.twitch.tv/oauth2/token"
+const (
+	defaultTokenURL    = "https://id.twitch.tv/oauth2/token"
+	defaultValidateURL = "https://id.twitch.tv/oauth2/validate"
+
+	// tokenValidateInterval limits how often CheckToken asks Twitch if the token is still valid
+	tokenValidateInterval = 5 * time.Minute
+)
 
 // HelixTokenProvider manages app access tokens for the Twitch Helix API.
 // It handles token creation and caching using the client credentials flow.
@@ -20,9 +27,12 @@ type HelixTokenProvider struct {
 	clientID     string
 	clientSecret string
 	tokenURL     string // configurable for testing
+	validateURL  string // configurable for testing
+	metrics      *Metrics
 
-	mu    sync.Mutex
-	token string
+	mu          sync.Mutex
+	token       string
+	validatedAt time.Time
 }
 
 // NewHelixTokenProvider creates a new Helix token provider.
@@ -32,6 +42,7 @@ func NewHelixTokenProvider(client *http.Client, clientID, clientSecret string) *
 		clientID:     clientID,
 		clientSecret: clientSecret,
 		tokenURL:     defaultTokenURL,
+		validateURL:  defaultValidateURL,
 	}
 }
 
@@ -46,14 +57,57 @@ func (p *HelixTokenProvider) EnsureToken(ctx context.Context) (string, error) {
 	}
 
 	token, err := p.createToken(ctx)
+	p.metrics.observeAppTokenRefresh(err)
 	if err != nil {
 		return "", err
 	}
 
 	p.token = token
+	p.validatedAt = time.Now()
 	return token, nil
 }
 
+// CheckToken verifies that a valid app access token is available. The token is validated with Twitch at most every
+// tokenValidateInterval, a revoked or expired token is replaced with a new one.
+func (p *HelixTokenProvider) CheckToken(ctx context.Context) error {
+	token, err := p.EnsureToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	recent := p.token == token && time.Since(p.validatedAt) < tokenValidateInterval
+	p.mu.Unlock()
+
+	if recent {
+		return nil
+	}
+
+	valid, err := p.validateToken(ctx, token)
+	if err != nil {
+		return err
+	}
+
+	if !valid {
+		p.mu.Lock()
+		if p.token == token {
+			p.token = ""
+		}
+		p.mu.Unlock()
+
+		_, err := p.EnsureToken(ctx)
+		return err
+	}
+
+	p.mu.Lock()
+	if p.token == token {
+		p.validatedAt = time.Now()
+	}
+	p.mu.Unlock()
+
+	return nil
+}
+
 // InvalidateToken clears the cached token, forcing a refresh on next GetToken call.
 func (p *HelixTokenProvider) InvalidateToken() {
 	p.mu.Lock()
@@ -61,6 +115,31 @@ func (p *HelixTokenProvider) InvalidateToken() {
 	p.token = ""
 }
 
+// validateToken asks Twitch if the token is still valid.
+func (p *HelixTokenProvider) validateToken(ctx context.Context, token string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.validateURL, nil)
+	if err != nil {
+		return false, fmt.Errorf("create validate request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "OAuth "+token)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("validate request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusUnauthorized:
+		return false, nil
+	default:
+		return false, fmt.Errorf("validate request returned status %d", resp.StatusCode)
+	}
+}
+
 // createToken requests a new app access token from Twitch using client credentials flow.
 func (p *HelixTokenProvider) createToken(ctx context.Context) (string, error) {
 	formVal := url.Values{}
@@ -2,10 +2,12 @@ package server
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -188,3 +190,47 @@ func TestHelixTokenProvider_Concurrency(t *testing.T) {
 	// But since they might all check before lock, we allow up to a few
 	require.LessOrEqual(t, callCount, 2, "token should be fetched at most twice due to race")
 }
+
+func TestHelixTokenProvider_CheckToken(t *testing.T) {
+	t.Parallel()
+
+	var created, validated int
+	var mu sync.Mutex
+	twitch := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch r.URL.Path {
+		case "/token":
+			created++
+			w.Write([]byte(fmt.Sprintf(`{"access_token":"token-v%d"}`, created)))
+		case "/validate":
+			validated++
+			if r.Header.Get("Authorization") == "OAuth token-v1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer twitch.Close()
+
+	provider := NewHelixTokenProvider(http.DefaultClient, "test-client-id", "test-secret")
+	provider.tokenURL = twitch.URL + "/token"
+	provider.validateURL = twitch.URL + "/validate"
+
+	// a freshly created token is not validated again
+	require.NoError(t, provider.CheckToken(context.Background()))
+	require.Equal(t, 1, created)
+	require.Equal(t, 0, validated)
+
+	// a revoked token is replaced
+	provider.validatedAt = time.Time{}
+	require.NoError(t, provider.CheckToken(context.Background()))
+	require.Equal(t, 2, created)
+	require.Equal(t, 1, validated)
+
+	token, err := provider.EnsureToken(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, "token-v2", token)
+}
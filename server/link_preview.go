@@ -0,0 +1,314 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// linkPreviewTTL is how long previews are cached, pages rarely change their title
+	linkPreviewTTL = time.Hour
+
+	// linkPreviewCacheEntries is the size of the in-memory link preview cache
+	linkPreviewCacheEntries = 5000
+
+	// linkPreviewMaxBody is how much of an HTML page is read to find the title, it is in the head of the page
+	linkPreviewMaxBody = 512 * 1024
+
+	// linkPreviewMaxText limits title and description, chat only shows a short excerpt anyway
+	linkPreviewMaxText = 300
+
+	linkPreviewTimeout = 10 * time.Second
+)
+
+// knownShorteners are URL shortener hosts, their links hide the actual target
+var knownShorteners = map[string]struct{}{
+	"bit.ly":      {},
+	"buff.ly":     {},
+	"cutt.ly":     {},
+	"goo.gl":      {},
+	"is.gd":       {},
+	"ow.ly":       {},
+	"rb.gy":       {},
+	"rebrand.ly":  {},
+	"shorturl.at": {},
+	"t.co":        {},
+	"t.ly":        {},
+	"tiny.cc":     {},
+	"tinyurl.com": {},
+}
+
+// LinkPreview is the JSON response of the /proxy/link_preview endpoint.
+type LinkPreview struct {
+	URL           string   `json:"url"`
+	FinalURL      string   `json:"final_url"`
+	StatusCode    int      `json:"status_code"`
+	ContentType   string   `json:"content_type,omitempty"`
+	ContentLength int64    `json:"content_length,omitempty"` // -1 if unknown
+	Title         string   `json:"title,omitempty"`
+	Description   string   `json:"description,omitempty"`
+	Redirects     []string `json:"redirects,omitempty"`
+	Shortener     bool     `json:"shortener,omitempty"` // the URL belongs to a known URL shortener
+}
+
+// linkPreviewError is a link preview request rejected before anything was fetched
+type linkPreviewError struct {
+	status  int
+	outcome string
+	msg     string
+}
+
+func (e linkPreviewError) Error() string {
+	return e.msg
+}
+
+func (a *API) handleLinkPreview() http.HandlerFunc {
+	return a.linkPreviewHandler(newLinkCheckTransport(), validateURLSecurity)
+}
+
+// linkPreviewHandler is the internal implementation with a configurable transport and URL validation, used by tests.
+func (a *API) linkPreviewHandler(transport http.RoundTripper, validate func(*url.URL) error) http.HandlerFunc {
+	cache := a.linkPreviewCache
+	var group singleflight.Group
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := a.getLoggerFrom(r.Context())
+
+		target, err := parseLinkPreviewTarget(r.URL.Query().Get("target"), validate)
+		if err != nil {
+			var previewErr linkPreviewError
+			if errors.As(err, &previewErr) {
+				a.metrics.observeLinkCheck(previewErr.outcome)
+				http.Error(w, previewErr.msg, previewErr.status)
+				return
+			}
+
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		key := "linkpreview:" + target.String()
+
+		if cache != nil {
+			cached, ok, err := cache.Get(r.Context(), key)
+			if err != nil {
+				logger.Warn().Err(err).Msg("failed to read link preview cache")
+			}
+
+			if ok {
+				writeCachedResponse(w, r, cached, "HIT")
+				return
+			}
+		}
+
+		// concurrent requests for the same link, e.g. a link posted in a big chat, share one fetch
+		v, err, _ := group.Do(key, func() (any, error) {
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), linkPreviewTimeout)
+			defer cancel()
+
+			var visited []string
+			client := newLinkCheckClient(transport, validate, logger, &visited)
+
+			preview, err := fetchLinkPreview(ctx, client, target)
+			if err != nil {
+				return nil, err
+			}
+
+			preview.Redirects = visited
+			return preview, nil
+		})
+		if err != nil {
+			logger.Err(err).Str("target", target.String()).Msg("failed link preview request")
+			a.metrics.observeLinkCheck(linkCheckFailed)
+			http.Error(w, fmt.Sprintf("Failed to fetch link preview: %q", err.Error()), http.StatusBadGateway)
+			return
+		}
+
+		a.metrics.observeLinkCheck(linkCheckOK)
+
+		body, err := json.Marshal(v.(LinkPreview))
+		if err != nil {
+			logger.Err(err).Msg("failed to marshal link preview")
+			http.Error(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		resp := newCachedResponse("application/json", body, linkPreviewTTL)
+
+		if cache != nil {
+			if err := cache.Set(r.Context(), key, resp); err != nil {
+				logger.Warn().Err(err).Msg("failed to write link preview cache")
+			}
+		}
+
+		writeCachedResponse(w, r, resp, "MISS")
+	}
+}
+
+func parseLinkPreviewTarget(raw string, validate func(*url.URL) error) (*url.URL, error) {
+	if raw == "" {
+		return nil, linkPreviewError{status: http.StatusBadRequest, outcome: linkCheckInvalid, msg: "Missing target URL"}
+	}
+
+	target, err := url.Parse(raw)
+	if err != nil {
+		return nil, linkPreviewError{status: http.StatusBadRequest, outcome: linkCheckInvalid, msg: fmt.Sprintf("%s is invalid target URL", raw)}
+	}
+
+	// the fragment never reaches the remote server, it would only split the cache
+	target.Fragment = ""
+
+	if err := validate(target); err != nil {
+		return nil, linkPreviewError{status: http.StatusBadRequest, outcome: linkCheckBlocked, msg: fmt.Sprintf("%s is invalid target URL: %s", raw, err)}
+	}
+
+	return target, nil
+}
+
+// fetchLinkPreview requests the target and extracts title and description of HTML pages.
+func fetchLinkPreview(ctx context.Context, client *http.Client, target *url.URL) (LinkPreview, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.String(), nil)
+	if err != nil {
+		return LinkPreview{}, err
+	}
+
+	setFakeFirefoxHeaders(req)
+	// the transport only decompresses responses if it asked for the encoding itself
+	req.Header.Del("Accept-Encoding")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return LinkPreview{}, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	_, shortener := knownShorteners[strings.TrimPrefix(strings.ToLower(target.Hostname()), "www.")]
+
+	preview := LinkPreview{
+		URL:           target.String(),
+		FinalURL:      resp.Request.URL.String(),
+		StatusCode:    resp.StatusCode,
+		ContentType:   resp.Header.Get("Content-Type"),
+		ContentLength: resp.ContentLength,
+		Shortener:     shortener,
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(preview.ContentType)
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return preview, nil
+	}
+
+	preview.Title, preview.Description = extractPageMeta(io.LimitReader(resp.Body, linkPreviewMaxBody))
+
+	return preview, nil
+}
+
+// extractPageMeta returns the title and description of an HTML page. OpenGraph tags are preferred,
+// they are meant for previews and usually better than the <title> of the page.
+func extractPageMeta(r io.Reader) (title, description string) {
+	var (
+		z            = html.NewTokenizer(r)
+		inTitle      bool
+		pageTitle    strings.Builder
+		ogTitle      string
+		ogDesc, desc string
+	)
+
+	for {
+		tt := z.Next()
+
+		switch tt {
+		case html.ErrorToken:
+			return finishPageMeta(ogTitle, pageTitle.String(), ogDesc, desc)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+
+			switch string(name) {
+			case "title":
+				inTitle = tt == html.StartTagToken
+			case "meta":
+				if !hasAttr {
+					continue
+				}
+
+				var key, content string
+				for {
+					attr, val, more := z.TagAttr()
+					switch strings.ToLower(string(attr)) {
+					case "property", "name":
+						key = strings.ToLower(string(val))
+					case "content":
+						content = string(val)
+					}
+
+					if !more {
+						break
+					}
+				}
+
+				switch key {
+				case "og:title":
+					ogTitle = content
+				case "og:description":
+					ogDesc = content
+				case "description":
+					desc = content
+				}
+			}
+		case html.EndTagToken:
+			name, _ := z.TagName()
+
+			switch string(name) {
+			case "title":
+				inTitle = false
+			case "head":
+				// everything needed is in the head, skip the rest of the page
+				return finishPageMeta(ogTitle, pageTitle.String(), ogDesc, desc)
+			}
+		case html.TextToken:
+			if inTitle {
+				pageTitle.Write(z.Text())
+			}
+		}
+	}
+}
+
+func finishPageMeta(ogTitle, pageTitle, ogDesc, desc string) (string, string) {
+	title := ogTitle
+	if strings.TrimSpace(title) == "" {
+		title = pageTitle
+	}
+
+	description := ogDesc
+	if strings.TrimSpace(description) == "" {
+		description = desc
+	}
+
+	return cleanPreviewText(title), cleanPreviewText(description)
+}
+
+// cleanPreviewText collapses whitespace and truncates the text to linkPreviewMaxText runes.
+func cleanPreviewText(s string) string {
+	s = strings.Join(strings.Fields(s), " ")
+
+	if utf8.RuneCountInString(s) <= linkPreviewMaxText {
+		return s
+	}
+
+	runes := []rune(s)
+	return strings.TrimSpace(string(runes[:linkPreviewMaxText])) + "…"
+}
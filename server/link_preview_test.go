@@ -0,0 +1,136 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractPageMeta(t *testing.T) {
+	t.Parallel()
+
+	t.Run("prefers OpenGraph", func(t *testing.T) {
+		t.Parallel()
+
+		title, desc := extractPageMeta(strings.NewReader(`<html><head>
+<title>Page &amp; Title</title>
+<meta property="og:title" content="OG Title">
+<meta name="description" content="Plain description">
+<meta property="og:description" content="OG   description">
+</head><body><title>ignored</title></body></html>`))
+
+		require.Equal(t, "OG Title", title)
+		require.Equal(t, "OG description", desc)
+	})
+
+	t.Run("falls back to title and description", func(t *testing.T) {
+		t.Parallel()
+
+		title, desc := extractPageMeta(strings.NewReader(`<title>
+  Page &amp; Title
+</title><meta name="Description" content="Plain description">`))
+
+		require.Equal(t, "Page & Title", title)
+		require.Equal(t, "Plain description", desc)
+	})
+
+	t.Run("truncates long text", func(t *testing.T) {
+		t.Parallel()
+
+		title, _ := extractPageMeta(strings.NewReader("<title>" + strings.Repeat("a", 400) + "</title>"))
+		require.Equal(t, strings.Repeat("a", linkPreviewMaxText)+"…", title)
+	})
+}
+
+func TestLinkPreviewHandler(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int64
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+
+		switch r.URL.Path {
+		case "/short":
+			http.Redirect(w, r, "/page", http.StatusFound)
+		case "/page":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.Write([]byte(`<html><head><title>Stream Highlights</title></head></html>`))
+		case "/image.png":
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(make([]byte, 2048))
+		}
+	}))
+	defer remote.Close()
+
+	api := createTestAPI(t)
+	api.linkPreviewCache = NewMemoryResponseCache(10)
+
+	// the test server is local, which validateURLSecurity rightly blocks
+	allowAll := func(*url.URL) error { return nil }
+	handler := api.linkPreviewHandler(http.DefaultTransport, allowAll)
+
+	get := func(target string) (*httptest.ResponseRecorder, LinkPreview) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/proxy/link_preview?target="+url.QueryEscape(target), nil))
+
+		var preview LinkPreview
+		if rec.Code == http.StatusOK {
+			require.NoError(t, json.NewDecoder(rec.Body).Decode(&preview))
+		}
+
+		return rec, preview
+	}
+
+	rec, preview := get(remote.URL + "/short#fragment")
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, "MISS", rec.Header().Get("X-Cache"))
+	require.Equal(t, remote.URL+"/short", preview.URL)
+	require.Equal(t, remote.URL+"/page", preview.FinalURL)
+	require.Equal(t, []string{remote.URL + "/page"}, preview.Redirects)
+	require.Equal(t, "Stream Highlights", preview.Title)
+	require.Equal(t, http.StatusOK, preview.StatusCode)
+
+	rec, _ = get(remote.URL + "/short")
+	require.Equal(t, "HIT", rec.Header().Get("X-Cache"))
+	require.Equal(t, int64(2), calls.Load(), "the cached preview is not fetched again")
+
+	_, preview = get(remote.URL + "/image.png")
+	require.Equal(t, "image/png", preview.ContentType)
+	require.Equal(t, int64(2048), preview.ContentLength)
+	require.Empty(t, preview.Title)
+
+	rec, _ = get("")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	blocked := api.linkPreviewHandler(http.DefaultTransport, validateURLSecurity)
+	rec = httptest.NewRecorder()
+	blocked.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/proxy/link_preview?target="+url.QueryEscape(remote.URL+"/page"), nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestKnownShortener(t *testing.T) {
+	t.Parallel()
+
+	remote := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer remote.Close()
+
+	// route bit.ly to the test server
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = func(*http.Request) (*url.URL, error) { return url.Parse(remote.URL) }
+
+	target, err := url.Parse("http://bit.ly/abc")
+	require.NoError(t, err)
+
+	preview, err := fetchLinkPreview(t.Context(), &http.Client{Transport: transport}, target)
+	require.NoError(t, err)
+	require.True(t, preview.Shortener)
+	require.Equal(t, http.StatusNoContent, preview.StatusCode)
+}
@@ -0,0 +1,192 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "chatuino"
+
+// Link check outcomes
+const (
+	linkCheckOK      = "ok"
+	linkCheckInvalid = "invalid"
+	linkCheckBlocked = "blocked"
+	linkCheckFailed  = "failed"
+)
+
+// Metrics collects the Prometheus metrics of the server. Every server has its own registry, so tests don't share state.
+// All methods are safe to call on a nil *Metrics, which records nothing.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests            *prometheus.CounterVec
+	requestDuration     *prometheus.HistogramVec
+	helixUpstream       *prometheus.CounterVec
+	helixRetries        prometheus.Counter
+	appTokenRefreshes   *prometheus.CounterVec
+	rateLimitRejections *prometheus.CounterVec
+	linkChecks          *prometheus.CounterVec
+}
+
+func NewMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: metricsNamespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latencies by route and method.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method"}),
+		helixUpstream: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "helix_upstream_responses_total",
+			Help:      "Responses of the Twitch Helix API by status code, including retried requests.",
+		}, []string{"status"}),
+		helixRetries: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "helix_retries_total",
+			Help:      "Helix requests retried with a new app token after 401 Unauthorized.",
+		}),
+		appTokenRefreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "app_token_refreshes_total",
+			Help:      "App access tokens requested from Twitch by result.",
+		}, []string{"result"}),
+		rateLimitRejections: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "ratelimit_rejections_total",
+			Help:      "Requests rejected by the rate limiter by policy.",
+		}, []string{"policy"}),
+		linkChecks: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      "link_checks_total",
+			Help:      "Link checks by outcome: ok, invalid, blocked or failed.",
+		}, []string{"outcome"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests,
+		m.requestDuration,
+		m.helixUpstream,
+		m.helixRetries,
+		m.appTokenRefreshes,
+		m.rateLimitRejections,
+		m.linkChecks,
+	)
+
+	return m
+}
+
+// registerCacheStats exports the counters of the Helix response cache.
+func (m *Metrics) registerCacheStats(stats *helixCacheStats) {
+	if m == nil {
+		return
+	}
+
+	counter := func(name, help string, load func() int64) prometheus.CounterFunc {
+		return prometheus.NewCounterFunc(prometheus.CounterOpts{
+			Namespace: metricsNamespace,
+			Name:      name,
+			Help:      help,
+		}, func() float64 { return float64(load()) })
+	}
+
+	m.registry.MustRegister(
+		counter("helix_cache_hits_total", "Helix requests answered from the response cache.", stats.hits.Load),
+		counter("helix_cache_misses_total", "Helix requests of cacheable endpoints fetched from Twitch.", stats.misses.Load),
+		counter("helix_cache_not_modified_total", "Helix requests answered with 304 Not Modified.", stats.notModified.Load),
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware records count and latency of requests. The route pattern is used as label, not the path,
+// so paths like /ttv/users?id=... don't create a time series per request.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+
+		next.ServeHTTP(ww, r)
+
+		route := "unmatched"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		m.requests.WithLabelValues(route, r.Method, strconv.Itoa(status)).Inc()
+		m.requestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+	})
+}
+
+func (m *Metrics) observeHelixUpstream(status int) {
+	if m == nil {
+		return
+	}
+
+	m.helixUpstream.WithLabelValues(strconv.Itoa(status)).Inc()
+}
+
+func (m *Metrics) observeHelixRetry() {
+	if m == nil {
+		return
+	}
+
+	m.helixRetries.Inc()
+}
+
+func (m *Metrics) observeAppTokenRefresh(err error) {
+	if m == nil {
+		return
+	}
+
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+
+	m.appTokenRefreshes.WithLabelValues(result).Inc()
+}
+
+func (m *Metrics) observeRateLimitRejection(policy string) {
+	if m == nil {
+		return
+	}
+
+	m.rateLimitRejections.WithLabelValues(policy).Inc()
+}
+
+func (m *Metrics) observeLinkCheck(outcome string) {
+	if m == nil {
+		return
+	}
+
+	m.linkChecks.WithLabelValues(outcome).Inc()
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	t.Run("records requests by route pattern", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMetrics()
+
+		r := chi.NewRouter()
+		r.Use(m.Middleware)
+		r.Get("/ttv/*", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTeapot)
+		})
+		r.Method(http.MethodGet, "/metrics", m.Handler())
+
+		for _, path := range []string{"/ttv/users?id=1", "/ttv/streams"} {
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+
+		body := scrapeMetrics(t, r)
+		require.Contains(t, body, `chatuino_http_requests_total{method="GET",route="/ttv/*",status="418"} 2`)
+		require.Contains(t, body, `chatuino_http_request_duration_seconds_count{method="GET",route="/ttv/*"} 2`)
+	})
+
+	t.Run("exports cache stats and outcomes", func(t *testing.T) {
+		t.Parallel()
+
+		m := NewMetrics()
+
+		var stats helixCacheStats
+		m.registerCacheStats(&stats)
+		stats.hits.Add(3)
+
+		m.observeHelixUpstream(http.StatusUnauthorized)
+		m.observeHelixRetry()
+		m.observeRateLimitRejection(authRateLimit.Name)
+		m.observeLinkCheck(linkCheckBlocked)
+		m.observeAppTokenRefresh(nil)
+
+		r := chi.NewRouter()
+		r.Method(http.MethodGet, "/metrics", m.Handler())
+
+		body := scrapeMetrics(t, r)
+		require.Contains(t, body, "chatuino_helix_cache_hits_total 3")
+		require.Contains(t, body, `chatuino_helix_upstream_responses_total{status="401"} 1`)
+		require.Contains(t, body, "chatuino_helix_retries_total 1")
+		require.Contains(t, body, `chatuino_ratelimit_rejections_total{policy="auth"} 1`)
+		require.Contains(t, body, `chatuino_link_checks_total{outcome="blocked"} 1`)
+		require.Contains(t, body, `chatuino_app_token_refreshes_total{result="success"} 1`)
+	})
+
+	t.Run("nil metrics record nothing", func(t *testing.T) {
+		t.Parallel()
+
+		var m *Metrics
+
+		require.NotPanics(t, func() {
+			m.observeHelixUpstream(http.StatusOK)
+			m.observeLinkCheck(linkCheckOK)
+			m.registerCacheStats(&helixCacheStats{})
+
+			rec := httptest.NewRecorder()
+			m.Middleware(http.NotFoundHandler()).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		})
+	})
+}
+
+func scrapeMetrics(t *testing.T, h http.Handler) string {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	return strings.TrimSpace(rec.Body.String())
+}
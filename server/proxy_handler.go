@@ -8,43 +8,26 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/rs/zerolog"
 )
 
 func (a *API) handleCheckRedirectsRequest() http.HandlerFunc {
-	dialFunc := http.DefaultTransport.(*http.Transport).DialContext
-	transport := &http.Transport{
-		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
-			host, _, err := net.SplitHostPort(addr)
-			if err != nil {
-				return nil, err
-			}
-
-			ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
-			if err != nil {
-				return nil, err
-			}
-
-			for _, ipAddr := range ips {
-				if isBlockedIP(ipAddr.IP) {
-					return nil, fmt.Errorf("connection to private/local IP address blocked: %s resolves to %s", host, ipAddr.IP)
-				}
-			}
-
-			return dialFunc(ctx, network, addr)
-		},
-	}
+	transport := newLinkCheckTransport()
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		logger := a.getLoggerFrom(r.Context())
 
 		rawTargetURL := r.URL.Query().Get("target")
 		if rawTargetURL == "" {
+			a.metrics.observeLinkCheck(linkCheckInvalid)
 			http.Error(w, "Missing target URL", http.StatusBadRequest)
 			return
 		}
 
 		target, err := url.Parse(rawTargetURL)
 		if err != nil {
+			a.metrics.observeLinkCheck(linkCheckInvalid)
 			http.Error(w, fmt.Sprintf("%s is invalid target URL", rawTargetURL), http.StatusBadRequest)
 			return
 		}
@@ -53,28 +36,13 @@ func (a *API) handleCheckRedirectsRequest() http.HandlerFunc {
 
 		if err := validateURLSecurity(target); err != nil {
 			logger.Err(err).Str("target", target.String()).Msg("got link check request for suspicious URL")
+			a.metrics.observeLinkCheck(linkCheckBlocked)
 			http.Error(w, fmt.Sprintf("%s is invalid target URL: %s", rawTargetURL, err), http.StatusBadRequest)
 			return
 		}
 
 		var visited []string
-		client := http.Client{
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				if err := validateURLSecurity(req.URL); err != nil {
-					logger.Err(err).Str("target", req.URL.String()).Msg("got link check request for suspicious URL")
-					return err
-				}
-
-				visited = append(visited, req.URL.String())
-
-				if len(via) > 10 {
-					return fmt.Errorf("too many redirects")
-				}
-
-				return nil
-			},
-			Transport: transport,
-		}
+		client := newLinkCheckClient(transport, validateURLSecurity, logger, &visited)
 
 		// prevent DoS attacks
 		ctx, cancel := context.WithTimeout(r.Context(), time.Second*10)
@@ -91,6 +59,7 @@ func (a *API) handleCheckRedirectsRequest() http.HandlerFunc {
 		resp, err := client.Do(proxyReq)
 		if err != nil {
 			logger.Err(err).Str("og_target", target.String()).Strs("visited", visited).Msg("failed link checker proxy request")
+			a.metrics.observeLinkCheck(linkCheckFailed)
 			http.Error(w, fmt.Sprintf("Failed to execute proxy request: %q", err.Error()), http.StatusBadGateway)
 			return
 		}
@@ -99,12 +68,63 @@ func (a *API) handleCheckRedirectsRequest() http.HandlerFunc {
 			_ = resp.Body.Close()
 		}()
 
+		a.metrics.observeLinkCheck(linkCheckOK)
+
 		w.Header().Set("X-Remote-Status-Code", fmt.Sprintf("%d", resp.StatusCode))
 		w.Header().Set("X-Remote-Content-Type", resp.Header.Get("Content-Type"))
 		w.Header().Set("X-Visited-URLs", url.QueryEscape(strings.Join(visited, ",")))
 	})
 }
 
+// newLinkCheckTransport returns a transport which refuses to connect to private and local addresses.
+// The resolved addresses are checked, so DNS names pointing to internal hosts are blocked as well.
+func newLinkCheckTransport() *http.Transport {
+	dialFunc := http.DefaultTransport.(*http.Transport).DialContext
+
+	return &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+
+			ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, ipAddr := range ips {
+				if isBlockedIP(ipAddr.IP) {
+					return nil, fmt.Errorf("connection to private/local IP address blocked: %s resolves to %s", host, ipAddr.IP)
+				}
+			}
+
+			return dialFunc(ctx, network, addr)
+		},
+	}
+}
+
+// newLinkCheckClient returns a client which validates every redirect target and records it in visited.
+func newLinkCheckClient(transport http.RoundTripper, validate func(*url.URL) error, logger zerolog.Logger, visited *[]string) *http.Client {
+	return &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if err := validate(req.URL); err != nil {
+				logger.Err(err).Str("target", req.URL.String()).Msg("got link check request for suspicious URL")
+				return err
+			}
+
+			*visited = append(*visited, req.URL.String())
+
+			if len(via) > 10 {
+				return fmt.Errorf("too many redirects")
+			}
+
+			return nil
+		},
+		Transport: transport,
+	}
+}
+
 // isBlockedIP checks if an IP address is in a blocked range
 func isBlockedIP(ip net.IP) bool {
 	// Loopback (127.0.0.0/8, ::1)
@@ -3,10 +3,13 @@ package server
 import (
 	"context"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
+	"net/netip"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -19,61 +22,122 @@ const (
 	windowDuration = time.Minute // Sliding window duration
 )
 
-// RateLimiter implements sliding window rate limiting using Redis
+// RateLimitPolicy limits the requests of a client IP to Limit per Window. Policies with different names are counted independently.
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// Per route policies. Link checks make the server request arbitrary URLs and the auth routes talk to Twitch's OAuth API,
+// so they are stricter than the Helix proxy which clients call a lot while loading channels.
+var (
+	ttvRateLimit       = RateLimitPolicy{Name: "ttv", Limit: burstCapacity, Window: windowDuration}
+	linkCheckRateLimit = RateLimitPolicy{Name: "link_check", Limit: 30, Window: windowDuration}
+	authRateLimit      = RateLimitPolicy{Name: "auth", Limit: 20, Window: windowDuration}
+)
+
+// RateLimitResult is the outcome of a single request.
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	Reset     time.Duration // until the quota is available again
+}
+
+// RateLimitBackend counts the requests per key.
+type RateLimitBackend interface {
+	Allow(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error)
+}
+
+// RateLimiter enforces rate limit policies per client IP.
+// Errors of the backend fall back to an in-process limiter, so a Redis outage does not disable the protection.
 type RateLimiter struct {
-	client *redis.Client
-	logger zerolog.Logger
+	backend        RateLimitBackend
+	fallback       RateLimitBackend
+	trustedProxies []netip.Prefix
+	logger         zerolog.Logger
+	metrics        *Metrics
 }
 
-// NewRateLimiter creates a new rate limiter with Redis client.
-// If client is nil, rate limiting is disabled.
-func NewRateLimiter(client *redis.Client, logger zerolog.Logger) *RateLimiter {
+// NewRateLimiter creates a new rate limiter with the backend.
+// If backend is nil, rate limiting is disabled. X-Forwarded-For is only used for requests from trustedProxies.
+func NewRateLimiter(backend RateLimitBackend, trustedProxies []netip.Prefix, logger zerolog.Logger) *RateLimiter {
 	return &RateLimiter{
-		client: client,
-		logger: logger,
+		backend:        backend,
+		fallback:       NewMemoryRateLimitBackend(),
+		trustedProxies: trustedProxies,
+		logger:         logger,
 	}
 }
 
-// Middleware returns a middleware that enforces rate limits per client IP
-func (rl *RateLimiter) Middleware(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// If no Redis client, pass through (rate limiting disabled)
-		if rl.client == nil {
-			next.ServeHTTP(w, r)
-			return
-		}
+// Middleware returns a middleware that enforces the policy per client IP
+func (rl *RateLimiter) Middleware(policy RateLimitPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// If no backend, pass through (rate limiting disabled)
+			if rl.backend == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
 
-		clientIP := extractClientIP(r)
+			clientIP := extractClientIP(r, rl.trustedProxies)
+			key := fmt.Sprintf("ratelimit:%s:%s", policy.Name, clientIP)
+
+			result, err := rl.backend.Allow(r.Context(), key, policy)
+			if err != nil {
+				rl.logger.Warn().Err(err).Str("client_ip", clientIP).Msg("rate limit check failed, using in-memory limiter")
+
+				result, err = rl.fallback.Allow(r.Context(), key, policy)
+				if err != nil {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			setRateLimitHeaders(w.Header(), policy, result)
+
+			if !result.Allowed {
+				rl.metrics.observeRateLimitRejection(policy.Name)
+				http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+				return
+			}
 
-		allowed, err := rl.checkLimit(r.Context(), clientIP)
-		if err != nil {
-			// Fail open - allow request if Redis unavailable
-			rl.logger.Warn().Err(err).Str("client_ip", clientIP).Msg("rate limit check failed, allowing request")
 			next.ServeHTTP(w, r)
-			return
-		}
+		})
+	}
+}
 
-		if !allowed {
-			// Set Ratelimit-Reset header with Unix timestamp (mimics Twitch behavior)
-			// Clients can retry after 60 seconds
-			resetTime := time.Now().Add(windowDuration).Unix()
-			w.Header().Set("Ratelimit-Reset", strconv.FormatInt(resetTime, 10))
-			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
-			return
-		}
+// setRateLimitHeaders sets the RateLimit header fields of the IETF draft. RateLimit-Reset holds the seconds until
+// the quota is available again, unlike Twitch's Unix timestamp. Retry-After carries the same delay on 429.
+func setRateLimitHeaders(h http.Header, policy RateLimitPolicy, result RateLimitResult) {
+	reset := int64(math.Ceil(result.Reset.Seconds()))
 
-		next.ServeHTTP(w, r)
-	})
+	h.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.Limit, int64(policy.Window.Seconds())))
+	h.Set("RateLimit-Limit", strconv.Itoa(policy.Limit))
+	h.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	h.Set("RateLimit-Reset", strconv.FormatInt(reset, 10))
+
+	if !result.Allowed {
+		h.Set("Retry-After", strconv.FormatInt(max(reset, 1), 10))
+	}
+}
+
+// RedisRateLimitBackend implements sliding window rate limiting using Redis, shared by all server instances.
+type RedisRateLimitBackend struct {
+	client *redis.Client
 }
 
-// checkLimit verifies if the client is within rate limits using sliding window algorithm.
-func (rl *RateLimiter) checkLimit(ctx context.Context, clientIP string) (bool, error) {
-	key := fmt.Sprintf("ratelimit:%s", clientIP)
+func NewRedisRateLimitBackend(client *redis.Client) *RedisRateLimitBackend {
+	return &RedisRateLimitBackend{client: client}
+}
+
+// Allow verifies if the key is within the policy using a sliding window log.
+func (b *RedisRateLimitBackend) Allow(ctx context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
 	now := time.Now()
-	windowStart := now.Add(-windowDuration)
+	windowStart := now.Add(-policy.Window)
 
 	// Pipeline batches commands for single network round-trip
-	pipe := rl.client.Pipeline()
+	pipe := b.client.Pipeline()
 
 	// 1. Remove old entries outside the sliding window
 	pipe.ZRemRangeByScore(ctx, key, "0", strconv.FormatInt(windowStart.UnixNano(), 10))
@@ -88,47 +152,193 @@ func (rl *RateLimiter) checkLimit(ctx context.Context, clientIP string) (bool, e
 	})
 
 	// 4. Set expiration on key (auto-cleanup for idle IPs)
-	pipe.Expire(ctx, key, windowDuration*2)
+	pipe.Expire(ctx, key, policy.Window*2)
 
 	// 5. Execute all commands in one network call
 	_, err := pipe.Exec(ctx)
 	if err != nil {
-		return false, err
+		return RateLimitResult{}, err
 	}
 
 	// 6. Get count result from command #2
-	count := countCmd.Val()
+	count := int(countCmd.Val())
+
+	// 7. Check against the limit (max requests in window)
+	return RateLimitResult{
+		Allowed:   count < policy.Limit,
+		Remaining: max(policy.Limit-count-1, 0),
+		Reset:     policy.Window,
+	}, nil
+}
+
+// MemoryRateLimitBackend implements a sliding window counter in process memory.
+// It needs no setup, but every server instance counts on its own.
+type MemoryRateLimitBackend struct {
+	mu        sync.Mutex
+	windows   map[string]*rateWindow
+	lastSweep time.Time
+	now       func() time.Time // configurable for testing
+}
+
+// rateWindow counts the requests of the current and previous fixed window. The previous count is weighted by how much
+// of it still overlaps the sliding window, which approximates a sliding window log with two counters.
+type rateWindow struct {
+	start             time.Time
+	window            time.Duration
+	current, previous int
+}
+
+func NewMemoryRateLimitBackend() *MemoryRateLimitBackend {
+	return &MemoryRateLimitBackend{
+		windows: map[string]*rateWindow{},
+		now:     time.Now,
+	}
+}
+
+func (b *MemoryRateLimitBackend) Allow(_ context.Context, key string, policy RateLimitPolicy) (RateLimitResult, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.sweep(now)
+
+	w, ok := b.windows[key]
+	if !ok {
+		w = &rateWindow{start: now.Truncate(policy.Window), window: policy.Window}
+		b.windows[key] = w
+	}
+
+	w.advance(now)
 
-	// 7. Check against burst capacity (max requests in window)
-	if count >= burstCapacity {
-		return false, nil
+	elapsed := now.Sub(w.start)
+	weight := 1 - float64(elapsed)/float64(w.window)
+	count := float64(w.previous)*weight + float64(w.current)
+
+	// the request itself has to fit into the limit
+	if count+1 > float64(policy.Limit) {
+		return RateLimitResult{Reset: w.resetAfter(now, policy.Limit-1)}, nil
+	}
+
+	w.current++
+
+	return RateLimitResult{
+		Allowed:   true,
+		Remaining: max(policy.Limit-int(math.Ceil(count))-1, 0),
+		Reset:     w.start.Add(w.window).Sub(now),
+	}, nil
+}
+
+func (w *rateWindow) advance(now time.Time) {
+	switch elapsed := now.Sub(w.start); {
+	case elapsed >= 2*w.window:
+		w.start = now.Truncate(w.window)
+		w.previous, w.current = 0, 0
+	case elapsed >= w.window:
+		w.start = w.start.Add(w.window)
+		w.previous, w.current = w.current, 0
+	}
+}
+
+// resetAfter returns when the weighted count drops to threshold again.
+func (w *rateWindow) resetAfter(now time.Time, threshold int) time.Duration {
+	if w.current > threshold {
+		// the current window becomes the previous one and has to decay: current*(1 - t/window) <= threshold
+		t := time.Duration(float64(w.window) * (1 - float64(threshold)/float64(w.current)))
+		return max(w.start.Add(w.window+t).Sub(now), time.Second)
+	}
+
+	// previous*(1 - t/window) + current <= threshold
+	t := time.Duration(float64(w.window) * (1 - float64(threshold-w.current)/float64(max(w.previous, 1))))
+	return max(w.start.Add(t).Sub(now), time.Second)
+}
+
+// sweep drops the windows of idle clients, at most once per minute.
+func (b *MemoryRateLimitBackend) sweep(now time.Time) {
+	if now.Sub(b.lastSweep) < time.Minute {
+		return
+	}
+
+	b.lastSweep = now
+
+	for key, w := range b.windows {
+		if now.Sub(w.start) >= 2*w.window {
+			delete(b.windows, key)
+		}
+	}
+}
+
+// ParseTrustedProxies parses a list of CIDRs or single IPs.
+func ParseTrustedProxies(values []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(values))
+
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+			}
+
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+
+		prefix, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", v, err)
+		}
+
+		prefixes = append(prefixes, prefix.Masked())
 	}
 
-	return true, nil
+	return prefixes, nil
 }
 
 // extractClientIP extracts the client IP from the request.
 //
-// SECURITY NOTE: X-Forwarded-For can be spoofed by clients.
-// This implementation trusts X-Forwarded-For, which is acceptable when:
-// 1. Server runs behind a trusted reverse proxy (nginx, Cloudflare, etc.) (applies for chatuino's deployment)
-// 2. Proxy strips/overwrites client-provided X-Forwarded-For headers
-// 3. Worst case: attacker bypasses rate limit for their IP only (no privilege escalation)
-func extractClientIP(r *http.Request) string {
+// X-Forwarded-For can be spoofed by clients, so it is only read if the connection comes from a trusted proxy.
+// The header is walked from the right, the first address which is not a trusted proxy is the client.
+// A proxy in front has to append to or overwrite client provided X-Forwarded-For headers (nginx, Cloudflare, etc. do).
+func extractClientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	remoteIP := r.RemoteAddr
+	if ip, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remoteIP = ip
+	}
+
 	xff := r.Header.Get("X-Forwarded-For")
-	if xff != "" {
-		// X-Forwarded-For format: "client, proxy1, proxy2"
-		// Split on comma and take first IP (original client)
-		ips := strings.Split(xff, ",")
-		if len(ips) > 0 {
-			return strings.TrimSpace(ips[0])
+	if xff == "" || !isTrustedProxy(remoteIP, trustedProxies) {
+		return remoteIP
+	}
+
+	// X-Forwarded-For format: "client, proxy1, proxy2"
+	ips := strings.Split(xff, ",")
+	for i := len(ips) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(ips[i])
+		if i == 0 || !isTrustedProxy(ip, trustedProxies) {
+			return ip
 		}
 	}
 
-	// No X-Forwarded-For, use direct connection IP
-	if ip, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
-		return ip
+	return remoteIP
+}
+
+func isTrustedProxy(ip string, trustedProxies []netip.Prefix) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+
+	for _, p := range trustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
 	}
 
-	return r.RemoteAddr
+	return false
 }
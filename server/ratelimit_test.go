@@ -2,8 +2,10 @@ package server
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/netip"
 	"strconv"
 	"testing"
 	"time"
@@ -30,14 +32,14 @@ func TestRateLimiter_Middleware(t *testing.T) {
 		}
 
 		// Clear any existing test data
-		testKey := "ratelimit:192.0.2.1"
+		testKey := "ratelimit:ttv:192.0.2.1"
 		client.Del(ctx, testKey)
 		defer client.Del(ctx, testKey)
 
 		logger := zerolog.Nop()
-		limiter := NewRateLimiter(client, logger)
+		limiter := NewRateLimiter(NewRedisRateLimitBackend(client), nil, logger)
 
-		handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler := limiter.Middleware(ttvRateLimit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 		}))
 
@@ -66,14 +68,14 @@ func TestRateLimiter_Middleware(t *testing.T) {
 			t.Skip("Redis not available:", err)
 		}
 
-		testKey := "ratelimit:192.0.2.2"
+		testKey := "ratelimit:ttv:192.0.2.2"
 		client.Del(ctx, testKey)
 		defer client.Del(ctx, testKey)
 
 		logger := zerolog.Nop()
-		limiter := NewRateLimiter(client, logger)
+		limiter := NewRateLimiter(NewRedisRateLimitBackend(client), nil, logger)
 
-		handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler := limiter.Middleware(ttvRateLimit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 		}))
 
@@ -113,14 +115,14 @@ func TestRateLimiter_Middleware(t *testing.T) {
 			t.Skip("Redis not available:", err)
 		}
 
-		testKey := "ratelimit:192.0.2.3"
+		testKey := "ratelimit:ttv:192.0.2.3"
 		client.Del(ctx, testKey)
 		defer client.Del(ctx, testKey)
 
 		logger := zerolog.Nop()
-		limiter := NewRateLimiter(client, logger)
+		limiter := NewRateLimiter(NewRedisRateLimitBackend(client), nil, logger)
 
-		handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler := limiter.Middleware(ttvRateLimit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 		}))
 
@@ -137,12 +139,11 @@ func TestRateLimiter_Middleware(t *testing.T) {
 				resetHeader := w.Header().Get("Ratelimit-Reset")
 				require.NotEmpty(t, resetHeader, "Ratelimit-Reset header should be present")
 
-				resetTime, err := strconv.ParseInt(resetHeader, 10, 64)
-				require.NoError(t, err, "Ratelimit-Reset should be valid Unix timestamp")
+				reset, err := strconv.ParseInt(resetHeader, 10, 64)
+				require.NoError(t, err, "Ratelimit-Reset should be valid delta seconds")
 
-				// Reset time should be ~60 seconds from now (within window duration)
-				expectedReset := beforeTest.Add(windowDuration).Unix()
-				require.InDelta(t, expectedReset, resetTime, 5, "reset time should be ~60 seconds from now")
+				// Reset should be ~60 seconds from now (within window duration)
+				require.InDelta(t, windowDuration.Seconds()-time.Since(beforeTest).Seconds(), reset, 5, "reset should be ~60 seconds from now")
 				return
 			}
 		}
@@ -163,15 +164,15 @@ func TestRateLimiter_Middleware(t *testing.T) {
 			t.Skip("Redis not available:", err)
 		}
 
-		testKey1 := "ratelimit:192.0.2.4"
-		testKey2 := "ratelimit:192.0.2.5"
+		testKey1 := "ratelimit:ttv:192.0.2.4"
+		testKey2 := "ratelimit:ttv:192.0.2.5"
 		client.Del(ctx, testKey1, testKey2)
 		defer client.Del(ctx, testKey1, testKey2)
 
 		logger := zerolog.Nop()
-		limiter := NewRateLimiter(client, logger)
+		limiter := NewRateLimiter(NewRedisRateLimitBackend(client), nil, logger)
 
-		handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler := limiter.Middleware(ttvRateLimit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 		}))
 
@@ -196,14 +197,14 @@ func TestRateLimiter_Middleware(t *testing.T) {
 		}
 	})
 
-	t.Run("middleware fails open when Redis unavailable", func(t *testing.T) {
+	t.Run("middleware passes through when disabled", func(t *testing.T) {
 		t.Parallel()
 
-		// Pass nil client (simulates disabled rate limiting)
+		// Pass nil backend (disabled rate limiting)
 		logger := zerolog.Nop()
-		limiter := NewRateLimiter(nil, logger)
+		limiter := NewRateLimiter(nil, nil, logger)
 
-		handler := limiter.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler := limiter.Middleware(ttvRateLimit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 		}))
 
@@ -214,11 +215,142 @@ func TestRateLimiter_Middleware(t *testing.T) {
 			w := httptest.NewRecorder()
 
 			handler.ServeHTTP(w, req)
-			require.Equal(t, http.StatusOK, w.Code, "request %d should succeed (fail-open)", i)
+			require.Equal(t, http.StatusOK, w.Code, "request %d should succeed (disabled)", i)
 		}
 	})
 }
 
+func TestRateLimiter_MemoryBackend(t *testing.T) {
+	t.Parallel()
+
+	policy := RateLimitPolicy{Name: "test", Limit: 5, Window: time.Minute}
+
+	t.Run("limits and sets headers", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := NewRateLimiter(NewMemoryRateLimitBackend(), nil, zerolog.Nop())
+		handler := limiter.Middleware(policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		for i := range policy.Limit {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = "192.0.2.10:12345"
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code, "request %d should succeed", i)
+			require.Equal(t, "5", w.Header().Get("RateLimit-Limit"))
+			require.Equal(t, strconv.Itoa(policy.Limit-i-1), w.Header().Get("RateLimit-Remaining"))
+			require.Equal(t, "5;w=60", w.Header().Get("RateLimit-Policy"))
+		}
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = "192.0.2.10:12345"
+		w := httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusTooManyRequests, w.Code)
+		require.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+		require.NotEmpty(t, w.Header().Get("Retry-After"))
+
+		reset, err := strconv.ParseInt(w.Header().Get("Ratelimit-Reset"), 10, 64)
+		require.NoError(t, err, "Ratelimit-Reset should be valid delta seconds")
+		require.Positive(t, reset)
+		require.LessOrEqual(t, reset, int64(policy.Window.Seconds()))
+		require.Equal(t, w.Header().Get("Retry-After"), w.Header().Get("Ratelimit-Reset"))
+
+		// other policies and IPs are counted independently
+		req = httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.RemoteAddr = "192.0.2.11:12345"
+		w = httptest.NewRecorder()
+
+		handler.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("sliding window", func(t *testing.T) {
+		t.Parallel()
+
+		now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		backend := NewMemoryRateLimitBackend()
+		backend.now = func() time.Time { return now }
+
+		for range policy.Limit {
+			result, err := backend.Allow(t.Context(), "key", policy)
+			require.NoError(t, err)
+			require.True(t, result.Allowed)
+		}
+
+		result, err := backend.Allow(t.Context(), "key", policy)
+		require.NoError(t, err)
+		require.False(t, result.Allowed)
+
+		// the next window still counts most of the previous requests
+		now = now.Add(time.Minute + 10*time.Second)
+		result, err = backend.Allow(t.Context(), "key", policy)
+		require.NoError(t, err)
+		require.False(t, result.Allowed)
+		require.InDelta(t, 2*time.Second, result.Reset, float64(time.Millisecond))
+
+		now = now.Add(2 * time.Second)
+		result, err = backend.Allow(t.Context(), "key", policy)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+
+		// idle clients start over
+		now = now.Add(3 * time.Minute)
+		result, err = backend.Allow(t.Context(), "key", policy)
+		require.NoError(t, err)
+		require.True(t, result.Allowed)
+		require.Equal(t, policy.Limit-1, result.Remaining)
+	})
+
+	t.Run("falls back to memory when the backend fails", func(t *testing.T) {
+		t.Parallel()
+
+		limiter := NewRateLimiter(failingRateLimitBackend{}, nil, zerolog.Nop())
+		handler := limiter.Middleware(policy)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		codes := map[int]int{}
+		for range policy.Limit + 1 {
+			req := httptest.NewRequest(http.MethodGet, "/test", nil)
+			req.RemoteAddr = "192.0.2.12:12345"
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+			codes[w.Code]++
+		}
+
+		require.Equal(t, map[int]int{http.StatusOK: policy.Limit, http.StatusTooManyRequests: 1}, codes)
+	})
+}
+
+type failingRateLimitBackend struct{}
+
+func (failingRateLimitBackend) Allow(context.Context, string, RateLimitPolicy) (RateLimitResult, error) {
+	return RateLimitResult{}, errors.New("redis unavailable")
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	t.Parallel()
+
+	prefixes, err := ParseTrustedProxies([]string{"10.0.0.0/8", " 192.0.2.1 ", "", "2001:db8::/32"})
+	require.NoError(t, err)
+	require.Equal(t, []netip.Prefix{
+		netip.MustParsePrefix("10.0.0.0/8"),
+		netip.MustParsePrefix("192.0.2.1/32"),
+		netip.MustParsePrefix("2001:db8::/32"),
+	}, prefixes)
+
+	_, err = ParseTrustedProxies([]string{"not-an-ip"})
+	require.Error(t, err)
+}
+
 func TestExtractClientIP(t *testing.T) {
 	t.Run("extracts IP from X-Forwarded-For", func(t *testing.T) {
 		t.Parallel()
@@ -227,7 +359,7 @@ func TestExtractClientIP(t *testing.T) {
 		req.Header.Set("X-Forwarded-For", "203.0.113.1")
 		req.RemoteAddr = "192.0.2.1:12345"
 
-		ip := extractClientIP(req)
+		ip := extractClientIP(req, []netip.Prefix{netip.MustParsePrefix("192.0.2.0/24")})
 		require.Equal(t, "203.0.113.1", ip, "should use X-Forwarded-For")
 	})
 
@@ -238,17 +370,44 @@ func TestExtractClientIP(t *testing.T) {
 		req.Header.Set("X-Forwarded-For", "203.0.113.1, 198.51.100.2, 192.0.2.1")
 		req.RemoteAddr = "10.0.0.1:12345"
 
-		ip := extractClientIP(req)
+		ip := extractClientIP(req, []netip.Prefix{netip.MustParsePrefix("0.0.0.0/0")})
 		require.Equal(t, "203.0.113.1", ip, "should extract first IP from comma-separated list")
 	})
 
+	t.Run("skips trusted proxies from the right", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("X-Forwarded-For", "203.0.113.1, 198.51.100.2, 192.0.2.1")
+		req.RemoteAddr = "10.0.0.1:12345"
+
+		trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("192.0.2.0/24")}
+
+		ip := extractClientIP(req, trusted)
+		require.Equal(t, "198.51.100.2", ip, "a spoofed leftmost entry must not be used")
+	})
+
+	t.Run("ignores X-Forwarded-For from untrusted connections", func(t *testing.T) {
+		t.Parallel()
+
+		req := httptest.NewRequest(http.MethodGet, "/test", nil)
+		req.Header.Set("X-Forwarded-For", "203.0.113.1")
+		req.RemoteAddr = "198.51.100.7:12345"
+
+		ip := extractClientIP(req, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")})
+		require.Equal(t, "198.51.100.7", ip)
+
+		ip = extractClientIP(req, nil)
+		require.Equal(t, "198.51.100.7", ip)
+	})
+
 	t.Run("extracts IP from RemoteAddr when no X-Forwarded-For", func(t *testing.T) {
 		t.Parallel()
 
 		req := httptest.NewRequest(http.MethodGet, "/test", nil)
 		req.RemoteAddr = "192.0.2.1:12345"
 
-		ip := extractClientIP(req)
+		ip := extractClientIP(req, nil)
 		require.Equal(t, "192.0.2.1", ip, "should extract IP from RemoteAddr")
 	})
 
@@ -258,7 +417,7 @@ func TestExtractClientIP(t *testing.T) {
 		req := httptest.NewRequest(http.MethodGet, "/test", nil)
 		req.RemoteAddr = "192.0.2.1"
 
-		ip := extractClientIP(req)
+		ip := extractClientIP(req, nil)
 		require.Equal(t, "192.0.2.1", ip, "should handle addr without port")
 	})
 }
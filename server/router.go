@@ -1,6 +1,9 @@
 package server
 
 import (
+	"crypto/subtle"
+	"net/http"
+
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/rs/zerolog"
@@ -14,21 +17,35 @@ func router(logger zerolog.Logger, api *API) *chi.Mux {
 		requestLogger(logger),
 		middleware.RequestSize(5*1024),
 		middleware.Recoverer,
+		api.metrics.Middleware,
+		corsMiddleware(api.conf.CORS.AllowedOrigins),
 	)
 
+	// Rate limiting is disabled if the backend is nil
+	limiter := NewRateLimiter(api.rateLimitBackend(), api.conf.TrustedProxies, logger)
+	limiter.metrics = api.metrics
+
 	c.Get("/install", api.handleInstallScript())
 	c.Get("/version", api.handleGetVersion())
 
-	c.Route("/internal", func(r chi.Router) {
-		r.Get("/health", api.handleGetHealth())
-		r.Get("/ready", api.handleGetHealth())
-	})
+	c.Get("/internal/health", api.handleGetHealth())
+
+	// without a separate internal address, the internal endpoints are only public if they are protected by a token
+	if api.conf.InternalAddr == "" && api.conf.InternalToken != "" {
+		c.Group(func(r chi.Router) {
+			r.Use(bearerTokenMiddleware(api.conf.InternalToken))
+			api.internalRoutes(r)
+		})
+	}
 
 	c.Route("/proxy", func(r chi.Router) {
+		r.Use(limiter.Middleware(api.rateLimitPolicy(linkCheckRateLimit)))
 		r.Get("/link_check", api.handleCheckRedirectsRequest())
+		r.Get("/link_preview", api.handleLinkPreview())
 	})
 
 	c.Route("/auth", func(r chi.Router) {
+		r.Use(limiter.Middleware(api.rateLimitPolicy(authRateLimit)))
 		r.Get("/start", api.handleAuthStart())
 		r.Get("/redirect", api.handleAuthRedirect())
 		r.Post("/revoke", api.handleAuthRevoke())
@@ -37,10 +54,8 @@ func router(logger zerolog.Logger, api *API) *chi.Mux {
 
 	// New helix-aligned proxy routes (rate limited, allowlist validated, proxied to Twitch)
 	c.Route("/ttv", func(r chi.Router) {
-		// Apply rate limiting (disabled if redisClient is nil)
-		limiter := NewRateLimiter(api.redisClient, logger)
-		r.Use(limiter.Middleware)
-		r.Use(HelixAllowlistMiddleware)
+		r.Use(limiter.Middleware(api.rateLimitPolicy(ttvRateLimit)))
+		r.Use(NewHelixAllowlistMiddleware(api.conf.HelixAllowedPaths))
 		r.Get("/*", api.handleHelixProxy())
 	})
 
@@ -50,3 +65,52 @@ func router(logger zerolog.Logger, api *API) *chi.Mux {
 
 	return c
 }
+
+// internalRouter serves the internal endpoints on the internal address.
+func internalRouter(logger zerolog.Logger, api *API) *chi.Mux {
+	c := chi.NewMux()
+
+	c.Use(
+		middleware.RequestID,
+		requestLogger(logger),
+		middleware.Recoverer,
+	)
+
+	c.Get("/internal/health", api.handleGetHealth())
+
+	c.Group(func(r chi.Router) {
+		if api.conf.InternalToken != "" {
+			r.Use(bearerTokenMiddleware(api.conf.InternalToken))
+		}
+
+		api.internalRoutes(r)
+	})
+
+	return c
+}
+
+func (a *API) internalRoutes(r chi.Router) {
+	if a.metrics != nil {
+		r.Method("GET", "/metrics", a.metrics.Handler())
+	}
+
+	r.Get("/internal/ready", a.handleGetReady())
+	r.Get("/internal/cache", a.handleGetCacheStats())
+}
+
+// bearerTokenMiddleware rejects requests without the token in the Authorization header.
+func bearerTokenMiddleware(token string) func(http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	"github.com/julez-dev/chatuino/server"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+func TestServerConfig(t *testing.T) {
+	t.Parallel()
+
+	file := server.FileConfig{
+		Addr:    ":9000",
+		Clients: []server.TwitchClient{{ID: "file-main", Secret: "a"}, {ID: "file-second", Secret: "b"}},
+		Redis:   server.RedisConfig{Addr: "redis:6379"},
+		Helix:   server.HelixFileConfig{Cache: server.HelixCacheRedis},
+	}
+
+	run := func(t *testing.T, args ...string) server.Config {
+		t.Helper()
+
+		var conf server.Config
+		cmd := &cli.Command{
+			Name:  "server",
+			Flags: serverCMD.Flags,
+			Action: func(ctx context.Context, command *cli.Command) (err error) {
+				conf, err = serverConfig(command, file)
+				return err
+			},
+		}
+
+		require.NoError(t, cmd.Run(context.Background(), append([]string{"server"}, args...)))
+		return conf
+	}
+
+	// the subtests share the flags of serverCMD and can't run in parallel
+	t.Run("file values replace defaults", func(t *testing.T) {
+		conf := run(t)
+		require.Equal(t, ":9000", conf.HostAndPort)
+		require.Equal(t, "file-main", conf.ClientID)
+		require.Equal(t, []server.TwitchClient{{ID: "file-second", Secret: "b"}}, conf.Clients)
+		require.Equal(t, "redis:6379", conf.Redis.Addr)
+		require.Equal(t, server.HelixCacheRedis, conf.HelixCache)
+		require.Equal(t, "https://chatuino.net/auth/redirect", conf.RedirectURL)
+		require.True(t, conf.EnableProxyRateLimit)
+	})
+
+	t.Run("flags win", func(t *testing.T) {
+		conf := run(t, "--addr", ":7000", "--client-id", "file-second", "--client-secret", "flag", "--helix-cache", "off")
+		require.Equal(t, ":7000", conf.HostAndPort)
+		require.Equal(t, "file-second", conf.ClientID)
+		require.Equal(t, "flag", conf.ClientSecret)
+		require.Equal(t, []server.TwitchClient{{ID: "file-main", Secret: "a"}}, conf.Clients)
+		require.Equal(t, server.HelixCacheDisabled, conf.HelixCache)
+	})
+}
@@ -0,0 +1,359 @@
+// Package spellcheck checks words against a Hunspell dictionary.
+//
+// A dictionary consists of a .dic file listing the words and an optional .aff file with the same name
+// describing the prefixes and suffixes words can take. Only UTF-8 dictionaries are supported. Compound
+// words and suggestions are not supported, the dictionary is expanded to all word forms when loading.
+package spellcheck
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/afero"
+)
+
+// Dictionary is the set of correctly spelled words.
+type Dictionary struct {
+	words map[string]struct{}
+}
+
+type flagMode int
+
+const (
+	flagShort flagMode = iota // each character is a flag
+	flagLong                  // each pair of characters is a flag
+	flagNum                   // flags are comma separated numbers
+)
+
+type affixRule struct {
+	strip     string
+	add       string
+	condition *regexp.Regexp
+}
+
+type affixGroup struct {
+	prefix bool
+	cross  bool // may be combined with affixes of the other kind
+	rules  []affixRule
+}
+
+type affixes struct {
+	mode      flagMode
+	groups    map[string]*affixGroup
+	forbidden string // flag of words which are misspelled
+	needAffix string // flag of words which are only valid with an affix
+	compound  string // flag of words which are only valid inside compounds
+}
+
+// Load reads the dictionary from the .dic file at dicPath and its .aff file, when present.
+func Load(fsys afero.Fs, dicPath string) (*Dictionary, error) {
+	aff := &affixes{groups: map[string]*affixGroup{}}
+
+	affPath := strings.TrimSuffix(dicPath, ".dic") + ".aff"
+	if f, err := fsys.Open(affPath); err == nil {
+		err := aff.parse(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse affix file %s: %w", affPath, err)
+		}
+	}
+
+	f, err := fsys.Open(dicPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	d := &Dictionary{words: map[string]struct{}{}}
+	if err := d.parse(f, aff); err != nil {
+		return nil, fmt.Errorf("failed to parse dictionary %s: %w", dicPath, err)
+	}
+
+	return d, nil
+}
+
+// New returns a dictionary containing exactly the given words.
+func New(words ...string) *Dictionary {
+	d := &Dictionary{words: make(map[string]struct{}, len(words))}
+	for _, w := range words {
+		d.words[w] = struct{}{}
+	}
+
+	return d
+}
+
+// Len returns the number of word forms in the dictionary.
+func (d *Dictionary) Len() int {
+	return len(d.words)
+}
+
+// Correct reports whether the word is spelled correctly. Capitalized and upper case spellings of dictionary
+// words are accepted, as are lower case spellings of names, since chat is rarely capitalized properly.
+func (d *Dictionary) Correct(word string) bool {
+	word = strings.ReplaceAll(word, "’", "'")
+
+	if d.has(word) {
+		return true
+	}
+
+	lower := strings.ToLower(word)
+	if d.has(lower) {
+		return true
+	}
+
+	r, size := utf8.DecodeRuneInString(lower)
+	return d.has(string(unicode.ToUpper(r)) + lower[size:])
+}
+
+func (d *Dictionary) has(word string) bool {
+	_, ok := d.words[word]
+	return ok
+}
+
+func (d *Dictionary) parse(r io.Reader, aff *affixes) error {
+	scanner := bufio.NewScanner(r)
+	first := true
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// the first line is the approximate number of words
+		if first {
+			first = false
+			if _, err := strconv.Atoi(line); err == nil {
+				continue
+			}
+		}
+
+		// morphological fields follow the word after whitespace
+		entry := strings.Fields(line)[0]
+		word, flagStr, _ := strings.Cut(entry, "/")
+		flags := aff.parseFlags(flagStr)
+
+		if aff.forbidden != "" && flags[aff.forbidden] {
+			continue
+		}
+
+		if !(aff.needAffix != "" && flags[aff.needAffix]) && !(aff.compound != "" && flags[aff.compound]) {
+			d.words[word] = struct{}{}
+		}
+
+		d.expand(word, flags, aff)
+	}
+
+	return scanner.Err()
+}
+
+// expand adds all word forms created by the affixes of the word.
+func (d *Dictionary) expand(word string, flags map[string]bool, aff *affixes) {
+	var (
+		prefixes []*affixGroup
+		suffixes []*affixGroup
+	)
+
+	for flag := range flags {
+		group, ok := aff.groups[flag]
+		if !ok {
+			continue
+		}
+
+		if group.prefix {
+			prefixes = append(prefixes, group)
+		} else {
+			suffixes = append(suffixes, group)
+		}
+	}
+
+	for _, sfx := range suffixes {
+		for _, rule := range sfx.rules {
+			suffixed, ok := rule.applySuffix(word)
+			if !ok {
+				continue
+			}
+
+			d.words[suffixed] = struct{}{}
+
+			for _, pfx := range prefixes {
+				if !pfx.cross || !sfx.cross {
+					continue
+				}
+
+				for _, prule := range pfx.rules {
+					if both, ok := prule.applyPrefix(suffixed); ok {
+						d.words[both] = struct{}{}
+					}
+				}
+			}
+		}
+	}
+
+	for _, pfx := range prefixes {
+		for _, rule := range pfx.rules {
+			if prefixed, ok := rule.applyPrefix(word); ok {
+				d.words[prefixed] = struct{}{}
+			}
+		}
+	}
+}
+
+func (r affixRule) applySuffix(word string) (string, bool) {
+	if !r.condition.MatchString(word) || !strings.HasSuffix(word, r.strip) {
+		return "", false
+	}
+
+	return strings.TrimSuffix(word, r.strip) + r.add, true
+}
+
+func (r affixRule) applyPrefix(word string) (string, bool) {
+	if !r.condition.MatchString(word) || !strings.HasPrefix(word, r.strip) {
+		return "", false
+	}
+
+	return r.add + strings.TrimPrefix(word, r.strip), true
+}
+
+func (a *affixes) parse(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		switch fields[0] {
+		case "SET":
+			if !strings.EqualFold(fields[1], "UTF-8") {
+				return fmt.Errorf("unsupported encoding %s, only UTF-8 dictionaries are supported", fields[1])
+			}
+		case "FLAG":
+			switch fields[1] {
+			case "long":
+				a.mode = flagLong
+			case "num":
+				a.mode = flagNum
+			default: // UTF-8 behaves like short flags on decoded runes
+				a.mode = flagShort
+			}
+		case "FORBIDDENWORD":
+			a.forbidden = fields[1]
+		case "NEEDAFFIX":
+			a.needAffix = fields[1]
+		case "ONLYINCOMPOUND":
+			a.compound = fields[1]
+		case "PFX", "SFX":
+			if err := a.parseAffix(fields); err != nil {
+				return err
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+func (a *affixes) parseAffix(fields []string) error {
+	flag := fields[1]
+	group, ok := a.groups[flag]
+
+	// header: SFX flag cross count
+	if !ok {
+		if len(fields) < 4 {
+			return fmt.Errorf("invalid affix header %q", strings.Join(fields, " "))
+		}
+
+		a.groups[flag] = &affixGroup{prefix: fields[0] == "PFX", cross: fields[2] == "Y"}
+		return nil
+	}
+
+	// rule: SFX flag strip add [condition]
+	if len(fields) < 4 {
+		return fmt.Errorf("invalid affix rule %q", strings.Join(fields, " "))
+	}
+
+	strip := fields[2]
+	if strip == "0" {
+		strip = ""
+	}
+
+	add, _, _ := strings.Cut(fields[3], "/") // continuation classes are not supported
+	if add == "0" {
+		add = ""
+	}
+
+	condition := "."
+	if len(fields) > 4 {
+		condition = fields[4]
+	}
+
+	re, err := compileCondition(condition, group.prefix)
+	if err != nil {
+		return fmt.Errorf("invalid affix condition %q: %w", condition, err)
+	}
+
+	group.rules = append(group.rules, affixRule{strip: strip, add: add, condition: re})
+	return nil
+}
+
+// compileCondition converts a Hunspell affix condition, which supports characters, . and [] groups,
+// to a regex matching the start of the word for prefixes or the end for suffixes.
+func compileCondition(condition string, prefix bool) (*regexp.Regexp, error) {
+	var b strings.Builder
+	inGroup := false
+
+	for _, r := range condition {
+		switch {
+		case r == '[':
+			inGroup = true
+			b.WriteRune(r)
+		case r == ']':
+			inGroup = false
+			b.WriteRune(r)
+		case inGroup && r == '^':
+			b.WriteRune(r)
+		case r == '.' && !inGroup:
+			b.WriteRune(r)
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+
+	if prefix {
+		return regexp.Compile("^(?:" + b.String() + ")")
+	}
+
+	return regexp.Compile("(?:" + b.String() + ")$")
+}
+
+func (a *affixes) parseFlags(s string) map[string]bool {
+	flags := map[string]bool{}
+	if s == "" {
+		return flags
+	}
+
+	switch a.mode {
+	case flagLong:
+		runes := []rune(s)
+		for i := 0; i+1 < len(runes); i += 2 {
+			flags[string(runes[i:i+2])] = true
+		}
+	case flagNum:
+		for f := range strings.SplitSeq(s, ",") {
+			flags[strings.TrimSpace(f)] = true
+		}
+	default:
+		for _, r := range s {
+			flags[string(r)] = true
+		}
+	}
+
+	return flags
+}
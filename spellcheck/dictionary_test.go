@@ -0,0 +1,80 @@
+package spellcheck
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/require"
+)
+
+const testAff = `SET UTF-8
+FLAG short
+NEEDAFFIX X
+
+PFX U Y 1
+PFX U 0 un .
+
+SFX S Y 3
+SFX S y ies [^aeiou]y
+SFX S 0 s [aeiou]y
+SFX S 0 s [^y]
+
+SFX D N 1
+SFX D 0 ed .
+`
+
+const testDic = `6
+happy/U
+fly/S
+play/SD
+Paris
+stream/SUD
+walk/X
+`
+
+func TestLoad(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "dict/en.aff", []byte(testAff), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "dict/en.dic", []byte(testDic), 0o644))
+
+	d, err := Load(fs, "dict/en.dic")
+	require.NoError(t, err)
+
+	for _, word := range []string{"happy", "unhappy", "fly", "flies", "plays", "played", "streams", "unstreams", "Paris", "paris", "PARIS", "Happy"} {
+		require.True(t, d.Correct(word), word)
+	}
+
+	// flys does not match the condition, D can't be combined with prefixes and walk needs an affix
+	for _, word := range []string{"flys", "plaies", "unstreamed", "walk", "hapy"} {
+		require.False(t, d.Correct(word), word)
+	}
+}
+
+func TestLoad_WithoutAffixes(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "words.dic", []byte("2\nhello/AB\nworld po:noun\n"), 0o644))
+
+	d, err := Load(fs, "words.dic")
+	require.NoError(t, err)
+	require.Equal(t, 2, d.Len())
+	require.True(t, d.Correct("hello"))
+	require.True(t, d.Correct("world"))
+
+	_, err = Load(fs, "missing.dic")
+	require.Error(t, err)
+}
+
+func TestLoad_UnsupportedEncoding(t *testing.T) {
+	t.Parallel()
+
+	fs := afero.NewMemMapFs()
+	require.NoError(t, afero.WriteFile(fs, "de.aff", []byte("SET ISO8859-1\n"), 0o644))
+	require.NoError(t, afero.WriteFile(fs, "de.dic", []byte("1\nhallo\n"), 0o644))
+
+	_, err := Load(fs, "de.dic")
+	require.ErrorContains(t, err, "only UTF-8")
+}
@@ -6,11 +6,7 @@ import (
 	"errors"
 )
 
-var errUnsupported = errors.New("image support not available for this platform")
-
-func hasImageSupport() bool {
-	return false
-}
+var errUnsupported = errors.New("terminal cell size not available for this platform")
 
 func getTermCellWidthHeight() (float32, float32, error) {
 	return 0, 0, errUnsupported
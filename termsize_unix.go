@@ -8,13 +8,6 @@ import (
 	"golang.org/x/sys/unix"
 )
 
-func hasImageSupport() bool {
-	_, isKitty := os.LookupEnv("KITTY_WINDOW_ID") // always defined by kitty
-	term := os.Getenv("TERM")
-
-	return isKitty || term == "xterm-ghostty"
-}
-
 func getTermCellWidthHeight() (float32, float32, error) {
 	f, err := os.OpenFile("/dev/tty", unix.O_NOCTTY|unix.O_CLOEXEC|unix.O_NDELAY|unix.O_RDWR, 0666)
 	if err != nil {
@@ -334,6 +334,65 @@ func (a *API) FetchUserFollowedChannels(ctx context.Context, userID string, broa
 	return channels, nil
 }
 
+func (a *API) FetchUserBlockList(ctx context.Context, userID string) ([]BlockedUser, error) {
+	users := []BlockedUser{}
+	var after string
+
+	for {
+		values := url.Values{}
+		values.Add("broadcaster_id", userID)
+		values.Add("first", "100")
+		if after != "" {
+			values.Add("after", after)
+		}
+
+		url := fmt.Sprintf("/users/blocks?%s", values.Encode())
+
+		resp, err := doAuthenticatedUserRequest[GetUserBlockListResponse](ctx, a, http.MethodGet, url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, resp.Data...)
+
+		if resp.Pagination.Cursor == "" {
+			break
+		}
+
+		after = resp.Pagination.Cursor
+	}
+
+	return users, nil
+}
+
+func (a *API) BlockUser(ctx context.Context, targetUserID string) error {
+	values := url.Values{}
+	values.Add("target_user_id", targetUserID)
+
+	url := fmt.Sprintf("/users/blocks?%s", values.Encode())
+
+	_, err := doAuthenticatedUserRequest[any](ctx, a, http.MethodPut, url, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *API) UnblockUser(ctx context.Context, targetUserID string) error {
+	values := url.Values{}
+	values.Add("target_user_id", targetUserID)
+
+	url := fmt.Sprintf("/users/blocks?%s", values.Encode())
+
+	_, err := doAuthenticatedUserRequest[any](ctx, a, http.MethodDelete, url, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (a *API) FetchUnbanRequests(ctx context.Context, broadcasterID, moderatorID string) ([]UnbanRequest, error) {
 	// Fetch all unban requests for the broadcaster
 	// For all statuses, handle each status in a separate goroutine
@@ -708,20 +767,30 @@ const validateURL = "https://id.twitch.tv/oauth2/validate"
 // ValidateToken checks if an access token is still valid by calling Twitch's validate endpoint.
 // Returns true if valid, false if invalid/expired.
 func ValidateToken(ctx context.Context, httpClient *http.Client, accessToken string) (bool, error) {
+	validation, err := ValidateTokenScopes(ctx, httpClient, accessToken)
+	if err != nil {
+		return false, err
+	}
+
+	return validation.Valid, nil
+}
+
+// ValidateTokenScopes calls Twitch's validate endpoint and returns the scopes granted to a valid access token.
+func ValidateTokenScopes(ctx context.Context, httpClient *http.Client, accessToken string) (TokenValidation, error) {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, validateURL, nil)
 	if err != nil {
-		return false, err
+		return TokenValidation{}, err
 	}
 
 	req.Header.Add("Authorization", "OAuth "+accessToken)
 
 	resp, err := httpClient.Do(req)
 	if err != nil {
-		return false, err
+		return TokenValidation{}, err
 	}
 	defer func() {
 		io.Copy(io.Discard, resp.Body)
@@ -729,5 +798,15 @@ func ValidateToken(ctx context.Context, httpClient *http.Client, accessToken str
 	}()
 
 	// 200 = valid token, 401 = invalid/expired token
-	return resp.StatusCode == http.StatusOK, nil
+	if resp.StatusCode != http.StatusOK {
+		return TokenValidation{}, nil
+	}
+
+	var validation TokenValidation
+	if err := json.NewDecoder(resp.Body).Decode(&validation); err != nil {
+		return TokenValidation{}, err
+	}
+
+	validation.Valid = true
+	return validation, nil
 }
@@ -208,6 +208,22 @@ type (
 	}
 )
 
+// https://dev.twitch.tv/docs/api/reference/#get-user-block-list
+type (
+	//easyjson:json
+	GetUserBlockListResponse struct {
+		Data       []BlockedUser `json:"data"`
+		Pagination Pagination    `json:"pagination"`
+	}
+
+	//easyjson:json
+	BlockedUser struct {
+		UserID      string `json:"user_id"`
+		UserLogin   string `json:"user_login"`
+		DisplayName string `json:"display_name"`
+	}
+)
+
 // https://dev.twitch.tv/docs/api/reference/#get-eventsub-subscriptions
 type (
 	//easyjson:json
@@ -382,3 +398,16 @@ type (
 		ClickURL     string `json:"click_url"`
 	}
 )
+
+// https://dev.twitch.tv/docs/authentication/validate-tokens/
+type (
+	//easyjson:json
+	TokenValidation struct {
+		Valid     bool     `json:"-"`
+		ClientID  string   `json:"client_id"`
+		Login     string   `json:"login"`
+		UserID    string   `json:"user_id"`
+		Scopes    []string `json:"scopes"`
+		ExpiresIn int      `json:"expires_in"`
+	}
+)
@@ -0,0 +1,190 @@
+// Package twitchauth implements the logins to create user access tokens without pasting them by hand.
+//
+// The device code flow talks to Twitch directly and only needs the client ID. The loopback flow lets the
+// Chatuino server exchange the authorization code and hands the tokens to a local HTTP listener.
+package twitchauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const defaultBaseURL = "https://id.twitch.tv/oauth2"
+
+var (
+	ErrExpired = errors.New("the login code expired before it was authorized")
+	ErrDenied  = errors.New("the login was not authorized")
+)
+
+// Token is a user access token with its refresh token.
+type Token struct {
+	AccessToken  string   `json:"access_token"`
+	RefreshToken string   `json:"refresh_token"`
+	Scopes       []string `json:"scope"`
+}
+
+// DeviceAuthorization is a pending device code login. The user authorizes it by entering UserCode at VerificationURI.
+type DeviceAuthorization struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"` // seconds
+	Interval        int    `json:"interval"`   // seconds between polls
+
+	scopes    []string
+	expiresAt time.Time
+}
+
+type errorResponse struct {
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+func (e errorResponse) Error() string {
+	return fmt.Sprintf("twitch returned %d: %s", e.Status, e.Message)
+}
+
+type Client struct {
+	httpClient *http.Client
+	clientID   string
+	baseURL    string // configurable for testing
+
+	pollInterval time.Duration // overrides the interval sent by Twitch, for testing
+}
+
+func NewClient(clientID string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	return &Client{
+		httpClient: httpClient,
+		clientID:   clientID,
+		baseURL:    defaultBaseURL,
+	}
+}
+
+// StartDeviceAuthorization starts a device code login requesting the scopes.
+func (c *Client) StartDeviceAuthorization(ctx context.Context, scopes []string) (DeviceAuthorization, error) {
+	form := url.Values{}
+	form.Set("client_id", c.clientID)
+	form.Set("scopes", strings.Join(scopes, " "))
+
+	var auth DeviceAuthorization
+	if err := c.post(ctx, "/device", form, &auth); err != nil {
+		return DeviceAuthorization{}, err
+	}
+
+	auth.scopes = scopes
+	auth.expiresAt = time.Now().Add(time.Duration(auth.ExpiresIn) * time.Second)
+
+	return auth, nil
+}
+
+// WaitForDeviceToken polls Twitch until the user authorized the device code login, it expired or ctx is done.
+func (c *Client) WaitForDeviceToken(ctx context.Context, auth DeviceAuthorization) (Token, error) {
+	interval := time.Duration(max(auth.Interval, 1)) * time.Second
+	if c.pollInterval > 0 {
+		interval = c.pollInterval
+	}
+
+	form := url.Values{}
+	form.Set("client_id", c.clientID)
+	form.Set("scopes", strings.Join(auth.scopes, " "))
+	form.Set("device_code", auth.DeviceCode)
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+
+	for {
+		select {
+		case <-ctx.Done():
+			return Token{}, ctx.Err()
+		case <-time.After(interval):
+		}
+
+		if !auth.expiresAt.IsZero() && time.Now().After(auth.expiresAt) {
+			return Token{}, ErrExpired
+		}
+
+		var token Token
+		err := c.post(ctx, "/token", form, &token)
+
+		var respErr errorResponse
+		if !errors.As(err, &respErr) {
+			return token, err
+		}
+
+		switch respErr.Message {
+		case "authorization_pending":
+		case "slow_down":
+			interval += c.slowDown()
+		case "invalid device code", "expired_token":
+			return Token{}, ErrExpired
+		case "access_denied":
+			return Token{}, ErrDenied
+		default:
+			return Token{}, err
+		}
+	}
+}
+
+// RefreshToken refreshes a user access token directly with Twitch. Twitch only allows this without the client secret
+// for tokens of public clients, like the ones created by the device code flow.
+func (c *Client) RefreshToken(ctx context.Context, refreshToken string) (string, string, error) {
+	form := url.Values{}
+	form.Set("client_id", c.clientID)
+	form.Set("grant_type", "refresh_token")
+	form.Set("refresh_token", refreshToken)
+
+	var token Token
+	if err := c.post(ctx, "/token", form, &token); err != nil {
+		return "", "", err
+	}
+
+	return token.AccessToken, token.RefreshToken, nil
+}
+
+func (c *Client) slowDown() time.Duration {
+	if c.pollInterval > 0 {
+		return c.pollInterval
+	}
+
+	return 5 * time.Second
+}
+
+func (c *Client) post(ctx context.Context, path string, form url.Values, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		respErr := errorResponse{Status: resp.StatusCode}
+		if err := json.Unmarshal(body, &respErr); err != nil || respErr.Message == "" {
+			respErr.Message = strings.TrimSpace(string(body))
+		}
+
+		return respErr
+	}
+
+	return json.Unmarshal(body, v)
+}
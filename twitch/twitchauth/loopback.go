@@ -0,0 +1,140 @@
+package twitchauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Loopback is a local HTTP listener receiving the tokens of a browser login through the Chatuino server.
+//
+// The server's /auth/start endpoint remembers the loopback port and state, after Twitch redirected back to
+// the server and the code was exchanged, the browser posts the tokens to http://127.0.0.1:<port>/callback.
+// The state protects against tokens injected by other websites.
+type Loopback struct {
+	listener net.Listener
+	server   *http.Server
+	state    string
+	result   chan loopbackResult
+}
+
+type loopbackResult struct {
+	token Token
+	err   error
+}
+
+// ListenLoopback starts listening on a random port of 127.0.0.1.
+func ListenLoopback() (*Loopback, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for login callback: %w", err)
+	}
+
+	l := &Loopback{
+		listener: listener,
+		state:    hex.EncodeToString(b),
+		result:   make(chan loopbackResult, 1),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /callback", l.handleCallback)
+
+	l.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go l.server.Serve(listener)
+
+	return l, nil
+}
+
+// AuthURL returns the URL of the Chatuino server at apiHost to open in the browser.
+func (l *Loopback) AuthURL(apiHost string) string {
+	values := url.Values{}
+	values.Set("loopback_port", strconv.Itoa(l.listener.Addr().(*net.TCPAddr).Port))
+	values.Set("loopback_state", l.state)
+
+	return strings.TrimSuffix(apiHost, "/") + "/auth/start?" + values.Encode()
+}
+
+// Wait blocks until the browser was redirected to the listener or ctx is done.
+func (l *Loopback) Wait(ctx context.Context) (Token, error) {
+	select {
+	case <-ctx.Done():
+		return Token{}, ctx.Err()
+	case r := <-l.result:
+		return r.token, r.err
+	}
+}
+
+// Close stops the listener.
+func (l *Loopback) Close() error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	return l.server.Shutdown(ctx)
+}
+
+func (l *Loopback) handleCallback(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, 64*1024)
+	if err := r.ParseForm(); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("Invalid login callback"))
+		return
+	}
+
+	values := r.PostForm
+
+	if subtle.ConstantTimeCompare([]byte(values.Get("state")), []byte(l.state)) != 1 {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("Login state does not match, please start the login from Chatuino again"))
+		return
+	}
+
+	var result loopbackResult
+
+	switch {
+	case values.Get("error") != "":
+		result.err = fmt.Errorf("%w: %s", ErrDenied, values.Get("error"))
+	case values.Get("access_token") == "" || values.Get("refresh_token") == "":
+		result.err = errors.New("the login callback is missing the tokens")
+	default:
+		result.token = Token{
+			AccessToken:  values.Get("access_token"),
+			RefreshToken: values.Get("refresh_token"),
+			Scopes:       strings.Fields(values.Get("scope")),
+		}
+	}
+
+	select {
+	case l.result <- result:
+	default:
+		// a login was already received
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte("Chatuino already received a login, you can close this window"))
+		return
+	}
+
+	if result.err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte("Login failed, check Chatuino for details. You can close this window"))
+		return
+	}
+
+	_, _ = w.Write([]byte("Login successful, you can close this window and return to Chatuino"))
+}
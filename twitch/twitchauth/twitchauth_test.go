@@ -0,0 +1,206 @@
+package twitchauth
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestOAuthServer stands in for id.twitch.tv, the device code is authorized after pending polls.
+func newTestOAuthServer(t *testing.T, pending int, finalError string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var polls atomic.Int32
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /device", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "client-id", r.Form.Get("client_id"))
+		require.Equal(t, "chat:read chat:edit", r.Form.Get("scopes"))
+
+		w.Write([]byte(`{"device_code":"device","user_code":"ABCDEFGH","verification_uri":"https://www.twitch.tv/activate?device-code=ABCDEFGH","expires_in":1800,"interval":5}`))
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "device", r.Form.Get("device_code"))
+		require.Equal(t, "urn:ietf:params:oauth:grant-type:device_code", r.Form.Get("grant_type"))
+
+		if int(polls.Add(1)) <= pending {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":400,"message":"authorization_pending"}`))
+			return
+		}
+
+		if finalError != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":400,"message":"` + finalError + `"}`))
+			return
+		}
+
+		w.Write([]byte(`{"access_token":"access","refresh_token":"refresh","scope":["chat:read","chat:edit"],"token_type":"bearer"}`))
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	return srv, &polls
+}
+
+func newTestClient(srv *httptest.Server) *Client {
+	c := NewClient("client-id", srv.Client())
+	c.baseURL = srv.URL
+	c.pollInterval = time.Millisecond
+
+	return c
+}
+
+func TestDeviceCodeFlow(t *testing.T) {
+	t.Parallel()
+
+	t.Run("polls until authorized", func(t *testing.T) {
+		t.Parallel()
+
+		srv, polls := newTestOAuthServer(t, 2, "")
+		c := newTestClient(srv)
+
+		auth, err := c.StartDeviceAuthorization(t.Context(), []string{"chat:read", "chat:edit"})
+		require.NoError(t, err)
+		require.Equal(t, "ABCDEFGH", auth.UserCode)
+		require.Equal(t, 5, auth.Interval)
+
+		token, err := c.WaitForDeviceToken(t.Context(), auth)
+		require.NoError(t, err)
+		require.Equal(t, Token{AccessToken: "access", RefreshToken: "refresh", Scopes: []string{"chat:read", "chat:edit"}}, token)
+		require.EqualValues(t, 3, polls.Load())
+	})
+
+	t.Run("expired device code", func(t *testing.T) {
+		t.Parallel()
+
+		srv, _ := newTestOAuthServer(t, 1, "invalid device code")
+		c := newTestClient(srv)
+
+		auth, err := c.StartDeviceAuthorization(t.Context(), []string{"chat:read", "chat:edit"})
+		require.NoError(t, err)
+
+		_, err = c.WaitForDeviceToken(t.Context(), auth)
+		require.ErrorIs(t, err, ErrExpired)
+	})
+
+	t.Run("stops when cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		srv, _ := newTestOAuthServer(t, 1_000_000, "")
+		c := newTestClient(srv)
+
+		auth, err := c.StartDeviceAuthorization(t.Context(), []string{"chat:read", "chat:edit"})
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err = c.WaitForDeviceToken(ctx, auth)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestLoopback(t *testing.T) {
+	t.Parallel()
+
+	t.Run("receives tokens", func(t *testing.T) {
+		t.Parallel()
+
+		l, err := ListenLoopback()
+		require.NoError(t, err)
+		defer l.Close()
+
+		authURL, err := url.Parse(l.AuthURL("https://chatuino.example/"))
+		require.NoError(t, err)
+		require.Equal(t, "/auth/start", authURL.Path)
+
+		// the Chatuino server has the browser post the tokens to the loopback listener
+		callback := url.Values{}
+		callback.Set("state", authURL.Query().Get("loopback_state"))
+		callback.Set("access_token", "access")
+		callback.Set("refresh_token", "refresh")
+		callback.Set("scope", "chat:read chat:edit")
+
+		resp, err := http.PostForm("http://127.0.0.1:"+authURL.Query().Get("loopback_port")+"/callback", callback)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		token, err := l.Wait(t.Context())
+		require.NoError(t, err)
+		require.Equal(t, Token{AccessToken: "access", RefreshToken: "refresh", Scopes: []string{"chat:read", "chat:edit"}}, token)
+	})
+
+	t.Run("rejects wrong state", func(t *testing.T) {
+		t.Parallel()
+
+		l, err := ListenLoopback()
+		require.NoError(t, err)
+		defer l.Close()
+
+		authURL, err := url.Parse(l.AuthURL("https://chatuino.example"))
+		require.NoError(t, err)
+
+		resp, err := http.PostForm("http://127.0.0.1:"+authURL.Query().Get("loopback_port")+"/callback", url.Values{
+			"state":         {"wrong"},
+			"access_token":  {"a"},
+			"refresh_token": {"b"},
+		})
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		// tokens in the query string are not accepted
+		resp, err = http.Get("http://127.0.0.1:" + authURL.Query().Get("loopback_port") + "/callback?state=" + authURL.Query().Get("loopback_state") + "&access_token=a&refresh_token=b")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+		ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err = l.Wait(ctx)
+		require.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestRefreshToken(t *testing.T) {
+	t.Parallel()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "/token", r.URL.Path)
+		require.Equal(t, "client-id", r.Form.Get("client_id"))
+		require.Equal(t, "refresh_token", r.Form.Get("grant_type"))
+		require.Empty(t, r.Form.Get("client_secret"))
+
+		if r.Form.Get("refresh_token") != "refresh" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":400,"message":"Invalid refresh token"}`))
+			return
+		}
+
+		w.Write([]byte(`{"access_token":"new-access","refresh_token":"new-refresh","scope":["chat:read"],"token_type":"bearer"}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	c := newTestClient(srv)
+
+	access, refresh, err := c.RefreshToken(context.Background(), "refresh")
+	require.NoError(t, err)
+	require.Equal(t, "new-access", access)
+	require.Equal(t, "new-refresh", refresh)
+
+	_, _, err = c.RefreshToken(context.Background(), "revoked")
+	require.ErrorContains(t, err, "Invalid refresh token")
+}
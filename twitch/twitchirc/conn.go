@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
 	"slices"
 	"strings"
@@ -17,15 +18,44 @@ import (
 )
 
 const (
-	DefaultIRCWSURL   = "wss://irc-ws.chat.twitch.tv:443"
-	ircDialTimeout    = 5 * time.Second
-	ircPingInterval   = 10 * time.Second
-	ircPingTimeout    = 5 * time.Second
-	ircReconnectDelay = 5 * time.Second
-	ircMaxMessageSize = 1 * 1024 * 1024 // 1MiB
-	ircSendBufferSize = 64
+	DefaultIRCWSURL       = "wss://irc-ws.chat.twitch.tv:443"
+	ircDialTimeout        = 5 * time.Second
+	ircPingInterval       = 10 * time.Second
+	ircPingTimeout        = 5 * time.Second
+	ircReconnectBaseDelay = 1 * time.Second
+	ircReconnectMaxDelay  = 2 * time.Minute
+	ircStableConnection   = 1 * time.Minute // connections lasting longer reset the backoff
+	ircMaxMessageSize     = 1 * 1024 * 1024 // 1MiB
+	ircSendBufferSize     = 64
 )
 
+// errReconnectRequested is returned by the read loop when Twitch announces a server restart with RECONNECT.
+var errReconnectRequested = errors.New("server requested reconnect")
+
+// ConnStatus is a snapshot of the health of a connection.
+type ConnStatus struct {
+	Connected      bool
+	ConnectedSince time.Time     // zero when not connected
+	Reconnects     int           // reconnect attempts since the connection was created
+	LastError      error         // last error which caused a disconnect, nil if none occurred
+	LastErrorAt    time.Time     // time LastError occurred
+	Latency        time.Duration // round trip time of the last PING/PONG, zero if unknown
+	JoinedChannels int
+	NextReconnect  time.Time // time of the next reconnect attempt, zero when connected
+}
+
+// reconnectDelay returns the exponential backoff for a reconnect attempt, starting at zero.
+// The delay is randomized between half and the full backoff so clients don't reconnect in lockstep.
+func reconnectDelay(attempt int, jitter func(n int64) int64) time.Duration {
+	delay := ircReconnectMaxDelay
+	if attempt < 16 {
+		delay = min(ircReconnectBaseDelay<<attempt, ircReconnectMaxDelay)
+	}
+
+	half := int64(delay / 2)
+	return time.Duration(half + jitter(half+1))
+}
+
 // ConnAccountProvider retrieves account credentials for IRC authentication.
 type ConnAccountProvider interface {
 	GetAccountBy(id string) (save.Account, error)
@@ -47,6 +77,7 @@ type Conn struct {
 	channels []string
 	refs     int
 	closed   bool
+	status   ConnStatus
 
 	// WSURL allows overriding the WebSocket URL for testing
 	WSURL string
@@ -122,6 +153,22 @@ func (c *Conn) JoinChannel(channel string) error {
 	return c.Send(JoinMessage{Channel: channel})
 }
 
+// Status returns a snapshot of the connection health.
+func (c *Conn) Status() ConnStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status := c.status
+	status.JoinedChannels = len(c.channels)
+	return status
+}
+
+func (c *Conn) updateStatus(fn func(s *ConnStatus)) {
+	c.mu.Lock()
+	fn(&c.status)
+	c.mu.Unlock()
+}
+
 func (c *Conn) getChannels() []string {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -137,28 +184,65 @@ func (c *Conn) emitError(err error) {
 }
 
 // Run is the main loop that maintains the connection with automatic reconnect.
+// Failed connections are retried with exponential backoff, a RECONNECT sent by Twitch reconnects immediately.
 // It blocks until Close is called or the context is cancelled.
 func (c *Conn) Run() {
 	defer close(c.sendCh)
 
+	var attempt int
 	for {
+		started := time.Now()
 		err := c.connectOnce()
+
+		c.updateStatus(func(s *ConnStatus) {
+			s.Connected = false
+			s.ConnectedSince = time.Time{}
+			s.Latency = 0
+		})
+
 		if c.ctx.Err() != nil {
 			c.logger.Info().Msg("connection stopped (context cancelled)")
 			return
 		}
 
-		if err != nil {
-			c.logger.Warn().Err(err).Msg("connection error, will reconnect")
+		if time.Since(started) > ircStableConnection {
+			attempt = 0
+		}
+
+		var delay time.Duration
+		switch {
+		case errors.Is(err, errReconnectRequested):
+			c.logger.Info().Msg("server requested reconnect")
+			attempt = 0
+		case err != nil:
+			c.logger.Warn().Err(err).Int("attempt", attempt).Msg("connection error, will reconnect")
+			c.updateStatus(func(s *ConnStatus) {
+				s.LastError = err
+				s.LastErrorAt = time.Now()
+			})
 			c.emitError(fmt.Errorf("disconnected from chat server: %w", err))
+			delay = reconnectDelay(attempt, rand.Int64N)
+			attempt++
+		default:
+			delay = reconnectDelay(attempt, rand.Int64N)
+			attempt++
 		}
 
+		c.updateStatus(func(s *ConnStatus) {
+			s.NextReconnect = time.Now().Add(delay)
+		})
+
 		select {
 		case <-c.ctx.Done():
 			return
-		case <-time.After(ircReconnectDelay):
-			c.logger.Info().Msg("reconnecting...")
+		case <-time.After(delay):
+			c.logger.Info().Dur("delay", delay).Msg("reconnecting...")
 		}
+
+		c.updateStatus(func(s *ConnStatus) {
+			s.Reconnects++
+			s.NextReconnect = time.Time{}
+		})
 	}
 }
 
@@ -188,14 +272,22 @@ func (c *Conn) connectOnce() error {
 		}
 	}
 
+	c.updateStatus(func(s *ConnStatus) {
+		s.Connected = true
+		s.ConnectedSince = time.Now()
+	})
+
 	// Run reader/writer/pinger concurrently
 	g, ctx := errgroup.WithContext(c.ctx)
 
 	// Internal channel for PONG messages (reader → writer)
 	pongCh := make(chan struct{}, 1)
 
+	// Internal channel for PONG replies to our own PINGs (reader → pinger)
+	pongReceivedCh := make(chan struct{}, 1)
+
 	g.Go(func() error {
-		return c.readLoop(ctx, ws, pongCh)
+		return c.readLoop(ctx, ws, pongCh, pongReceivedCh)
 	})
 
 	g.Go(func() error {
@@ -203,7 +295,7 @@ func (c *Conn) connectOnce() error {
 	})
 
 	g.Go(func() error {
-		return c.pingLoop(ctx, ws)
+		return c.pingLoop(ctx, ws, pongReceivedCh)
 	})
 
 	return g.Wait()
@@ -235,7 +327,7 @@ func (c *Conn) authenticate(ws *websocket.Conn) error {
 	return nil
 }
 
-func (c *Conn) readLoop(ctx context.Context, ws *websocket.Conn, pongCh chan<- struct{}) error {
+func (c *Conn) readLoop(ctx context.Context, ws *websocket.Conn, pongCh, pongReceivedCh chan<- struct{}) error {
 	for {
 		_, data, err := ws.Read(ctx)
 		if err != nil {
@@ -262,13 +354,22 @@ func (c *Conn) readLoop(ctx context.Context, ws *websocket.Conn, pongCh chan<- s
 				return fmt.Errorf("parse error: %w", err)
 			}
 
-			// Handle PING → signal writer to send PONG
-			if _, ok := parsed.(PingMessage); ok {
+			switch parsed.(type) {
+			case PingMessage:
+				// Handle PING → signal writer to send PONG
 				select {
 				case pongCh <- struct{}{}:
 				default:
 				}
 				continue
+			case PongMessage:
+				select {
+				case pongReceivedCh <- struct{}{}:
+				default:
+				}
+				continue
+			case ReconnectMessage:
+				return errReconnectRequested
 			}
 
 			c.emit(parsed)
@@ -296,7 +397,7 @@ func (c *Conn) writeLoop(ctx context.Context, ws *websocket.Conn, pongCh <-chan
 	}
 }
 
-func (c *Conn) pingLoop(ctx context.Context, ws *websocket.Conn) error {
+func (c *Conn) pingLoop(ctx context.Context, ws *websocket.Conn, pongReceivedCh <-chan struct{}) error {
 	ticker := time.NewTicker(ircPingInterval)
 	defer ticker.Stop()
 
@@ -305,11 +406,21 @@ func (c *Conn) pingLoop(ctx context.Context, ws *websocket.Conn) error {
 		case <-ctx.Done():
 			return nil
 		case <-ticker.C:
-			pingCtx, cancel := context.WithTimeout(ctx, ircPingTimeout)
-			err := ws.Ping(pingCtx)
-			cancel()
-			if err != nil {
-				return fmt.Errorf("ping timeout: %w", err)
+			sent := time.Now()
+			if err := ws.Write(ctx, websocket.MessageText, []byte(PingMessage{}.IRC())); err != nil {
+				return err
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-pongReceivedCh:
+				latency := time.Since(sent)
+				c.updateStatus(func(s *ConnStatus) {
+					s.Latency = latency
+				})
+			case <-time.After(ircPingTimeout):
+				return errors.New("ping timeout: no PONG received")
 			}
 		}
 	}
@@ -0,0 +1,32 @@
+package twitchirc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_reconnectDelay(t *testing.T) {
+	t.Parallel()
+
+	noJitter := func(int64) int64 { return 0 }
+	fullJitter := func(n int64) int64 { return n - 1 }
+
+	cases := []struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		{attempt: 0, min: 500 * time.Millisecond, max: time.Second},
+		{attempt: 1, min: time.Second, max: 2 * time.Second},
+		{attempt: 3, min: 4 * time.Second, max: 8 * time.Second},
+		{attempt: 7, min: time.Minute, max: 2 * time.Minute},
+		{attempt: 8, min: time.Minute, max: 2 * time.Minute},
+		{attempt: 100, min: time.Minute, max: 2 * time.Minute},
+	}
+
+	for _, tc := range cases {
+		require.Equal(t, tc.min, reconnectDelay(tc.attempt, noJitter), "attempt %d", tc.attempt)
+		require.Equal(t, tc.max, reconnectDelay(tc.attempt, fullJitter), "attempt %d", tc.attempt)
+	}
+}
@@ -127,6 +127,13 @@ func (p PingMessage) IRC() string {
 	return "PING :tmi.twitch.tv"
 }
 
+// ReconnectMessage is sent by Twitch before the server restarts, clients should reconnect and rejoin channels.
+type ReconnectMessage struct{}
+
+func (r ReconnectMessage) IRC() string {
+	return "RECONNECT"
+}
+
 type JoinMessage struct {
 	Channel string
 }
@@ -200,6 +200,10 @@ func ParseIRC(message string) (IRCer, error) {
 		return &p, nil
 	case "PING":
 		return PingMessage{}, nil
+	case "PONG":
+		return PongMessage{}, nil
+	case "RECONNECT":
+		return ReconnectMessage{}, nil
 	case "NOTICE":
 		n := Notice{
 			MsgID:           MsgID(c.tags["msg-id"]),
@@ -215,6 +215,22 @@ func Test_parseTags(t *testing.T) {
 	}
 }
 
+func Test_ParseIRC_ConnectionCommands(t *testing.T) {
+	t.Parallel()
+
+	msg, err := ParseIRC("PING :tmi.twitch.tv")
+	require.NoError(t, err)
+	require.Equal(t, PingMessage{}, msg)
+
+	msg, err = ParseIRC(":tmi.twitch.tv PONG tmi.twitch.tv :tmi.twitch.tv")
+	require.NoError(t, err)
+	require.Equal(t, PongMessage{}, msg)
+
+	msg, err = ParseIRC(":tmi.twitch.tv RECONNECT")
+	require.NoError(t, err)
+	require.Equal(t, ReconnectMessage{}, msg)
+}
+
 func Test_ParseIRC_Files(t *testing.T) {
 	messageFile, err := os.Open("testdata/messages.txt")
 	require.NoError(t, err)
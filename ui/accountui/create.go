@@ -2,6 +2,7 @@ package accountui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -11,26 +12,51 @@ import (
 	"charm.land/bubbles/v2/textinput"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
+	"github.com/cli/browser"
 	"github.com/julez-dev/chatuino/save"
 	"github.com/julez-dev/chatuino/server"
 	"github.com/julez-dev/chatuino/twitch/twitchapi"
+	"github.com/julez-dev/chatuino/twitch/twitchauth"
 )
 
 type createState int
 
 const (
-	input createState = iota
+	selectMethod createState = iota
+	input
+	deviceCode
+	loopback
 	loading
 	finished
 )
 
+// loginTimeout limits how long a device code or browser login waits for the user.
+const loginTimeout = 10 * time.Minute
+
 type setAccountMessage struct {
 	account save.Account
+	reauth  bool // the tokens of the existing account are replaced
 	err     error
 }
 
 type cancelCreateMessage struct{}
 
+type deviceAuthorizationMessage struct {
+	auth twitchauth.DeviceAuthorization
+	err  error
+}
+
+type loopbackStartedMessage struct {
+	authURL string
+	err     error
+}
+
+type loginTokenMessage struct {
+	token  twitchauth.Token
+	method save.AuthMethod
+	err    error
+}
+
 type createModel struct {
 	state     createState
 	textinput textinput.Model
@@ -41,6 +67,16 @@ type createModel struct {
 	width, height     int
 	clientID, apiHost string
 
+	// cancel stops a running device code or browser login
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	device  twitchauth.DeviceAuthorization
+	authURL string
+
+	// reauthID is the existing account authorized again, the login has to be for the same user
+	reauthID, reauthName string
+
 	err     error
 	account save.Account
 }
@@ -48,7 +84,6 @@ type createModel struct {
 func newCreateModel(width, height int, clientID, apiHost string, keymap save.KeyMap, theme save.Theme) createModel {
 	ti := textinput.New()
 	ti.Placeholder = "xxxxxxxxxxxxxxxxxxxxxxxxxxxxxx%xxxxxxxxxxxxxxxxxxxxxxxxxxxxxx"
-	ti.Focus()
 	ti.SetWidth(width - 2)
 
 	s := spinner.New()
@@ -69,7 +104,21 @@ func newCreateModel(width, height int, clientID, apiHost string, keymap save.Key
 }
 
 func (c createModel) Init() tea.Cmd {
-	return c.textinput.Focus()
+	return nil
+}
+
+// startLogin starts the timeout of a device code or browser login, so the time spent choosing a method doesn't count.
+func (c createModel) startLogin() createModel {
+	c.stop()
+	c.ctx, c.cancel = context.WithTimeout(context.Background(), loginTimeout)
+	return c
+}
+
+// stop cancels a running login.
+func (c createModel) stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
 }
 
 func (c createModel) Update(msg tea.Msg) (createModel, tea.Cmd) {
@@ -77,6 +126,8 @@ func (c createModel) Update(msg tea.Msg) (createModel, tea.Cmd) {
 
 	switch msg := msg.(type) {
 	case setAccountMessage:
+		c.stop()
+
 		if msg.err != nil {
 			c.err = msg.err
 			c.state = finished
@@ -85,30 +136,78 @@ func (c createModel) Update(msg tea.Msg) (createModel, tea.Cmd) {
 
 		c.account = msg.account
 		c.state = finished
+	case deviceAuthorizationMessage:
+		if msg.err != nil {
+			return c, c.fail(fmt.Errorf("could not start device login: %w", msg.err))
+		}
+
+		c.device = msg.auth
+		c.state = deviceCode
+		_ = browser.OpenURL(msg.auth.VerificationURI)
+
+		return c, tea.Batch(c.waitForDeviceToken(), c.spinner.Tick)
+	case loopbackStartedMessage:
+		if msg.err != nil {
+			return c, c.fail(msg.err)
+		}
+
+		c.authURL = msg.authURL
+		c.state = loopback
+		_ = browser.OpenURL(msg.authURL)
+
+		return c, c.spinner.Tick
+	case loginTokenMessage:
+		if msg.err != nil {
+			if errors.Is(msg.err, context.Canceled) {
+				return c, nil
+			}
+
+			return c, c.fail(msg.err)
+		}
+
+		c.state = loading
+		return c, c.fetchAccount(msg.token.AccessToken, msg.token.RefreshToken, msg.method)
 	case tea.KeyPressMsg:
 		if key.Matches(msg, c.keymap.Quit) {
+			c.stop()
 			return c, tea.Quit
 		}
 		if key.Matches(msg, c.keymap.Escape) {
-			if c.state == input {
+			if c.state != loading && c.state != finished {
+				c.stop()
 				return c, func() tea.Msg { return cancelCreateMessage{} }
 			}
 		}
-		if key.Matches(msg, c.keymap.Confirm) {
-			if c.state == input {
-				cmd = c.handleSent(c.textinput.Value())
+
+		switch c.state {
+		case selectMethod:
+			switch msg.String() {
+			case "d":
+				c = c.startLogin()
 				c.state = loading
-				return c, tea.Batch(cmd, c.spinner.Tick)
+				return c, tea.Batch(c.startDeviceAuthorization(), c.spinner.Tick)
+			case "b":
+				c = c.startLogin()
+				c.state = loading
+				return c, tea.Batch(c.startLoopback(), c.spinner.Tick)
+			case "p":
+				c.state = input
+				return c, c.textinput.Focus()
+			}
+
+			return c, nil
+		case input:
+			if key.Matches(msg, c.keymap.Confirm) {
+				c.state = loading
+				return c, tea.Batch(c.handleSent(c.textinput.Value()), c.spinner.Tick)
 			}
 		}
 	}
 
-	if c.state == loading {
+	switch c.state {
+	case loading, deviceCode, loopback:
 		c.spinner, cmd = c.spinner.Update(msg)
-		return c, cmd
-	}
-
-	if c.state == input {
+	case input:
 		c.textinput, cmd = c.textinput.Update(msg)
 	}
 
@@ -118,10 +217,30 @@ func (c createModel) Update(msg tea.Msg) (createModel, tea.Cmd) {
 func (c createModel) View() string {
 	view := ""
 	switch c.state {
+	case selectMethod:
+		if c.reauthID != "" {
+			view = fmt.Sprintf("Authorize %s again to grant the permissions Chatuino needs.\nThe account keeps its settings.\n\n", c.reauthName)
+		}
+
+		view += "How do you want to log in?\n\n" +
+			"d: Enter a code on twitch.tv (device login)\n" +
+			"b: Log in with the browser, completes automatically\n" +
+			"p: Paste an Access Token + Refresh Token combination\n\n" +
+			"Esc: Cancel"
 	case input:
 		view = fmt.Sprintf(
 			"Please enter the Access Token + Refresh Token combination.\nAccess %s/auth/start to start auth flow\nDon't show on stream!\n%s\n\nEsc: Cancel", c.apiHost, c.textinput.View(),
 		)
+	case deviceCode:
+		view = fmt.Sprintf(
+			"Open %s\nand enter the code\n\n%s\n\n%s Waiting for authorization\n\nEsc: Cancel",
+			c.device.VerificationURI, lipgloss.NewStyle().Bold(true).Render(c.device.UserCode), c.spinner.View(),
+		)
+	case loopback:
+		view = fmt.Sprintf(
+			"Log in with the browser window that was opened.\nIf no browser opened, visit\n%s\n\n%s Waiting for login\n\nEsc: Cancel",
+			c.authURL, c.spinner.View(),
+		)
 	case loading:
 		view = c.spinner.View() + " Loading user information"
 	case finished:
@@ -141,20 +260,80 @@ func (c createModel) View() string {
 		Render(view)
 }
 
-func (c createModel) handleSent(input string) tea.Cmd {
+func (c createModel) fail(err error) tea.Cmd {
+	c.stop()
+	return func() tea.Msg {
+		return setAccountMessage{err: err}
+	}
+}
+
+func (c createModel) startDeviceAuthorization() tea.Cmd {
+	client := twitchauth.NewClient(c.clientID, nil)
+	ctx := c.ctx
+
+	return func() tea.Msg {
+		auth, err := client.StartDeviceAuthorization(ctx, server.Scopes())
+		return deviceAuthorizationMessage{auth: auth, err: err}
+	}
+}
+
+func (c createModel) waitForDeviceToken() tea.Cmd {
+	client := twitchauth.NewClient(c.clientID, nil)
+	ctx := c.ctx
+	auth := c.device
+
+	return func() tea.Msg {
+		token, err := client.WaitForDeviceToken(ctx, auth)
+		return loginTokenMessage{token: token, method: save.AuthMethodDevice, err: err}
+	}
+}
+
+// startLoopback starts the local listener of the browser login. The Chatuino server posts the tokens
+// to it after the login, so the login completes without pasting the tokens.
+func (c createModel) startLoopback() tea.Cmd {
+	ctx := c.ctx
+	apiHost := c.apiHost
+
 	return func() tea.Msg {
-		split := strings.SplitN(input, "%", 2)
+		l, err := twitchauth.ListenLoopback()
+		if err != nil {
+			return loopbackStartedMessage{err: err}
+		}
+
+		return tea.BatchMsg{
+			func() tea.Msg { return loopbackStartedMessage{authURL: l.AuthURL(apiHost)} },
+			func() tea.Msg {
+				defer l.Close()
+
+				token, err := l.Wait(ctx)
+				return loginTokenMessage{token: token, method: save.AuthMethodBrowser, err: err}
+			},
+		}
+	}
+}
+
+func (c createModel) handleSent(input string) tea.Cmd {
+	split := strings.SplitN(input, "%", 2)
 
-		if len(split) != 2 {
+	if len(split) != 2 {
+		return func() tea.Msg {
 			return setAccountMessage{
 				err: fmt.Errorf("got invalid input"),
 			}
 		}
+	}
+
+	return c.fetchAccount(split[0], split[1], save.AuthMethodPasted)
+}
+
+func (c createModel) fetchAccount(accessToken, refreshToken string, method save.AuthMethod) tea.Cmd {
+	reauthID, reauthName := c.reauthID, c.reauthName
 
+	return func() tea.Msg {
 		tmpAccount := &save.Account{
 			ID:           "temp-static-account",
-			AccessToken:  split[0],
-			RefreshToken: split[1],
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
 		}
 
 		api, err := twitchapi.NewAPI(
@@ -186,13 +365,21 @@ func (c createModel) handleSent(input string) tea.Cmd {
 			}
 		}
 
+		if reauthID != "" && resp.Data[0].ID != reauthID {
+			return setAccountMessage{
+				err: fmt.Errorf("logged in as %s, log in as %s to authorize the account again", resp.Data[0].DisplayName, reauthName),
+			}
+		}
+
 		return setAccountMessage{
+			reauth: reauthID != "",
 			account: save.Account{
 				ID:           resp.Data[0].ID,
 				LoginName:    resp.Data[0].Login,
 				DisplayName:  resp.Data[0].DisplayName,
 				AccessToken:  tmpAccount.AccessToken,
 				RefreshToken: tmpAccount.RefreshToken,
+				AuthMethod:   method,
 				CreatedAt:    time.Now(),
 			},
 		}
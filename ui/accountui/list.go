@@ -19,6 +19,7 @@ type AccountProvider interface {
 	GetAllAccounts() ([]save.Account, error)
 	GetAccountBy(id string) (save.Account, error)
 	UpdateTokensFor(id, accessToken, refreshToken string) error
+	UpdateAuthMethodFor(id string, method save.AuthMethod) error
 	MarkAccountAsMain(id string) error
 	Remove(id string) error
 	Add(account save.Account) error
@@ -39,6 +40,9 @@ type accountRow struct {
 	isMain      bool
 	createdAt   time.Time
 	tokenValid  bool
+
+	// missingScopes are the scopes Chatuino requests which the token was not granted, the account needs to be authorized again
+	missingScopes []string
 }
 
 type setAccountsMessage struct {
@@ -47,8 +51,9 @@ type setAccountsMessage struct {
 }
 
 type tokenValidationMessage struct {
-	accountID string
-	valid     bool
+	accountID     string
+	valid         bool
+	missingScopes []string
 }
 
 type List struct {
@@ -69,15 +74,16 @@ type List struct {
 	confirmDeleteName string
 
 	// Styles
-	borderStyle       lipgloss.Style
-	headerStyle       lipgloss.Style
-	selectedStyle     lipgloss.Style
-	dimmedStyle       lipgloss.Style
-	mainBadgeStyle    lipgloss.Style
-	validTokenStyle   lipgloss.Style
-	invalidTokenStyle lipgloss.Style
-	errorStyle        lipgloss.Style
-	footerStyle       lipgloss.Style
+	borderStyle        lipgloss.Style
+	headerStyle        lipgloss.Style
+	selectedStyle      lipgloss.Style
+	dimmedStyle        lipgloss.Style
+	mainBadgeStyle     lipgloss.Style
+	validTokenStyle    lipgloss.Style
+	invalidTokenStyle  lipgloss.Style
+	missingScopesStyle lipgloss.Style
+	errorStyle         lipgloss.Style
+	footerStyle        lipgloss.Style
 
 	clientID, apiHost string
 }
@@ -92,15 +98,16 @@ func NewList(clientID, apiHost string, accountProvider AccountProvider, keymap s
 		keymap:          keymap,
 		theme:           theme,
 
-		borderStyle:       lipgloss.NewStyle().Foreground(borderColor),
-		headerStyle:       lipgloss.NewStyle().Foreground(borderColor).Bold(true),
-		selectedStyle:     lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ListSelectedColor)).Bold(true),
-		dimmedStyle:       lipgloss.NewStyle().Foreground(lipgloss.Color(theme.DimmedTextColor)),
-		mainBadgeStyle:    lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ActiveLabelColor)),
-		validTokenStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color("#a3be8c")), // Nord green
-		invalidTokenStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("#bf616a")), // Nord red
-		errorStyle:        lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ChatErrorColor)),
-		footerStyle:       lipgloss.NewStyle().Foreground(lipgloss.Color(theme.DimmedTextColor)),
+		borderStyle:        lipgloss.NewStyle().Foreground(borderColor),
+		headerStyle:        lipgloss.NewStyle().Foreground(borderColor).Bold(true),
+		selectedStyle:      lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ListSelectedColor)).Bold(true),
+		dimmedStyle:        lipgloss.NewStyle().Foreground(lipgloss.Color(theme.DimmedTextColor)),
+		mainBadgeStyle:     lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ActiveLabelColor)),
+		validTokenStyle:    lipgloss.NewStyle().Foreground(lipgloss.Color("#a3be8c")), // Nord green
+		invalidTokenStyle:  lipgloss.NewStyle().Foreground(lipgloss.Color("#bf616a")), // Nord red
+		missingScopesStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("#ebcb8b")), // Nord yellow
+		errorStyle:         lipgloss.NewStyle().Foreground(lipgloss.Color(theme.ChatErrorColor)),
+		footerStyle:        lipgloss.NewStyle().Foreground(lipgloss.Color(theme.DimmedTextColor)),
 	}
 }
 
@@ -134,12 +141,17 @@ func (l List) validateTokens() tea.Cmd {
 				return tokenValidationMessage{accountID: acc.id, valid: false}
 			}
 
-			valid, err := twitchapi.ValidateToken(ctx, nil, account.AccessToken)
+			validation, err := twitchapi.ValidateTokenScopes(ctx, nil, account.AccessToken)
 			if err != nil {
 				// Network error - assume valid to avoid false negatives
 				return tokenValidationMessage{accountID: acc.id, valid: true}
 			}
-			return tokenValidationMessage{accountID: acc.id, valid: valid}
+
+			if !validation.Valid {
+				return tokenValidationMessage{accountID: acc.id, valid: false}
+			}
+
+			return tokenValidationMessage{accountID: acc.id, valid: true, missingScopes: server.MissingScopes(validation.Scopes)}
 		})
 	}
 	return tea.Batch(cmds...)
@@ -171,7 +183,11 @@ func (l List) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		l.err = msg.err
 		l.state = inTable
 
-		if msg.err == nil {
+		switch {
+		case msg.err != nil:
+		case msg.reauth:
+			cmds = append(cmds, l.reauthAccountRefresh(msg.account))
+		default:
 			cmds = append(cmds, l.addNewAccountRefresh(msg.account))
 		}
 
@@ -192,6 +208,7 @@ func (l List) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		for i, acc := range l.accounts {
 			if acc.id == msg.accountID {
 				l.accounts[i].tokenValid = msg.valid
+				l.accounts[i].missingScopes = msg.missingScopes
 				break
 			}
 		}
@@ -228,6 +245,15 @@ func (l List) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				l.state = inCreate
 				l.create = newCreateModel(l.width, l.height, l.clientID, l.apiHost, l.keymap, l.theme)
 				return l, l.create.Init()
+			case key.Matches(msg, l.keymap.Reauthorize):
+				if len(l.accounts) > 0 && l.cursor < len(l.accounts) {
+					acc := l.accounts[l.cursor]
+					l.state = inCreate
+					l.create = newCreateModel(l.width, l.height, l.clientID, l.apiHost, l.keymap, l.theme)
+					l.create.reauthID = acc.id
+					l.create.reauthName = acc.displayName
+					return l, l.create.Init()
+				}
 			case key.Matches(msg, l.keymap.Remove):
 				if len(l.accounts) > 0 && l.cursor < len(l.accounts) {
 					acc := l.accounts[l.cursor]
@@ -245,6 +271,7 @@ func (l List) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		} else if l.state == inCreate {
 			if key.Matches(msg, l.keymap.Create) {
+				l.create.stop()
 				l.state = inTable
 			}
 		}
@@ -361,10 +388,13 @@ func (l List) renderAccountRow(acc accountRow, selected bool) string {
 	}
 
 	// Token status indicator
-	if acc.tokenValid {
-		parts = append(parts, l.validTokenStyle.Render("●"))
-	} else {
+	switch {
+	case !acc.tokenValid:
 		parts = append(parts, l.invalidTokenStyle.Render("●"))
+	case len(acc.missingScopes) > 0:
+		parts = append(parts, l.missingScopesStyle.Render("●"))
+	default:
+		parts = append(parts, l.validTokenStyle.Render("●"))
 	}
 
 	// ID (shortened)
@@ -402,6 +432,10 @@ func (l List) renderAccountRow(acc accountRow, selected bool) string {
 		parts = append(parts, l.dimmedStyle.Render(dateStr))
 	}
 
+	if !acc.tokenValid || len(acc.missingScopes) > 0 {
+		parts = append(parts, l.missingScopesStyle.Render(firstKey(l.keymap.Reauthorize)+":Re-auth"))
+	}
+
 	return strings.Join(parts, " ")
 }
 
@@ -410,12 +444,14 @@ func (l List) renderFooter() string {
 	addKey := firstKey(l.keymap.Create)
 	delKey := firstKey(l.keymap.Remove)
 	mainKey := firstKey(l.keymap.MarkLeader)
+	reauthKey := firstKey(l.keymap.Reauthorize)
 	quitKey := firstKey(l.keymap.Quit)
 
 	hints := []string{
 		addKey + ":Add",
 		delKey + ":Delete",
 		mainKey + ":Main",
+		reauthKey + ":Re-auth",
 		quitKey + ":Quit",
 	}
 	return l.footerStyle.Render("[ " + strings.Join(hints, " ") + " ]")
@@ -554,23 +590,50 @@ func (l List) addNewAccountRefresh(account save.Account) tea.Cmd {
 	}
 }
 
+// reauthAccountRefresh replaces the tokens of an existing account after it was authorized again. The ID,
+// main flag and everything stored for the account are kept.
+func (l List) reauthAccountRefresh(account save.Account) tea.Cmd {
+	existingRows := l.accounts
+	return func() tea.Msg {
+		if err := l.accountProvider.UpdateTokensFor(account.ID, account.AccessToken, account.RefreshToken); err != nil {
+			return setAccountsMessage{err: err}
+		}
+
+		// the new tokens may come from another login method
+		if err := l.accountProvider.UpdateAuthMethodFor(account.ID, account.AuthMethod); err != nil {
+			return setAccountsMessage{err: err}
+		}
+
+		accounts, err := fetchAccountsNonAnonymous(l.accountProvider)
+		if err != nil {
+			return setAccountsMessage{err: err}
+		}
+
+		// validation runs again for all accounts after the update
+		return setAccountsMessage{accounts: accountsToRows(accounts, existingRows)}
+	}
+}
+
 // accountsToRows converts save.Account slice to accountRow slice, preserving token validation status from existing rows.
 func accountsToRows(accounts []save.Account, existingRows []accountRow) []accountRow {
 	rows := make([]accountRow, 0, len(accounts))
 	for _, acc := range accounts {
 		valid := true
+		var missingScopes []string
 		for _, existing := range existingRows {
 			if existing.id == acc.ID {
 				valid = existing.tokenValid
+				missingScopes = existing.missingScopes
 				break
 			}
 		}
 		rows = append(rows, accountRow{
-			id:          acc.ID,
-			displayName: acc.DisplayName,
-			isMain:      acc.IsMain,
-			createdAt:   acc.CreatedAt,
-			tokenValid:  valid,
+			id:            acc.ID,
+			displayName:   acc.DisplayName,
+			isMain:        acc.IsMain,
+			createdAt:     acc.CreatedAt,
+			tokenValid:    valid,
+			missingScopes: missingScopes,
 		})
 	}
 	return rows
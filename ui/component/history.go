@@ -0,0 +1,93 @@
+package component
+
+import (
+	"slices"
+	"strings"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+)
+
+// historySearch is the state of a reverse incremental history search.
+type historySearch struct {
+	query    string
+	original string // input value before the search started, restored when the search is cancelled
+	index    int    // history index of the current match, len(history) when nothing matched yet
+	failing  bool   // no older entry matches the query
+}
+
+// History returns the sent inputs, oldest first.
+func (s *SuggestionTextInput) History() []string {
+	return slices.Clone(s.history)
+}
+
+// SetHistory replaces the sent inputs, oldest first. Only the newest MaxHistory entries are kept.
+func (s *SuggestionTextInput) SetHistory(history []string) {
+	s.history = slices.Clone(history[max(len(history)-MaxHistory, 0):])
+	s.historyIndex = len(s.history)
+	s.browsingHistory = false
+}
+
+// IsSearchingHistory reports whether a reverse history search is active. While searching all key presses
+// belong to the search, enter accepts the match without sending it.
+func (s *SuggestionTextInput) IsSearchingHistory() bool {
+	return s.historySearch != nil
+}
+
+func (s *SuggestionTextInput) addHistory(value string) {
+	if value != "" && (len(s.history) == 0 || s.history[len(s.history)-1] != value) {
+		s.history = append(s.history, value)
+	}
+
+	if len(s.history) > MaxHistory {
+		s.history = slices.Clone(s.history[len(s.history)-MaxHistory:])
+	}
+
+	s.historyIndex = len(s.history)
+	s.browsingHistory = false
+}
+
+func (s *SuggestionTextInput) updateHistorySearch(msg tea.KeyPressMsg) {
+	search := s.historySearch
+
+	switch {
+	case key.Matches(msg, s.KeyMap.SearchHistory):
+		// jump to the next older match
+		s.searchHistory(search.index - 1)
+	case msg.String() == "esc" || msg.String() == "ctrl+g":
+		s.historySearch = nil
+		s.SetValue(search.original)
+	case msg.String() == "backspace":
+		if search.query == "" {
+			return
+		}
+
+		runes := []rune(search.query)
+		search.query = string(runes[:len(runes)-1])
+		s.searchHistory(len(s.history) - 1)
+	case msg.Text != "":
+		search.query += msg.Text
+		s.searchHistory(min(search.index, len(s.history)-1))
+	default:
+		// enter, tab or any other key accepts the current match
+		s.historySearch = nil
+		s.historyIndex = len(s.history)
+	}
+}
+
+// searchHistory looks for the newest entry containing the query, starting at index and moving to older entries.
+func (s *SuggestionTextInput) searchHistory(from int) {
+	search := s.historySearch
+	query := strings.ToLower(search.query)
+
+	for i := from; i >= 0; i-- {
+		if strings.Contains(strings.ToLower(s.history[i]), query) {
+			search.index = i
+			search.failing = false
+			s.SetValue(s.history[i])
+			return
+		}
+	}
+
+	search.failing = true
+}
@@ -0,0 +1,92 @@
+package component
+
+import (
+	"slices"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+func send(s *SuggestionTextInput, msgs ...tea.KeyPressMsg) {
+	for _, msg := range msgs {
+		s.Update(msg)
+	}
+}
+
+func typeText(s *SuggestionTextInput, text string) {
+	for _, r := range text {
+		s.Update(tea.KeyPressMsg{Code: r, Text: string(r)})
+	}
+}
+
+func TestSuggestionTextInput_History(t *testing.T) {
+	t.Parallel()
+
+	s := NewSuggestionTextInput(nil, nil)
+	s.Focus()
+
+	for _, v := range []string{"first", "second", "second", ""} {
+		s.SetValue(v)
+		send(s, tea.KeyPressMsg{Code: tea.KeyEnter})
+	}
+
+	// empty and repeated inputs are not added
+	if got := s.History(); !slices.Equal(got, []string{"first", "second"}) {
+		t.Fatalf("unexpected history %v", got)
+	}
+
+	entries := make([]string, MaxHistory+10)
+	for i := range entries {
+		entries[i] = string(rune('a' + i%26))
+	}
+
+	s.SetHistory(entries)
+	if got := s.History(); len(got) != MaxHistory || got[len(got)-1] != entries[len(entries)-1] {
+		t.Fatalf("expected the newest %d entries, got %d", MaxHistory, len(got))
+	}
+}
+
+func TestSuggestionTextInput_HistorySearch(t *testing.T) {
+	t.Parallel()
+
+	s := NewSuggestionTextInput(nil, nil)
+	s.Focus()
+	s.SetHistory([]string{"hello world", "/ban spammer", "hello there", "bye"})
+	s.SetValue("draft")
+
+	ctrlR := tea.KeyPressMsg{Code: 'r', Mod: tea.ModCtrl}
+
+	send(s, ctrlR)
+	if !s.IsSearchingHistory() {
+		t.Fatal("expected history search to start")
+	}
+
+	typeText(s, "hello")
+	if got := s.Value(); got != "hello there" {
+		t.Fatalf("expected newest match, got %q", got)
+	}
+
+	// ctrl+r again moves to older matches
+	send(s, ctrlR)
+	if got := s.Value(); got != "hello world" {
+		t.Fatalf("expected older match, got %q", got)
+	}
+
+	// escape restores the draft
+	send(s, tea.KeyPressMsg{Code: tea.KeyEscape})
+	if s.IsSearchingHistory() || s.Value() != "draft" {
+		t.Fatalf("expected cancelled search with draft, got %q", s.Value())
+	}
+
+	send(s, ctrlR)
+	typeText(s, "ban")
+	send(s, tea.KeyPressMsg{Code: tea.KeyEnter})
+	if s.IsSearchingHistory() || s.Value() != "/ban spammer" {
+		t.Fatalf("expected accepted match, got %q", s.Value())
+	}
+
+	// accepting a match does not add it to the history
+	if got := s.History(); len(got) != 4 {
+		t.Fatalf("unexpected history %v", got)
+	}
+}
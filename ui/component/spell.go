@@ -0,0 +1,136 @@
+package component
+
+import (
+	"strings"
+	"unicode"
+
+	"charm.land/lipgloss/v2"
+)
+
+// SpellChecker reports whether a word of the input is spelled correctly.
+type SpellChecker interface {
+	Correct(word string) bool
+}
+
+// misspellings caches the misspelled rune positions of an input value.
+type misspellings struct {
+	value      string
+	misspelled []bool // per rune of value
+}
+
+// misspelledRunes returns for each rune of the input whether it belongs to a misspelled word, nil when
+// spell checking is disabled or all words are correct.
+// Commands, mentions, links, words with digits and user names are not checked.
+func (s *SuggestionTextInput) misspelledRunes() []bool {
+	if s.SpellChecker == nil {
+		return nil
+	}
+
+	value := s.InputModel.Value()
+	if s.misspellings != nil && s.misspellings.value == value {
+		return s.misspellings.misspelled
+	}
+
+	runes := []rune(value)
+	var misspelled []bool
+
+	for start := 0; start < len(runes); {
+		if unicode.IsSpace(runes[start]) {
+			start++
+			continue
+		}
+
+		end := start
+		for end < len(runes) && !unicode.IsSpace(runes[end]) {
+			end++
+		}
+
+		if wordStart, wordEnd, ok := s.checkableWord(runes, start, end); ok && !s.SpellChecker.Correct(string(runes[wordStart:wordEnd])) {
+			if misspelled == nil {
+				misspelled = make([]bool, len(runes))
+			}
+
+			for i := wordStart; i < wordEnd; i++ {
+				misspelled[i] = true
+			}
+		}
+
+		start = end
+	}
+
+	s.misspellings = &misspellings{value: value, misspelled: misspelled}
+	return misspelled
+}
+
+// checkableWord trims the punctuation around the token runes[start:end] and reports whether it should be checked.
+func (s *SuggestionTextInput) checkableWord(runes []rune, start, end int) (int, int, bool) {
+	token := string(runes[start:end])
+
+	if (start == 0 && strings.HasPrefix(token, "/")) || strings.HasPrefix(token, "@") || strings.Contains(token, "://") {
+		return 0, 0, false
+	}
+
+	if _, ok := s.userCache[strings.ToLower(token)]; ok {
+		return 0, 0, false
+	}
+
+	isLetter := func(r rune) bool { return unicode.IsLetter(r) }
+
+	for start < end && !isLetter(runes[start]) {
+		start++
+	}
+
+	for end > start && !isLetter(runes[end-1]) {
+		end--
+	}
+
+	if end-start < 2 {
+		return 0, 0, false
+	}
+
+	for _, r := range runes[start:end] {
+		if unicode.IsDigit(r) {
+			return 0, 0, false
+		}
+	}
+
+	return start, end, true
+}
+
+// renderText renders runes of the input starting at rune offset start of the value, underlining misspelled words.
+func (s *SuggestionTextInput) renderText(runes []rune, start int, misspelled []bool) string {
+	textStyle := s.InputModel.Styles().Focused.Text
+	if misspelled == nil {
+		return textStyle.Render(string(runes))
+	}
+
+	misspelledStyle := s.MisspelledStyle.Inherit(textStyle)
+
+	var b strings.Builder
+	for i := 0; i < len(runes); {
+		wrong := isMisspelled(misspelled, start+i)
+
+		j := i
+		for j < len(runes) && isMisspelled(misspelled, start+j) == wrong {
+			j++
+		}
+
+		if wrong {
+			b.WriteString(misspelledStyle.Render(string(runes[i:j])))
+		} else {
+			b.WriteString(textStyle.Render(string(runes[i:j])))
+		}
+
+		i = j
+	}
+
+	return b.String()
+}
+
+func isMisspelled(misspelled []bool, i int) bool {
+	return i >= 0 && i < len(misspelled) && misspelled[i]
+}
+
+func defaultMisspelledStyle() lipgloss.Style {
+	return lipgloss.NewStyle().UnderlineStyle(lipgloss.UnderlineCurly).UnderlineColor(lipgloss.Color("#bf616a"))
+}
@@ -0,0 +1,94 @@
+package component
+
+import (
+	"strings"
+	"testing"
+)
+
+type wordList map[string]bool
+
+func (w wordList) Correct(word string) bool {
+	return w[strings.ToLower(word)]
+}
+
+func misspelledWords(s *SuggestionTextInput) []string {
+	runes := []rune(s.Value())
+	misspelled := s.misspelledRunes()
+
+	var words []string
+	for i := 0; i < len(runes); i++ {
+		if !isMisspelled(misspelled, i) {
+			continue
+		}
+
+		j := i
+		for j < len(runes) && isMisspelled(misspelled, j) {
+			j++
+		}
+
+		words = append(words, string(runes[i:j]))
+		i = j
+	}
+
+	return words
+}
+
+func TestSuggestionTextInput_Misspellings(t *testing.T) {
+	t.Parallel()
+
+	s := NewSuggestionTextInput(map[string]func(...string) string{
+		"julezdev": func(s ...string) string { return strings.Join(s, " ") },
+	}, nil)
+
+	s.SetValue("hello wrold")
+	if s.misspelledRunes() != nil {
+		t.Fatalf("expected no misspellings without spell checker")
+	}
+
+	s.SpellChecker = wordList{"hello": true, "world": true}
+
+	tests := []struct {
+		value string
+		want  []string
+	}{
+		{value: "Hello world!", want: nil},
+		{value: "hello wrold, wrld", want: []string{"wrold", "wrld"}},
+		{value: "/ban julezdev @someone https://example.com/foo a x2 l33t", want: nil},
+		{value: "\"helo\" world", want: []string{"helo"}},
+	}
+
+	for _, tt := range tests {
+		s.SetValue(tt.value)
+
+		got := misspelledWords(s)
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Fatalf("misspellings of %q = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestSuggestionTextInput_MisspellingsView(t *testing.T) {
+	t.Parallel()
+
+	s := NewSuggestionTextInput(nil, nil)
+	s.SetMaxVisibleLines(3)
+	s.SetWidth(40)
+	s.SpellChecker = wordList{"hello": true}
+	s.MisspelledStyle = s.MisspelledStyle.Underline(true)
+	s.SetValue("hello wrold")
+
+	view := s.View()
+
+	s.SpellChecker = nil
+	s.misspellings = nil
+	plain := s.View()
+
+	if !strings.Contains(plain, "hello wrold") {
+		t.Fatalf("view is missing the input: %q", plain)
+	}
+
+	// the correct word is rendered the same, the misspelled word is styled
+	if !strings.Contains(view, "hello ") || view == plain {
+		t.Fatalf("expected only the misspelled word to be styled: %q", view)
+	}
+}
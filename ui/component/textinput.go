@@ -31,6 +31,7 @@ type KeyMap struct {
 	AcceptSuggestion key.Binding
 	NextSuggestion   key.Binding
 	PrevSuggestion   key.Binding
+	SearchHistory    key.Binding
 }
 
 // DefaultKeyMap is the default set of key bindings for navigating and acting
@@ -39,8 +40,12 @@ var DefaultKeyMap = KeyMap{
 	AcceptSuggestion: key.NewBinding(key.WithKeys("tab")),
 	NextSuggestion:   key.NewBinding(key.WithKeys("down", "ctrl+n")),
 	PrevSuggestion:   key.NewBinding(key.WithKeys("up", "ctrl+p")),
+	SearchHistory:    key.NewBinding(key.WithKeys("ctrl+r")),
 }
 
+// MaxHistory is the number of sent inputs kept in the history.
+const MaxHistory = 100
+
 type SuggestionTextInput struct {
 	trie *trie.Trie
 
@@ -53,14 +58,21 @@ type SuggestionTextInput struct {
 	history                    []string
 	historyIndex               int
 	browsingHistory            bool // true when navigating history with up/down
+	historySearch              *historySearch
 	IncludeCommandSuggestions  bool
 	IncludeModeratorCommands   bool
 	DisableAutoSpaceSuggestion bool
 	DisableHistory             bool
 	EmoteReplacer              Replacer
 
+	// SpellChecker underlines misspelled words with MisspelledStyle in the multi-line view, nil disables spell checking.
+	SpellChecker    SpellChecker
+	MisspelledStyle lipgloss.Style
+	misspellings    *misspellings
+
 	customSuggestions map[string]string
-	emoteReplacements map[string]string // emoteText:unicode
+	emoteReplacements map[string]string  // emoteText:unicode
+	suggestionScores  map[string]float64 // suggestion:score, higher scores are suggested first
 
 	userCache    map[string]func(...string) string // [username]render func
 	channelCache map[string]struct{}               // channel logins for /join autocomplete
@@ -123,6 +135,7 @@ func NewSuggestionTextInput(userCache map[string]func(...string) string, customS
 		maxVisibleLines:           1, // default single-line for backward compat
 		LineNumberStyle:           lipgloss.NewStyle().Faint(true),
 		CurrentLineNumberStyle:    lipgloss.NewStyle().Foreground(lipgloss.Color("212")), // bright highlight
+		MisspelledStyle:           defaultMisspelledStyle(),
 	}
 }
 
@@ -141,10 +154,14 @@ func (s *SuggestionTextInput) Update(msg tea.Msg) (*SuggestionTextInput, tea.Cmd
 		}
 	case tea.KeyPressMsg:
 		switch {
+		case s.historySearch != nil:
+			s.updateHistorySearch(msg)
+			return s, nil
+		case key.Matches(msg, s.KeyMap.SearchHistory) && !s.DisableHistory:
+			s.historySearch = &historySearch{original: s.InputModel.Value(), index: len(s.history)}
+			return s, nil
 		case msg.String() == "enter" && !s.DisableHistory:
-			s.history = append(s.history, s.InputModel.Value())
-			s.historyIndex = len(s.history)
-			s.browsingHistory = false
+			s.addHistory(s.InputModel.Value())
 			return s, nil
 		case key.Matches(msg, s.KeyMap.PrevSuggestion) && (s.InputModel.Value() == "" || s.browsingHistory):
 			if len(s.history) == 0 {
@@ -288,6 +305,15 @@ func (s *SuggestionTextInput) View() string {
 		inputView = s.InputModel.View()
 	}
 
+	if s.historySearch != nil {
+		label := "history search"
+		if s.historySearch.failing {
+			label = "failing history search"
+		}
+
+		return fmt.Sprintf(" (%s) %s_\n%s", label, s.historySearch.query, inputView)
+	}
+
 	if s.canAcceptSuggestion() {
 		suggestion := s.suggestions[s.suggestionIndex]
 
@@ -385,6 +411,13 @@ func (s *SuggestionTextInput) SetSuggestions(suggestions []string) {
 	s.updateSuggestions()
 }
 
+// SetSuggestionScores sets the scores used to rank suggestions, e.g. based on how often an emote was used.
+// Suggestions with a higher score come first, suggestions without a score are sorted by length.
+func (s *SuggestionTextInput) SetSuggestionScores(scores map[string]float64) {
+	s.suggestionScores = scores
+	s.updateSuggestions()
+}
+
 // SetChannelSuggestions replaces the channel cache used for /join autocomplete.
 func (s *SuggestionTextInput) SetChannelSuggestions(channels []string) {
 	m := make(map[string]struct{}, len(channels))
@@ -401,6 +434,28 @@ func (s *SuggestionTextInput) SetValue(val string) {
 	s.updateSuggestions()
 }
 
+// InsertAtCursor inserts text at the cursor position, separated by spaces from the surrounding words,
+// and moves the cursor behind the inserted text.
+func (s *SuggestionTextInput) InsertAtCursor(text string) {
+	runes := []rune(s.InputModel.Value())
+	pos := min(s.InputModel.Position(), len(runes))
+	before, after := string(runes[:pos]), string(runes[pos:])
+
+	if before != "" && !strings.HasSuffix(before, " ") {
+		text = " " + text
+	}
+
+	if !strings.HasPrefix(after, " ") {
+		text += " "
+	}
+
+	before = collapseSpaces(before + text)
+	s.InputModel.SetValue(collapseSpaces(before + after))
+	s.InputModel.SetCursor(len([]rune(before)))
+	s.suggestionIndex = 0
+	s.updateSuggestions()
+}
+
 // wouldCreateConsecutiveSpaces reports whether inserting a space at pos would
 // create consecutive spaces. Operates on rune positions matching the textinput
 // cursor model.
@@ -510,8 +565,15 @@ func (s *SuggestionTextInput) updateSuggestions() {
 		}
 	}
 
-	// sort suggestions by word length
+	// sort suggestions by score, then by word length
 	slices.SortFunc(s.suggestions, func(a, b string) int {
+		if scoreA, scoreB := s.suggestionScores[a], s.suggestionScores[b]; scoreA != scoreB {
+			if scoreA > scoreB {
+				return -1
+			}
+			return 1
+		}
+
 		if len(a) == len(b) {
 			return strings.Compare(a, b)
 		}
@@ -813,6 +875,8 @@ func (s *SuggestionTextInput) renderMultiLineView() string {
 	showUpArrow := s.viewOffset > 0
 	showDownArrow := endLine < totalLines
 
+	misspelled := s.misspelledRunes()
+
 	// Render each visible line
 	var result strings.Builder
 	promptPadding := strings.Repeat(" ", promptWidth)
@@ -844,11 +908,16 @@ func (s *SuggestionTextInput) renderMultiLineView() string {
 			result.WriteString(" ") // padding between line number and text
 		}
 
+		lineStart := 0
+		if actualLineIdx > 0 {
+			lineStart = breaks[actualLineIdx-1]
+		}
+
 		// Render line content with cursor if this is the cursor line
 		if actualLineIdx == cursorLine {
-			result.WriteString(s.renderLineWithCursor(line, cursorCol))
+			result.WriteString(s.renderLineWithCursor(line, lineStart, cursorCol, misspelled))
 		} else {
-			result.WriteString(s.InputModel.Styles().Focused.Text.Render(line))
+			result.WriteString(s.renderText([]rune(line), lineStart, misspelled))
 		}
 	}
 
@@ -881,7 +950,8 @@ func (s *SuggestionTextInput) cursorStyle() lipgloss.Style {
 }
 
 // renderLineWithCursor renders a single line with the cursor at the specified column.
-func (s *SuggestionTextInput) renderLineWithCursor(line string, cursorCol int) string {
+// lineStart is the rune offset of the line in the input value, used to underline misspelled words.
+func (s *SuggestionTextInput) renderLineWithCursor(line string, lineStart, cursorCol int, misspelled []bool) string {
 	runes := []rune(line)
 	lineLen := len(runes)
 	curStyle := s.cursorStyle()
@@ -889,7 +959,7 @@ func (s *SuggestionTextInput) renderLineWithCursor(line string, cursorCol int) s
 
 	// Cursor at end of line
 	if cursorCol >= lineLen {
-		rendered := textStyle.Render(line)
+		rendered := s.renderText(runes, lineStart, misspelled)
 		if s.InputModel.Focused() {
 			rendered += curStyle.Render(" ")
 		}
@@ -897,12 +967,10 @@ func (s *SuggestionTextInput) renderLineWithCursor(line string, cursorCol int) s
 	}
 
 	// Cursor within line
-	before := string(runes[:cursorCol])
 	cursorRune := string(runes[cursorCol])
-	after := string(runes[cursorCol+1:])
 
 	var result strings.Builder
-	result.WriteString(textStyle.Render(before))
+	result.WriteString(s.renderText(runes[:cursorCol], lineStart, misspelled))
 
 	if s.InputModel.Focused() {
 		result.WriteString(curStyle.Render(cursorRune))
@@ -910,7 +978,7 @@ func (s *SuggestionTextInput) renderLineWithCursor(line string, cursorCol int) s
 		result.WriteString(textStyle.Render(cursorRune))
 	}
 
-	result.WriteString(textStyle.Render(after))
+	result.WriteString(s.renderText(runes[cursorCol+1:], lineStart+cursorCol+1, misspelled))
 
 	return result.String()
 }
@@ -1,6 +1,7 @@
 package component
 
 import (
+	"slices"
 	"strings"
 	"testing"
 )
@@ -558,3 +559,67 @@ func TestSuggestionTextInput_SetValueCollapsesSpaces(t *testing.T) {
 		})
 	}
 }
+
+func TestSuggestionTextInput_SetSuggestionScores(t *testing.T) {
+	t.Parallel()
+
+	s := NewSuggestionTextInput(nil, nil)
+	s.SetSuggestions([]string{"KappaRoss", "KappaHD", "KappaPride", "Kappa"})
+
+	s.InputModel.SetValue("Kap")
+	s.InputModel.CursorEnd()
+	s.updateSuggestions()
+
+	if want := []string{"Kappa", "KappaHD", "KappaRoss", "KappaPride"}; !slices.Equal(s.suggestions, want) {
+		t.Fatalf("got suggestions %v, want %v", s.suggestions, want)
+	}
+
+	s.SetSuggestionScores(map[string]float64{
+		"KappaPride": 3,
+		"KappaRoss":  0.5,
+	})
+
+	if want := []string{"KappaPride", "KappaRoss", "Kappa", "KappaHD"}; !slices.Equal(s.suggestions, want) {
+		t.Fatalf("got suggestions %v, want %v", s.suggestions, want)
+	}
+}
+
+func TestSuggestionTextInput_InsertAtCursor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		value      string
+		cursor     int
+		insert     string
+		wantValue  string
+		wantCursor int
+	}{
+		{"empty input", "", 0, "KEKW", "KEKW ", 5},
+		{"end of input", "hello", 5, "KEKW", "hello KEKW ", 11},
+		{"end of input after space", "hello ", 6, "KEKW", "hello KEKW ", 11},
+		{"start of input", "hello", 0, "KEKW", "KEKW hello", 5},
+		{"between words", "hello world", 5, "KEKW", "hello KEKW world", 10},
+		{"inside word", "helloworld", 5, "KEKW", "hello KEKW world", 11},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := NewSuggestionTextInput(nil, nil)
+			s.InputModel.SetValue(tt.value)
+			s.InputModel.SetCursor(tt.cursor)
+
+			s.InsertAtCursor(tt.insert)
+
+			if got := s.InputModel.Value(); got != tt.wantValue {
+				t.Errorf("InsertAtCursor(%q) resulted in %q, want %q", tt.insert, got, tt.wantValue)
+			}
+
+			if got := s.InputModel.Position(); got != tt.wantCursor {
+				t.Errorf("InsertAtCursor(%q) moved cursor to %d, want %d", tt.insert, got, tt.wantCursor)
+			}
+		})
+	}
+}
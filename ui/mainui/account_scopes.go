@@ -0,0 +1,89 @@
+package mainui
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/server"
+	"github.com/julez-dev/chatuino/twitch/twitchapi"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+)
+
+// accountScopesMessage contains the scopes missing from the tokens of the accounts, by account ID.
+type accountScopesMessage struct {
+	missing map[string][]string
+}
+
+// checkAccountScopesCommand validates the tokens of all accounts at startup. Accounts created before a feature
+// needed a new scope fail those requests, so they are flagged to be authorized again.
+// Expired tokens are skipped, they are refreshed with the same scopes when used.
+func (r *Root) checkAccountScopesCommand() tea.Cmd {
+	accounts := r.dependencies.Accounts
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		return accountScopesMessage{missing: missingAccountScopes(ctx, accounts, twitchapi.ValidateTokenScopes)}
+	}
+}
+
+type tokenValidateFunc func(ctx context.Context, httpClient *http.Client, accessToken string) (twitchapi.TokenValidation, error)
+
+// missingAccountScopes returns the scopes missing from the tokens of the accounts, by account ID.
+func missingAccountScopes(ctx context.Context, accounts []save.Account, validate tokenValidateFunc) map[string][]string {
+	var (
+		mu      sync.Mutex
+		missing = map[string][]string{}
+		wg      errgroup.Group
+	)
+
+	for _, acc := range accounts {
+		if acc.IsAnonymous || acc.AccessToken == "" {
+			continue
+		}
+
+		wg.Go(func() error {
+			validation, err := validate(ctx, http.DefaultClient, acc.AccessToken)
+			if err != nil {
+				log.Logger.Err(err).Str("user_id", acc.ID).Msg("could not validate account token")
+				return nil
+			}
+
+			if !validation.Valid {
+				return nil
+			}
+
+			if scopes := server.MissingScopes(validation.Scopes); len(scopes) > 0 {
+				log.Logger.Warn().Str("user_id", acc.ID).Strs("missing", scopes).Msg("account token is missing scopes, the account needs to be authorized again")
+
+				mu.Lock()
+				missing[acc.ID] = scopes
+				mu.Unlock()
+			}
+
+			return nil
+		})
+	}
+
+	_ = wg.Wait()
+
+	return missing
+}
+
+func (r *Root) handleAccountScopes(msg accountScopesMessage) tea.Cmd {
+	r.missingScopes = msg.missing
+
+	for _, t := range r.tabs {
+		if bt, ok := t.(*broadcastTab); ok {
+			bt.missingScopes = r.missingScopes[bt.account.ID]
+		}
+	}
+
+	return nil
+}
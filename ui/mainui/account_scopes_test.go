@@ -0,0 +1,47 @@
+package mainui
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/server"
+	"github.com/julez-dev/chatuino/twitch/twitchapi"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissingAccountScopes(t *testing.T) {
+	t.Parallel()
+
+	accounts := []save.Account{
+		{ID: "anonymous", IsAnonymous: true},
+		{ID: "current", AccessToken: "current-token"},
+		{ID: "old", AccessToken: "old-token"},
+		{ID: "expired", AccessToken: "expired-token"},
+		{ID: "offline", AccessToken: "offline-token"},
+	}
+
+	validate := func(_ context.Context, _ *http.Client, token string) (twitchapi.TokenValidation, error) {
+		switch token {
+		case "current-token":
+			return twitchapi.TokenValidation{Valid: true, Scopes: server.Scopes()}, nil
+		case "old-token":
+			return twitchapi.TokenValidation{Valid: true, Scopes: []string{"chat:read", "chat:edit"}}, nil
+		case "offline-token":
+			return twitchapi.TokenValidation{}, errors.New("network down")
+		}
+
+		return twitchapi.TokenValidation{}, nil
+	}
+
+	missing := missingAccountScopes(t.Context(), accounts, validate)
+	require.Len(t, missing, 1)
+	require.NotContains(t, missing["old"], "chat:read")
+	require.Contains(t, missing["old"], "user:write:chat")
+
+	r := &Root{}
+	r.handleAccountScopes(accountScopesMessage{missing: missing})
+	require.Equal(t, missing, r.missingScopes)
+}
@@ -0,0 +1,187 @@
+package mainui
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/julez-dev/chatuino/save"
+	"github.com/rs/zerolog/log"
+)
+
+const blockUsage = "Expected Usage: /block [field=content|user|badge] [match=exact|contains|glob|regex] [channel=here|<channel>,...] [for=<duration>] <pattern>"
+
+// handleBlockCommand runs the /block, /unblock and /blocks commands. The block rules are shared by all tabs,
+// changes apply to new messages right away and are written to the settings file.
+// channel is the channel of the tab the command was sent in, used for channel=here.
+func handleBlockCommand(deps *DependencyContainer, name string, args []string, channel string, notice func(string) tea.Cmd) tea.Cmd {
+	args = slices.DeleteFunc(slices.Clone(args), func(s string) bool { return s == "" })
+	rules := deps.UserConfig.Settings.BlockSettings.Rules
+
+	switch name {
+	case "blocks":
+		if len(rules) == 0 {
+			return notice("No block rules, add one with /block <pattern>")
+		}
+
+		now := time.Now()
+		cmds := make([]tea.Cmd, 0, len(rules))
+		for i, r := range rules {
+			cmds = append(cmds, notice(describeBlockRule(i, r, now)))
+		}
+
+		return tea.Sequence(cmds...)
+	case "block":
+		rule, err := parseBlockRule(args, channel, time.Now())
+		if err != nil {
+			return notice(err.Error())
+		}
+
+		rules = append(slices.Clone(rules), rule)
+		deps.UserConfig.Settings.BlockSettings.Rules = rules
+
+		return tea.Sequence(
+			notice("Added "+describeBlockRule(len(rules)-1, rule, time.Now())),
+			saveBlockRulesCmd(deps, rules, notice),
+		)
+	case "unblock":
+		if len(args) == 0 {
+			return notice("Expected Usage: /unblock <number|pattern>, see /blocks for the numbers")
+		}
+
+		target := strings.Join(args, " ")
+		remaining := slices.Clone(rules)
+
+		if n, err := strconv.Atoi(target); err == nil && n >= 1 && n <= len(rules) {
+			remaining = slices.Delete(remaining, n-1, n)
+		} else {
+			remaining = slices.DeleteFunc(remaining, func(r save.BlockRule) bool {
+				return strings.EqualFold(r.Pattern, target)
+			})
+		}
+
+		removed := len(rules) - len(remaining)
+		if removed == 0 {
+			return notice(fmt.Sprintf("No block rule matches %q, see /blocks", target))
+		}
+
+		deps.UserConfig.Settings.BlockSettings.Rules = remaining
+
+		return tea.Sequence(
+			notice(fmt.Sprintf("Removed %d block rule(s)", removed)),
+			saveBlockRulesCmd(deps, remaining, notice),
+		)
+	}
+
+	return nil
+}
+
+func saveBlockRulesCmd(deps *DependencyContainer, rules []save.BlockRule, notice func(string) tea.Cmd) tea.Cmd {
+	if deps.BlockRules == nil {
+		return nil
+	}
+
+	store := deps.BlockRules
+	return func() tea.Msg {
+		if err := store.SaveBlockRules(rules); err != nil {
+			log.Logger.Err(err).Msg("failed to save block rules")
+			return notice(fmt.Sprintf("Block rules only apply until restart, could not save settings: %s", err))()
+		}
+
+		return nil
+	}
+}
+
+// parseBlockRule parses the arguments of /block. Leading key=value arguments are options, the remaining
+// arguments form the pattern.
+func parseBlockRule(args []string, channel string, now time.Time) (save.BlockRule, error) {
+	var rule save.BlockRule
+
+options:
+	for ; len(args) > 0; args = args[1:] {
+		key, value, ok := strings.Cut(args[0], "=")
+		if !ok {
+			break
+		}
+
+		switch key {
+		case "field":
+			rule.Field = strings.ToLower(value)
+		case "match":
+			rule.Match = strings.ToLower(value)
+		case "channel":
+			for c := range strings.SplitSeq(strings.ToLower(value), ",") {
+				c = strings.TrimPrefix(c, "#")
+				if c == "here" {
+					c = channel
+				}
+
+				if c != "" && !slices.Contains(rule.Channels, c) {
+					rule.Channels = append(rule.Channels, c)
+				}
+			}
+
+			if len(rule.Channels) == 0 {
+				return save.BlockRule{}, fmt.Errorf("channel=%s does not name a channel", value)
+			}
+		case "for":
+			d, err := parseBlockDuration(value)
+			if err != nil {
+				return save.BlockRule{}, err
+			}
+
+			expires := now.Add(d)
+			rule.Expires = &expires
+		default:
+			// not an option, e.g. a pattern containing =
+			break options
+		}
+	}
+
+	rule.Pattern = strings.Join(args, " ")
+	if rule.Pattern == "" {
+		return save.BlockRule{}, errors.New(blockUsage)
+	}
+
+	return save.NewBlockRule(rule)
+}
+
+// parseBlockDuration parses a Go duration, additionally accepting days like 7d.
+func parseBlockDuration(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return time.Duration(n) * 24 * time.Hour, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("for=%s must be a positive duration like 30m, 12h or 7d", s)
+	}
+
+	return d, nil
+}
+
+func describeBlockRule(i int, r save.BlockRule, now time.Time) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "#%d: %s %s %q", i+1, r.FieldOrDefault(), r.MatchOrDefault(), r.Pattern)
+
+	if len(r.Channels) == 0 {
+		b.WriteString(" in all channels")
+	} else {
+		b.WriteString(" in #" + strings.Join(r.Channels, ", #"))
+	}
+
+	switch {
+	case r.Expired(now):
+		b.WriteString(" (expired)")
+	case r.Expires != nil:
+		fmt.Fprintf(&b, " until %s", r.Expires.Local().Format("2006-01-02 15:04"))
+	}
+
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package mainui
+
+import (
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+	"github.com/stretchr/testify/require"
+)
+
+type stubBlockRuleStore struct {
+	saved []save.BlockRule
+}
+
+func (s *stubBlockRuleStore) SaveBlockRules(rules []save.BlockRule) error {
+	s.saved = rules
+	return nil
+}
+
+func TestParseBlockRule(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	rule, err := parseBlockRule([]string{"field=user", "match=glob", "channel=here,#Other", "for=7d", "spam*bot"}, "current", now)
+	require.NoError(t, err)
+	require.Equal(t, save.BlockFieldUser, rule.Field)
+	require.Equal(t, save.BlockMatchGlob, rule.Match)
+	require.Equal(t, []string{"current", "other"}, rule.Channels)
+	require.Equal(t, now.Add(7*24*time.Hour), *rule.Expires)
+	require.Equal(t, "spam*bot", rule.Pattern)
+
+	// arguments after the pattern belong to the pattern
+	rule, err = parseBlockRule([]string{"buy", "field=user"}, "current", now)
+	require.NoError(t, err)
+	require.Equal(t, "buy field=user", rule.Pattern)
+	require.Empty(t, rule.Field)
+
+	_, err = parseBlockRule([]string{"match=regex", "("}, "current", now)
+	require.Error(t, err)
+
+	_, err = parseBlockRule([]string{"for=soon", "x"}, "current", now)
+	require.Error(t, err)
+
+	_, err = parseBlockRule([]string{"field=user"}, "current", now)
+	require.ErrorContains(t, err, "Expected Usage")
+}
+
+func TestHandleBlockCommand(t *testing.T) {
+	t.Parallel()
+
+	store := &stubBlockRuleStore{}
+	deps := &DependencyContainer{BlockRules: store}
+	notice := func(string) tea.Cmd { return nil }
+
+	run := func(name string, args ...string) {
+		// without notices only the save command is left
+		cmd := handleBlockCommand(deps, name, args, "channel", notice)
+		require.NotNil(t, cmd)
+		require.Nil(t, cmd())
+	}
+
+	msg := &twitchirc.PrivateMessage{DisplayName: "Spammer", ChannelUserName: "channel", Message: "buy followers"}
+	require.False(t, messageMatchesBlocked(msg, deps.UserConfig.Settings.BlockSettings))
+
+	run("block", "channel=here", "followers")
+	require.True(t, messageMatchesBlocked(msg, deps.UserConfig.Settings.BlockSettings))
+	require.Len(t, store.saved, 1)
+
+	run("block", "field=badge", "turbo")
+	require.Len(t, deps.UserConfig.Settings.BlockSettings.Rules, 2)
+
+	run("unblock", "1")
+	require.False(t, messageMatchesBlocked(msg, deps.UserConfig.Settings.BlockSettings))
+	require.Equal(t, []string{"turbo"}, []string{store.saved[0].Pattern})
+
+	run("unblock", "TURBO")
+	require.Empty(t, deps.UserConfig.Settings.BlockSettings.Rules)
+	require.Empty(t, store.saved)
+}
+
+func TestMessageMatchesBlocked_Rules(t *testing.T) {
+	t.Parallel()
+
+	past := time.Now().Add(-time.Minute)
+	settings := save.BlockSettings{Rules: []save.BlockRule{
+		{Pattern: "spam*", Field: save.BlockFieldUser, Match: save.BlockMatchGlob},
+		{Pattern: "turbo", Field: save.BlockFieldBadge},
+		{Pattern: "expired", Expires: &past},
+		{Pattern: "scoped", Channels: []string{"other"}},
+	}}
+
+	blocked := []twitchirc.IRCer{
+		&twitchirc.PrivateMessage{DisplayName: "x", LoginName: "spambot", Message: "hi"},
+		&twitchirc.PrivateMessage{DisplayName: "x", Badges: []twitchirc.Badge{{Name: "turbo"}}, Message: "hi"},
+		&twitchirc.PrivateMessage{DisplayName: "x", ChannelUserName: "other", Message: "scoped"},
+		&twitchirc.SubMessage{UserNotice: twitchirc.UserNotice{DisplayName: "SpamBot"}},
+	}
+
+	allowed := []twitchirc.IRCer{
+		&twitchirc.PrivateMessage{DisplayName: "x", Message: "expired"},
+		&twitchirc.PrivateMessage{DisplayName: "x", ChannelUserName: "channel", Message: "scoped"},
+		&twitchirc.PrivateMessage{DisplayName: "spambot", Mod: true, Message: "hi"},
+	}
+
+	for _, msg := range blocked {
+		require.True(t, messageMatchesBlocked(msg, settings), "%+v", msg)
+	}
+
+	for _, msg := range allowed {
+		require.False(t, messageMatchesBlocked(msg, settings), "%+v", msg)
+	}
+}
@@ -2,6 +2,7 @@ package mainui
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
@@ -12,7 +13,6 @@ import (
 	"strings"
 	"text/template"
 	"time"
-	"unicode/utf8"
 
 	"github.com/google/uuid"
 	"github.com/jellydator/ttlcache/v3"
@@ -21,6 +21,7 @@ import (
 
 	"github.com/julez-dev/chatuino/emote"
 	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/save/messagelog"
 
 	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/spinner"
@@ -58,14 +59,18 @@ type setChannelDataMessage struct {
 	channel         string
 	channelID       string
 	initialMessages []twitchirc.IRCer
+	loggedMessages  int // number of initial messages loaded from local chat logs
+	recentErr       error
 	isUserMod       bool
+	isUserVIP       bool
 	modFetchErr     error
 }
 
 type emoteSetRefreshedMessage struct {
-	targetID string
-	err      error
-	manually bool
+	targetID    string
+	err         error
+	manually    bool
+	emoteScores map[string]float64
 }
 
 type broadcastTabState int
@@ -79,7 +84,7 @@ func (t broadcastTabState) String() string {
 	case 3:
 		return "Inspect / Insert"
 	case 4:
-		return "Emote Overview"
+		return "Emote Picker"
 	}
 
 	return "View"
@@ -112,6 +117,14 @@ type ModStatusFetcher interface {
 	GetModVIPList(ctx context.Context, channel string) (ivr.ModVIPResponse, error)
 }
 
+// modVIPStatus reports if the user is a moderator or VIP according to the list.
+func modVIPStatus(list ivr.ModVIPResponse, userID string) (isMod, isVIP bool) {
+	isMod = slices.ContainsFunc(list.Mods, func(u ivr.PrivilegedUser) bool { return u.ID == userID })
+	isVIP = slices.ContainsFunc(list.VIPs, func(u ivr.PrivilegedUser) bool { return u.ID == userID })
+
+	return isMod, isVIP
+}
+
 type broadcastTab struct {
 	id      string
 	account save.Account
@@ -122,14 +135,20 @@ type broadcastTab struct {
 	lastMessages     *ttlcache.Cache[string, struct{}]
 
 	isUserMod          bool
+	isUserVIP          bool // VIPs share the higher message rate limit of moderators
 	isModStatusAssumed bool // true when mod fetch failed; don't show "Mod" in status
 	focused            bool
 	updateInfo         *UpdateInfo
+	missingScopes      []string // the account needs to be authorized again
 
 	channelDataLoaded         bool
 	pendingChannelSuggestions []string
+	pendingInputHistory       *save.InputHistory // restored history, set once the message input is created
 	lastMessageSent           string
 	lastMessageSentAt         time.Time
+	emoteScores               map[string]float64        // ranking of emote suggestions by usage
+	replyTo                   *twitchirc.PrivateMessage // message the next sent message replies to
+	historyExhausted          bool                      // no older messages left in the local chat logs
 
 	channel      string
 	channelID    string
@@ -222,13 +241,18 @@ func (t *broadcastTab) InitWithUserData(userData twitchapi.UserData) tea.Cmd {
 
 		group, ctx := errgroup.WithContext(ctx)
 
-		var recentMessages []twitchirc.IRCer
+		var (
+			recentMessages []twitchirc.IRCer
+			recentErr      error
+		)
 		group.Go(func() error {
 			// fetch recent messages
 			msgs, err := t.deps.RecentMessageService.GetRecentMessagesFor(ctx, userData.Login)
 
 			// call sometimes timeouts, but recent message are not really that important to crash the tab, so ignore the error
+			// the local chat logs are used as fallback
 			if err != nil {
+				recentErr = err
 				return nil
 			}
 
@@ -237,7 +261,23 @@ func (t *broadcastTab) InitWithUserData(userData twitchapi.UserData) tea.Cmd {
 			return nil
 		})
 
-		var isUserMod bool
+		var loggedEntries []messagelog.LogEntry
+		group.Go(func() error {
+			if t.deps.MessageLogger == nil {
+				return nil
+			}
+
+			entries, err := t.deps.MessageLogger.RecentMessagesInChannel(userData.Login, loggedHistorySize)
+			if err != nil {
+				log.Logger.Err(err).Str("channel", userData.Login).Msg("failed to load logged messages for scrollback")
+				return nil
+			}
+
+			loggedEntries = entries
+			return nil
+		})
+
+		var isUserMod, isUserVIP bool
 		var modFetchErr error
 		group.Go(func() error {
 			modVips, err := t.modFetcher.GetModVIPList(ctx, userData.Login)
@@ -249,13 +289,7 @@ func (t *broadcastTab) InitWithUserData(userData twitchapi.UserData) tea.Cmd {
 				return nil
 			}
 
-			for _, mod := range modVips.Mods {
-				if mod.ID == t.account.ID {
-					isUserMod = true
-					break
-				}
-			}
-
+			isUserMod, isUserVIP = modVIPStatus(modVips, t.account.ID)
 			return nil
 		})
 
@@ -266,13 +300,18 @@ func (t *broadcastTab) InitWithUserData(userData twitchapi.UserData) tea.Cmd {
 			}
 		}
 
+		initialMessages, loggedMessages := mergeLoggedMessages(recentMessages, loggedEntries)
+
 		return setChannelDataMessage{
 			targetID:        t.id,
 			channelID:       userData.ID,
 			channel:         userData.DisplayName,
 			channelLogin:    userData.Login,
-			initialMessages: recentMessages,
+			initialMessages: initialMessages,
+			loggedMessages:  loggedMessages,
+			recentErr:       recentErr,
 			isUserMod:       isUserMod,
+			isUserVIP:       isUserVIP,
 			modFetchErr:     modFetchErr,
 		}
 	}
@@ -304,7 +343,7 @@ func (t *broadcastTab) refreshEmotes(login, channelID string, manually bool) tea
 		})
 
 		err := group.Wait()
-		if err != nil {
+		if err != nil && !errors.Is(err, emote.ErrPartialFetch) {
 			return emoteSetRefreshedMessage{
 				targetID: t.id,
 				err:      err,
@@ -312,9 +351,16 @@ func (t *broadcastTab) refreshEmotes(login, channelID string, manually bool) tea
 			}
 		}
 
+		var scores map[string]float64
+		if !t.account.IsAnonymous {
+			scores = loadEmoteScores(t.deps, channelID, login)
+		}
+
 		return emoteSetRefreshedMessage{
-			targetID: t.id,
-			manually: manually,
+			targetID:    t.id,
+			err:         err,
+			manually:    manually,
+			emoteScores: scores,
 		}
 	}
 }
@@ -370,6 +416,7 @@ func (t *broadcastTab) Update(msg tea.Msg) (tab, tea.Cmd) {
 		t.streamInfo = newStreamInfo(msg.channelID, t.deps.APIUserClients[t.account.ID], t.width)
 		t.poll = newPoll(t.width)
 		t.chatWindow = newChatWindow(t.width, t.height, t.deps)
+		t.chatWindow.scrollbackID = t.id
 
 		t.messageInput = component.NewSuggestionTextInput(t.chatWindow.userColorCache, t.deps.UserConfig.Settings.BuildCustomSuggestionMap())
 		t.messageInput.EmoteReplacer = t.deps.EmoteReplacer // enable emote replacement
@@ -378,16 +425,27 @@ func (t *broadcastTab) Update(msg tea.Msg) (tab, tea.Cmd) {
 		t.messageInput.InputModel.SetStyles(msgInputStyles)
 		t.messageInput.SetMaxVisibleLines(3) // allow input to grow up to 3 lines
 
+		// long messages are split when sent
+		t.messageInput.InputModel.CharLimit = twitchMessageLimit * maxMessageParts
+		t.messageInput.SpellChecker = newEmoteSpellChecker(t.deps, func() string { return t.channelID })
+		t.messageInput.MisspelledStyle = t.messageInput.MisspelledStyle.UnderlineColor(lipgloss.Color(t.deps.UserConfig.Theme.ChatErrorColor))
+
 		if len(t.pendingChannelSuggestions) > 0 {
 			t.messageInput.SetChannelSuggestions(t.pendingChannelSuggestions)
 			t.pendingChannelSuggestions = nil
 		}
 
+		if t.pendingInputHistory != nil {
+			t.setInputHistory(*t.pendingInputHistory)
+			t.pendingInputHistory = nil
+		}
+
 		t.statusInfo = newStreamStatus(t.width, t.height, t, t.account.ID, msg.channelID, t.deps)
 
 		// set chat suggestions if non-anonymous user
 		if !t.account.IsAnonymous {
 			t.isUserMod = msg.isUserMod
+			t.isUserVIP = msg.isUserVIP
 			t.isModStatusAssumed = msg.modFetchErr != nil
 
 			// if user is broadcaster, allow mod commands
@@ -407,6 +465,7 @@ func (t *broadcastTab) Update(msg tea.Msg) (tab, tea.Cmd) {
 		}
 
 		ircCmds := make([]tea.Cmd, 0, 3)
+		recentCount := len(msg.initialMessages) - msg.loggedMessages
 
 		if msg.modFetchErr != nil {
 			msg.initialMessages = append(msg.initialMessages, &twitchirc.Notice{
@@ -422,7 +481,7 @@ func (t *broadcastTab) Update(msg tea.Msg) (tab, tea.Cmd) {
 			FakeTimestamp:   time.Now(),
 			ChannelUserName: t.channelLogin,
 			MsgID:           twitchirc.MsgID(uuid.NewString()),
-			Message:         fmt.Sprintf("Loaded %d recent messages; powered by https://recent-messages.robotty.de", len(msg.initialMessages)),
+			Message:         initialMessagesNotice(recentCount, msg.loggedMessages, msg.recentErr),
 		})
 
 		// Pass recent messages, recorded before the application was started, to chat window
@@ -529,6 +588,9 @@ func (t *broadcastTab) Update(msg tea.Msg) (tab, tea.Cmd) {
 			suggestions := slices.Collect(maps.Keys(unique))
 			t.messageInput.SetSuggestions(suggestions)
 
+			t.emoteScores = msg.emoteScores
+			t.messageInput.SetSuggestionScores(t.emoteScores)
+
 			// notify user if not all emotes could be fetched
 			if errors.Is(msg.err, emote.ErrPartialFetch) {
 				return t, func() tea.Msg {
@@ -574,6 +636,29 @@ func (t *broadcastTab) Update(msg tea.Msg) (tab, tea.Cmd) {
 		}
 		cmd = t.handleEventSubMessage(msg.Message)
 		return t, cmd
+	case chatScrolledPastTopMessage:
+		if !t.channelDataLoaded || msg.owner != t.chatWindow {
+			return t, nil
+		}
+
+		return t, t.loadOlderMessages(msg.before)
+	case chatHistoryLoadedMessage:
+		if msg.tabID != t.id {
+			return t, nil
+		}
+
+		return t, t.handleOlderMessagesLoaded(msg)
+	case linkPreviewMessage:
+		// the windows only annotate messages they show, no need to check account and channel
+		if t.chatWindow != nil {
+			t.chatWindow.handleLinkPreview(msg)
+		}
+
+		if t.userInspect != nil {
+			t.userInspect.chatWindow.handleLinkPreview(msg)
+		}
+
+		return t, nil
 	case chatEventMessage: // delegate message event to chat window
 		// ignore all messages that don't target this account and channel
 
@@ -623,6 +708,13 @@ func (t *broadcastTab) Update(msg tea.Msg) (tab, tea.Cmd) {
 		if t.focused {
 			switch msg := msg.(type) {
 			case tea.KeyPressMsg:
+				// All keys belong to the input history search until it ends
+				if (t.state == insertMode || t.state == userInspectInsertMode) && t.messageInput.IsSearchingHistory() {
+					t.messageInput, cmd = t.messageInput.Update(msg)
+					t.HandleResize()
+					return t, cmd
+				}
+
 				// Focus message input, when not in insert mode and not in search mode inside chat window, depending on the current active chat window
 				if key.Matches(msg, t.deps.Keymap.InsertMode) &&
 					(t.state == inChatWindow && t.chatWindow.state != searchChatWindowState || t.state == userInspectMode && t.userInspect.chatWindow.state != searchChatWindowState) {
@@ -638,6 +730,16 @@ func (t *broadcastTab) Update(msg tea.Msg) (tab, tea.Cmd) {
 					return t, tea.Batch(cmds...)
 				}
 
+				// Open emote picker, the picked emote is inserted into the message input
+				if key.Matches(msg, t.deps.Keymap.EmotePicker) && (t.state == inChatWindow && t.chatWindow.state != searchChatWindowState || t.state == insertMode) {
+					return t, t.handleOpenEmoteOverview()
+				}
+
+				// Insert emote selected in emote picker
+				if key.Matches(msg, t.deps.Keymap.Confirm) && t.state == emoteOverviewMode {
+					return t, t.handleInsertPickedEmote()
+				}
+
 				// Open chat in browser
 				if key.Matches(msg, t.deps.Keymap.ChatPopUp, t.deps.Keymap.ChannelPopUp) && (t.state == inChatWindow || t.state == userInspectMode) {
 					return t, t.handleOpenBrowser(msg)
@@ -673,6 +775,16 @@ func (t *broadcastTab) Update(msg tea.Msg) (tab, tea.Cmd) {
 					return t, nil
 				}
 
+				// Reply to selected message
+				if key.Matches(msg, t.deps.Keymap.Reply) && (t.state == inChatWindow || t.state == userInspectMode) {
+					return t, t.handleReplyToMessage()
+				}
+
+				// Filter chat to the reply thread of the selected message
+				if key.Matches(msg, t.deps.Keymap.ShowThread) && t.state == inChatWindow {
+					return t, t.handleShowThread()
+				}
+
 				// Copy selected message to message input
 				if key.Matches(msg, t.deps.Keymap.CopyMessage) && (t.state == inChatWindow || t.state == userInspectMode) {
 					t.handleCopyMessage()
@@ -688,6 +800,12 @@ func (t *broadcastTab) Update(msg tea.Msg) (tab, tea.Cmd) {
 						return t, tea.Batch(cmds...)
 					}
 
+					// end filtering in emote picker before closing it
+					if t.state == emoteOverviewMode && t.emoteOverview != nil && t.emoteOverview.isFiltering() {
+						t.emoteOverview.stopFiltering()
+						return t, nil
+					}
+
 					// second case, end inspect mode or end insert mode in inspect window
 					if t.state == userInspectMode || t.state == userInspectInsertMode {
 						t.handleEscapePressed()
@@ -925,6 +1043,10 @@ func (t *broadcastTab) IsSearching() bool {
 		return true
 	}
 
+	if t.state == emoteOverviewMode && t.emoteOverview != nil && t.emoteOverview.isFiltering() {
+		return true
+	}
+
 	return t.userInspect != nil && t.userInspect.chatWindow.state == searchChatWindowState
 }
 
@@ -963,6 +1085,7 @@ func (t *broadcastTab) handleEscapePressed() {
 		t.state = userInspectMode
 		t.userInspect.chatWindow.Focus()
 		t.messageInput.Blur()
+		t.replyTo = nil
 		return
 	}
 
@@ -970,6 +1093,7 @@ func (t *broadcastTab) handleEscapePressed() {
 		t.state = inChatWindow
 		t.chatWindow.Focus()
 		t.messageInput.Blur()
+		t.replyTo = nil
 	}
 }
 
@@ -1336,6 +1460,12 @@ func (t *broadcastTab) shouldIgnoreMessage(msg twitchirc.IRCer) bool {
 func (t *broadcastTab) handleMessageSent(quickSend bool) tea.Cmd {
 	input := t.messageInput.Value()
 
+	var replyMessageID string
+	if t.replyTo != nil {
+		replyMessageID = t.replyTo.ID
+		t.replyTo = nil
+	}
+
 	if !quickSend {
 		// reset state
 		if t.state == userInspectInsertMode {
@@ -1394,6 +1524,10 @@ func (t *broadcastTab) handleMessageSent(quickSend bool) tea.Cmd {
 			return t.handleManualRefreshEmotes()
 		case "join":
 			return t.handleJoinCommand(args)
+		case "block", "unblock", "blocks":
+			return handleBlockCommand(t.deps, commandName, args, t.channelLogin, t.noticeCmd)
+		case "twitchblock", "twitchunblock":
+			return handleTwitchBlockCommand(t.deps, t.account.ID, commandName == "twitchblock", args, t.noticeCmd)
 		}
 
 		if !t.isUserMod {
@@ -1418,10 +1552,28 @@ func (t *broadcastTab) handleMessageSent(quickSend bool) tea.Cmd {
 		return handleCommand(commandName, args, channelID, channel, accountID, client)
 	}
 
-	// Check if message is the same as the last message sent
-	// If so, append special character to bypass twitch duplicate message filter
-	if strings.EqualFold(input, t.lastMessageSent) {
-		input = input + " " + string(duplicateBypass)
+	sentEmotes := emotesInMessage(t.deps.EmoteCache, t.channelID, input)
+	if len(sentEmotes) > 0 {
+		if t.emoteScores == nil {
+			t.emoteScores = map[string]float64{}
+		}
+
+		// a new use counts for the global and channel usage, see save.EmoteUsageEntry.Score. Emotes the user
+		// did not send before only have a chat score below 1, see loadEmoteScores.
+		for _, e := range sentEmotes {
+			t.emoteScores[e] = max(t.emoteScores[e], 1) + 2
+		}
+
+		t.messageInput.SetSuggestionScores(t.emoteScores)
+	}
+
+	// Long messages are split into multiple messages, repeated messages get a special character appended
+	// to bypass the twitch duplicate message filter
+	parts := messageParts(input, t.lastMessageSent)
+
+	delay := messageDelay
+	if t.isUserMod || t.isUserVIP {
+		delay = elevatedMessageDelay
 	}
 
 	lastSent := t.lastMessageSentAt
@@ -1430,52 +1582,54 @@ func (t *broadcastTab) handleMessageSent(quickSend bool) tea.Cmd {
 	userID := t.account.ID
 
 	cmd := func() tea.Msg {
-		const delay = time.Second
-		diff := time.Since(lastSent)
-		if diff < delay {
-			time.Sleep(delay - diff)
-		}
-
-		notice := &twitchirc.Notice{
-			FakeTimestamp: time.Now(),
-		}
-
-		resp := chatEventMessage{
-			isFakeEvent: true,
-			accountID:   userID,
-			channel:     t.channelLogin,
-			channelID:   t.channelID,
-			tabID:       t.id,
-			message:     notice,
-		}
-
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-		defer cancel()
-
-		r, err := client.SendChatMessage(ctx, twitchapi.SendChatMessageRequest{
-			BroadcasterID: broadcasterID,
-			SenderID:      userID,
-			Message:       input,
-		})
+		err := sendChatMessageParts(client, twitchapi.SendChatMessageRequest{
+			BroadcasterID:  broadcasterID,
+			SenderID:       userID,
+			ReplyMessageID: replyMessageID,
+		}, parts, lastSent, delay)
 		if err != nil {
-			notice.Message = fmt.Sprintf("Could not send message: %s", err.Error())
-			return resp
+			return chatEventMessage{
+				isFakeEvent: true,
+				accountID:   userID,
+				channel:     t.channelLogin,
+				channelID:   t.channelID,
+				tabID:       t.id,
+				message: &twitchirc.Notice{
+					FakeTimestamp: time.Now(),
+					Message:       fmt.Sprintf("Could not send message: %s", err.Error()),
+				},
+			}
 		}
 
-		if len(r.Data) > 0 && !r.Data[0].IsSent {
-			notice.Message = fmt.Sprintf("Could not send message: %s", r.Data[0].DropReason.Message)
-			return resp
+		if t.deps.EmoteUsage != nil {
+			if err := t.deps.EmoteUsage.RecordUsage(broadcasterID, sentEmotes); err != nil {
+				log.Logger.Err(err).Msg("failed to record emote usage")
+			}
 		}
 
 		return nil
 	}
 
-	t.lastMessageSent = input
-	t.lastMessageSentAt = time.Now()
+	// the parts are sent in the background, count the time from the last part
+	t.lastMessageSent = parts[len(parts)-1]
+	t.lastMessageSentAt = time.Now().Add(time.Duration(len(parts)-1) * delay)
 
 	return cmd
 }
 
+func (t *broadcastTab) noticeCmd(notice string) tea.Cmd {
+	return func() tea.Msg {
+		return requestLocalMessageHandleMessage{
+			tabID:     t.id,
+			accountID: t.account.ID,
+			message: &twitchirc.Notice{
+				FakeTimestamp: time.Now(),
+				Message:       notice,
+			},
+		}
+	}
+}
+
 func (t *broadcastTab) handleCreateClipMessage() tea.Cmd {
 	return func() tea.Msg {
 		api, ok := t.deps.APIUserClients[t.account.ID].(userAuthenticatedAPIClient)
@@ -1572,9 +1726,182 @@ func (t *broadcastTab) handleCopyMessage() {
 
 	t.messageInput.Focus()
 	t.messageInput.SetValue(strings.ReplaceAll(msg.Message, string(duplicateBypass), ""))
+	t.replyTo = nil
 	t.HandleResize() // Recalculate layout after copying message
 }
 
+func (t *broadcastTab) handleReplyToMessage() tea.Cmd {
+	if t.account.IsAnonymous {
+		return nil
+	}
+
+	cw := t.chatWindow
+	if t.state == userInspectMode {
+		cw = t.userInspect.chatWindow
+	}
+
+	_, entry := cw.entryForCurrentCursor()
+	if entry == nil {
+		return nil
+	}
+
+	msg, ok := entry.Event.message.(*twitchirc.PrivateMessage)
+	if !ok || msg.ID == "" {
+		return nil
+	}
+
+	if cw.state == searchChatWindowState {
+		cw.handleStopSearchModeKeepSelected()
+	}
+	cw.Blur()
+
+	if t.state == userInspectMode {
+		t.state = userInspectInsertMode
+	} else {
+		t.state = insertMode
+	}
+
+	t.replyTo = msg
+	t.messageInput.Focus()
+	t.HandleResize()
+
+	return t.messageInput.InputModel.Focus()
+}
+
+// loadOlderMessages pages older messages back into chat. Messages spilled to the scrollback are restored first,
+// afterwards messages sent before the oldest message in chat are fetched from the local chat logs.
+func (t *broadcastTab) loadOlderMessages(before time.Time) tea.Cmd {
+	if t.chatWindow.loadingHistory {
+		return nil
+	}
+
+	popSpilled := t.chatWindow.popSpilled(loggedHistoryPageSize)
+	loadLogged := t.deps.MessageLogger != nil && !t.historyExhausted
+
+	if popSpilled == nil && !loadLogged {
+		return nil
+	}
+
+	t.chatWindow.loadingHistory = true
+
+	var (
+		tabID        = t.id
+		accountID    = t.account.ID
+		channelLogin = t.channelLogin
+		logger       = t.deps.MessageLogger
+	)
+
+	return func() tea.Msg {
+		var scrollbackEmpty bool
+
+		if popSpilled != nil {
+			spilled, err := popSpilled()
+			if err != nil {
+				return chatHistoryLoadedMessage{
+					tabID: tabID,
+					err:   fmt.Errorf("could not restore older messages: %w", err),
+				}
+			}
+
+			if len(spilled) > 0 {
+				messages, deletedIDs := scrollbackEntriesToMessages(spilled)
+				return requestLocalHistoryHandleMessage{
+					tabID:      tabID,
+					accountID:  accountID,
+					messages:   messages,
+					deletedIDs: deletedIDs,
+					popped:     len(spilled),
+				}
+			}
+
+			scrollbackEmpty = true
+		}
+
+		if !loadLogged {
+			return chatHistoryLoadedMessage{tabID: tabID, scrollbackEmpty: scrollbackEmpty}
+		}
+
+		entries, err := logger.MessagesInChannelBefore(channelLogin, before, loggedHistoryPageSize)
+		if err != nil {
+			return chatHistoryLoadedMessage{
+				tabID:           tabID,
+				scrollbackEmpty: scrollbackEmpty,
+				err:             fmt.Errorf("could not load older messages: %w", err),
+			}
+		}
+
+		return requestLocalHistoryHandleMessage{
+			tabID:           tabID,
+			accountID:       accountID,
+			messages:        loggedEntriesToMessages(entries, nil),
+			scrollbackEmpty: scrollbackEmpty,
+		}
+	}
+}
+
+func (t *broadcastTab) handleOlderMessagesLoaded(msg chatHistoryLoadedMessage) tea.Cmd {
+	t.chatWindow.loadingHistory = false
+	t.chatWindow.restoredSpilled(msg.popped, msg.scrollbackEmpty)
+
+	var notice string
+	switch {
+	case msg.err != nil:
+		notice = msg.err.Error()
+	case len(msg.events) == 0:
+		t.historyExhausted = true
+		notice = "No older messages in local chat logs"
+	}
+
+	if notice != "" {
+		return func() tea.Msg {
+			return requestLocalMessageHandleMessage{
+				tabID:     t.id,
+				accountID: t.account.ID,
+				message: &twitchirc.Notice{
+					FakeTimestamp: time.Now(),
+					Message:       notice,
+				},
+			}
+		}
+	}
+
+	events := make([]chatEventMessage, 0, len(msg.events))
+	for _, e := range msg.events {
+		if t.shouldIgnoreMessage(e.message) {
+			continue
+		}
+
+		events = append(events, e)
+	}
+
+	t.chatWindow.prependEntries(events, msg.deletedIDs)
+	return nil
+}
+
+func (t *broadcastTab) handleShowThread() tea.Cmd {
+	_, entry := t.chatWindow.entryForCurrentCursor()
+	if entry == nil {
+		return nil
+	}
+
+	msg, ok := entry.Event.message.(*twitchirc.PrivateMessage)
+	if !ok {
+		return nil
+	}
+
+	// replies reference the first message of the thread, other messages may start a new thread
+	threadID := msg.ThreadParentMsgID
+	if threadID == "" {
+		threadID = cmp.Or(msg.ParentMsgID, msg.ID)
+	}
+
+	if threadID == "" {
+		return nil
+	}
+
+	return t.chatWindow.showThread(threadID)
+}
+
 func (t *broadcastTab) handleOpenUserInspect(args []string) tea.Cmd {
 	var cmds []tea.Cmd
 
@@ -1677,6 +2004,7 @@ func (t *broadcastTab) handleTimeoutShortcut() {
 
 	t.messageInput.Focus()
 	t.messageInput.SetValue("/timeout " + msg.DisplayName + " 600")
+	t.replyTo = nil
 	t.HandleResize() // Recalculate layout after setting timeout command
 }
 
@@ -1690,18 +2018,16 @@ func (t *broadcastTab) renderMessageInput() string {
 
 	// Labels
 	topLabel := "[ Chat ]"
-	charCount := fmt.Sprintf("[ %d / %d ]", utf8.RuneCountInString(t.messageInput.InputModel.Value()), t.messageInput.InputModel.CharLimit)
+	if t.replyTo != nil {
+		topLabel = "[ Reply to @" + t.replyTo.DisplayName + " ]"
+	}
 
 	innerWidth := t.width - 2 // -2 for left/right border chars
 
 	// Top border: ┌─[ Chat ]─────...─┐
-	topFill := innerWidth - len(topLabel) - 2
+	topFill := max(innerWidth-lipgloss.Width(topLabel)-2, 0)
 	topBorder := "┌─" + topLabel + strings.Repeat("─", topFill) + "─┐"
 
-	// Bottom border: └─────...─[ 7 / 500 ]─┘ (counter on RIGHT)
-	bottomFill := innerWidth - len(charCount) - 2
-	bottomBorder := "└─" + strings.Repeat("─", bottomFill) + charCount + "─┘"
-
 	// Wrap input lines with │ borders
 	inputLines := strings.Split(inputView, "\n")
 	var borderedLines []string
@@ -1713,7 +2039,7 @@ func (t *broadcastTab) renderMessageInput() string {
 	// Combine
 	result := borderStyle.Render(topBorder) + "\n"
 	result += borderStyle.Render(strings.Join(borderedLines, "\n")) + "\n"
-	result += borderStyle.Render(bottomBorder)
+	result += renderInputBottomBorder(innerWidth, t.messageInput.Value(), borderStyle, t.deps.UserConfig.Theme)
 
 	return result
 }
@@ -1994,17 +2320,36 @@ func (t *broadcastTab) handleOpenEmoteOverview() tea.Cmd {
 	}
 
 	t.state = emoteOverviewMode
+	t.messageInput.Blur()
+	t.chatWindow.Blur()
 
 	if t.emoteOverview != nil {
 		t.HandleResize()
 		return nil
 	}
 
-	t.emoteOverview = NewEmoteOverview(t.channelID, t.deps.EmoteCache, t.deps.EmoteReplacer, t.width, t.height)
+	t.emoteOverview = NewEmoteOverview(t.channelID, t.channelLogin, t.account.ID, t.deps, t.width, t.height)
 	t.HandleResize()
 	return t.emoteOverview.Init()
 }
 
+// handleInsertPickedEmote inserts the emote selected in the emote picker at the cursor of the message input
+// and switches to insert mode.
+func (t *broadcastTab) handleInsertPickedEmote() tea.Cmd {
+	text, ok := t.emoteOverview.selectedEmote()
+	if !ok {
+		return nil
+	}
+
+	t.emoteOverview.stopFiltering()
+	t.state = insertMode
+	t.messageInput.InsertAtCursor(text)
+	t.messageInput.Focus()
+	t.HandleResize()
+
+	return t.messageInput.InputModel.Focus()
+}
+
 func (t *broadcastTab) handleManualRefreshEmotes() tea.Cmd {
 	if t.account.IsAnonymous {
 		return nil
@@ -2043,7 +2388,31 @@ func (t *broadcastTab) Blur() {
 	}
 }
 
+func (t *broadcastTab) inputHistory() (save.InputHistory, bool) {
+	if t.messageInput == nil {
+		if t.pendingInputHistory != nil {
+			return *t.pendingInputHistory, true
+		}
+
+		return save.InputHistory{}, false
+	}
+
+	return save.InputHistory{Entries: t.messageInput.History(), Draft: t.messageInput.Value()}, true
+}
+
+func (t *broadcastTab) setInputHistory(h save.InputHistory) {
+	if t.messageInput == nil {
+		t.pendingInputHistory = &h
+		return
+	}
+
+	t.messageInput.SetHistory(h.Entries)
+	t.messageInput.SetValue(h.Draft)
+}
+
 func (t *broadcastTab) close() {
+	t.chatWindow.dropScrollback()
+
 	t.lastMessages.DeleteAll()
 	t.lastMessages.Stop()
 	t.lastMessages = nil
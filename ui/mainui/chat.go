@@ -15,16 +15,19 @@ import (
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
 	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/save/messagelog"
 	"github.com/julez-dev/chatuino/search"
 	"github.com/julez-dev/chatuino/twitch/twitchirc"
+	"github.com/julez-dev/reflow/truncate"
 	"github.com/julez-dev/reflow/wordwrap"
 	"github.com/julez-dev/reflow/wrap"
 	"github.com/rs/zerolog/log"
 )
 
 const (
-	cleanupAfterMessage float64 = 800.0
-	cleanupThreshold            = int(cleanupAfterMessage * 1.5)
+	// spillChunkDivisor controls how many entries above the scrollback limit are collected before spilling,
+	// so lines are only recalculated once per chunk instead of on every message.
+	spillChunkDivisor = 4
 	// prefixPadding               = 41
 	prefixPadding = 0
 
@@ -48,6 +51,13 @@ func (c *chatWindow) smoothScrollTickCmd() tea.Cmd {
 	})
 }
 
+// chatScrolledPastTopMessage is sent when the user tries to move above the oldest message in a chat window.
+// before is the time of the oldest chat message, older messages may be loaded and prepended.
+type chatScrolledPastTopMessage struct {
+	owner  *chatWindow
+	before time.Time
+}
+
 type chatEntry struct {
 	Position   position
 	Selected   bool
@@ -97,6 +107,13 @@ type chatWindow struct {
 	// Every single row, multiple rows may be part of a single message
 	lines []string
 
+	// scrollbackID identifies the chat in the scrollback, older entries are only spilled to disk when set.
+	// Without an ID entries beyond the scrollback limit are dropped.
+	scrollbackID    string
+	spilledEntries  int              // number of entries currently stored in the scrollback
+	loadingHistory  bool             // older entries are being loaded and will be prepended
+	scrollbackQueue *scrollbackQueue // orders the scrollback I/O done in commands
+
 	// optimize color rendering by caching render functions
 	// so we don't need to recreate a new lipgloss.Style for every message
 	userColorCache map[string]func(...string) string
@@ -169,6 +186,7 @@ func newChatWindow(width, height int, deps *DependencyContainer) *chatWindow {
 		timePaddingWidth: timePadWidth,
 		timeFormatWidth:  timeFormatWidth,
 		searchInput:      input,
+		scrollbackQueue:  newScrollbackQueue(),
 
 		indicator:           indicator,
 		indicatorWidth:      lipgloss.Width(indicator),
@@ -215,6 +233,9 @@ func (c *chatWindow) Update(msg tea.Msg) (*chatWindow, tea.Cmd) {
 		return c, c.smoothScrollTickCmd()
 	case chatEventMessage:
 		return c, c.handleMessage(msg)
+	case linkPreviewMessage:
+		c.handleLinkPreview(msg)
+		return c, nil
 	case tea.KeyPressMsg:
 		if c.focused {
 			switch {
@@ -239,9 +260,13 @@ func (c *chatWindow) Update(msg tea.Msg) (*chatWindow, tea.Cmd) {
 				c.snapScroll()
 				return c, nil
 			case key.Matches(msg, c.deps.Keymap.Up):
+				if i, _ := c.entryForCurrentCursor(); i == 0 && c.state == viewChatWindowState {
+					cmd = c.scrolledPastTopCmd()
+				}
+
 				c.messageUp(1)
 				c.snapScroll()
-				return c, nil
+				return c, cmd
 			case key.Matches(msg, c.deps.Keymap.GoToBottom):
 				c.moveToBottom()
 				c.snapScroll()
@@ -274,6 +299,16 @@ func (c *chatWindow) handleStartSearchMode() tea.Cmd {
 	return c.searchInput.Focus()
 }
 
+// showThread filters the chat to a reply thread using the thread search filter.
+func (c *chatWindow) showThread(threadID string) tea.Cmd {
+	cmd := c.handleStartSearchMode()
+	c.searchInput.SetValue("thread:" + threadID)
+	c.searchInput.CursorEnd()
+	c.applySearch()
+	c.snapScroll()
+	return cmd
+}
+
 func (c *chatWindow) handleStopSearchModeKeepSelected() {
 	_, e := c.entryForCurrentCursor()
 
@@ -579,6 +614,54 @@ func (c *chatWindow) messageUp(n int) {
 	c.updatePort()
 }
 
+func (c *chatWindow) scrolledPastTopCmd() tea.Cmd {
+	before := time.Now()
+	for _, e := range c.entries {
+		if privateMsg, ok := e.Event.message.(*twitchirc.PrivateMessage); ok {
+			before = privateMsg.TMISentTS
+			break
+		}
+	}
+
+	return func() tea.Msg {
+		return chatScrolledPastTopMessage{owner: c, before: before}
+	}
+}
+
+// prependEntries adds older events before all existing entries while keeping the current selection.
+// Private messages with an ID in deletedIDs are shown as deleted.
+func (c *chatWindow) prependEntries(events []chatEventMessage, deletedIDs map[string]struct{}) {
+	if len(events) == 0 {
+		return
+	}
+
+	_, selected := c.entryForCurrentCursor()
+
+	entries := make([]*chatEntry, 0, len(events)+len(c.entries))
+	for _, event := range events {
+		entry := &chatEntry{Event: event}
+		if privateMsg, ok := event.message.(*twitchirc.PrivateMessage); ok {
+			_, entry.IsDeleted = deletedIDs[privateMsg.ID]
+		}
+
+		if c.state == searchChatWindowState && c.currentMatcher != nil && !c.entryMatchesSearch(entry) {
+			entry.IsFiltered = true
+		}
+
+		entries = append(entries, entry)
+	}
+
+	c.entries = append(entries, c.entries...)
+	c.invalidateFilteredEntries()
+	c.recalculateLines()
+
+	if selected != nil {
+		c.cursor = selected.Position.CursorStart
+		c.updatePort()
+		c.snapScroll()
+	}
+}
+
 func (c *chatWindow) moveToBottom() {
 	i, currentEntry := c.entryForCurrentCursor()
 
@@ -609,24 +692,56 @@ func (c *chatWindow) getNewestEntry() *chatEntry {
 	return nil
 }
 
-func (c *chatWindow) cleanup() {
-	// todo: make this smarter, so we can delete more often
-	// c.logger.Info().Msgf("(%d/%d)", len(c.entries), cleanupThreshold)
-	if len(c.entries) < cleanupThreshold {
-		return
+// spill moves the oldest entries out of memory once the chat holds more entries than the scrollback limit.
+// Entries in view or selected are kept, so scrolling up or searching is not interrupted.
+// The returned command writes the entries to the scrollback.
+func (c *chatWindow) spill() tea.Cmd {
+	limit := c.deps.UserConfig.Settings.Chat.ScrollbackLimit()
+	if len(c.entries) < limit+limit/spillChunkDivisor || c.loadingHistory {
+		return nil
 	}
 
-	if c.state == searchChatWindowState {
-		return
+	n := c.spillableEntries(len(c.entries) - limit)
+	if n == 0 {
+		return nil
 	}
 
-	if e := c.getNewestEntry(); e == nil || !e.Selected {
-		log.Logger.Info().Msg("skip cleanup because not on newest message")
-		return
+	var removedLines int
+	scrollbackEntries := make([]messagelog.ScrollbackEntry, 0, n)
+	for _, e := range c.entries[:n] {
+		if !e.IsFiltered {
+			removedLines += e.Position.CursorEnd - e.Position.CursorStart + 1
+		}
+
+		scrollbackEntries = append(scrollbackEntries, messagelog.ScrollbackEntry{
+			Message:   e.Event.message,
+			IsDeleted: e.IsDeleted,
+		})
 	}
 
-	log.Logger.Info().Int("cleanup-after", int(cleanupAfterMessage)).Int("len", len(c.entries)).Msg("cleanup")
-	c.entries = c.entries[int(cleanupAfterMessage):]
+	var cmd tea.Cmd
+	if c.deps.Scrollback != nil && c.scrollbackID != "" {
+		// counted right away, a failed push is noticed when popping returns fewer entries
+		c.spilledEntries += n
+
+		scrollback, id := c.deps.Scrollback, c.scrollbackID
+		cmd = c.scrollbackQueue.cmd(func() tea.Msg {
+			if err := scrollback.Push(id, scrollbackEntries); err != nil {
+				log.Logger.Err(err).Str("scrollback", id).Msg("failed to spill chat entries, dropping them")
+			}
+
+			return nil
+		})
+	}
+
+	log.Logger.Debug().Int("spilled", n).Int("len", len(c.entries)).Msg("spill chat entries")
+
+	c.entries = slices.Delete(c.entries, 0, n)
+
+	// keep the viewport on the same messages
+	c.lineStart = max(c.lineStart-removedLines, 0)
+	c.smoothLineStart = max(c.smoothLineStart-float64(removedLines), 0)
+
 	c.invalidateFilteredEntries()
 	c.recalculateLines()
 
@@ -642,27 +757,89 @@ func (c *chatWindow) cleanup() {
 	for user := range c.userColorCache {
 		// user no longer exists in chat
 		if _, ok := usersLeft[user]; !ok {
-			log.Logger.Info().Str("user", user).Msg("delete user from cache")
 			delete(c.userColorCache, user)
 		}
 	}
 
-	// for i, e := range c.entries {
-	// 	if e.IsIgnored {
-	// 		continue
-	// 	}
+	return cmd
+}
 
-	// 	if c.lineStart >= e.Position.CursorStart && c.lineStart <= e.Position.CursorEnd {
-	// 		c.logger.Info().Int("index", i).Int("threshold", cleanupThreshold).Msg("cleanup")
+// spillableEntries returns how many of the oldest entries, up to limit, are neither selected nor in view.
+func (c *chatWindow) spillableEntries(limit int) int {
+	for i, e := range c.entries[:limit] {
+		if e.Selected || !e.IsFiltered && e.Position.CursorEnd >= c.lineStart {
+			return i
+		}
+	}
 
-	// 		if i > cleanupThreshold {
-	// 			c.entries = c.entries[i:]
-	// 			c.recalculateLines()
-	// 		}
+	return limit
+}
+
+// popSpilled returns a function taking up to limit of the most recently spilled entries back from the scrollback,
+// oldest first, or nil if nothing was spilled. It does disk I/O and has to run in a command, where it waits until
+// all spills scheduled before it are written.
+func (c *chatWindow) popSpilled(limit int) func() ([]messagelog.ScrollbackEntry, error) {
+	if c.spilledEntries == 0 || c.deps.Scrollback == nil || c.scrollbackID == "" {
+		return nil
+	}
 
-	// 		return
-	// 	}
-	// }
+	scrollback, id := c.deps.Scrollback, c.scrollbackID
+
+	var (
+		entries []messagelog.ScrollbackEntry
+		err     error
+	)
+
+	wait := c.scrollbackQueue.enqueue(func() {
+		entries, err = scrollback.Pop(id, limit)
+	})
+
+	return func() ([]messagelog.ScrollbackEntry, error) {
+		wait()
+		return entries, err
+	}
+}
+
+// restoredSpilled updates the number of spilled entries after popped of them were restored. empty means popping
+// found nothing, e.g. because a spill failed.
+func (c *chatWindow) restoredSpilled(popped int, empty bool) {
+	if empty {
+		c.spilledEntries = 0
+		return
+	}
+
+	c.spilledEntries = max(c.spilledEntries-popped, 0)
+}
+
+// scrollbackEntriesToMessages returns the messages of entries and the ids of the deleted private messages.
+func scrollbackEntriesToMessages(entries []messagelog.ScrollbackEntry) ([]twitchirc.IRCer, map[string]struct{}) {
+	messages := make([]twitchirc.IRCer, 0, len(entries))
+	deletedIDs := map[string]struct{}{}
+	for _, e := range entries {
+		messages = append(messages, e.Message)
+		if privateMsg, ok := e.Message.(*twitchirc.PrivateMessage); ok && e.IsDeleted {
+			deletedIDs[privateMsg.ID] = struct{}{}
+		}
+	}
+
+	return messages, deletedIDs
+}
+
+// dropScrollback removes the spilled entries in the background once the pending scrollback I/O is done.
+func (c *chatWindow) dropScrollback() {
+	if c.deps.Scrollback == nil || c.scrollbackID == "" {
+		return
+	}
+
+	scrollback, id := c.deps.Scrollback, c.scrollbackID
+
+	wait := c.scrollbackQueue.enqueue(func() {
+		if err := scrollback.Drop(id); err != nil {
+			log.Logger.Err(err).Str("tab", id).Msg("failed to drop scrollback")
+		}
+	})
+
+	go wait()
 }
 
 func (c *chatWindow) handleMessage(msg chatEventMessage) tea.Cmd {
@@ -672,7 +849,7 @@ func (c *chatWindow) handleMessage(msg chatEventMessage) tea.Cmd {
 		return nil
 	}
 
-	c.cleanup()
+	spillCmd := c.spill()
 	c.handleTimeoutMessage(msg)
 	c.handleMessageDeletion(msg)
 
@@ -716,11 +893,11 @@ func (c *chatWindow) handleMessage(msg chatEventMessage) tea.Cmd {
 	if wasLatestMessage {
 		c.moveToBottom()
 		if c.deps.UserConfig.Settings.Chat.SmoothScroll {
-			return c.startAnimating()
+			return tea.Batch(spillCmd, c.startAnimating())
 		}
 	}
 
-	return nil
+	return spillCmd
 }
 
 func (c *chatWindow) handleTimeoutMessage(msg chatEventMessage) {
@@ -733,6 +910,11 @@ func (c *chatWindow) handleTimeoutMessage(msg chatEventMessage) {
 				continue
 			}
 
+			// the combined chat shows multiple channels, only messages sent in the channel of the timeout are affected
+			if timeoutMsg.ChannelUserName != "" && !strings.EqualFold(privMsg.ChannelUserName, timeoutMsg.ChannelUserName) {
+				continue
+			}
+
 			if strings.EqualFold(privMsg.LoginName, *timeoutMsg.UserName) && !e.IsDeleted {
 				hasDeleted = true
 				e.IsDeleted = true
@@ -769,10 +951,15 @@ func (c *chatWindow) handleMessageDeletion(msg chatEventMessage) {
 	}
 }
 
-// buildAlertPrefix creates a standardized prefix with timestamp and styled alert label.
+// buildAlertPrefix creates a standardized prefix with timestamp, optional channel tag and styled alert label.
 // Example output: "  15:04:05 [Notice]: "
-func (c *chatWindow) buildAlertPrefix(timestamp time.Time, label string, style lipgloss.Style) string {
-	return "  " + c.dimmedStyle.Render(c.timeFormatFunc(timestamp)) + " [" + style.Render(label) + "]: "
+func (c *chatWindow) buildAlertPrefix(timestamp time.Time, channelTag string, label string, style lipgloss.Style) string {
+	prefix := "  " + c.dimmedStyle.Render(c.timeFormatFunc(timestamp)) + " "
+	if channelTag != "" {
+		prefix += channelTag + " "
+	}
+
+	return prefix + "[" + style.Render(label) + "]: "
 }
 
 // formatMessageText applies word replacements and color processing to message content.
@@ -784,7 +971,7 @@ func (c *chatWindow) formatMessageText(content string, modifier messageContentMo
 		return c.strikethroughStyle.Render(content)
 	}
 	if modifier.italic {
-		return c.italicStyle.Render(content)
+		return c.applyStyledWordReplacements(content, modifier.wordReplacements, c.italicStyle)
 	}
 
 	content = c.applyWordReplacements(content, modifier.wordReplacements)
@@ -813,6 +1000,24 @@ func (c *chatWindow) applyWordReplacements(content string, replacements wordRepl
 	return strings.Join(words, " ")
 }
 
+// applyStyledWordReplacements applies word replacements like applyWordReplacements and renders every other word with style.
+// The words are styled on their own instead of the whole content: inline images end by resetting the colours, so a
+// style rendered around them would not reach the text after them.
+func (c *chatWindow) applyStyledWordReplacements(content string, replacements wordReplacement, style lipgloss.Style) string {
+	words := strings.Split(content, " ")
+	for i, word := range words {
+		if replacement, ok := replacements[word]; ok {
+			words[i] = replacement
+			continue
+		}
+
+		if word != "" {
+			words[i] = style.Render(word)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
 func (c *chatWindow) setUserColorModifier(content string, modifier *messageContentModifier) {
 	words := strings.Split(content, " ")
 
@@ -848,8 +1053,17 @@ func (c *chatWindow) messageToText(event chatEventMessage) []string {
 	case *twitchirc.PrivateMessage:
 		userRenderFunc := c.getSetUserColorFunc(msg.LoginName, msg.Color)
 
-		// Build prefix components: time, [guest channel], [badges], username
-		parts := []string{"  " + c.dimmedStyle.Render(c.timeFormatFunc(msg.TMISentTS))}
+		// Build prefix components: time, [channel tag], [guest channel], [badges], username
+		timeStyle := c.dimmedStyle
+		if event.displayModifier.highlight != "" {
+			timeStyle = lipgloss.NewStyle().Background(lipgloss.Color(event.displayModifier.highlight)).Foreground(lipgloss.Color("#000000"))
+		}
+
+		parts := []string{"  " + timeStyle.Render(c.timeFormatFunc(msg.TMISentTS))}
+
+		if event.displayModifier.channelTag != "" {
+			parts = append(parts, event.displayModifier.channelTag)
+		}
 
 		if event.channelGuestDisplayName != "" {
 			parts = append(parts, "|"+event.channelGuestDisplayName+"|")
@@ -870,21 +1084,27 @@ func (c *chatWindow) messageToText(event chatEventMessage) []string {
 		prefix := strings.Join(parts, " ")
 
 		c.setUserColorModifier(msg.Message, &event.displayModifier)
-		return c.wordwrapMessage(prefix, c.formatMessageText(msg.Message, event.displayModifier))
+		lines := c.wordwrapMessage(prefix, c.formatMessageText(msg.Message, event.displayModifier))
+
+		if msg.ParentMsgID != "" {
+			lines = append([]string{c.replyContextLine(msg)}, lines...)
+		}
+
+		return lines
 	case *twitchirc.Notice:
 		title := "Notice"
 		if event.isFakeEvent {
 			title = "Fake-Notice"
 		}
 
-		prefix := c.buildAlertPrefix(msg.FakeTimestamp, title, c.noticeAlertStyle)
+		prefix := c.buildAlertPrefix(msg.FakeTimestamp, event.displayModifier.channelTag, title, c.noticeAlertStyle)
 
 		event.displayModifier.italic = true
 		c.setUserColorModifier(msg.Message, &event.displayModifier)
 
 		return c.wordwrapMessage(prefix, c.formatMessageText(msg.Message, event.displayModifier))
 	case *twitchirc.ClearMessage:
-		prefix := c.buildAlertPrefix(msg.TMISentTS, "Clear Message", c.clearChatAlertStyle)
+		prefix := c.buildAlertPrefix(msg.TMISentTS, event.displayModifier.channelTag, "Clear Message", c.clearChatAlertStyle)
 		prefix += "A message from "
 		text := msg.Login + " was removed."
 
@@ -892,7 +1112,7 @@ func (c *chatWindow) messageToText(event chatEventMessage) []string {
 
 		return c.wordwrapMessage(prefix, c.formatMessageText(text, event.displayModifier))
 	case *twitchirc.ClearChat:
-		prefix := c.buildAlertPrefix(msg.TMISentTS, "Clear Chat", c.clearChatAlertStyle)
+		prefix := c.buildAlertPrefix(msg.TMISentTS, event.displayModifier.channelTag, "Clear Chat", c.clearChatAlertStyle)
 
 		if msg.TargetUserID == nil {
 			return c.wordwrapMessage(prefix, c.formatMessageText("Clear chat prevented by Chatuino. Chat restored.", event.displayModifier))
@@ -910,7 +1130,7 @@ func (c *chatWindow) messageToText(event chatEventMessage) []string {
 
 		return c.wordwrapMessage(prefix, c.formatMessageText(text, event.displayModifier))
 	case *twitchirc.SubMessage:
-		prefix := c.buildAlertPrefix(msg.TMISentTS, "Sub Alert", c.subAlertStyle)
+		prefix := c.buildAlertPrefix(msg.TMISentTS, event.displayModifier.channelTag, "Sub Alert", c.subAlertStyle)
 
 		subResubText := "subscribed"
 		if msg.MsgID == "resub" {
@@ -935,7 +1155,7 @@ func (c *chatWindow) messageToText(event chatEventMessage) []string {
 
 		return c.wordwrapMessage(prefix, c.formatMessageText(text, event.displayModifier))
 	case *twitchirc.SubGiftMessage:
-		prefix := c.buildAlertPrefix(msg.TMISentTS, "Sub Gift Alert", c.subAlertStyle)
+		prefix := c.buildAlertPrefix(msg.TMISentTS, event.displayModifier.channelTag, "Sub Gift Alert", c.subAlertStyle)
 
 		_ = c.getSetUserColorFunc(msg.Login, msg.Color)
 
@@ -951,7 +1171,11 @@ func (c *chatWindow) messageToText(event chatEventMessage) []string {
 		return c.wordwrapMessage(prefix, c.formatMessageText(text, event.displayModifier))
 	case *twitchirc.AnnouncementMessage:
 		style := lipgloss.NewStyle().Foreground(lipgloss.Color(msg.ParamColor.RGBHex())).Bold(true)
-		prefix := "  " + c.timeFormatFunc(msg.TMISentTS) + " [" + style.Render("Announcement") + "] "
+		prefix := "  " + c.timeFormatFunc(msg.TMISentTS) + " "
+		if event.displayModifier.channelTag != "" {
+			prefix += event.displayModifier.channelTag + " "
+		}
+		prefix += "[" + style.Render("Announcement") + "] "
 
 		_ = c.getSetUserColorFunc(msg.Login, msg.Color)
 		// Unlike other messages the announcer name is part of the wrapped body
@@ -967,6 +1191,20 @@ func (c *chatWindow) messageToText(event chatEventMessage) []string {
 	return []string{}
 }
 
+// replyContextLine renders the dimmed line shown above a reply, naming the author and content of the parent message.
+func (c *chatWindow) replyContextLine(msg *twitchirc.PrivateMessage) string {
+	name := msg.ParentDisplayName
+	if name == "" {
+		name = msg.ParentUserLogin
+	}
+
+	body := strings.ReplaceAll(msg.ParentMsgBody, string(duplicateBypass), "")
+	text := "↳ replying to @" + name + ": " + strings.ReplaceAll(body, "\n", " ")
+
+	maxWidth := max(c.width-c.indicatorWidth-2, 1)
+	return "  " + c.dimmedStyle.Render(truncate.StringWithTail(text, uint(maxWidth), "…"))
+}
+
 func (c *chatWindow) getSetUserColorFunc(name string, colorHex string) func(strs ...string) string {
 	_, ok := c.userColorCache[name]
 
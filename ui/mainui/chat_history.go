@@ -0,0 +1,81 @@
+package mainui
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/julez-dev/chatuino/save/messagelog"
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+)
+
+const (
+	// loggedHistorySize is the number of logged messages used to seed the chat of a new tab.
+	loggedHistorySize = 500
+
+	// loggedHistoryPageSize is the number of older logged messages loaded when scrolling past the top of the chat.
+	loggedHistoryPageSize = 100
+)
+
+// mergeLoggedMessages prepends logged messages sent before the oldest recent message to the recent messages.
+// Messages already part of the recent messages are skipped. entries are expected newest first, like returned
+// by the message logger. The number of messages taken from the log is returned alongside the merged messages.
+func mergeLoggedMessages(recent []twitchirc.IRCer, entries []messagelog.LogEntry) ([]twitchirc.IRCer, int) {
+	seen := make(map[string]struct{}, len(recent))
+
+	var oldest time.Time
+	for _, m := range recent {
+		privateMsg, ok := m.(*twitchirc.PrivateMessage)
+		if !ok {
+			continue
+		}
+
+		seen[privateMsg.ID] = struct{}{}
+		if oldest.IsZero() || privateMsg.TMISentTS.Before(oldest) {
+			oldest = privateMsg.TMISentTS
+		}
+	}
+
+	logged := loggedEntriesToMessages(entries, func(msg *twitchirc.PrivateMessage) bool {
+		if _, ok := seen[msg.ID]; ok {
+			return false
+		}
+
+		return oldest.IsZero() || msg.TMISentTS.Before(oldest)
+	})
+
+	return append(logged, recent...), len(logged)
+}
+
+// loggedEntriesToMessages converts logged entries, newest first, into chronologically ordered messages.
+// keep decides which messages are included, nil includes all messages.
+func loggedEntriesToMessages(entries []messagelog.LogEntry, keep func(msg *twitchirc.PrivateMessage) bool) []twitchirc.IRCer {
+	messages := make([]twitchirc.IRCer, 0, len(entries))
+	for _, entry := range slices.Backward(entries) {
+		if entry.PrivateMessage == nil {
+			continue
+		}
+
+		if keep != nil && !keep(entry.PrivateMessage) {
+			continue
+		}
+
+		messages = append(messages, entry.PrivateMessage)
+	}
+
+	return messages
+}
+
+// initialMessagesNotice describes where the messages shown when opening a tab were loaded from.
+func initialMessagesNotice(recentCount, loggedCount int, recentErr error) string {
+	if recentErr != nil {
+		return fmt.Sprintf("Could not load recent messages (%s). Loaded %d messages from local chat logs", recentErr, loggedCount)
+	}
+
+	notice := fmt.Sprintf("Loaded %d recent messages; powered by https://recent-messages.robotty.de", recentCount)
+	if loggedCount > 0 {
+		notice += fmt.Sprintf(". Loaded %d older messages from local chat logs", loggedCount)
+	}
+
+	return notice
+}
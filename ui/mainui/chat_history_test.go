@@ -0,0 +1,230 @@
+package mainui
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/julez-dev/chatuino/save/messagelog"
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+	"github.com/stretchr/testify/require"
+)
+
+func messageIDs(t *testing.T, messages []twitchirc.IRCer) []string {
+	t.Helper()
+
+	ids := make([]string, 0, len(messages))
+	for _, m := range messages {
+		privateMsg, ok := m.(*twitchirc.PrivateMessage)
+		require.True(t, ok)
+		ids = append(ids, privateMsg.ID)
+	}
+
+	return ids
+}
+
+func TestMergeLoggedMessages(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	msg := func(id string, age time.Duration) *twitchirc.PrivateMessage {
+		return &twitchirc.PrivateMessage{ID: id, TMISentTS: now.Add(-age)}
+	}
+
+	// logger returns newest first
+	entries := []messagelog.LogEntry{
+		{PrivateMessage: msg("4", time.Minute)},
+		{PrivateMessage: msg("3", 2*time.Minute)},
+		{PrivateMessage: msg("2", 3*time.Minute)},
+		{PrivateMessage: msg("1", 4*time.Minute)},
+	}
+
+	t.Run("fallback-without-recent-messages", func(t *testing.T) {
+		t.Parallel()
+
+		merged, logged := mergeLoggedMessages(nil, entries)
+		require.Equal(t, 4, logged)
+		require.Equal(t, []string{"1", "2", "3", "4"}, messageIDs(t, merged))
+	})
+
+	t.Run("merge-deduplicated", func(t *testing.T) {
+		t.Parallel()
+
+		recent := []twitchirc.IRCer{msg("3", 2*time.Minute), msg("4", time.Minute), msg("5", 0)}
+
+		merged, logged := mergeLoggedMessages(recent, entries)
+		require.Equal(t, 2, logged)
+		require.Equal(t, []string{"1", "2", "3", "4", "5"}, messageIDs(t, merged))
+	})
+
+	t.Run("skip-logged-messages-newer-than-recent", func(t *testing.T) {
+		t.Parallel()
+
+		// message 3 was logged with a different id but is newer than the oldest recent message
+		recent := []twitchirc.IRCer{msg("x", 2*time.Minute+time.Second), msg("4", time.Minute)}
+
+		merged, logged := mergeLoggedMessages(recent, entries)
+		require.Equal(t, 2, logged)
+		require.Equal(t, []string{"1", "2", "x", "4"}, messageIDs(t, merged))
+	})
+}
+
+func TestInitialMessagesNotice(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "Loaded 10 recent messages; powered by https://recent-messages.robotty.de", initialMessagesNotice(10, 0, nil))
+	require.Equal(t, "Loaded 10 recent messages; powered by https://recent-messages.robotty.de. Loaded 5 older messages from local chat logs", initialMessagesNotice(10, 5, nil))
+	require.Equal(t, "Could not load recent messages (timeout). Loaded 5 messages from local chat logs", initialMessagesNotice(0, 5, errors.New("timeout")))
+}
+
+func TestChatWindow_PrependEntries(t *testing.T) {
+	t.Parallel()
+
+	c := newTestChatWindow(80, 5)
+
+	now := time.Now()
+	for _, id := range []string{"3", "4"} {
+		c.handleMessage(chatEventMessage{message: &twitchirc.PrivateMessage{ID: id, DisplayName: "user", Message: "msg " + id, TMISentTS: now}})
+	}
+
+	c.messageUp(1)
+	_, selected := c.entryForCurrentCursor()
+	require.Equal(t, "3", selected.Event.message.(*twitchirc.PrivateMessage).ID)
+
+	c.prependEntries([]chatEventMessage{
+		{message: &twitchirc.PrivateMessage{ID: "1", DisplayName: "user", Message: "msg 1", TMISentTS: now.Add(-time.Minute)}},
+		{message: &twitchirc.PrivateMessage{ID: "2", DisplayName: "user", Message: "msg 2", TMISentTS: now.Add(-time.Minute)}},
+	}, nil)
+
+	ids := make([]string, 0, len(c.entries))
+	for _, e := range c.entries {
+		ids = append(ids, e.Event.message.(*twitchirc.PrivateMessage).ID)
+	}
+	require.Equal(t, []string{"1", "2", "3", "4"}, ids)
+
+	// selection stays on the previously selected message
+	_, selected = c.entryForCurrentCursor()
+	require.Equal(t, "3", selected.Event.message.(*twitchirc.PrivateMessage).ID)
+
+	c.messageUp(1)
+	_, selected = c.entryForCurrentCursor()
+	require.Equal(t, "2", selected.Event.message.(*twitchirc.PrivateMessage).ID)
+}
+
+type memoryScrollback struct {
+	Scrollback
+	buffers map[string][]messagelog.ScrollbackEntry
+}
+
+func (m *memoryScrollback) Push(buffer string, entries []messagelog.ScrollbackEntry) error {
+	m.buffers[buffer] = append(m.buffers[buffer], entries...)
+	return nil
+}
+
+func (m *memoryScrollback) Pop(buffer string, limit int) ([]messagelog.ScrollbackEntry, error) {
+	entries := m.buffers[buffer]
+	n := min(limit, len(entries))
+	popped := entries[len(entries)-n:]
+	m.buffers[buffer] = entries[:len(entries)-n]
+	return popped, nil
+}
+
+func TestChatWindow_Spill(t *testing.T) {
+	t.Parallel()
+
+	store := &memoryScrollback{buffers: map[string][]messagelog.ScrollbackEntry{}}
+
+	c := newTestChatWindow(80, 10)
+	c.deps.Scrollback = store
+	c.deps.UserConfig.Settings.Chat.ScrollbackSize = 100
+	c.scrollbackID = "tab"
+
+	now := time.Now()
+	var spillCmds []tea.Cmd
+	for i := range 126 {
+		if cmd := c.handleMessage(chatEventMessage{message: &twitchirc.PrivateMessage{
+			ID:          strconv.Itoa(i),
+			DisplayName: "user",
+			Message:     "message",
+			TMISentTS:   now,
+		}}); cmd != nil {
+			spillCmds = append(spillCmds, cmd)
+		}
+	}
+
+	// oldest entries are spilled once the limit is exceeded by a chunk
+	require.Len(t, c.entries, 101)
+	require.Equal(t, "25", c.entries[0].Event.message.(*twitchirc.PrivateMessage).ID)
+	require.Len(t, c.lines, 101)
+	require.Equal(t, 25, c.spilledEntries)
+	require.Len(t, spillCmds, 1)
+	require.Empty(t, store.buffers["tab"], "entries are written by the returned command")
+
+	_, selected := c.entryForCurrentCursor()
+	require.Equal(t, "125", selected.Event.message.(*twitchirc.PrivateMessage).ID)
+
+	// the pop is scheduled before the spill command ran, it has to wait for it
+	pop := c.popSpilled(10)
+	type popResult struct {
+		entries []messagelog.ScrollbackEntry
+		err     error
+	}
+	result := make(chan popResult)
+	go func() {
+		entries, err := pop()
+		result <- popResult{entries, err}
+	}()
+
+	spillCmds[0]()
+
+	restored := <-result
+	require.NoError(t, restored.err)
+	require.Len(t, restored.entries, 10)
+	require.Equal(t, "15", restored.entries[0].Message.(*twitchirc.PrivateMessage).ID)
+
+	c.restoredSpilled(len(restored.entries), false)
+	require.Equal(t, 15, c.spilledEntries)
+
+	c.restoredSpilled(0, true)
+	require.Zero(t, c.spilledEntries)
+	require.Nil(t, c.popSpilled(10))
+}
+
+func TestChatWindow_SpillKeepsViewport(t *testing.T) {
+	t.Parallel()
+
+	c := newTestChatWindow(80, 10)
+	c.deps.UserConfig.Settings.Chat.ScrollbackSize = 100
+
+	now := time.Now()
+	add := func(i int) {
+		c.handleMessage(chatEventMessage{message: &twitchirc.PrivateMessage{
+			ID:          strconv.Itoa(i),
+			DisplayName: "user",
+			Message:     "message",
+			TMISentTS:   now,
+		}})
+	}
+
+	for i := range 100 {
+		add(i)
+	}
+
+	// scroll up to message 10
+	c.messageUp(89)
+	_, selected := c.entryForCurrentCursor()
+	require.Equal(t, "10", selected.Event.message.(*twitchirc.PrivateMessage).ID)
+
+	for i := 100; i < 130; i++ {
+		add(i)
+	}
+
+	// only entries above the viewport are dropped, without a scrollback they are lost
+	require.Equal(t, 0, c.spilledEntries)
+
+	_, selected = c.entryForCurrentCursor()
+	require.Equal(t, "10", selected.Event.message.(*twitchirc.PrivateMessage).ID)
+	require.Equal(t, "10", c.entries[c.lineStart].Event.message.(*twitchirc.PrivateMessage).ID)
+}
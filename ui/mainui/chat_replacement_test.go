@@ -1,8 +1,10 @@
 package mainui
 
 import (
+	"strings"
 	"testing"
 
+	"charm.land/lipgloss/v2"
 	"github.com/stretchr/testify/require"
 )
 
@@ -77,3 +79,22 @@ func TestApplyWordReplacements(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyStyledWordReplacements(t *testing.T) {
+	t.Parallel()
+
+	// a half block emote, it resets the foreground color after every cell
+	emote := "\x1b[38;2;1;2;3m▀\x1b[39m\x1b[38;2;4;5;6m▄\x1b[39m"
+	style := lipgloss.NewStyle().Italic(true).Foreground(lipgloss.Color("#ff0000"))
+
+	c := &chatWindow{}
+	got := c.applyStyledWordReplacements("before Kappa after  end", wordReplacement{"Kappa": emote}, style)
+
+	require.Equal(t, style.Render("before")+" "+emote+" "+style.Render("after")+"  "+style.Render("end"), got)
+
+	// the text after the emote starts with the color again
+	_, after, found := strings.Cut(got, emote)
+	require.True(t, found)
+	require.True(t, strings.HasPrefix(after, " \x1b["), after)
+	require.Contains(t, after, "255;0;0")
+}
@@ -0,0 +1,94 @@
+package mainui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"charm.land/lipgloss/v2"
+	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestChatWindow(width, height int) *chatWindow {
+	deps := &DependencyContainer{
+		Keymap: save.BuildDefaultKeyMap(),
+		UserConfig: UserConfiguration{
+			Settings: save.Settings{Chat: save.ChatSettings{TimeFormat: "15:04"}},
+		},
+	}
+
+	return newChatWindow(width, height, deps)
+}
+
+func TestChatWindow_ReplyContextLine(t *testing.T) {
+	t.Parallel()
+
+	c := newTestChatWindow(60, 10)
+
+	lines := c.messageToText(chatEventMessage{message: &twitchirc.PrivateMessage{
+		DisplayName:       "Replier",
+		Message:           "@parent I agree",
+		TMISentTS:         time.Now(),
+		ParentMsgID:       "parent-id",
+		ParentDisplayName: "Parent",
+		ParentMsgBody:     "hello chat",
+	}})
+
+	require.Len(t, lines, 2)
+	require.Equal(t, "  ↳ replying to @Parent: hello chat", stripAnsi(lines[0]))
+	require.Contains(t, stripAnsi(lines[1]), "Replier: @parent I agree")
+
+	lines = c.messageToText(chatEventMessage{message: &twitchirc.PrivateMessage{
+		DisplayName:     "Replier",
+		Message:         "ok",
+		TMISentTS:       time.Now(),
+		ParentMsgID:     "parent-id",
+		ParentUserLogin: "parent",
+		ParentMsgBody:   strings.Repeat("long message ", 20),
+	}})
+
+	require.Len(t, lines, 2)
+	require.True(t, strings.HasPrefix(stripAnsi(lines[0]), "  ↳ replying to @parent: long message"))
+	require.True(t, strings.HasSuffix(stripAnsi(lines[0]), "…"))
+	require.LessOrEqual(t, lipgloss.Width(lines[0]), 60-c.indicatorWidth)
+
+	lines = c.messageToText(chatEventMessage{message: &twitchirc.PrivateMessage{
+		DisplayName: "User",
+		Message:     "not a reply",
+		TMISentTS:   time.Now(),
+	}})
+	require.Len(t, lines, 1)
+}
+
+func TestChatWindow_ShowThread(t *testing.T) {
+	t.Parallel()
+
+	c := newTestChatWindow(80, 20)
+
+	messages := []*twitchirc.PrivateMessage{
+		{ID: "root", DisplayName: "a", Message: "question?"},
+		{ID: "other", DisplayName: "b", Message: "unrelated"},
+		{ID: "reply-1", DisplayName: "c", Message: "answer", ParentMsgID: "root", ThreadParentMsgID: "root", ParentMsgBody: "question?"},
+		{ID: "reply-2", DisplayName: "a", Message: "thanks", ParentMsgID: "reply-1", ThreadParentMsgID: "root", ParentMsgBody: "answer"},
+	}
+
+	for _, m := range messages {
+		m.TMISentTS = time.Now()
+		c.handleMessage(chatEventMessage{message: m})
+	}
+
+	c.showThread("root")
+
+	require.Equal(t, searchChatWindowState, c.state)
+
+	var ids []string
+	for _, e := range c.activeEntries() {
+		ids = append(ids, e.Event.message.(*twitchirc.PrivateMessage).ID)
+	}
+	require.Equal(t, []string{"root", "reply-1", "reply-2"}, ids)
+
+	c.handleStopSearchMode()
+	require.Len(t, c.activeEntries(), 4)
+}
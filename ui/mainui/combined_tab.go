@@ -0,0 +1,733 @@
+package mainui
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"charm.land/bubbles/v2/key"
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/search"
+	"github.com/julez-dev/chatuino/twitch/ivr"
+	"github.com/julez-dev/chatuino/twitch/twitchapi"
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+	"github.com/julez-dev/chatuino/ui/component"
+	"github.com/rs/zerolog/log"
+)
+
+// channelTagColors are used for the channel tags in the combined chat, each channel always gets the same color.
+var channelTagColors = [...]string{
+	"#e06c75",
+	"#98c379",
+	"#e5c07b",
+	"#61afef",
+	"#c678dd",
+	"#56b6c2",
+	"#d19a66",
+	"#be5046",
+}
+
+type setCombinedTabDataMessage struct {
+	targetID   string
+	channelIDs map[string]string // channel login:channel id
+	err        error
+}
+
+// combinedChannelRoleMessage reports if the account of a combined tab is a moderator or VIP in a channel.
+type combinedChannelRoleMessage struct {
+	targetID string
+	channel  string
+	elevated bool
+}
+
+// combinedTab merges the live chat of multiple channels into a single chat. When no channels are set,
+// the messages of all channels joined by other tabs are shown.
+type combinedTab struct {
+	id      string
+	account save.Account // account used to join the channels and send messages
+	deps    *DependencyContainer
+
+	focused bool
+
+	state         broadcastTabState
+	width, height int
+
+	channels      []string          // channel logins, empty for all open channels
+	channelIDs    map[string]string // channel login:channel id, learned from received messages
+	filterQuery   string
+	filter        search.Matcher
+	targetChannel string // channel messages are sent to
+	seen          *ttlcache.Cache[string, struct{}]
+	hasDataLoaded bool
+
+	lastMessageSent   string
+	lastMessageSentAt time.Time
+
+	inputBorderStyle lipgloss.Style
+
+	chatWindow   *chatWindow
+	messageInput *component.SuggestionTextInput
+
+	modFetcher ModStatusFetcher
+	// elevated is true for channels the account is broadcaster, moderator or VIP in, these have a higher message rate limit.
+	// Channels are missing while their role is not known yet.
+	elevated      map[string]bool
+	roleRequested map[string]struct{}
+}
+
+func newCombinedTab(id string, width, height int, account save.Account, channels []string, deps *DependencyContainer) *combinedTab {
+	// messages are received once per connected account, remember ids to show each message once
+	cache := ttlcache.New(
+		ttlcache.WithTTL[string, struct{}](time.Minute),
+	)
+	go cache.Start()
+
+	chatWindow := newChatWindow(width, height, deps)
+	chatWindow.scrollbackID = id
+
+	messageInput := component.NewSuggestionTextInput(chatWindow.userColorCache, deps.UserConfig.Settings.BuildCustomSuggestionMap())
+	messageInput.EmoteReplacer = deps.EmoteReplacer
+	messageInput.IncludeCommandSuggestions = false // only the combined chat commands are supported
+	msgInputStyles := messageInput.InputModel.Styles()
+	msgInputStyles.Focused.Prompt = lipgloss.NewStyle().Foreground(lipgloss.Color(deps.UserConfig.Theme.InputPromptColor))
+	messageInput.InputModel.SetStyles(msgInputStyles)
+	messageInput.SetMaxVisibleLines(3)
+	messageInput.InputModel.CharLimit = twitchMessageLimit * maxMessageParts // long messages are split when sent
+	messageInput.MisspelledStyle = messageInput.MisspelledStyle.UnderlineColor(lipgloss.Color(deps.UserConfig.Theme.ChatErrorColor))
+
+	c := &combinedTab{
+		id:               id,
+		account:          account,
+		deps:             deps,
+		state:            inChatWindow,
+		width:            width,
+		height:           height,
+		channels:         channels,
+		channelIDs:       map[string]string{},
+		seen:             cache,
+		inputBorderStyle: lipgloss.NewStyle().Foreground(lipgloss.Color(deps.UserConfig.Theme.InputPromptColor)),
+		chatWindow:       chatWindow,
+		messageInput:     messageInput,
+		modFetcher:       ivr.NewAPI(http.DefaultClient),
+		elevated:         map[string]bool{},
+		roleRequested:    map[string]struct{}{},
+	}
+
+	// emotes of the channel messages are sent to are not spell checked
+	messageInput.SpellChecker = newEmoteSpellChecker(deps, func() string { return c.channelIDs[c.targetChannel] })
+
+	return c
+}
+
+// parseCombinedChannels splits the comma separated channel input of the join dialog into channel logins.
+func parseCombinedChannels(input string) []string {
+	var channels []string
+	for channel := range strings.SplitSeq(input, ",") {
+		channel = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(channel), "#"))
+		if channel == "" || slices.Contains(channels, channel) {
+			continue
+		}
+
+		channels = append(channels, channel)
+	}
+
+	return channels
+}
+
+func (c *combinedTab) Init() tea.Cmd {
+	if len(c.channels) == 0 {
+		return func() tea.Msg {
+			return setCombinedTabDataMessage{targetID: c.id}
+		}
+	}
+
+	channels := c.channels
+	client := c.deps.APIUserClients[c.account.ID]
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+
+		resp, err := client.GetUsers(ctx, channels, nil)
+		if err != nil {
+			return setCombinedTabDataMessage{targetID: c.id, err: err}
+		}
+
+		channelIDs := make(map[string]string, len(resp.Data))
+		for _, u := range resp.Data {
+			channelIDs[u.Login] = u.ID
+		}
+
+		return setCombinedTabDataMessage{targetID: c.id, channelIDs: channelIDs}
+	}
+}
+
+func (c *combinedTab) InitWithUserData(twitchapi.UserData) tea.Cmd {
+	return c.Init()
+}
+
+func (c *combinedTab) Update(msg tea.Msg) (tab, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+	case setCombinedTabDataMessage:
+		if msg.targetID != c.id {
+			return c, nil
+		}
+
+		c.hasDataLoaded = true
+		for login, id := range msg.channelIDs {
+			c.channelIDs[login] = id
+		}
+
+		var roleCmd tea.Cmd
+		if len(c.channels) > 0 {
+			roleCmd = c.setTargetChannel(c.channels[0])
+		}
+
+		notices := []string{"Combining chat of all open channels"}
+		if len(c.channels) > 0 {
+			notices[0] = fmt.Sprintf("Combining chat of: %s", strings.Join(c.channels, ", "))
+		}
+
+		if msg.err != nil {
+			notices = append(notices, fmt.Sprintf("Could not resolve channels, sending messages is only possible after a message was received (%s)", msg.err))
+		}
+
+		if c.filterQuery != "" {
+			notices = append(notices, fmt.Sprintf("Showing only messages matching: %s", c.filterQuery))
+		}
+
+		cmds := make([]tea.Cmd, 0, len(notices)+2)
+		cmds = append(cmds, roleCmd)
+		for _, notice := range notices {
+			cmds = append(cmds, c.noticeCmd(notice))
+		}
+
+		// join the channels with the tab's own account, messages of all other channels are received through other tabs
+		if len(c.channels) > 0 {
+			accountID := c.account.ID
+			channels := c.channels
+			cmds = append(cmds, func() tea.Msg {
+				c.deps.Pool.ConnectIRC(accountID)
+				for _, channel := range channels {
+					c.deps.Pool.JoinChannel(accountID, channel)
+				}
+				return nil
+			})
+		}
+
+		c.HandleResize()
+		return c, tea.Sequence(cmds...)
+	case combinedChannelRoleMessage:
+		if msg.targetID == c.id {
+			c.elevated[msg.channel] = msg.elevated
+		}
+
+		return c, nil
+	case chatScrolledPastTopMessage:
+		if msg.owner != c.chatWindow {
+			return c, nil
+		}
+
+		return c, c.loadSpilledMessages()
+	case chatHistoryLoadedMessage:
+		if msg.tabID != c.id {
+			return c, nil
+		}
+
+		c.chatWindow.loadingHistory = false
+		c.chatWindow.restoredSpilled(msg.popped, msg.scrollbackEmpty)
+		if msg.err != nil {
+			return c, c.noticeCmd(msg.err.Error())
+		}
+
+		for i := range msg.events {
+			msg.events[i].displayModifier.channelTag = channelTag(msg.events[i].channel)
+		}
+
+		c.chatWindow.prependEntries(msg.events, msg.deletedIDs)
+		return c, nil
+	case linkPreviewMessage:
+		c.chatWindow.handleLinkPreview(msg)
+		return c, nil
+	case chatEventMessage:
+		// events created for this tab, like notices
+		if msg.tabID == c.id {
+			c.chatWindow, cmd = c.chatWindow.Update(msg)
+			return c, cmd
+		}
+
+		if msg.tabID != "" || !c.accepts(msg) {
+			return c, nil
+		}
+
+		if msg.channel != "" && msg.channelID != "" {
+			c.channelIDs[msg.channel] = msg.channelID
+		}
+
+		msg.displayModifier.channelTag = channelTag(msg.channel)
+
+		c.chatWindow, cmd = c.chatWindow.Update(msg)
+		return c, cmd
+	}
+
+	if c.focused && c.hasDataLoaded {
+		// all keys belong to the input history search until it ends
+		if msg, ok := msg.(tea.KeyPressMsg); ok && !c.messageInput.IsSearchingHistory() {
+			if key.Matches(msg, c.deps.Keymap.InsertMode) && c.state == inChatWindow && c.chatWindow.state != searchChatWindowState {
+				return c, c.handleStartInsertMode()
+			}
+
+			if key.Matches(msg, c.deps.Keymap.Confirm) && len(c.messageInput.Value()) > 0 && c.state == insertMode {
+				c.messageInput, _ = c.messageInput.Update(tea.KeyPressMsg{Code: tea.KeyEnter})
+				return c, c.handleMessageSent()
+			}
+
+			if key.Matches(msg, c.deps.Keymap.Escape) && c.state == insertMode {
+				c.state = inChatWindow
+				c.messageInput.Blur()
+				c.chatWindow.Focus()
+				return c, nil
+			}
+		}
+
+		if c.state == insertMode {
+			lineCountBefore := c.messageInput.LineCount()
+
+			c.messageInput, cmd = c.messageInput.Update(msg)
+
+			if c.messageInput.LineCount() != lineCountBefore {
+				c.HandleResize()
+			}
+
+			return c, cmd
+		}
+	}
+
+	// don't update any components when key message but not focused
+	if _, ok := msg.(tea.KeyPressMsg); ok && !c.focused {
+		return c, nil
+	}
+
+	c.chatWindow, cmd = c.chatWindow.Update(msg)
+	return c, cmd
+}
+
+// accepts reports whether a live chat event belongs to the combined chat. Events of the same message,
+// received by multiple connections, are only accepted once.
+func (c *combinedTab) accepts(event chatEventMessage) bool {
+	var key string
+	switch msg := event.message.(type) {
+	case *twitchirc.PrivateMessage:
+		if c.filter != nil && !c.filter.Match(msg) {
+			return false
+		}
+
+		key = msg.ID
+	case *twitchirc.SubMessage:
+		key = msg.ID
+	case *twitchirc.SubGiftMessage:
+		key = msg.ID
+	case *twitchirc.AnnouncementMessage:
+		key = msg.ID
+	case *twitchirc.ClearMessage:
+		key = "clearmsg:" + msg.TargetMsgID
+	case *twitchirc.ClearChat:
+		key = "clearchat:" + msg.ChannelUserName + ":" + msg.TMISentTS.String()
+	default:
+		return false
+	}
+
+	// with a filter only matching chat messages are shown, deletions are still needed to strike through matched messages
+	if c.filter != nil {
+		switch event.message.(type) {
+		case *twitchirc.SubMessage, *twitchirc.SubGiftMessage, *twitchirc.AnnouncementMessage:
+			return false
+		}
+	}
+
+	if len(c.channels) > 0 && !slices.Contains(c.channels, strings.ToLower(event.channel)) {
+		return false
+	}
+
+	if messageMatchesBlocked(event.message, c.deps.UserConfig.Settings.BlockSettings) {
+		return false
+	}
+
+	if key != "" {
+		if c.seen.Has(key) {
+			return false
+		}
+
+		c.seen.Set(key, struct{}{}, ttlcache.DefaultTTL)
+	}
+
+	return true
+}
+
+// setFilter parses the query and uses it to filter new chat messages. An empty query removes the filter.
+func (c *combinedTab) setFilter(query string) error {
+	matcher, err := search.Parse(query)
+	if err != nil {
+		return err
+	}
+
+	c.filter = matcher
+	c.filterQuery = strings.TrimSpace(query)
+
+	return nil
+}
+
+// joinsChannel reports whether the tab joined the channel with the account itself.
+func (c *combinedTab) joinsChannel(accountID, channel string) bool {
+	return c.account.ID == accountID && slices.Contains(c.channels, strings.ToLower(channel))
+}
+
+func (c *combinedTab) handleStartInsertMode() tea.Cmd {
+	if c.account.IsAnonymous {
+		return nil
+	}
+
+	// send to the channel of the selected message, if there is one
+	var roleCmd tea.Cmd
+	if _, entry := c.chatWindow.entryForCurrentCursor(); entry != nil {
+		if privateMsg, ok := entry.Event.message.(*twitchirc.PrivateMessage); ok {
+			roleCmd = c.setTargetChannel(privateMsg.ChannelUserName)
+		}
+	}
+
+	c.state = insertMode
+	c.chatWindow.Blur()
+	c.messageInput.Focus()
+	c.HandleResize()
+
+	return tea.Batch(roleCmd, c.messageInput.InputModel.Focus())
+}
+
+// setTargetChannel sets the channel messages are sent to and looks up the role of the account in it.
+func (c *combinedTab) setTargetChannel(channel string) tea.Cmd {
+	c.targetChannel = channel
+
+	if c.account.IsAnonymous {
+		return nil
+	}
+
+	if _, ok := c.roleRequested[channel]; ok {
+		return nil
+	}
+	c.roleRequested[channel] = struct{}{}
+
+	if c.channelIDs[channel] == c.account.ID {
+		c.elevated[channel] = true
+		return nil
+	}
+
+	var (
+		tabID      = c.id
+		accountID  = c.account.ID
+		modFetcher = c.modFetcher
+	)
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+		defer cancel()
+
+		modVIPs, err := modFetcher.GetModVIPList(ctx, channel)
+		if err != nil {
+			// unlike the broadcast tab the role only decides the message delay, the lower rate limit is the safe choice
+			log.Logger.Warn().Err(err).Str("channel", channel).Msg("failed to fetch mod and VIP list for combined tab")
+			return combinedChannelRoleMessage{targetID: tabID, channel: channel}
+		}
+
+		isMod, isVIP := modVIPStatus(modVIPs, accountID)
+		return combinedChannelRoleMessage{targetID: tabID, channel: channel, elevated: isMod || isVIP}
+	}
+}
+
+func (c *combinedTab) inputHistory() (save.InputHistory, bool) {
+	return save.InputHistory{Entries: c.messageInput.History(), Draft: c.messageInput.Value()}, true
+}
+
+func (c *combinedTab) setInputHistory(h save.InputHistory) {
+	c.messageInput.SetHistory(h.Entries)
+	c.messageInput.SetValue(h.Draft)
+}
+
+func (c *combinedTab) handleMessageSent() tea.Cmd {
+	input := c.messageInput.Value()
+
+	c.state = inChatWindow
+	c.messageInput.Blur()
+	c.messageInput.SetValue("")
+	c.chatWindow.Focus()
+	c.chatWindow.moveToBottom()
+	c.HandleResize()
+
+	if strings.HasPrefix(input, "/") {
+		commandName, argStr, _ := strings.Cut(input[1:], " ")
+		argStr = strings.TrimSpace(argStr)
+
+		switch commandName {
+		case "filter":
+			if err := c.setFilter(argStr); err != nil {
+				return c.noticeCmd(fmt.Sprintf("Invalid filter: %s", err))
+			}
+
+			if c.filter == nil {
+				return c.noticeCmd("Filter removed")
+			}
+
+			return c.noticeCmd(fmt.Sprintf("Showing only new messages matching: %s", c.filterQuery))
+		case "target":
+			channel := strings.ToLower(strings.TrimPrefix(argStr, "#"))
+			if _, ok := c.channelIDs[channel]; !ok {
+				return c.noticeCmd(fmt.Sprintf("Unknown channel %q, messages can only be sent to channels shown in this tab", argStr))
+			}
+
+			return tea.Batch(c.setTargetChannel(channel), c.noticeCmd(fmt.Sprintf("Sending messages to #%s", channel)))
+		case "block", "unblock", "blocks":
+			return handleBlockCommand(c.deps, commandName, strings.Fields(argStr), c.targetChannel, c.noticeCmd)
+		}
+
+		return c.noticeCmd(fmt.Sprintf("Command /%s is not available in the combined chat, use /filter, /target or /block", commandName))
+	}
+
+	broadcasterID, ok := c.channelIDs[c.targetChannel]
+	if !ok {
+		return c.noticeCmd("No channel to send the message to, select a message or use /target <channel>")
+	}
+
+	// Long messages are split into multiple messages, repeated messages get a special character appended
+	// to bypass the twitch duplicate message filter
+	parts := messageParts(input, c.lastMessageSent)
+
+	client, ok := c.deps.APIUserClients[c.account.ID].(userAuthenticatedAPIClient)
+	if !ok {
+		return c.noticeCmd("Messages can't be sent with this account, log in with a Twitch account to chat")
+	}
+
+	delay := messageDelay
+	if c.elevated[c.targetChannel] {
+		delay = elevatedMessageDelay
+	}
+
+	lastSent := c.lastMessageSentAt
+	userID := c.account.ID
+	noticeCmd := c.noticeCmd
+
+	cmd := func() tea.Msg {
+		err := sendChatMessageParts(client, twitchapi.SendChatMessageRequest{
+			BroadcasterID: broadcasterID,
+			SenderID:      userID,
+		}, parts, lastSent, delay)
+		if err != nil {
+			return noticeCmd(fmt.Sprintf("Could not send message: %s", err.Error()))()
+		}
+
+		return nil
+	}
+
+	// the parts are sent in the background, count the time from the last part
+	c.lastMessageSent = parts[len(parts)-1]
+	c.lastMessageSentAt = time.Now().Add(time.Duration(len(parts)-1) * delay)
+
+	return cmd
+}
+
+// loadSpilledMessages pages entries spilled to the scrollback back into the chat. The combined chat has no
+// message log of its own, so only spilled entries can be loaded.
+func (c *combinedTab) loadSpilledMessages() tea.Cmd {
+	if c.chatWindow.loadingHistory || c.chatWindow.spilledEntries == 0 {
+		return nil
+	}
+
+	popSpilled := c.chatWindow.popSpilled(loggedHistoryPageSize)
+	if popSpilled == nil {
+		return nil
+	}
+
+	c.chatWindow.loadingHistory = true
+
+	tabID, accountID := c.id, c.account.ID
+	return func() tea.Msg {
+		entries, err := popSpilled()
+		if err != nil {
+			return chatHistoryLoadedMessage{
+				tabID: tabID,
+				err:   fmt.Errorf("could not restore older messages: %w", err),
+			}
+		}
+
+		if len(entries) == 0 {
+			return chatHistoryLoadedMessage{tabID: tabID, scrollbackEmpty: true}
+		}
+
+		messages, deletedIDs := scrollbackEntriesToMessages(entries)
+		return requestLocalHistoryHandleMessage{
+			messages:   messages,
+			deletedIDs: deletedIDs,
+			accountID:  accountID,
+			tabID:      tabID,
+			popped:     len(entries),
+		}
+	}
+}
+
+func (c *combinedTab) noticeCmd(notice string) tea.Cmd {
+	return func() tea.Msg {
+		return requestLocalMessageHandleMessage{
+			tabID:     c.id,
+			accountID: c.account.ID,
+			message: &twitchirc.Notice{
+				FakeTimestamp: time.Now(),
+				Message:       notice,
+			},
+		}
+	}
+}
+
+// channelTag renders the channel name in a color derived from the name.
+func channelTag(channel string) string {
+	if channel == "" {
+		return ""
+	}
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(channel))
+	color := channelTagColors[h.Sum32()%uint32(len(channelTagColors))]
+
+	return lipgloss.NewStyle().Foreground(lipgloss.Color(color)).Render("#" + channel)
+}
+
+func (c *combinedTab) renderMessageInput() string {
+	if c.account.IsAnonymous {
+		return ""
+	}
+
+	topLabel := "[ Combined Chat ]"
+	if c.targetChannel != "" {
+		topLabel = "[ Send to #" + c.targetChannel + " ]"
+	}
+
+	innerWidth := c.width - 2 // -2 for left/right border chars
+
+	topFill := max(innerWidth-lipgloss.Width(topLabel)-2, 0)
+	topBorder := "┌─" + topLabel + strings.Repeat("─", topFill) + "─┐"
+
+	inputLines := strings.Split(c.messageInput.View(), "\n")
+	borderedLines := make([]string, 0, len(inputLines))
+	for _, line := range inputLines {
+		padNeeded := max(0, innerWidth-lipgloss.Width(line))
+		borderedLines = append(borderedLines, "│"+line+strings.Repeat(" ", padNeeded)+"│")
+	}
+
+	return c.inputBorderStyle.Render(topBorder) + "\n" +
+		c.inputBorderStyle.Render(strings.Join(borderedLines, "\n")) + "\n" +
+		renderInputBottomBorder(innerWidth, c.messageInput.Value(), c.inputBorderStyle, c.deps.UserConfig.Theme)
+}
+
+func (c *combinedTab) View() string {
+	input := c.renderMessageInput()
+	if input == "" {
+		return c.chatWindow.View()
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left, c.chatWindow.View(), input)
+}
+
+func (c *combinedTab) ViewWithoutStatusBar() string {
+	return c.View() // combined tab has no status bar
+}
+
+func (c *combinedTab) StatusBarView() string {
+	return "" // combined tab has no status bar
+}
+
+func (c *combinedTab) Focus() {
+	c.focused = true
+
+	if c.state == insertMode {
+		c.messageInput.Focus()
+		return
+	}
+
+	c.chatWindow.Focus()
+}
+
+func (c *combinedTab) Blur() {
+	c.focused = false
+	c.chatWindow.Blur()
+	c.messageInput.Blur()
+}
+
+func (c *combinedTab) AccountID() string {
+	return c.account.ID
+}
+
+// Channel returns the comma separated channels of the tab, which is also the format used by the join dialog.
+func (c *combinedTab) Channel() string {
+	return strings.Join(c.channels, ",")
+}
+
+func (c *combinedTab) State() broadcastTabState {
+	return c.state
+}
+
+func (c *combinedTab) IsSearching() bool {
+	return c.chatWindow.state == searchChatWindowState
+}
+
+func (c *combinedTab) IsDataLoaded() bool {
+	return c.hasDataLoaded
+}
+
+func (c *combinedTab) ID() string {
+	return c.id
+}
+
+func (c *combinedTab) Focused() bool {
+	return c.focused
+}
+
+func (c *combinedTab) ChannelID() string {
+	return ""
+}
+
+func (c *combinedTab) HandleResize() {
+	c.messageInput.SetWidth(c.width - 2)
+
+	chatHeight := c.height
+	if input := c.renderMessageInput(); input != "" {
+		chatHeight -= lipgloss.Height(input)
+	}
+
+	c.chatWindow.Resize(c.width, max(chatHeight, 0))
+}
+
+func (c *combinedTab) SetSize(width, height int) {
+	c.width = width
+	c.height = height
+}
+
+func (c *combinedTab) SetFullWidth(_ int) {
+	// No-op for combined tab (no status bar)
+}
+
+func (c *combinedTab) Kind() TabKind {
+	return CombinedTabKind
+}
+
+func (c *combinedTab) close() {
+	c.chatWindow.dropScrollback()
+
+	c.seen.DeleteAll()
+	c.seen.Stop()
+}
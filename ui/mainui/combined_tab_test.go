@@ -0,0 +1,173 @@
+package mainui
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/twitch/ivr"
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCombinedTab(t *testing.T, channels []string) *combinedTab {
+	t.Helper()
+
+	deps := &DependencyContainer{
+		Keymap: save.BuildDefaultKeyMap(),
+		UserConfig: UserConfiguration{
+			Settings: save.Settings{Chat: save.ChatSettings{TimeFormat: "15:04"}},
+		},
+	}
+
+	c := newCombinedTab("combined", 80, 20, save.Account{ID: "account"}, channels, deps)
+	c.modFetcher = stubModFetcher{}
+	t.Cleanup(c.close)
+
+	return c
+}
+
+func TestParseCombinedChannels(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"julez", "forsen"}, parseCombinedChannels(" #Julez, forsen,,julez "))
+	require.Empty(t, parseCombinedChannels(""))
+}
+
+func TestCombinedTab_Accepts(t *testing.T) {
+	t.Parallel()
+
+	msg := func(id, channel, text string) chatEventMessage {
+		return chatEventMessage{
+			channel: channel,
+			message: &twitchirc.PrivateMessage{ID: id, ChannelUserName: channel, LoginName: "user", Message: text, TMISentTS: time.Now()},
+		}
+	}
+
+	t.Run("selected-channels", func(t *testing.T) {
+		t.Parallel()
+
+		c := newTestCombinedTab(t, []string{"julez", "forsen"})
+
+		require.True(t, c.accepts(msg("1", "julez", "hello")))
+		require.True(t, c.accepts(msg("2", "forsen", "hello")))
+		require.False(t, c.accepts(msg("3", "other", "hello")))
+
+		// same message received by another connection
+		require.False(t, c.accepts(msg("1", "julez", "hello")))
+
+		// events without a chat message are not part of the combined chat
+		require.False(t, c.accepts(chatEventMessage{channel: "julez", message: &twitchirc.RoomState{}}))
+	})
+
+	t.Run("all-channels", func(t *testing.T) {
+		t.Parallel()
+
+		c := newTestCombinedTab(t, nil)
+
+		require.True(t, c.accepts(msg("1", "julez", "hello")))
+		require.True(t, c.accepts(msg("2", "other", "hello")))
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		t.Parallel()
+
+		c := newTestCombinedTab(t, nil)
+		require.NoError(t, c.setFilter("channel:julez gg"))
+
+		require.True(t, c.accepts(msg("1", "julez", "gg wp")))
+		require.False(t, c.accepts(msg("2", "julez", "hello")))
+		require.False(t, c.accepts(msg("3", "forsen", "gg")))
+		require.False(t, c.accepts(chatEventMessage{channel: "julez", message: &twitchirc.SubMessage{UserNotice: twitchirc.UserNotice{ID: "4"}}}))
+
+		// deletions are still shown, so matched messages are struck through
+		require.True(t, c.accepts(chatEventMessage{channel: "julez", message: &twitchirc.ClearMessage{TargetMsgID: "1"}}))
+
+		require.Error(t, c.setFilter("is:unknown"))
+		require.Equal(t, "channel:julez gg", c.filterQuery)
+
+		require.NoError(t, c.setFilter(""))
+		require.True(t, c.accepts(msg("5", "forsen", "hello")))
+	})
+}
+
+func TestCombinedTab_ChannelTag(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCombinedTab(t, nil)
+
+	lines := c.chatWindow.messageToText(chatEventMessage{
+		message:         &twitchirc.PrivateMessage{DisplayName: "user", Message: "hello", TMISentTS: time.Now()},
+		displayModifier: messageContentModifier{channelTag: channelTag("julez")},
+	})
+
+	require.Len(t, lines, 1)
+	require.Contains(t, stripAnsi(lines[0]), "#julez user: hello")
+	require.Equal(t, channelTag("julez"), channelTag("julez"))
+	require.Empty(t, channelTag(""))
+}
+
+func TestChatWindow_TimeoutOnlyAffectsChannel(t *testing.T) {
+	t.Parallel()
+
+	c := newTestChatWindow(80, 10)
+
+	now := time.Now()
+	for _, channel := range []string{"julez", "forsen"} {
+		c.handleMessage(chatEventMessage{message: &twitchirc.PrivateMessage{ID: channel, ChannelUserName: channel, LoginName: "user", DisplayName: "user", Message: "hello", TMISentTS: now}})
+	}
+
+	user := "user"
+	c.handleMessage(chatEventMessage{message: &twitchirc.ClearChat{ChannelUserName: "julez", UserName: &user, TargetUserID: &user, TMISentTS: now}})
+
+	require.True(t, c.entries[0].IsDeleted)
+	require.False(t, c.entries[1].IsDeleted)
+}
+
+func TestCombinedTab_SendWithoutUserClient(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCombinedTab(t, []string{"julez"})
+	c.channelIDs["julez"] = "1"
+	c.targetChannel = "julez"
+	c.messageInput.SetValue("hello")
+
+	cmd := c.handleMessageSent()
+	require.NotNil(t, cmd)
+
+	msg, ok := cmd().(requestLocalMessageHandleMessage)
+	require.True(t, ok)
+	require.Contains(t, msg.message.(*twitchirc.Notice).Message, "can't be sent with this account")
+}
+
+type stubModFetcher struct {
+	list ivr.ModVIPResponse
+}
+
+func (s stubModFetcher) GetModVIPList(context.Context, string) (ivr.ModVIPResponse, error) {
+	return s.list, nil
+}
+
+func TestCombinedTab_ChannelRole(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCombinedTab(t, []string{"julez", "forsen", "account"})
+	c.channelIDs = map[string]string{"julez": "1", "forsen": "2", "account": "account"}
+	c.modFetcher = stubModFetcher{list: ivr.ModVIPResponse{VIPs: []ivr.PrivilegedUser{{ID: "account"}}}}
+
+	cmd := c.setTargetChannel("julez")
+	require.NotNil(t, cmd)
+	require.Nil(t, c.setTargetChannel("julez"), "the role is only fetched once")
+
+	c.Update(cmd())
+	require.True(t, c.elevated["julez"])
+
+	c.modFetcher = stubModFetcher{}
+	c.Update(c.setTargetChannel("forsen")())
+	require.False(t, c.elevated["forsen"])
+
+	// broadcasters don't need to be looked up
+	require.Nil(t, c.setTargetChannel("account"))
+	require.True(t, c.elevated["account"])
+}
@@ -0,0 +1,183 @@
+package mainui
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"charm.land/lipgloss/v2"
+	"charm.land/lipgloss/v2/table"
+	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+)
+
+const connectionStatusRefreshInterval = time.Second
+
+// connectionStatusTickMessage carries the generation of the tick loop it belongs to,
+// ticks of loops started before the overlay was reopened are dropped.
+type connectionStatusTickMessage struct {
+	generation int
+}
+
+// connectionStatus is an overlay showing the health of the IRC connection of each account.
+type connectionStatus struct {
+	deps  *DependencyContainer
+	width int
+
+	statuses   map[string]twitchirc.ConnStatus
+	now        func() time.Time
+	generation int
+}
+
+func newConnectionStatus(width int, deps *DependencyContainer) *connectionStatus {
+	return &connectionStatus{
+		deps:  deps,
+		width: width,
+		now:   time.Now,
+	}
+}
+
+// Init starts a new tick loop, replacing the one of a previous opening.
+func (c *connectionStatus) Init() tea.Cmd {
+	c.generation++
+	c.refresh()
+	return c.tick()
+}
+
+func (c *connectionStatus) Update(msg tea.Msg) (*connectionStatus, tea.Cmd) {
+	if msg, ok := msg.(connectionStatusTickMessage); ok {
+		if msg.generation != c.generation {
+			return c, nil
+		}
+
+		c.refresh()
+		return c, c.tick()
+	}
+
+	return c, nil
+}
+
+func (c *connectionStatus) View() string {
+	style := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color(c.deps.UserConfig.Theme.ListLabelColor)).
+		Padding(0, 1).
+		MaxWidth(c.width)
+
+	headlineStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(c.deps.UserConfig.Theme.ActiveLabelColor))
+	hintStyle := lipgloss.NewStyle().Foreground(lipgloss.Color(c.deps.UserConfig.Theme.ListLabelColor))
+
+	rows := connectionStatusRows(c.deps.Accounts, c.statuses, c.now())
+
+	var body string
+	if len(rows) == 0 {
+		body = "No open chat connections"
+	} else {
+		t := table.New().
+			Border(lipgloss.HiddenBorder()).
+			Headers("Account", "State", "Connected", "Reconnects", "Latency", "Channels", "Last Error").
+			Rows(rows...).
+			StyleFunc(func(row, col int) lipgloss.Style {
+				s := lipgloss.NewStyle().PaddingRight(2)
+				if row == table.HeaderRow {
+					return s.Bold(true)
+				}
+				return s
+			})
+		body = t.Render()
+	}
+
+	hint := hintStyle.Render(fmt.Sprintf("%s/%s to close", c.deps.Keymap.Escape.Help().Key, c.deps.Keymap.ConnectionStatus.Help().Key))
+
+	return style.Render(headlineStyle.Render("Connection Status") + "\n" + body + "\n" + hint)
+}
+
+func (c *connectionStatus) refresh() {
+	c.statuses = c.deps.Pool.IRCStatus()
+}
+
+func (c *connectionStatus) tick() tea.Cmd {
+	generation := c.generation
+	return tea.Tick(connectionStatusRefreshInterval, func(time.Time) tea.Msg {
+		return connectionStatusTickMessage{generation: generation}
+	})
+}
+
+func (c *connectionStatus) handleResize(width int) {
+	c.width = width
+}
+
+// connectionStatusRows builds one table row per connection, sorted by account name.
+func connectionStatusRows(accounts []save.Account, statuses map[string]twitchirc.ConnStatus, now time.Time) [][]string {
+	type namedStatus struct {
+		name   string
+		status twitchirc.ConnStatus
+	}
+
+	named := make([]namedStatus, 0, len(statuses))
+	for _, id := range slices.Sorted(maps.Keys(statuses)) {
+		named = append(named, namedStatus{name: connectionAccountName(accounts, id), status: statuses[id]})
+	}
+
+	slices.SortStableFunc(named, func(a, b namedStatus) int {
+		return cmp.Compare(strings.ToLower(a.name), strings.ToLower(b.name))
+	})
+
+	rows := make([][]string, 0, len(named))
+	for _, n := range named {
+		s := n.status
+
+		state := "connecting"
+		connected := "-"
+		switch {
+		case s.Connected:
+			state = "connected"
+			connected = humanizeDuration(now.Sub(s.ConnectedSince))
+		case !s.NextReconnect.IsZero():
+			state = "reconnecting in " + humanizeDuration(max(s.NextReconnect.Sub(now), 0))
+		}
+
+		latency := "-"
+		if s.Latency > 0 {
+			latency = s.Latency.Round(time.Millisecond).String()
+		}
+
+		lastErr := "-"
+		if s.LastError != nil {
+			lastErr = fmt.Sprintf("%s (%s ago)", s.LastError, humanizeDuration(now.Sub(s.LastErrorAt)))
+		}
+
+		rows = append(rows, []string{
+			n.name,
+			state,
+			connected,
+			strconv.Itoa(s.Reconnects),
+			latency,
+			strconv.Itoa(s.JoinedChannels),
+			lastErr,
+		})
+	}
+
+	return rows
+}
+
+func connectionAccountName(accounts []save.Account, id string) string {
+	for _, a := range accounts {
+		if a.ID != id {
+			continue
+		}
+
+		if a.IsAnonymous {
+			return "Anonymous"
+		}
+
+		return a.DisplayName
+	}
+
+	return id
+}
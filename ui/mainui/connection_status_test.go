@@ -0,0 +1,82 @@
+package mainui
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_connectionStatusRows(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	accounts := []save.Account{
+		{ID: "1", DisplayName: "zoe"},
+		{ID: "2", DisplayName: "Alice"},
+		{ID: "3", IsAnonymous: true, DisplayName: "justinfan123"},
+	}
+
+	statuses := map[string]twitchirc.ConnStatus{
+		"1": {
+			Connected:      true,
+			ConnectedSince: now.Add(-5 * time.Minute),
+			Reconnects:     2,
+			Latency:        42*time.Millisecond + 300*time.Microsecond,
+			JoinedChannels: 3,
+			LastError:      errors.New("read failed"),
+			LastErrorAt:    now.Add(-6 * time.Minute),
+		},
+		"2": {
+			NextReconnect: now.Add(10 * time.Second),
+			Reconnects:    1,
+		},
+		"3":       {},
+		"unknown": {},
+	}
+
+	rows := connectionStatusRows(accounts, statuses, now)
+
+	require.Equal(t, [][]string{
+		{"Alice", "reconnecting in 10 seconds", "-", "1", "-", "0", "-"},
+		{"Anonymous", "connecting", "-", "0", "-", "0", "-"},
+		{"unknown", "connecting", "-", "0", "-", "0", "-"},
+		{"zoe", "connected", "5 minutes", "2", "42ms", "3", "read failed (6 minutes ago)"},
+	}, rows)
+}
+
+type statusPool struct {
+	ConnectionPool
+	calls int
+}
+
+func (p *statusPool) IRCStatus() map[string]twitchirc.ConnStatus {
+	p.calls++
+	return nil
+}
+
+func Test_connectionStatus_dropsStaleTicks(t *testing.T) {
+	t.Parallel()
+
+	pool := &statusPool{}
+	c := newConnectionStatus(80, &DependencyContainer{Pool: pool})
+
+	c.Init()
+	stale := connectionStatusTickMessage{generation: c.generation}
+
+	// the overlay was closed and opened again before the first tick arrived
+	c.Init()
+	require.Equal(t, 2, pool.calls)
+
+	_, cmd := c.Update(stale)
+	require.Nil(t, cmd)
+	require.Equal(t, 2, pool.calls)
+
+	_, cmd = c.Update(connectionStatusTickMessage{generation: c.generation})
+	require.NotNil(t, cmd)
+	require.Equal(t, 3, pool.calls)
+}
@@ -2,12 +2,13 @@ package mainui
 
 import (
 	"context"
+	"time"
 
 	"github.com/julez-dev/chatuino/badge"
 	"github.com/julez-dev/chatuino/emote"
-	"github.com/julez-dev/chatuino/kittyimg"
 	"github.com/julez-dev/chatuino/save"
 	"github.com/julez-dev/chatuino/save/messagelog"
+	"github.com/julez-dev/chatuino/script"
 	"github.com/julez-dev/chatuino/server"
 	"github.com/julez-dev/chatuino/twitch/twitchapi"
 	"github.com/julez-dev/chatuino/twitch/twitchirc"
@@ -30,6 +31,8 @@ type EmoteCache interface {
 	RefreshLocal(ctx context.Context, channelID string) error
 	RefreshGlobal(ctx context.Context) error
 	GetAllForChannel(id string) emote.EmoteSet
+	GetGlobal() emote.EmoteSet
+	GetForeign() emote.EmoteSet
 	AddUserEmotes(userID string, emotes []emote.Emote)
 	AllEmotesUsableByUser(userID string) []emote.Emote
 	RemoveEmoteSetForChannel(channelID string)
@@ -54,7 +57,7 @@ type APIClient interface {
 type ChatuinoServer interface {
 	APIClient
 	RefreshToken(ctx context.Context, refreshToken string) (string, string, error)
-	CheckLink(ctx context.Context, targetURL string) (server.CheckLinkResponse, error)
+	GetLinkPreview(ctx context.Context, targetURL string) (server.LinkPreview, error)
 	GetLatestVersion(ctx context.Context) (string, error)
 }
 
@@ -69,6 +72,7 @@ type ConnectionPool interface {
 	SendIRC(accountID string, msg twitchirc.IRCer) error
 	JoinChannel(accountID, channel string) error
 	SubscribeEventSub(accountID string, req twitchapi.CreateEventSubSubscriptionRequest, service wspool.EventSubService) error
+	IRCStatus() map[string]twitchirc.ConnStatus
 	Close() error
 }
 
@@ -78,6 +82,20 @@ type RecentMessageService interface {
 
 type MessageLogger interface {
 	MessagesFromUserInChannel(username string, broadcasterChannel string) ([]messagelog.LogEntry, error)
+	RecentMessagesInChannel(broadcasterChannel string, limit int) ([]messagelog.LogEntry, error)
+	MessagesInChannelBefore(broadcasterChannel string, before time.Time, limit int) ([]messagelog.LogEntry, error)
+}
+
+// Scrollback stores chat messages evicted from the in-memory chat buffer of a tab.
+type Scrollback interface {
+	Push(buffer string, entries []messagelog.ScrollbackEntry) error
+	Pop(buffer string, limit int) ([]messagelog.ScrollbackEntry, error)
+	Drop(buffer string) error
+}
+
+// ImageDisplayManager is the graphics backend used to display emotes and badges.
+type ImageDisplayManager interface {
+	CleanupOldImagesCommand(maxAge time.Duration) string
 }
 
 type AppStateManager interface {
@@ -91,6 +109,33 @@ type ChannelHistory interface {
 	RecordChannel(login string) error
 }
 
+// EmoteUsage tracks the emotes sent by the user.
+type EmoteUsage interface {
+	LoadUsage() ([]save.EmoteUsageEntry, error)
+	RecordUsage(channelID string, emotes []string) error
+}
+
+// InputHistoryStore persists the message input history and drafts of the tabs.
+type InputHistoryStore interface {
+	LoadInputHistory() (map[string]save.InputHistory, error)
+	SaveInputHistory(map[string]save.InputHistory) error
+}
+
+// ScriptRunner runs the user scripts for live chat messages.
+type ScriptRunner interface {
+	Run(msg script.Message) (script.Result, error)
+}
+
+// BlockRuleStore persists block rules edited with the /block commands.
+type BlockRuleStore interface {
+	SaveBlockRules(rules []save.BlockRule) error
+}
+
+// SpellChecker checks the spelling of words typed into the message input.
+type SpellChecker interface {
+	Correct(word string) bool
+}
+
 type DependencyContainer struct {
 	UserConfig UserConfiguration
 	Keymap     save.KeyMap
@@ -105,10 +150,16 @@ type DependencyContainer struct {
 	BadgeCache           *badge.Cache
 	EmoteReplacer        EmoteReplacer
 	BadgeReplacer        BadgeReplacer
-	ImageDisplayManager  *kittyimg.DisplayManager
+	ImageDisplayManager  ImageDisplayManager
 	RecentMessageService RecentMessageService
 	MessageLogger        MessageLogger
+	Scrollback           Scrollback
 	Pool                 ConnectionPool
 	AppStateManager      AppStateManager
 	ChannelHistory       ChannelHistory
+	EmoteUsage           EmoteUsage
+	Scripts              ScriptRunner
+	BlockRules           BlockRuleStore
+	InputHistory         InputHistoryStore
+	SpellChecker         SpellChecker
 }
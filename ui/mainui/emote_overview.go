@@ -1,12 +1,17 @@
 package mainui
 
 import (
+	"cmp"
 	"context"
+	"fmt"
 	"slices"
 	"strings"
 	"sync"
+	"time"
 
+	"charm.land/bubbles/v2/key"
 	"charm.land/bubbles/v2/spinner"
+	"charm.land/bubbles/v2/textinput"
 	"charm.land/bubbles/v2/viewport"
 	tea "charm.land/bubbletea/v2"
 	"charm.land/lipgloss/v2"
@@ -16,56 +21,251 @@ import (
 	"golang.org/x/sync/semaphore"
 )
 
+// emoteSetKind describes where an emote in the picker comes from.
+type emoteSetKind int
+
+const (
+	emoteSetGlobal emoteSetKind = iota
+	emoteSetChannel
+	emoteSetSub
+	emoteSetForeign
+)
+
+func (k emoteSetKind) String() string {
+	switch k {
+	case emoteSetChannel:
+		return "channel"
+	case emoteSetSub:
+		return "sub"
+	case emoteSetForeign:
+		return "foreign"
+	}
+
+	return "global"
+}
+
 type emoteWithOverwrite struct {
 	emote     emote.Emote
 	overwrite string
+	set       emoteSetKind
 }
 
 type emoteOverviewSetDataMessage struct {
 	id             string
-	set            map[string][]emoteWithOverwrite
+	emotes         []emoteWithOverwrite
+	mostUsed       []string
 	prepareCommand string // Kitty graphics prepare command
 }
 
-type emoteOverview struct {
-	id        string
-	store     EmoteCache
-	channelID string
+const (
+	mostUsedEmotesHeader = "Most used in this channel"
+	mostUsedEmotesLimit  = 20
+
+	emoteOverviewHeaderHeight = 2 // filter input and help line
+	emoteOverviewRowHeight    = 3 // image, text and an empty line
+)
+
+// emoteProviderOrder is the order in which the providers are listed in the picker.
+var emoteProviderOrder = []emote.Platform{emote.Twitch, emote.SevenTV, emote.BTTV, emote.FFZ, emote.Unknown}
+
+// emoteFilter is the parsed filter query of the emote picker.
+type emoteFilter struct {
+	terms     []string // lower case name substrings, all must match
+	platforms []emote.Platform
+	sets      []emoteSetKind
+}
+
+// parseEmoteFilter parses a filter query.
+//
+// Syntax:
+//
+//	term                            emote name substring (case-insensitive)
+//	provider:twitch|7tv|bttv|ffz    only emotes of a provider, p: is an alias
+//	set:global|channel|sub|foreign  only emotes of a set, s: is an alias
+//
+// Multiple tokens are combined with AND, multiple providers or sets with OR.
+func parseEmoteFilter(query string) (emoteFilter, error) {
+	var f emoteFilter
+
+	for token := range strings.FieldsSeq(query) {
+		prefix, value, found := strings.Cut(token, ":")
+		if !found || value == "" {
+			f.terms = append(f.terms, strings.ToLower(token))
+			continue
+		}
+
+		switch strings.ToLower(prefix) {
+		case "provider", "p":
+			p, err := parseEmotePlatform(value)
+			if err != nil {
+				return emoteFilter{}, err
+			}
+
+			f.platforms = append(f.platforms, p)
+		case "set", "s":
+			s, err := parseEmoteSetKind(value)
+			if err != nil {
+				return emoteFilter{}, err
+			}
+
+			f.sets = append(f.sets, s)
+		default:
+			f.terms = append(f.terms, strings.ToLower(token))
+		}
+	}
+
+	return f, nil
+}
+
+func parseEmotePlatform(s string) (emote.Platform, error) {
+	switch strings.ToLower(s) {
+	case "twitch", "ttv":
+		return emote.Twitch, nil
+	case "7tv", "seventv", "stv":
+		return emote.SevenTV, nil
+	case "bttv", "betterttv":
+		return emote.BTTV, nil
+	case "ffz", "frankerfacez":
+		return emote.FFZ, nil
+	}
+
+	return emote.Unknown, fmt.Errorf("unknown provider %q, expected twitch, 7tv, bttv or ffz", s)
+}
+
+func parseEmoteSetKind(s string) (emoteSetKind, error) {
+	switch strings.ToLower(s) {
+	case "global":
+		return emoteSetGlobal, nil
+	case "channel":
+		return emoteSetChannel, nil
+	case "sub", "subs":
+		return emoteSetSub, nil
+	case "foreign":
+		return emoteSetForeign, nil
+	}
+
+	return emoteSetGlobal, fmt.Errorf("unknown set %q, expected global, channel, sub or foreign", s)
+}
+
+func (f emoteFilter) matches(e emoteWithOverwrite) bool {
+	if len(f.platforms) > 0 && !slices.Contains(f.platforms, e.emote.Platform) {
+		return false
+	}
 
-	vp      viewport.Model
-	spinner spinner.Model
+	if len(f.sets) > 0 && !slices.Contains(f.sets, e.set) {
+		return false
+	}
+
+	text := strings.ToLower(e.emote.Text)
+	for _, term := range f.terms {
+		if !strings.Contains(text, term) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// emoteOverview is the emote picker. Emotes can be filtered by name, provider and set
+// and the selected emote is inserted into the message input.
+type emoteOverview struct {
+	id           string
+	deps         *DependencyContainer
+	store        EmoteCache
+	channelID    string
+	channelLogin string
+	accountID    string
+
+	vp          viewport.Model
+	spinner     spinner.Model
+	filterInput textinput.Model
+	width       int
 
 	ctx    context.Context
 	cancel context.CancelFunc
 
 	emoteReplacer EmoteReplacer
-	emotes        map[string][]emoteWithOverwrite
+	emotes        []emoteWithOverwrite
+	mostUsed      []string
 	isLoaded      bool
+
+	filter    emoteFilter
+	filterErr error
+
+	// layout of the currently visible emotes, rebuilt on every content update
+	items    []emoteWithOverwrite
+	rows     [][]int // indexes into items
+	rowLines []int   // first content line of each row
+	cursor   int     // index into items
+
+	selectedStyle lipgloss.Style
 }
 
 // loadingSpinner is the unified spinner used across all loading states.
 var loadingSpinner = spinner.Points
 
-func NewEmoteOverview(channelID string, cache EmoteCache, replacer EmoteReplacer, width, height int) *emoteOverview {
-	vp := viewport.New(viewport.WithWidth(width), viewport.WithHeight(height))
+func NewEmoteOverview(channelID, channelLogin, accountID string, deps *DependencyContainer, width, height int) *emoteOverview {
+	vp := viewport.New(viewport.WithWidth(width), viewport.WithHeight(max(height-emoteOverviewHeaderHeight, 0)))
 
 	ctx, cancel := context.WithCancel(context.Background())
 
+	input := textinput.New()
+	input.CharLimit = 128
+	input.Prompt = "  /"
+	input.Placeholder = "filter — name provider:twitch|7tv|bttv|ffz set:global|channel|sub|foreign"
+	styles := input.Styles()
+	styles.Focused.Prompt = lipgloss.NewStyle().Foreground(lipgloss.Color(deps.UserConfig.Theme.InputPromptColor))
+	styles.Cursor.BlinkSpeed = time.Millisecond * 750
+	input.SetStyles(styles)
+	input.SetWidth(width)
+
 	return &emoteOverview{
 		id:            uuid.New().String(),
-		store:         cache,
+		deps:          deps,
+		store:         deps.EmoteCache,
 		channelID:     channelID,
-		emoteReplacer: replacer,
+		channelLogin:  channelLogin,
+		accountID:     accountID,
+		emoteReplacer: deps.EmoteReplacer,
 		vp:            vp,
 		spinner:       spinner.New(spinner.WithSpinner(loadingSpinner)),
+		filterInput:   input,
+		width:         width,
 		ctx:           ctx,
 		cancel:        cancel,
+		selectedStyle: lipgloss.NewStyle().Reverse(true),
+	}
+}
+
+// collectPickerEmotes returns all emotes usable in the channel, tagged with the set they belong to.
+func collectPickerEmotes(store EmoteCache, channelID, accountID string) []emoteWithOverwrite {
+	seen := map[string]struct{}{}
+	var emotes []emoteWithOverwrite
+
+	add := func(set emote.EmoteSet, kind emoteSetKind) {
+		for _, e := range set {
+			key := e.Platform.String() + e.ID
+			if _, ok := seen[key]; ok {
+				continue
+			}
+
+			seen[key] = struct{}{}
+			emotes = append(emotes, emoteWithOverwrite{emote: e, set: kind})
+		}
 	}
+
+	// global emotes are added first so they are not listed as channel or sub emotes
+	add(store.GetGlobal(), emoteSetGlobal)
+	add(store.GetAllForChannel(channelID), emoteSetChannel)
+	add(store.AllEmotesUsableByUser(accountID), emoteSetSub)
+	add(store.GetForeign(), emoteSetForeign)
+
+	return emotes
 }
 
 func (e *emoteOverview) Init() tea.Cmd {
 	initCmd := func() tea.Msg {
-		set := e.store.GetAllForChannel(e.channelID)
+		set := collectPickerEmotes(e.store, e.channelID, e.accountID)
 
 		type channelData struct {
 			emote   emoteWithOverwrite
@@ -74,15 +274,13 @@ func (e *emoteOverview) Init() tea.Cmd {
 
 		ch := make(chan channelData)
 
-		r := make(map[string][]emoteWithOverwrite)
-
 		chunks := slices.Collect(slices.Chunk(set, 200))
 		wg := sync.WaitGroup{}
 		wg.Add(len(chunks))
 		sema := semaphore.NewWeighted(3) // no more than 3 goroutines at once
 
 		for _, chunk := range chunks {
-			go func(set emote.EmoteSet) {
+			go func(set []emoteWithOverwrite) {
 				defer wg.Done()
 
 				if err := sema.Acquire(e.ctx, 1); err != nil {
@@ -91,22 +289,20 @@ func (e *emoteOverview) Init() tea.Cmd {
 
 				defer sema.Release(1)
 
-				for _, emote := range set {
+				for _, data := range set {
 					if e.ctx.Err() != nil {
 						return
 					}
 
-					prepare, overwrite, err := e.emoteReplacer.Replace(e.channelID, emote.Text, nil)
+					prepare, overwrite, err := e.emoteReplacer.Replace(e.channelID, data.emote.Text, nil)
 					if err != nil {
 						log.Logger.Error().Err(err).Send()
 						continue
 					}
 
+					data.overwrite = overwrite[data.emote.Text]
 					ch <- channelData{
-						emote: emoteWithOverwrite{
-							emote:     emote,
-							overwrite: overwrite[emote.Text],
-						},
+						emote:   data,
 						prepare: prepare,
 					}
 				}
@@ -120,16 +316,30 @@ func (e *emoteOverview) Init() tea.Cmd {
 		}()
 
 		var prepare strings.Builder
+		var emotes []emoteWithOverwrite
 		for d := range ch {
-			r[d.emote.emote.Platform.String()] = append(r[d.emote.emote.Platform.String()], d.emote)
+			emotes = append(emotes, d.emote)
 			prepare.WriteString(d.prepare)
 		}
 
+		slices.SortFunc(emotes, func(a, b emoteWithOverwrite) int {
+			return cmp.Or(
+				strings.Compare(strings.ToLower(a.emote.Text), strings.ToLower(b.emote.Text)),
+				strings.Compare(a.emote.Text, b.emote.Text),
+			)
+		})
+
+		var mostUsed []string
+		for _, used := range mostUsedEmotesInChannel(e.deps, e.channelID, e.channelLogin, mostUsedEmotesLimit) {
+			mostUsed = append(mostUsed, used.text)
+		}
+
 		log.Logger.Info().Msg("emote overview loaded")
 
 		return emoteOverviewSetDataMessage{
 			id:             e.id,
-			set:            r,
+			emotes:         emotes,
+			mostUsed:       mostUsed,
 			prepareCommand: prepare.String(),
 		}
 	}
@@ -145,13 +355,20 @@ func (e *emoteOverview) Update(msg tea.Msg) (*emoteOverview, tea.Cmd) {
 		}
 
 		e.isLoaded = true
-		e.emotes = msg.set
+		e.emotes = msg.emotes
+		e.mostUsed = msg.mostUsed
 		e.updateContent()
 
 		if msg.prepareCommand != "" {
 			return e, tea.Raw(msg.prepareCommand)
 		}
 		return e, nil
+	case tea.KeyPressMsg:
+		if !e.isLoaded {
+			return e, nil
+		}
+
+		return e, e.handleKey(msg)
 	}
 
 	var cmd tea.Cmd
@@ -160,90 +377,284 @@ func (e *emoteOverview) Update(msg tea.Msg) (*emoteOverview, tea.Cmd) {
 		return e, cmd
 	}
 
+	if e.filterInput.Focused() {
+		e.filterInput, cmd = e.filterInput.Update(msg)
+		return e, cmd
+	}
+
 	e.vp, cmd = e.vp.Update(msg)
 	return e, cmd
 }
 
+func (e *emoteOverview) handleKey(msg tea.KeyPressMsg) tea.Cmd {
+	// while filtering only the arrow keys navigate, everything else edits the filter
+	if e.filterInput.Focused() {
+		switch msg.String() {
+		case "up":
+			e.moveRow(-1)
+		case "down":
+			e.moveRow(1)
+		default:
+			var cmd tea.Cmd
+			e.filterInput, cmd = e.filterInput.Update(msg)
+			e.applyFilter()
+			return cmd
+		}
+
+		return nil
+	}
+
+	switch {
+	case key.Matches(msg, e.deps.Keymap.SearchMode):
+		return e.filterInput.Focus()
+	case key.Matches(msg, e.deps.Keymap.Up):
+		e.moveRow(-1)
+	case key.Matches(msg, e.deps.Keymap.Down):
+		e.moveRow(1)
+	case key.Matches(msg, e.deps.Keymap.Left):
+		e.moveCursor(-1)
+	case key.Matches(msg, e.deps.Keymap.Right):
+		e.moveCursor(1)
+	case key.Matches(msg, e.deps.Keymap.GoToTop):
+		e.moveCursor(-len(e.items))
+	case key.Matches(msg, e.deps.Keymap.GoToBottom):
+		e.moveCursor(len(e.items))
+	}
+
+	return nil
+}
+
+// isFiltering reports whether the filter input has focus and receives all key presses.
+func (e *emoteOverview) isFiltering() bool {
+	return e.filterInput.Focused()
+}
+
+// stopFiltering removes the focus from the filter input but keeps the filter applied.
+func (e *emoteOverview) stopFiltering() {
+	e.filterInput.Blur()
+}
+
+// selectedEmote returns the text of the emote under the cursor.
+func (e *emoteOverview) selectedEmote() (string, bool) {
+	if !e.isLoaded || e.cursor >= len(e.items) {
+		return "", false
+	}
+
+	return e.items[e.cursor].emote.Text, true
+}
+
+func (e *emoteOverview) applyFilter() {
+	filter, err := parseEmoteFilter(e.filterInput.Value())
+	e.filterErr = err
+	if err != nil {
+		return
+	}
+
+	e.filter = filter
+	e.cursor = 0
+	e.updateContent()
+}
+
+func (e *emoteOverview) moveCursor(n int) {
+	if len(e.items) == 0 {
+		return
+	}
+
+	e.cursor = max(min(e.cursor+n, len(e.items)-1), 0)
+	e.updateContent()
+}
+
+// moveRow moves the cursor n rows up or down, keeping the column where possible.
+func (e *emoteOverview) moveRow(n int) {
+	row, col := e.cursorPosition()
+	if row == -1 {
+		return
+	}
+
+	row = max(min(row+n, len(e.rows)-1), 0)
+	e.cursor = e.rows[row][min(col, len(e.rows[row])-1)]
+	e.updateContent()
+}
+
+func (e *emoteOverview) cursorPosition() (int, int) {
+	for r, row := range e.rows {
+		if c := slices.Index(row, e.cursor); c != -1 {
+			return r, c
+		}
+	}
+
+	return -1, -1
+}
+
 func (e *emoteOverview) View() string {
 	if !e.isLoaded {
-		return lipgloss.NewStyle().Width(e.vp.Width()).Height(e.vp.Height()).AlignHorizontal(lipgloss.Center).AlignVertical(lipgloss.Center).Render(e.spinner.View() + " Loading emote overview")
+		return lipgloss.NewStyle().Width(e.vp.Width()).Height(e.vp.Height() + emoteOverviewHeaderHeight).AlignHorizontal(lipgloss.Center).AlignVertical(lipgloss.Center).Render(e.spinner.View() + " Loading emote overview")
+	}
+
+	help := fmt.Sprintf("  %d emotes — %s filter, %s insert", len(e.items), e.deps.Keymap.SearchMode.Help().Key, e.deps.Keymap.Confirm.Help().Key)
+	helpStyle := lipgloss.NewStyle().Faint(true)
+	if e.filterErr != nil {
+		help = "  " + e.filterErr.Error()
+		helpStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
 	}
 
-	return e.vp.View()
+	return e.filterInput.View() + "\n" + helpStyle.MaxWidth(e.width).Render(help) + "\n" + e.vp.View()
 }
 
 func (e *emoteOverview) resize(width, height int) {
+	e.width = width
+	e.filterInput.SetWidth(width)
 	e.vp.SetWidth(width)
-	e.vp.SetHeight(height)
+	e.vp.SetHeight(max(height-emoteOverviewHeaderHeight, 0))
 	e.updateContent()
 }
 
 func (e *emoteOverview) updateContent() {
 	maxWidthRow := e.vp.Width()
 
+	e.items = e.items[:0]
+	e.rows = e.rows[:0]
+	e.rowLines = e.rowLines[:0]
+
 	var sb strings.Builder
-	for provider, emotes := range e.emotes {
-		// write provider header
-		_, _ = sb.WriteString(lipgloss.NewStyle().Margin(1).MarginBottom(2).Render(provider))
-
-		var totalSpaceTakenInCurrentRow int
-		var rowIndex int
-		var emoteRows [][]emoteWithOverwrite
-		emoteWidths := map[string]int{}
-
-		// calculate emote rows for each provider based on available space
-		for _, emoteData := range emotes {
-			emoteTextWidth := lipgloss.Width(emoteData.emote.Text)
-			emoteOverwriteWidth := lipgloss.Width(emoteData.overwrite)
-			emoteWidth := emoteTextWidth
-
-			if emoteOverwriteWidth > emoteTextWidth {
-				emoteWidth = emoteOverwriteWidth
+	var line int
+
+	if len(e.mostUsed) > 0 {
+		var mostUsed []emoteWithOverwrite
+		for _, text := range e.mostUsed {
+			i := slices.IndexFunc(e.emotes, func(data emoteWithOverwrite) bool {
+				return data.emote.Text == text
+			})
+
+			// only show emotes which are still available in the channel
+			if i != -1 && e.filter.matches(e.emotes[i]) {
+				mostUsed = append(mostUsed, e.emotes[i])
 			}
+		}
 
-			emoteWidths[emoteData.emote.Platform.String()+emoteData.emote.ID] = emoteWidth
-
-			//log.Logger.Info().Int("current-width-row", totalSpaceTakenInCurrentRow).Int("emote-width", emoteWidth).Int("max-width-row", maxWidthRow).Str("emote", emoteData.emote.Text).Msg("")
-
-			// does not fit add to next row
-			if totalSpaceTakenInCurrentRow+emoteWidth+2 > maxWidthRow {
-				totalSpaceTakenInCurrentRow = 0
-				rowIndex++
-				emoteRows = append(emoteRows, []emoteWithOverwrite{
-					emoteData,
-				})
-			} else {
-				// fits add to current row, create new one if not exists yet
-				totalSpaceTakenInCurrentRow += emoteWidth + 4
-				// ensure row at index exists
-				if len(emoteRows) <= rowIndex {
-					emoteRows = append(emoteRows, []emoteWithOverwrite{})
-				}
-				emoteRows[rowIndex] = append(emoteRows[rowIndex], emoteData)
+		line = e.writeEmoteSection(&sb, line, mostUsedEmotesHeader, mostUsed, maxWidthRow)
+	}
+
+	for _, provider := range emoteProviderOrder {
+		var emotes []emoteWithOverwrite
+		for _, data := range e.emotes {
+			if data.emote.Platform == provider && e.filter.matches(data) {
+				emotes = append(emotes, data)
 			}
 		}
 
-		_, _ = sb.WriteString("\n")
+		line = e.writeEmoteSection(&sb, line, provider.String(), emotes, maxWidthRow)
+	}
 
-		for _, row := range emoteRows {
-			// write overwritten emote, then start new line and align the text for the emote
-			for _, emote := range row {
-				key := emote.emote.Platform.String() + emote.emote.ID
-				_, _ = sb.WriteString(lipgloss.NewStyle().Width(emoteWidths[key]).MarginRight(2).AlignHorizontal(lipgloss.Center).Render(emote.overwrite))
-			}
+	if len(e.items) == 0 {
+		sb.WriteString(lipgloss.NewStyle().Margin(1).Render("No emotes found"))
+	}
+
+	e.cursor = max(min(e.cursor, len(e.items)-1), 0)
+	e.vp.SetContent(sb.String())
+	e.scrollToCursor()
+}
+
+// scrollToCursor scrolls the viewport so the row with the selected emote is visible.
+func (e *emoteOverview) scrollToCursor() {
+	row, _ := e.cursorPosition()
+	if row == -1 {
+		return
+	}
+
+	start := e.rowLines[row]
+	end := start + emoteOverviewRowHeight - 1
+
+	switch {
+	case row == 0:
+		e.vp.GotoTop()
+	case start < e.vp.YOffset():
+		e.vp.SetYOffset(start)
+	case end >= e.vp.YOffset()+e.vp.Height():
+		e.vp.SetYOffset(end - e.vp.Height() + 1)
+	}
+}
 
-			_, _ = sb.WriteString("\n")
+// writeEmoteSection writes a section with the emotes laid out in rows and records the layout for navigation.
+// line is the current content line, the line after the section is returned.
+func (e *emoteOverview) writeEmoteSection(sb *strings.Builder, line int, header string, emotes []emoteWithOverwrite, maxWidthRow int) int {
+	if len(emotes) == 0 {
+		return line
+	}
+
+	// write section header
+	headerView := lipgloss.NewStyle().Margin(1).MarginBottom(2).Render(header)
+	_, _ = sb.WriteString(headerView)
+	_, _ = sb.WriteString("\n")
+	line += lipgloss.Height(headerView)
+
+	var totalSpaceTakenInCurrentRow int
+	var rowIndex int
+	var emoteRows [][]emoteWithOverwrite
+	emoteWidths := map[string]int{}
+
+	// calculate emote rows based on available space
+	for _, emoteData := range emotes {
+		emoteTextWidth := lipgloss.Width(emoteData.emote.Text)
+		emoteOverwriteWidth := lipgloss.Width(emoteData.overwrite)
+		emoteWidth := emoteTextWidth
+
+		if emoteOverwriteWidth > emoteTextWidth {
+			emoteWidth = emoteOverwriteWidth
+		}
 
-			for _, emote := range row {
-				key := emote.emote.Platform.String() + emote.emote.ID
-				_, _ = sb.WriteString(lipgloss.NewStyle().Width(emoteWidths[key]).MarginRight(2).AlignHorizontal(lipgloss.Center).Render(emote.emote.Text))
+		emoteWidths[emoteData.emote.Platform.String()+emoteData.emote.ID] = emoteWidth
+
+		// does not fit add to next row
+		if totalSpaceTakenInCurrentRow+emoteWidth+2 > maxWidthRow && len(emoteRows) > 0 {
+			totalSpaceTakenInCurrentRow = emoteWidth + 4
+			rowIndex++
+			emoteRows = append(emoteRows, []emoteWithOverwrite{
+				emoteData,
+			})
+		} else {
+			// fits add to current row, create new one if not exists yet
+			totalSpaceTakenInCurrentRow += emoteWidth + 4
+			// ensure row at index exists
+			if len(emoteRows) <= rowIndex {
+				emoteRows = append(emoteRows, []emoteWithOverwrite{})
 			}
+			emoteRows[rowIndex] = append(emoteRows[rowIndex], emoteData)
+		}
+	}
+
+	for _, row := range emoteRows {
+		indexes := make([]int, 0, len(row))
+		for range row {
+			indexes = append(indexes, len(e.items)+len(indexes))
+		}
 
-			sb.WriteString("\n\n")
+		// write overwritten emote, then start new line and align the text for the emote
+		for _, emote := range row {
+			key := emote.emote.Platform.String() + emote.emote.ID
+			_, _ = sb.WriteString(lipgloss.NewStyle().Width(emoteWidths[key]).MarginRight(2).AlignHorizontal(lipgloss.Center).Render(emote.overwrite))
 		}
+
+		_, _ = sb.WriteString("\n")
+
+		for i, emote := range row {
+			key := emote.emote.Platform.String() + emote.emote.ID
+			text := emote.emote.Text
+			if indexes[i] == e.cursor {
+				text = e.selectedStyle.Render(text)
+			}
+			_, _ = sb.WriteString(lipgloss.NewStyle().Width(emoteWidths[key]).MarginRight(2).AlignHorizontal(lipgloss.Center).Render(text))
+		}
+
+		sb.WriteString("\n\n")
+
+		e.items = append(e.items, row...)
+		e.rows = append(e.rows, indexes)
+		e.rowLines = append(e.rowLines, line)
+		line += emoteOverviewRowHeight
 	}
 
-	e.vp.SetContent(sb.String())
+	return line
 }
 
 func (e *emoteOverview) close() {
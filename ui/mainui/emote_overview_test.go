@@ -0,0 +1,157 @@
+package mainui
+
+import (
+	"testing"
+
+	"github.com/julez-dev/chatuino/emote"
+	"github.com/julez-dev/chatuino/save"
+	"github.com/stretchr/testify/require"
+)
+
+type stubPickerEmoteCache struct {
+	EmoteCache
+	global, channel, user, foreign emote.EmoteSet
+}
+
+func (s stubPickerEmoteCache) GetGlobal() emote.EmoteSet { return s.global }
+
+func (s stubPickerEmoteCache) GetAllForChannel(string) emote.EmoteSet {
+	return append(append(emote.EmoteSet{}, s.channel...), s.global...)
+}
+
+func (s stubPickerEmoteCache) AllEmotesUsableByUser(string) []emote.Emote {
+	return append(append(emote.EmoteSet{}, s.user...), s.global...)
+}
+
+func (s stubPickerEmoteCache) GetForeign() emote.EmoteSet { return s.foreign }
+
+func TestParseEmoteFilter(t *testing.T) {
+	t.Parallel()
+
+	f, err := parseEmoteFilter("Pepe provider:7tv p:bttv set:sub LAUGH")
+	require.NoError(t, err)
+	require.Equal(t, emoteFilter{
+		terms:     []string{"pepe", "laugh"},
+		platforms: []emote.Platform{emote.SevenTV, emote.BTTV},
+		sets:      []emoteSetKind{emoteSetSub},
+	}, f)
+
+	_, err = parseEmoteFilter("provider:youtube")
+	require.ErrorContains(t, err, "unknown provider")
+
+	_, err = parseEmoteFilter("set:everything")
+	require.ErrorContains(t, err, "unknown set")
+
+	f, err = parseEmoteFilter("")
+	require.NoError(t, err)
+	require.True(t, f.matches(emoteWithOverwrite{emote: emote.Emote{Text: "Kappa"}}))
+}
+
+func TestEmoteFilter_matches(t *testing.T) {
+	t.Parallel()
+
+	pepeLaugh := emoteWithOverwrite{emote: emote.Emote{Text: "PepeLaugh", Platform: emote.SevenTV}, set: emoteSetChannel}
+
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"pepe", true},
+		{"pepe laugh", true},
+		{"pepe hands", false},
+		{"provider:7tv", true},
+		{"provider:bttv", false},
+		{"provider:bttv provider:7tv", true},
+		{"set:channel pepe", true},
+		{"set:global", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+
+			f, err := parseEmoteFilter(tt.query)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, f.matches(pepeLaugh))
+		})
+	}
+}
+
+func TestCollectPickerEmotes(t *testing.T) {
+	t.Parallel()
+
+	cache := stubPickerEmoteCache{
+		global:  emote.EmoteSet{{ID: "1", Text: "Kappa", Platform: emote.Twitch}},
+		channel: emote.EmoteSet{{ID: "2", Text: "KEKW", Platform: emote.SevenTV}},
+		user: emote.EmoteSet{
+			{ID: "3", Text: "subHype", Platform: emote.Twitch},
+			{ID: "2", Text: "KEKW", Platform: emote.SevenTV},
+		},
+		foreign: emote.EmoteSet{{ID: "4", Text: "otherHype", Platform: emote.Twitch}},
+	}
+
+	got := map[string]emoteSetKind{}
+	for _, e := range collectPickerEmotes(cache, "channel-id", "user-id") {
+		got[e.emote.Text] = e.set
+	}
+
+	require.Equal(t, map[string]emoteSetKind{
+		"Kappa":     emoteSetGlobal,
+		"KEKW":      emoteSetChannel,
+		"subHype":   emoteSetSub,
+		"otherHype": emoteSetForeign,
+	}, got)
+}
+
+func TestEmoteOverview_navigation(t *testing.T) {
+	t.Parallel()
+
+	deps := &DependencyContainer{Keymap: save.BuildDefaultKeyMap()}
+	o := NewEmoteOverview("channel-id", "channel", "user-id", deps, 30, 40)
+
+	var emotes []emoteWithOverwrite
+	for _, text := range []string{"aaaa", "bbbb", "cccc", "dddd", "eeee", "ffff", "gggg"} {
+		emotes = append(emotes, emoteWithOverwrite{emote: emote.Emote{ID: text, Text: text, Platform: emote.SevenTV}, overwrite: text})
+	}
+
+	o, _ = o.Update(emoteOverviewSetDataMessage{id: o.id, emotes: emotes})
+
+	require.Equal(t, [][]int{{0, 1, 2, 3}, {4, 5, 6}}, o.rows)
+
+	selected := func() string {
+		text, ok := o.selectedEmote()
+		require.True(t, ok)
+		return text
+	}
+
+	require.Equal(t, "aaaa", selected())
+
+	o.moveCursor(1)
+	require.Equal(t, "bbbb", selected())
+
+	o.moveRow(1)
+	require.Equal(t, "ffff", selected())
+
+	// moving past the last row keeps the cursor in place
+	o.moveRow(1)
+	require.Equal(t, "ffff", selected())
+
+	o.moveCursor(1)
+	o.moveRow(-1)
+	require.Equal(t, "cccc", selected())
+
+	// the last row is shorter, the cursor moves to its last column
+	o.moveCursor(1)
+	o.moveRow(1)
+	require.Equal(t, "gggg", selected())
+
+	o.filterInput.SetValue("provider:7tv ee")
+	o.applyFilter()
+	require.Equal(t, "eeee", selected())
+	require.Len(t, o.items, 1)
+
+	o.filterInput.SetValue("provider:bttv")
+	o.applyFilter()
+	_, ok := o.selectedEmote()
+	require.False(t, ok)
+}
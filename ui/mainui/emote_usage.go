@@ -0,0 +1,136 @@
+package mainui
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/julez-dev/chatuino/save/messagelog"
+	"github.com/rs/zerolog/log"
+)
+
+// channelEmoteHistorySize is the number of logged messages searched for the emotes chat uses the most.
+const channelEmoteHistorySize = 2000
+
+type emoteUsageCount struct {
+	text  string
+	count int
+}
+
+// emotesInMessage returns the distinct emotes used in a message.
+func emotesInMessage(cache EmoteCache, channelID, message string) []string {
+	var emotes []string
+	for word := range strings.FieldsSeq(message) {
+		if slices.Contains(emotes, word) {
+			continue
+		}
+
+		if _, ok := cache.GetByText(channelID, word); ok {
+			emotes = append(emotes, word)
+		}
+	}
+
+	return emotes
+}
+
+// countChatEmotes counts the messages using each emote. Repeating an emote in a message counts once.
+func countChatEmotes(cache EmoteCache, channelID string, entries []messagelog.LogEntry) map[string]int {
+	counts := map[string]int{}
+	for _, entry := range entries {
+		if entry.PrivateMessage == nil {
+			continue
+		}
+
+		for _, e := range emotesInMessage(cache, channelID, entry.PrivateMessage.Message) {
+			counts[e]++
+		}
+	}
+
+	return counts
+}
+
+func loadChatEmoteCounts(deps *DependencyContainer, channelID, channelLogin string) map[string]int {
+	if deps.MessageLogger == nil {
+		return nil
+	}
+
+	entries, err := deps.MessageLogger.RecentMessagesInChannel(channelLogin, channelEmoteHistorySize)
+	if err != nil {
+		log.Logger.Err(err).Str("channel", channelLogin).Msg("failed to load logged messages for emote usage")
+		return nil
+	}
+
+	return countChatEmotes(deps.EmoteCache, channelID, entries)
+}
+
+// loadEmoteScores ranks emotes for tab completion. Emotes recently and frequently sent by the user
+// come first, followed by the emotes chat uses the most in the channel. Emotes used by chat score below 1 and
+// emotes sent by the user 1 and above, so the user's emotes stay first however long ago they were sent.
+func loadEmoteScores(deps *DependencyContainer, channelID, channelLogin string) map[string]float64 {
+	scores := map[string]float64{}
+
+	chatCounts := loadChatEmoteCounts(deps, channelID, channelLogin)
+	maxCount := 0
+	for _, count := range chatCounts {
+		maxCount = max(maxCount, count)
+	}
+
+	for text, count := range chatCounts {
+		scores[text] = float64(count) / float64(maxCount+1)
+	}
+
+	if deps.EmoteUsage == nil {
+		return scores
+	}
+
+	usage, err := deps.EmoteUsage.LoadUsage()
+	if err != nil {
+		log.Logger.Err(err).Msg("failed to load emote usage")
+		return scores
+	}
+
+	now := time.Now()
+	for _, entry := range usage {
+		scores[entry.Text] = 1 + entry.Score(channelID, now)
+	}
+
+	return scores
+}
+
+// mostUsedEmotesInChannel returns the emotes used the most in a channel by chat and the user, most used first.
+func mostUsedEmotesInChannel(deps *DependencyContainer, channelID, channelLogin string, limit int) []emoteUsageCount {
+	counts := loadChatEmoteCounts(deps, channelID, channelLogin)
+	if counts == nil {
+		counts = map[string]int{}
+	}
+
+	if deps.EmoteUsage != nil {
+		usage, err := deps.EmoteUsage.LoadUsage()
+		if err != nil {
+			log.Logger.Err(err).Msg("failed to load emote usage")
+		}
+
+		for _, entry := range usage {
+			if n := entry.Channels[channelID]; n > 0 {
+				counts[entry.Text] += n
+			}
+		}
+	}
+
+	ranked := make([]emoteUsageCount, 0, len(counts))
+	for _, text := range slices.Sorted(maps.Keys(counts)) {
+		ranked = append(ranked, emoteUsageCount{text: text, count: counts[text]})
+	}
+
+	slices.SortStableFunc(ranked, func(a, b emoteUsageCount) int {
+		return cmp.Compare(b.count, a.count)
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	return ranked
+}
@@ -0,0 +1,111 @@
+package mainui
+
+import (
+	"testing"
+	"time"
+
+	"github.com/julez-dev/chatuino/emote"
+	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/save/messagelog"
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+	"github.com/stretchr/testify/require"
+)
+
+type stubEmoteCache struct {
+	EmoteCache
+	emotes map[string]struct{}
+}
+
+func (s stubEmoteCache) GetByText(_, text string) (emote.Emote, bool) {
+	_, ok := s.emotes[text]
+	return emote.Emote{Text: text}, ok
+}
+
+type stubMessageLogger struct {
+	MessageLogger
+	messages []string
+}
+
+func (s stubMessageLogger) RecentMessagesInChannel(string, int) ([]messagelog.LogEntry, error) {
+	entries := make([]messagelog.LogEntry, 0, len(s.messages))
+	for _, m := range s.messages {
+		entries = append(entries, messagelog.LogEntry{PrivateMessage: &twitchirc.PrivateMessage{Message: m}})
+	}
+	return entries, nil
+}
+
+type stubEmoteUsage struct {
+	EmoteUsage
+	entries []save.EmoteUsageEntry
+}
+
+func (s stubEmoteUsage) LoadUsage() ([]save.EmoteUsageEntry, error) {
+	return s.entries, nil
+}
+
+func TestEmotesInMessage(t *testing.T) {
+	t.Parallel()
+
+	cache := stubEmoteCache{emotes: map[string]struct{}{"KEKW": {}, "Kappa": {}}}
+
+	require.Equal(t, []string{"KEKW", "Kappa"}, emotesInMessage(cache, "", "KEKW that was funny KEKW Kappa"))
+	require.Empty(t, emotesInMessage(cache, "", "no emotes here"))
+}
+
+func TestMostUsedEmotesInChannel(t *testing.T) {
+	t.Parallel()
+
+	deps := &DependencyContainer{
+		EmoteCache: stubEmoteCache{emotes: map[string]struct{}{"KEKW": {}, "Kappa": {}, "LUL": {}, "PogU": {}}},
+		MessageLogger: stubMessageLogger{messages: []string{
+			"KEKW KEKW KEKW",
+			"KEKW",
+			"LUL",
+			"Kappa hello",
+		}},
+		EmoteUsage: stubEmoteUsage{entries: []save.EmoteUsageEntry{
+			{Text: "PogU", Count: 5, Channels: map[string]int{"channel-id": 3}},
+			{Text: "Kappa", Count: 10, Channels: map[string]int{"other-channel-id": 10}},
+		}},
+	}
+
+	got := mostUsedEmotesInChannel(deps, "channel-id", "channel", 3)
+	require.Equal(t, []emoteUsageCount{
+		{text: "PogU", count: 3},
+		{text: "KEKW", count: 2},
+		{text: "Kappa", count: 1},
+	}, got)
+}
+
+func TestLoadEmoteScores(t *testing.T) {
+	t.Parallel()
+
+	deps := &DependencyContainer{
+		EmoteCache:    stubEmoteCache{emotes: map[string]struct{}{"KEKW": {}, "LUL": {}}},
+		MessageLogger: stubMessageLogger{messages: []string{"KEKW", "KEKW", "LUL"}},
+	}
+
+	scores := loadEmoteScores(deps, "channel-id", "channel")
+	require.InDelta(t, 2.0/3, scores["KEKW"], 0.0001)
+	require.InDelta(t, 1.0/3, scores["LUL"], 0.0001)
+
+	t.Run("emotes sent by the user rank above chat", func(t *testing.T) {
+		t.Parallel()
+
+		messages := make([]string, 0, channelEmoteHistorySize)
+		for range channelEmoteHistorySize {
+			messages = append(messages, "KEKW")
+		}
+
+		deps := &DependencyContainer{
+			EmoteCache:    stubEmoteCache{emotes: map[string]struct{}{"KEKW": {}, "LUL": {}}},
+			MessageLogger: stubMessageLogger{messages: messages},
+			EmoteUsage: stubEmoteUsage{entries: []save.EmoteUsageEntry{
+				{Text: "LUL", Count: 1, LastUsed: time.Now().AddDate(-2, 0, 0)},
+			}},
+		}
+
+		scores := loadEmoteScores(deps, "channel-id", "channel")
+		require.Greater(t, scores["LUL"], scores["KEKW"])
+	})
+}
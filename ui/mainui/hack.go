@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/julez-dev/chatuino/save"
 	"github.com/julez-dev/chatuino/twitch/twitchirc"
@@ -122,9 +123,12 @@ func messageContainsCaseInsensitive(msg *twitchirc.PrivateMessage, sub string) b
 
 func messageMatchesBlocked(msg twitchirc.IRCer, settings save.BlockSettings) bool {
 	var (
-		senderUserName string
-		senderMessage  string
-		senderUserType twitchirc.UserType
+		senderUserName  string
+		senderLoginName string
+		senderMessage   string
+		senderBadges    []twitchirc.Badge
+		senderUserType  twitchirc.UserType
+		channel         string
 	)
 
 	switch msg := msg.(type) {
@@ -134,23 +138,32 @@ func messageMatchesBlocked(msg twitchirc.IRCer, settings save.BlockSettings) boo
 		}
 
 		senderUserName = msg.DisplayName
+		senderLoginName = msg.LoginName
 		senderMessage = msg.Message
+		senderBadges = msg.Badges
 		senderUserType = msg.UserType
+		channel = msg.ChannelUserName
 	case *twitchirc.SubGiftMessage:
 		if msg.Mod {
 			return false
 		}
 
 		senderUserName = msg.DisplayName
+		senderLoginName = msg.Login
+		senderBadges = msg.Badges
 		senderUserType = msg.UserType
+		channel = msg.ChannelUserName
 	case *twitchirc.SubMessage:
 		if msg.Mod {
 			return false
 		}
 
 		senderUserName = msg.DisplayName
+		senderLoginName = msg.Login
 		senderMessage = msg.Message
+		senderBadges = msg.Badges
 		senderUserType = msg.UserType
+		channel = msg.ChannelUserName
 	default:
 		return false
 	}
@@ -159,21 +172,46 @@ func messageMatchesBlocked(msg twitchirc.IRCer, settings save.BlockSettings) boo
 		return false
 	}
 
-	senderUserName = strings.ToLower(senderUserName)
-	senderMessage = strings.ToLower(senderMessage)
-
 	for _, blockedUser := range settings.Users {
 		if strings.EqualFold(senderUserName, blockedUser) {
 			return true
 		}
 	}
 
+	for _, blockedUser := range settings.TwitchUsers {
+		if strings.EqualFold(senderLoginName, blockedUser) || strings.EqualFold(senderUserName, blockedUser) {
+			return true
+		}
+	}
+
 	for _, blockedWord := range settings.Words {
 		if strings.EqualFold(senderMessage, blockedWord) {
 			return true
 		}
 	}
 
+	now := time.Now()
+	for _, rule := range settings.Rules {
+		if !rule.AppliesTo(channel, now) {
+			continue
+		}
+
+		switch rule.FieldOrDefault() {
+		case save.BlockFieldContent:
+			if rule.Matches(senderMessage) {
+				return true
+			}
+		case save.BlockFieldUser:
+			if rule.Matches(senderUserName) || (senderLoginName != "" && rule.Matches(senderLoginName)) {
+				return true
+			}
+		case save.BlockFieldBadge:
+			if slices.ContainsFunc(senderBadges, func(b twitchirc.Badge) bool { return rule.Matches(b.Name) }) {
+				return true
+			}
+		}
+	}
+
 	return false
 }
 
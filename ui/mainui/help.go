@@ -26,6 +26,8 @@ func newHelp(height, width int, deps *DependencyContainer) *help {
 			[]key.Binding{
 				deps.Keymap.Up,
 				deps.Keymap.Down,
+				deps.Keymap.Left,
+				deps.Keymap.Right,
 				deps.Keymap.Escape,
 				deps.Keymap.Confirm,
 				deps.Keymap.Help,
@@ -40,6 +42,7 @@ func newHelp(height, width int, deps *DependencyContainer) *help {
 				deps.Keymap.Remove,
 				deps.Keymap.CloseTab,
 				deps.Keymap.DumpScreen,
+				deps.Keymap.ConnectionStatus,
 			},
 		},
 		{
@@ -49,6 +52,15 @@ func newHelp(height, width int, deps *DependencyContainer) *help {
 				deps.Keymap.Previous,
 			},
 		},
+		{
+			"Split View",
+			[]key.Binding{
+				deps.Keymap.SplitView,
+				deps.Keymap.SplitOrientation,
+				deps.Keymap.NextPane,
+				deps.Keymap.PreviousPane,
+			},
+		},
 		{
 			"Chat Binds",
 			[]key.Binding{
@@ -63,12 +75,16 @@ func newHelp(height, width int, deps *DependencyContainer) *help {
 				deps.Keymap.CopyMessage,
 				deps.Keymap.SearchMode,
 				deps.Keymap.QuickSent,
+				deps.Keymap.EmotePicker,
+				deps.Keymap.Reply,
+				deps.Keymap.ShowThread,
 			},
 		},
 		{
 			"Account Binds",
 			[]key.Binding{
 				deps.Keymap.MarkLeader,
+				deps.Keymap.Reauthorize,
 			},
 		},
 	}
@@ -0,0 +1,82 @@
+package mainui
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/julez-dev/chatuino/save"
+)
+
+// inputHistoryTab is a tab with a message input, its history and unsent draft are kept across restarts.
+type inputHistoryTab interface {
+	inputHistory() (save.InputHistory, bool) // false when the input is not ready yet
+	setInputHistory(save.InputHistory)
+}
+
+// inputHistoryKey identifies the input history of a tab by account and channel, so tabs of the same channel
+// with different accounts keep their own history.
+func inputHistoryKey(t tab) string {
+	return t.AccountID() + ":" + legacyInputHistoryKey(t)
+}
+
+// legacyInputHistoryKey is the key of histories saved before the account was part of it.
+func legacyInputHistoryKey(t tab) string {
+	if t.Kind() == CombinedTabKind {
+		return "combined:" + strings.ToLower(t.Channel())
+	}
+
+	return strings.ToLower(t.Channel())
+}
+
+// restoreInputHistory sets the input history of the last tab with the same account and channel.
+func (r *Root) restoreInputHistory(t tab) {
+	ht, ok := t.(inputHistoryTab)
+	if !ok || r.inputHistory == nil {
+		return
+	}
+
+	if h, ok := r.inputHistory[inputHistoryKey(t)]; ok {
+		ht.setInputHistory(h)
+		return
+	}
+
+	// the first tab of the channel takes over a history saved without account
+	if h, ok := r.inputHistory[legacyInputHistoryKey(t)]; ok {
+		delete(r.inputHistory, legacyInputHistoryKey(t))
+		ht.setInputHistory(h)
+	}
+}
+
+// storeInputHistory remembers the input history of the tab, so it is still saved after the tab was closed.
+func (r *Root) storeInputHistory(t tab) {
+	ht, ok := t.(inputHistoryTab)
+	if !ok || r.inputHistory == nil {
+		return
+	}
+
+	h, ok := ht.inputHistory()
+	if !ok {
+		return
+	}
+
+	if len(h.Entries) == 0 && h.Draft == "" {
+		delete(r.inputHistory, inputHistoryKey(t))
+		return
+	}
+
+	r.inputHistory[inputHistoryKey(t)] = h
+}
+
+// InputHistorySnapshot returns the input history of all open and previously closed tabs.
+// It returns nil when the history was not loaded, to not overwrite the history on disk.
+func (r *Root) InputHistorySnapshot() map[string]save.InputHistory {
+	if r.inputHistory == nil {
+		return nil
+	}
+
+	for _, t := range r.tabs {
+		r.storeInputHistory(t)
+	}
+
+	return maps.Clone(r.inputHistory)
+}
@@ -0,0 +1,59 @@
+package mainui
+
+import (
+	"testing"
+
+	"github.com/julez-dev/chatuino/save"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoot_InputHistory(t *testing.T) {
+	t.Parallel()
+
+	r := &Root{}
+	c := newTestCombinedTab(t, []string{"julez"})
+
+	// nothing is saved before the history was loaded
+	r.tabs = []tab{c}
+	require.Nil(t, r.InputHistorySnapshot())
+
+	r.inputHistory = map[string]save.InputHistory{
+		"account:combined:julez": {Entries: []string{"hello"}, Draft: "unsent"},
+		"other:combined:julez":   {Entries: []string{"other account"}},
+		"forsen":                 {Entries: []string{"other"}},
+	}
+
+	r.restoreInputHistory(c)
+	require.Equal(t, []string{"hello"}, c.messageInput.History())
+	require.Equal(t, "unsent", c.messageInput.Value())
+
+	c.messageInput.SetHistory([]string{"hello", "world"})
+	c.messageInput.SetValue("")
+
+	snapshot := r.InputHistorySnapshot()
+	require.Equal(t, save.InputHistory{Entries: []string{"hello", "world"}}, snapshot["account:combined:julez"])
+
+	// history of closed tabs and other accounts is kept
+	require.Equal(t, []string{"other"}, snapshot["forsen"].Entries)
+	require.Equal(t, []string{"other account"}, snapshot["other:combined:julez"].Entries)
+}
+
+func TestRoot_InputHistoryWithoutAccount(t *testing.T) {
+	t.Parallel()
+
+	r := &Root{}
+	c := newTestCombinedTab(t, []string{"julez"})
+	r.tabs = []tab{c}
+
+	r.inputHistory = map[string]save.InputHistory{
+		"combined:julez": {Entries: []string{"hello"}},
+	}
+
+	// histories saved before the account was part of the key are taken over once
+	r.restoreInputHistory(c)
+	require.Equal(t, []string{"hello"}, c.messageInput.History())
+
+	snapshot := r.InputHistorySnapshot()
+	require.NotContains(t, snapshot, "combined:julez")
+	require.Equal(t, []string{"hello"}, snapshot["account:combined:julez"].Entries)
+}
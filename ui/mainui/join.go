@@ -143,9 +143,13 @@ func newJoin(parentWidth int, deps *DependencyContainer) *join {
 			title: LiveNotificationTabKind.String(),
 			kind:  LiveNotificationTabKind,
 		},
+		listItem{
+			title: CombinedTabKind.String(),
+			kind:  CombinedTabKind,
+		},
 	})
 	tabKindList.Select(0)
-	tabKindList.SetHeight(4)
+	tabKindList.SetHeight(5)
 
 	channelList := createDefaultList(0, deps.UserConfig.Theme.ListSelectedColor)
 	channelList.SetStatusBarItemName("account", "accounts")
@@ -323,7 +327,8 @@ func (j *join) Update(msg tea.Msg) (*join, tea.Cmd) {
 			// Check if inputs are valid for confirmation
 			isValid := (j.input.Value() != "" && kind == BroadcastTabKind) ||
 				kind == MentionTabKind ||
-				kind == LiveNotificationTabKind
+				kind == LiveNotificationTabKind ||
+				kind == CombinedTabKind // no channels combine all open channels
 
 			if key.Matches(msg, j.deps.Keymap.Confirm) && isValid {
 				channel := j.input.Value()
@@ -413,22 +418,27 @@ func (j *join) View() string {
 
 	selectedLabelStyle := lipgloss.NewStyle().MarginBottom(1).MarginTop(1).Foreground(lipgloss.Color(j.deps.UserConfig.Theme.ActiveLabelColor)).Bold(true).Render
 
+	channelLabel := "Channel"
+	if i, ok := j.tabKindList.SelectedItem().(listItem); ok && i.kind == CombinedTabKind {
+		channelLabel = "Channels (comma separated, empty = all)"
+	}
+
 	switch j.selectedInput {
 	case channelInput:
 		labelTab = labelStyle("Tab type")
-		labelChannel = selectedLabelStyle("Channel")
+		labelChannel = selectedLabelStyle(channelLabel)
 		labelIdentity = labelStyle("Identity")
 	case accountSelect:
 		labelTab = labelStyle("Tab type")
-		labelChannel = labelStyle("Channel")
+		labelChannel = labelStyle(channelLabel)
 		labelIdentity = selectedLabelStyle("Identity")
 	case tabSelect:
 		labelTab = selectedLabelStyle("Tab type")
-		labelChannel = labelStyle("Channel")
+		labelChannel = labelStyle(channelLabel)
 		labelIdentity = labelStyle("Identity")
 	default:
 		labelTab = labelStyle("Tab type")
-		labelChannel = labelStyle("Channel")
+		labelChannel = labelStyle(channelLabel)
 		labelIdentity = labelStyle("Identity")
 	}
 
@@ -0,0 +1,190 @@
+package mainui
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/julez-dev/chatuino/server"
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// linkTitleMaxLen limits the page title shown next to links, the server already shortens it but chat needs it shorter
+	linkTitleMaxLen = 60
+
+	// linkPreviewTimeout bounds fetching the previews of one message
+	linkPreviewTimeout = 15 * time.Second
+)
+
+// linkPreviewMessage carries the annotated tokens of a message, sent once the previews of its links arrived.
+type linkPreviewMessage struct {
+	tabID       string          // set for events built for a single tab, like loaded history
+	message     twitchirc.IRCer // the message the previews belong to, matched by identity
+	annotations wordReplacement // token:token with the annotated links
+}
+
+// linkPreviewCmd fetches the previews of the links in the message of the event, the chat shows the message
+// right away and annotates the links when the previews arrive.
+func (r *Root) linkPreviewCmd(evt chatEventMessage) tea.Cmd {
+	if !r.dependencies.UserConfig.Settings.Security.CheckLinks {
+		return nil
+	}
+
+	message := ircMessageText(evt.message)
+	if !strings.Contains(message, "://") {
+		return nil
+	}
+
+	api := r.dependencies.ServerAPI
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), linkPreviewTimeout)
+		defer cancel()
+
+		annotations := wordReplacement{}
+
+		// Key link annotations on the whole space-delimited token so they match
+		// exactly in applyWordReplacements (same contract as emotes). The URL is
+		// annotated in place, preserving any surrounding punctuation in the token.
+		for _, token := range strings.Split(message, " ") {
+			annotated := token
+			for _, u := range extractValidURLs(token) {
+				preview, err := api.GetLinkPreview(ctx, u)
+				if err != nil {
+					log.Logger.Info().Err(err).Str("url", u).Msg("failed to get link preview")
+					continue
+				}
+
+				if annotation := linkAnnotation(preview); annotation != "" {
+					annotated = strings.Replace(annotated, u, fmt.Sprintf("%s [%s]", u, annotation), 1)
+				}
+			}
+
+			if annotated != token {
+				annotations[token] = annotated
+			}
+		}
+
+		if len(annotations) == 0 {
+			return nil
+		}
+
+		return linkPreviewMessage{tabID: evt.tabID, message: evt.message, annotations: annotations}
+	}
+}
+
+// handleLinkPreview annotates the links of the message, if it is still shown.
+func (c *chatWindow) handleLinkPreview(msg linkPreviewMessage) {
+	var changed bool
+
+	for _, e := range c.entries {
+		if e.Event.message != msg.message {
+			continue
+		}
+
+		// the replacements of an event are shared between tabs
+		replacements := maps.Clone(e.Event.displayModifier.wordReplacements)
+		if replacements == nil {
+			replacements = wordReplacement{}
+		}
+
+		maps.Copy(replacements, msg.annotations)
+		e.Event.displayModifier.wordReplacements = replacements
+		changed = true
+	}
+
+	if changed {
+		c.recalculateLines()
+	}
+}
+
+// ircMessageText returns the chat message of the event types whose text is shown, empty for other types.
+func ircMessageText(ircer twitchirc.IRCer) string {
+	switch msg := ircer.(type) {
+	case *twitchirc.PrivateMessage:
+		return msg.Message
+	case *twitchirc.SubMessage:
+		return msg.Message
+	case *twitchirc.RitualMessage:
+		return msg.Message
+	case *twitchirc.AnnouncementMessage:
+		return msg.Message
+	}
+
+	return ""
+}
+
+// linkAnnotation returns the text shown in brackets next to a link: the page title for pages, media type and size for
+// other files, the status if the link is broken and the target of redirects, e.g. of URL shorteners.
+func linkAnnotation(preview server.LinkPreview) string {
+	var parts []string
+
+	if preview.StatusCode != http.StatusOK {
+		parts = append(parts, http.StatusText(preview.StatusCode))
+	}
+
+	if preview.Title != "" {
+		parts = append(parts, fmt.Sprintf("%q", truncateRunes(preview.Title, linkTitleMaxLen)))
+	} else if preview.ContentType != "" {
+		mediaType, _, err := mime.ParseMediaType(preview.ContentType)
+		if err != nil {
+			mediaType, _, _ = strings.Cut(preview.ContentType, ";")
+		}
+
+		if preview.ContentLength > 0 {
+			mediaType += " " + formatByteSize(preview.ContentLength)
+		}
+
+		parts = append(parts, mediaType)
+	}
+
+	if preview.FinalURL != "" && preview.FinalURL != preview.URL {
+		final, err := url.QueryUnescape(preview.FinalURL)
+		if err != nil {
+			final = preview.FinalURL
+		}
+
+		if preview.Shortener {
+			final = "shortened, → " + final
+		} else {
+			final = "→ " + final
+		}
+
+		parts = append(parts, final)
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+
+	return strings.TrimSpace(string([]rune(s)[:n])) + "…"
+}
+
+// formatByteSize formats n bytes like "1.5 MB"
+func formatByteSize(n int64) string {
+	const unit = 1000
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "kMGTPE"[exp])
+}
@@ -0,0 +1,108 @@
+package mainui
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/server"
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkAnnotation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		preview server.LinkPreview
+		want    string
+	}{
+		{
+			name:    "page title",
+			preview: server.LinkPreview{URL: "https://a.com", FinalURL: "https://a.com", StatusCode: http.StatusOK, ContentType: "text/html; charset=utf-8", Title: "Stream Highlights"},
+			want:    `"Stream Highlights"`,
+		},
+		{
+			name:    "file with size",
+			preview: server.LinkPreview{URL: "https://a.com/x.png", FinalURL: "https://a.com/x.png", StatusCode: http.StatusOK, ContentType: "image/png", ContentLength: 1_500_000},
+			want:    "image/png 1.5 MB",
+		},
+		{
+			name:    "resolved shortener",
+			preview: server.LinkPreview{URL: "https://bit.ly/abc", FinalURL: "https://b.com/page%20one", StatusCode: http.StatusOK, Title: "Page", Shortener: true},
+			want:    `"Page", shortened, → https://b.com/page one`,
+		},
+		{
+			name:    "broken link",
+			preview: server.LinkPreview{URL: "https://a.com/gone", FinalURL: "https://a.com/gone", StatusCode: http.StatusNotFound, ContentType: "text/html"},
+			want:    "Not Found, text/html",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, linkAnnotation(tt.preview))
+		})
+	}
+}
+
+func TestFormatByteSize(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "999 B", formatByteSize(999))
+	require.Equal(t, "1.0 kB", formatByteSize(1000))
+	require.Equal(t, "2.5 GB", formatByteSize(2_500_000_000))
+}
+
+type stubLinkPreviewServer struct {
+	ChatuinoServer
+	previews map[string]server.LinkPreview
+}
+
+func (s stubLinkPreviewServer) GetLinkPreview(_ context.Context, targetURL string) (server.LinkPreview, error) {
+	preview, ok := s.previews[targetURL]
+	if !ok {
+		return server.LinkPreview{}, errors.New("not found")
+	}
+
+	return preview, nil
+}
+
+func TestLinkPreview_AnnotatesShownMessage(t *testing.T) {
+	t.Parallel()
+
+	r := &Root{dependencies: &DependencyContainer{
+		ServerAPI: stubLinkPreviewServer{previews: map[string]server.LinkPreview{
+			"https://a.com": {URL: "https://a.com", FinalURL: "https://a.com", StatusCode: http.StatusOK, Title: "Stream Highlights"},
+		}},
+		UserConfig: UserConfiguration{Settings: save.Settings{Security: save.SecuritySettings{CheckLinks: true}}},
+	}}
+
+	c := newTestChatWindow(80, 10)
+
+	msg := &twitchirc.PrivateMessage{DisplayName: "User", Message: "look (https://a.com) https://b.com", TMISentTS: time.Now()}
+	evt := chatEventMessage{message: msg, displayModifier: messageContentModifier{wordReplacements: wordReplacement{}}}
+	c.handleMessage(evt)
+
+	// the message is shown before the preview is fetched
+	require.NotContains(t, stripAnsi(c.lines[0]), "Stream Highlights")
+
+	cmd := r.linkPreviewCmd(evt)
+	require.NotNil(t, cmd)
+
+	preview, ok := cmd().(linkPreviewMessage)
+	require.True(t, ok)
+	require.Equal(t, wordReplacement{"(https://a.com)": `(https://a.com ["Stream Highlights"])`}, preview.annotations)
+
+	c.handleLinkPreview(preview)
+	require.Contains(t, stripAnsi(c.lines[0]), `(https://a.com ["Stream Highlights"])`)
+	require.Empty(t, evt.displayModifier.wordReplacements, "the replacements shared with other tabs are not changed")
+
+	// messages without links don't fetch anything
+	require.Nil(t, r.linkPreviewCmd(chatEventMessage{message: &twitchirc.PrivateMessage{Message: "no links"}}))
+}
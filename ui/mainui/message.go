@@ -13,6 +13,7 @@ type persistedDataLoadedMessage struct {
 	ttvUsers           map[string]twitchapi.UserData
 	state              save.AppState
 	channelSuggestions []string
+	inputHistory       map[string]save.InputHistory
 }
 
 // chatEventMessage comes when we receive a IRC event
@@ -45,6 +46,8 @@ type (
 		wordReplacements wordReplacement
 		badgeReplacement wordReplacement
 		messageSuffix    string
+		channelTag       string // rendered channel name shown in front of messages in the combined chat
+		highlight        string // color of the time stamp background, set by user scripts
 		strikethrough    bool
 		italic           bool
 	}
@@ -66,6 +69,27 @@ type requestLocalMessageHandleBatchMessage struct {
 	tabID     string
 }
 
+// requestLocalHistoryHandleMessage comes when older messages should be converted into chat events and
+// prepended to the chat of a tab
+type requestLocalHistoryHandleMessage struct {
+	messages        []twitchirc.IRCer
+	deletedIDs      map[string]struct{} // ids of private messages which were deleted
+	accountID       string
+	tabID           string
+	popped          int  // number of messages restored from the scrollback
+	scrollbackEmpty bool // popping the scrollback found no messages
+}
+
+// chatHistoryLoadedMessage contains older messages for a tab, err is set when they could not be loaded
+type chatHistoryLoadedMessage struct {
+	tabID           string
+	events          []chatEventMessage
+	deletedIDs      map[string]struct{}
+	popped          int
+	scrollbackEmpty bool
+	err             error
+}
+
 // EventSubMessage is kept for backward compatibility but no longer used.
 // Events now come through wspool.EventSubEvent.
 type EventSubMessage struct {
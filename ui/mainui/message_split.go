@@ -0,0 +1,150 @@
+package mainui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"charm.land/lipgloss/v2"
+	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/twitch/twitchapi"
+)
+
+const (
+	// twitchMessageLimit is the maximum number of characters of a single chat message.
+	twitchMessageLimit = 500
+	// maxMessageParts limits how many messages a long input is split into.
+	maxMessageParts = 5
+	// messageLimitWarning is the input length at which the character counter changes colour.
+	messageLimitWarning = 450
+
+	// messageDelay is the time between sent messages for regular users.
+	messageDelay = time.Second
+	// elevatedMessageDelay is the time between sent messages for moderators and VIPs, which have a higher rate limit.
+	elevatedMessageDelay = 300 * time.Millisecond
+)
+
+// splitMessage splits text into messages of at most limit characters, breaking at spaces where possible.
+// Words longer than limit are split across messages.
+func splitMessage(text string, limit int) []string {
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) <= limit {
+		return []string{text}
+	}
+
+	var (
+		parts []string
+		runes = []rune(text)
+	)
+
+	for len(runes) > limit {
+		cut := limit
+		for i := limit; i > 0; i-- {
+			if unicode.IsSpace(runes[i]) {
+				cut = i
+				break
+			}
+		}
+
+		parts = append(parts, strings.TrimSpace(string(runes[:cut])))
+		runes = []rune(strings.TrimLeftFunc(string(runes[cut:]), unicode.IsSpace))
+	}
+
+	if len(runes) > 0 {
+		parts = append(parts, string(runes))
+	}
+
+	return parts
+}
+
+// messageParts splits the input into the messages to send. A message equal to the message sent before it
+// gets the duplicate bypass appended, since Twitch drops repeated messages.
+func messageParts(input, lastMessageSent string) []string {
+	limit := twitchMessageLimit
+	if utf8.RuneCountInString(input) > limit {
+		// leave room for the duplicate bypass
+		limit -= 2
+	}
+
+	parts := splitMessage(input, limit)
+	previous := lastMessageSent
+
+	for i, part := range parts {
+		if strings.EqualFold(part, previous) {
+			parts[i] = part + " " + string(duplicateBypass)
+		}
+
+		previous = parts[i]
+	}
+
+	return parts
+}
+
+// sendChatMessageParts sends the parts one after another, waiting delay between the messages to stay within
+// the chat rate limit. The reply in req only applies to the first part.
+func sendChatMessageParts(client userAuthenticatedAPIClient, req twitchapi.SendChatMessageRequest, parts []string, lastSent time.Time, delay time.Duration) error {
+	for i, part := range parts {
+		if diff := time.Since(lastSent); diff < delay {
+			time.Sleep(delay - diff)
+		}
+
+		req.Message = part
+		err := sendChatMessage(client, req)
+		lastSent = time.Now()
+		req.ReplyMessageID = ""
+
+		if err != nil {
+			if len(parts) > 1 {
+				return fmt.Errorf("part %d of %d: %w", i+1, len(parts), err)
+			}
+
+			return err
+		}
+	}
+
+	return nil
+}
+
+func sendChatMessage(client userAuthenticatedAPIClient, req twitchapi.SendChatMessageRequest) error {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	r, err := client.SendChatMessage(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if len(r.Data) > 0 && !r.Data[0].IsSent {
+		return errors.New(r.Data[0].DropReason.Message)
+	}
+
+	return nil
+}
+
+// renderInputBottomBorder renders the bottom border of the message input with the character counter on the right.
+// The counter shows how many messages a long input is split into and is coloured near and above the limit.
+func renderInputBottomBorder(innerWidth int, input string, borderStyle lipgloss.Style, theme save.Theme) string {
+	count := utf8.RuneCountInString(input)
+
+	counter := fmt.Sprintf("[ %d / %d ]", count, twitchMessageLimit)
+	if parts := len(messageParts(input, "")); count > twitchMessageLimit && parts > 1 {
+		counter = fmt.Sprintf("[ %d / %d · %d messages ]", count, twitchMessageLimit, parts)
+	}
+
+	counterStyle := borderStyle
+	switch {
+	case count > twitchMessageLimit:
+		counterStyle = borderStyle.Foreground(lipgloss.Color(theme.ChatErrorColor))
+	case count >= messageLimitWarning:
+		counterStyle = borderStyle.Foreground(lipgloss.Color(theme.ChatNoticeAlertColor))
+	}
+
+	// └─────...─[ 7 / 500 ]─┘
+	fill := max(innerWidth-lipgloss.Width(counter)-2, 0)
+
+	return borderStyle.Render("└─"+strings.Repeat("─", fill)) + counterStyle.Render(counter) + borderStyle.Render("─┘")
+}
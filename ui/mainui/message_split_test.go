@@ -0,0 +1,70 @@
+package mainui
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitMessage(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"hello world"}, splitMessage(" hello world ", 20))
+	require.Equal(t, []string{"hello", "world foo", "bar"}, splitMessage("hello world foo bar", 10))
+
+	// words longer than the limit are split
+	require.Equal(t, []string{"aaaa", "aaaa", "aa b"}, splitMessage("aaaaaaaaaa b", 4))
+
+	// limit counts characters, not bytes
+	require.Equal(t, []string{"äöü", "äöü"}, splitMessage("äöü äöü", 3))
+
+	long := strings.Repeat("word ", 300)
+	parts := splitMessage(long, twitchMessageLimit)
+	require.Len(t, parts, 3)
+	require.Equal(t, strings.Fields(long), strings.Fields(strings.Join(parts, " ")))
+
+	for _, p := range parts {
+		require.LessOrEqual(t, utf8.RuneCountInString(p), twitchMessageLimit)
+	}
+}
+
+func TestMessageParts(t *testing.T) {
+	t.Parallel()
+
+	bypass := " " + string(duplicateBypass)
+
+	require.Equal(t, []string{"hello"}, messageParts("hello", "other"))
+	require.Equal(t, []string{"hello" + bypass}, messageParts("hello", "HELLO"))
+
+	// repeated parts of a long message bypass the duplicate filter as well
+	word := strings.Repeat("a", twitchMessageLimit-2)
+	parts := messageParts(word+" "+word, "")
+	require.Equal(t, []string{word, word + bypass}, parts)
+
+	for _, p := range parts {
+		require.LessOrEqual(t, utf8.RuneCountInString(p), twitchMessageLimit)
+	}
+}
+
+func TestEmoteSpellChecker(t *testing.T) {
+	t.Parallel()
+
+	require.Nil(t, newEmoteSpellChecker(&DependencyContainer{}, nil))
+
+	checker := newEmoteSpellChecker(&DependencyContainer{
+		SpellChecker: stubSpellChecker{"hello": true},
+		EmoteCache:   stubEmoteCache{emotes: map[string]struct{}{"Kappa": {}}},
+	}, func() string { return "channel" })
+
+	require.True(t, checker.Correct("hello"))
+	require.True(t, checker.Correct("Kappa"))
+	require.False(t, checker.Correct("helo"))
+}
+
+type stubSpellChecker map[string]bool
+
+func (s stubSpellChecker) Correct(word string) bool {
+	return s[word]
+}
@@ -5,8 +5,6 @@ import (
 	"fmt"
 	"io"
 	"maps"
-	"net/http"
-	"net/url"
 	"os"
 	"slices"
 	"strings"
@@ -20,6 +18,7 @@ import (
 	overlay "github.com/julez-dev/bubbletea-overlay"
 	"github.com/julez-dev/chatuino/emote"
 	"github.com/julez-dev/chatuino/save"
+	"github.com/julez-dev/chatuino/script"
 	"github.com/julez-dev/chatuino/twitch/twitchapi"
 	"github.com/julez-dev/chatuino/twitch/twitchirc"
 	"github.com/julez-dev/chatuino/wspool"
@@ -33,6 +32,7 @@ const (
 	BroadcastTabKind TabKind = iota
 	MentionTabKind
 	LiveNotificationTabKind
+	CombinedTabKind
 )
 
 func (t TabKind) String() string {
@@ -43,6 +43,8 @@ func (t TabKind) String() string {
 		return "Mention"
 	case LiveNotificationTabKind:
 		return "Live Notifications"
+	case CombinedTabKind:
+		return "Combined Chat"
 	}
 
 	return "<not implemented>"
@@ -89,6 +91,7 @@ const (
 	inputScreen
 	quickJoinScreen
 	helpScreen
+	connectionStatusScreen
 )
 
 type ircConnectionError struct {
@@ -122,17 +125,27 @@ type Root struct {
 	// message logger
 	messageLoggerChan chan<- *twitchirc.PrivateMessage
 
+	scriptQueue     []wspool.IRCEvent // live chat events waiting for the user scripts
+	scriptRunning   bool              // the scripts run for the first message of scriptQueue
+	scriptResults   *scriptResults    // script results by message id, shared by all accounts receiving the message
+	scriptedActions *recentIDs        // messages user scripts already routed or ran commands for
+
+	inputHistory map[string]save.InputHistory // tab key:input history, nil until loaded
+
 	// components
 	splash         splash
 	header         header
 	joinInput      *join
 	quickJoinInput *quickJoin
 	help           *help
+	connStatus     *connectionStatus
 
 	tabCursor          int
 	tabs               []tab
+	split              splitLayout
 	channelSuggestions []string // cached for broadcast to new tabs
 	updateInfo         *UpdateInfo
+	missingScopes      map[string][]string // scopes missing from account tokens, by account ID
 }
 
 // NewUI creates the root Bubble Tea model. When initialState is non-nil the
@@ -167,8 +180,11 @@ func NewUI(
 		help:           newHelp(10, 10, dependencies),
 		joinInput:      newJoin(10, dependencies),
 		quickJoinInput: newQuickJoin(10, dependencies),
+		connStatus:     newConnectionStatus(10, dependencies),
 
 		messageLoggerChan: messageLoggerChan,
+		scriptResults:     newScriptResults(scriptMemory),
+		scriptedActions:   newRecentIDs(scriptMemory),
 	}
 }
 
@@ -351,16 +367,30 @@ func (r *Root) Init() tea.Cmd {
 
 			err = wg.Wait()
 
+			inputHistory := map[string]save.InputHistory{}
+			if r.dependencies.InputHistory != nil {
+				loaded, loadErr := r.dependencies.InputHistory.LoadInputHistory()
+				if loadErr != nil {
+					log.Logger.Err(loadErr).Msg("failed to load input history")
+					inputHistory = nil // keep the history on disk
+				} else {
+					inputHistory = loaded
+				}
+			}
+
 			return persistedDataLoadedMessage{
 				state:              state,
 				ttvUsers:           ttvUsers,
 				channelSuggestions: channelSuggestions,
+				inputHistory:       inputHistory,
 				err:                err,
 			}
 		},
 		r.tickPollStreamInfos(),
 		r.imageCleanUpCommand(),
 		r.checkVersionCommand(),
+		r.importTwitchBlocksCommand(),
+		r.checkAccountScopesCommand(),
 	)
 }
 
@@ -382,6 +412,10 @@ func (r *Root) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return r, tea.Batch(cmds...)
 	case versionCheckMessage:
 		return r, r.handleVersionCheck(msg)
+	case twitchBlockListMessage:
+		return r, r.handleTwitchBlockList(msg)
+	case accountScopesMessage:
+		return r, r.handleAccountScopes(msg)
 	case joinChannelMessage:
 		r.screenType = mainScreen
 
@@ -449,22 +483,17 @@ func (r *Root) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return r, tea.Batch(cmds...)
 		}
 
-		// Log private messages
 		if privateMsg, ok := msg.Message.(*twitchirc.PrivateMessage); ok {
 			r.messageLoggerChan <- privateMsg.Clone()
 		}
 
-		// Build and forward event to tabs
-		evt := r.buildChatEventMessage(msg.AccountID, "", msg.Message, false)
-		if evt.prepareCommand != "" {
-			cmds = append(cmds, tea.Raw(evt.prepareCommand))
-			evt.prepareCommand = ""
-		}
-		for i := range r.tabs {
-			r.tabs[i], cmd = r.tabs[i].Update(evt)
-			cmds = append(cmds, cmd)
+		if r.dependencies.Scripts != nil {
+			return r, r.queueScriptedEvent(msg)
 		}
-		return r, tea.Batch(cmds...)
+
+		return r, r.handleIRCMessage(msg.AccountID, msg.Message, script.Result{})
+	case scriptsDoneMessage:
+		return r, r.handleScriptsDone(msg)
 	case chatEventMessage:
 		// Handle locally-generated chat events (e.g., from recent messages)
 		if msg.prepareCommand != "" {
@@ -479,6 +508,18 @@ func (r *Root) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			r.tabs[i], cmd = r.tabs[i].Update(msg)
 			cmds = append(cmds, cmd)
 		}
+
+		cmds = append(cmds, r.linkPreviewCmd(msg))
+		return r, tea.Batch(cmds...)
+	case linkPreviewMessage:
+		for i := range r.tabs {
+			if msg.tabID != "" && msg.tabID != r.tabs[i].ID() {
+				continue
+			}
+
+			r.tabs[i], cmd = r.tabs[i].Update(msg)
+			cmds = append(cmds, cmd)
+		}
 		return r, tea.Batch(cmds...)
 	case requestLocalMessageHandleMessage:
 		return r, func() tea.Msg {
@@ -495,6 +536,40 @@ func (r *Root) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		cmds = append(cmds, tea.Sequence(batched...))
 		return r, tea.Batch(cmds...)
+	case requestLocalHistoryHandleMessage:
+		return r, func() tea.Msg {
+			events := make([]chatEventMessage, 0, len(msg.messages))
+			for _, ircer := range msg.messages {
+				events = append(events, r.buildChatEventMessage(msg.accountID, msg.tabID, ircer, true))
+			}
+
+			return chatHistoryLoadedMessage{
+				tabID:           msg.tabID,
+				events:          events,
+				deletedIDs:      msg.deletedIDs,
+				popped:          msg.popped,
+				scrollbackEmpty: msg.scrollbackEmpty,
+			}
+		}
+	case chatHistoryLoadedMessage:
+		for i := range msg.events {
+			if msg.events[i].prepareCommand != "" {
+				cmds = append(cmds, tea.Raw(msg.events[i].prepareCommand))
+				msg.events[i].prepareCommand = ""
+			}
+
+			cmds = append(cmds, r.linkPreviewCmd(msg.events[i]))
+		}
+
+		for i := range r.tabs {
+			if msg.tabID != r.tabs[i].ID() {
+				continue
+			}
+
+			r.tabs[i], cmd = r.tabs[i].Update(msg)
+			cmds = append(cmds, cmd)
+		}
+		return r, tea.Batch(cmds...)
 	case polledStreamInfoMessage:
 		if r.screenType == quickJoinScreen {
 			r.quickJoinInput, cmd = r.quickJoinInput.Update(msg)
@@ -504,6 +579,14 @@ func (r *Root) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return r, tea.Batch(cmds...)
 	case appStateSaveMessage:
 		return r, r.tickSaveAppState()
+	case connectionStatusTickMessage:
+		// stop refreshing once the overlay was closed
+		if r.screenType != connectionStatusScreen {
+			return r, nil
+		}
+
+		r.connStatus, cmd = r.connStatus.Update(msg)
+		return r, cmd
 	case tea.WindowSizeMsg:
 		r.width = msg.Width
 		r.height = msg.Height
@@ -521,7 +604,7 @@ func (r *Root) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if key.Matches(msg, r.dependencies.Keymap.Help) {
 			var isInsertMode bool
 			if len(r.tabs) > r.tabCursor {
-				isInsertMode = (r.tabs[r.tabCursor].State() == insertMode || r.tabs[r.tabCursor].State() == userInspectInsertMode)
+				isInsertMode = (r.tabs[r.tabCursor].State() == insertMode || r.tabs[r.tabCursor].State() == userInspectInsertMode || r.tabs[r.tabCursor].IsSearching())
 			}
 
 			if !isInsertMode && r.screenType == inputScreen && r.joinInput.input.InputModel.Focused() {
@@ -539,7 +622,7 @@ func (r *Root) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 		if key.Matches(msg, r.dependencies.Keymap.Escape) {
-			if r.screenType == inputScreen || r.screenType == helpScreen || r.screenType == quickJoinScreen {
+			if r.screenType == inputScreen || r.screenType == helpScreen || r.screenType == quickJoinScreen || r.screenType == connectionStatusScreen {
 				if len(r.tabs) > r.tabCursor {
 					r.tabs[r.tabCursor].Focus()
 				}
@@ -633,7 +716,45 @@ func (r *Root) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return r, nil
 		}
 
+		if key.Matches(msg, r.dependencies.Keymap.ConnectionStatus) {
+			switch r.screenType {
+			case mainScreen:
+				if len(r.tabs) > r.tabCursor {
+					r.tabs[r.tabCursor].Blur()
+				}
+
+				r.screenType = connectionStatusScreen
+				return r, r.connStatus.Init()
+			case connectionStatusScreen:
+				if len(r.tabs) > r.tabCursor {
+					r.tabs[r.tabCursor].Focus()
+				}
+
+				r.screenType = mainScreen
+				return r, nil
+			}
+		}
+
 		if r.screenType == mainScreen {
+			if key.Matches(msg, r.dependencies.Keymap.SplitView) && !r.activeTabCapturesInput() {
+				return r, r.toggleSplitPane()
+			}
+
+			if key.Matches(msg, r.dependencies.Keymap.SplitOrientation) && !r.activeTabCapturesInput() {
+				r.split.toggleOrientation()
+				r.handleResize()
+				return r, nil
+			}
+
+			if key.Matches(msg, r.dependencies.Keymap.NextPane) && !r.activeTabCapturesInput() {
+				r.focusPane(1)
+				return r, nil
+			}
+
+			if key.Matches(msg, r.dependencies.Keymap.PreviousPane) && !r.activeTabCapturesInput() {
+				r.focusPane(-1)
+				return r, nil
+			}
 
 			if key.Matches(msg, r.dependencies.Keymap.Next) {
 				if len(r.tabs) > r.tabCursor && (r.tabs[r.tabCursor].State() == insertMode || r.tabs[r.tabCursor].State() == userInspectInsertMode) {
@@ -664,6 +785,10 @@ func (r *Root) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 						// if there is another tab for the same channel and the same account
 						hasTabsSameAccountAndChannel := slices.ContainsFunc(r.tabs, func(t tab) bool {
+							if combined, ok := t.(*combinedTab); ok {
+								return combined.joinsChannel(currentTab.AccountID(), currentTab.Channel())
+							}
+
 							return t.ID() != currentTab.ID() &&
 								t.AccountID() == currentTab.AccountID() &&
 								t.ChannelID() == currentTab.ChannelID()
@@ -700,6 +825,10 @@ func (r *Root) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 						return r, tea.Sequence(cmds...)
 					}
 
+					if combined, ok := currentTab.(*combinedTab); ok && currentTab.IsDataLoaded() {
+						return r, r.partCombinedChannels(combined)
+					}
+
 					return r, nil
 				}
 			}
@@ -751,80 +880,30 @@ func (r *Root) renderView() string {
 
 	switch r.screenType {
 	case mainScreen:
-		if len(r.tabs) == 0 {
-			return r.splash.View()
-		}
-
-		if r.dependencies.UserConfig.Settings.VerticalTabList {
-			// In vertical mode, render status bar separately at full width
-			mainContent := lipgloss.JoinHorizontal(lipgloss.Left, r.header.View(), r.tabs[r.tabCursor].ViewWithoutStatusBar())
-			statusBar := r.tabs[r.tabCursor].StatusBarView()
-			if statusBar != "" {
-				return mainContent + "\n" + statusBar
-			}
-			return mainContent
-		}
-
-		return r.header.View() + "\n" + r.tabs[r.tabCursor].View()
+		return r.mainView()
 	case inputScreen:
 		// Composite join modal over the current active tab
-		var background string
-		if len(r.tabs) > 0 && r.tabCursor < len(r.tabs) {
-			if r.dependencies.UserConfig.Settings.VerticalTabList {
-				mainContent := lipgloss.JoinHorizontal(lipgloss.Left, r.header.View(), r.tabs[r.tabCursor].ViewWithoutStatusBar())
-				statusBar := r.tabs[r.tabCursor].StatusBarView()
-				if statusBar != "" {
-					background = mainContent + "\n" + statusBar
-				} else {
-					background = mainContent
-				}
-			} else {
-				background = r.header.View() + "\n" + r.tabs[r.tabCursor].View()
-			}
-		} else {
-			background = r.splash.View()
-		}
-
-		// Dim the background for modal effect
-		dimmedBackground := lipgloss.NewStyle().
-			Faint(true).
-			Render(background)
-
-		foreground := r.joinInput.View()
-
 		return overlay.Composite(
-			foreground,
-			dimmedBackground,
+			r.joinInput.View(),
+			r.dimmedMainView(),
 			overlay.Center,
 			overlay.Center,
 			0,
 			0,
 		)
 	case quickJoinScreen:
-		var background string
-		if len(r.tabs) > 0 && r.tabCursor < len(r.tabs) {
-			if r.dependencies.UserConfig.Settings.VerticalTabList {
-				mainContent := lipgloss.JoinHorizontal(lipgloss.Left, r.header.View(), r.tabs[r.tabCursor].ViewWithoutStatusBar())
-				statusBar := r.tabs[r.tabCursor].StatusBarView()
-				if statusBar != "" {
-					background = mainContent + "\n" + statusBar
-				} else {
-					background = mainContent
-				}
-			} else {
-				background = r.header.View() + "\n" + r.tabs[r.tabCursor].View()
-			}
-		} else {
-			background = r.splash.View()
-		}
-
-		dimmedBackground := lipgloss.NewStyle().
-			Faint(true).
-			Render(background)
-
 		return overlay.Composite(
 			r.quickJoinInput.View(),
-			dimmedBackground,
+			r.dimmedMainView(),
+			overlay.Center,
+			overlay.Center,
+			0,
+			0,
+		)
+	case connectionStatusScreen:
+		return overlay.Composite(
+			r.connStatus.View(),
+			r.dimmedMainView(),
 			overlay.Center,
 			overlay.Center,
 			0,
@@ -837,6 +916,139 @@ func (r *Root) renderView() string {
 	return ""
 }
 
+// mainView renders the header with the active tab, or the splash screen without tabs.
+func (r *Root) mainView() string {
+	if len(r.tabs) == 0 || r.tabCursor >= len(r.tabs) {
+		return r.splash.View()
+	}
+
+	current := r.tabs[r.tabCursor]
+
+	if r.dependencies.UserConfig.Settings.VerticalTabList {
+		// In vertical mode, render status bar separately at full width
+		mainContent := lipgloss.JoinHorizontal(lipgloss.Left, r.header.View(), r.tabContentView(current, true))
+		statusBar := current.StatusBarView()
+		if statusBar != "" {
+			return mainContent + "\n" + statusBar
+		}
+		return mainContent
+	}
+
+	return r.header.View() + "\n" + r.tabContentView(current, false)
+}
+
+// tabContentView renders the active tab, or all panes of the split view when the active tab is part of it.
+func (r *Root) tabContentView(current tab, withoutStatusBar bool) string {
+	render := func(t tab) string {
+		if withoutStatusBar {
+			return t.ViewWithoutStatusBar()
+		}
+
+		return t.View()
+	}
+
+	if !r.split.active() || !r.split.contains(current.ID()) {
+		return render(current)
+	}
+
+	views := make([]string, 0, len(r.split.tabIDs))
+	for _, id := range r.split.tabIDs {
+		i := slices.IndexFunc(r.tabs, func(t tab) bool { return t.ID() == id })
+		if i == -1 {
+			continue
+		}
+
+		views = append(views, render(r.tabs[i]))
+	}
+
+	if r.split.orientation == splitVertical {
+		return lipgloss.JoinVertical(lipgloss.Left, views...)
+	}
+
+	height := 0
+	for _, v := range views {
+		height = max(height, lipgloss.Height(v))
+	}
+
+	separator := lipgloss.NewStyle().
+		Foreground(lipgloss.Color(r.dependencies.UserConfig.Theme.ListLabelColor)).
+		Render(strings.TrimSuffix(strings.Repeat("│\n", height), "\n"))
+
+	joined := make([]string, 0, len(views)*2)
+	for i, v := range views {
+		if i > 0 {
+			joined = append(joined, separator)
+		}
+		joined = append(joined, v)
+	}
+
+	return lipgloss.JoinHorizontal(lipgloss.Top, joined...)
+}
+
+// dimmedMainView renders the main view faint as background for modals.
+func (r *Root) dimmedMainView() string {
+	// Dim the background for modal effect
+	return lipgloss.NewStyle().
+		Faint(true).
+		Render(r.mainView())
+}
+
+// activeTabCapturesInput reports whether the active tab currently handles typed keys itself.
+func (r *Root) activeTabCapturesInput() bool {
+	if len(r.tabs) <= r.tabCursor {
+		return false
+	}
+
+	state := r.tabs[r.tabCursor].State()
+	return state == insertMode || state == userInspectInsertMode || r.tabs[r.tabCursor].IsSearching()
+}
+
+// toggleSplitPane adds the active tab to the split view or removes it.
+func (r *Root) toggleSplitPane() tea.Cmd {
+	if len(r.tabs) <= r.tabCursor {
+		return nil
+	}
+
+	current := r.tabs[r.tabCursor]
+	if current.Kind() != BroadcastTabKind {
+		return nil
+	}
+
+	if !r.split.toggle(current.ID()) {
+		return func() tea.Msg {
+			return requestLocalMessageHandleMessage{
+				tabID:     current.ID(),
+				accountID: current.AccountID(),
+				message: &twitchirc.Notice{
+					FakeTimestamp: time.Now(),
+					Message:       fmt.Sprintf("Split view can show at most %d channels", maxSplitPanes),
+				},
+			}
+		}
+	}
+
+	r.handleResize()
+	return nil
+}
+
+// focusPane moves the focus step panes forward or backward inside the split view.
+func (r *Root) focusPane(step int) {
+	if len(r.tabs) <= r.tabCursor || !r.split.active() {
+		return
+	}
+
+	id := r.split.neighbour(r.tabs[r.tabCursor].ID(), step)
+	i := slices.IndexFunc(r.tabs, func(t tab) bool { return t.ID() == id })
+	if i == -1 || i == r.tabCursor {
+		return
+	}
+
+	r.tabs[r.tabCursor].Blur()
+	r.tabCursor = i
+	r.header.SelectTab(id)
+	r.tabs[i].Focus()
+}
+
 func (r *Root) HasSessionLoaded() bool {
 	return r.hasLoadedSession
 }
@@ -850,6 +1062,15 @@ func (r *Root) IsDetached() bool {
 func (r *Root) TakeStateSnapshot() save.AppState {
 	appState := save.AppState{}
 
+	if r.split.active() {
+		appState.Split = &save.SplitState{Vertical: r.split.orientation == splitVertical}
+		for _, id := range r.split.tabIDs {
+			if i := slices.IndexFunc(r.tabs, func(t tab) bool { return t.ID() == id }); i != -1 {
+				appState.Split.Tabs = append(appState.Split.Tabs, i)
+			}
+		}
+	}
+
 	for _, t := range r.tabs {
 		tabState := save.TabState{
 			IsFocused:  t.Focused(),
@@ -863,6 +1084,10 @@ func (r *Root) TakeStateSnapshot() save.AppState {
 			tabState.IsLocalSub = t.(*broadcastTab).isLocalSub
 		}
 
+		if t.Kind() == CombinedTabKind {
+			tabState.Filter = t.(*combinedTab).filterQuery
+		}
+
 		appState.Tabs = append(appState.Tabs, tabState)
 	}
 
@@ -871,6 +1096,7 @@ func (r *Root) TakeStateSnapshot() save.AppState {
 
 func (r *Root) tickSaveAppState() tea.Cmd {
 	state := r.TakeStateSnapshot()
+	inputHistory := r.InputHistorySnapshot()
 
 	return tea.Tick(time.Second*15, func(_ time.Time) tea.Msg {
 		log.Logger.Info().Msg("saving app state inside ticker")
@@ -879,6 +1105,12 @@ func (r *Root) tickSaveAppState() tea.Cmd {
 			log.Logger.Err(err).Msg("failed to save app state")
 		}
 
+		if inputHistory != nil && r.dependencies.InputHistory != nil {
+			if err := r.dependencies.InputHistory.SaveInputHistory(inputHistory); err != nil {
+				log.Logger.Err(err).Msg("failed to save input history")
+			}
+		}
+
 		return appStateSaveMessage{}
 	})
 }
@@ -1005,6 +1237,8 @@ func (r *Root) createTab(account save.Account, channel string, kind TabKind) (ta
 
 		nTab := newBroadcastTab(id, r.width, r.height-headerHeight, account, channel, r.dependencies)
 		nTab.updateInfo = r.updateInfo
+		nTab.missingScopes = r.missingScopes[account.ID]
+		r.restoreInputHistory(nTab)
 		return nTab, cmd
 	case MentionTabKind:
 		id, cmd := r.header.AddTab("mentioned", "all")
@@ -1016,6 +1250,24 @@ func (r *Root) createTab(account save.Account, channel string, kind TabKind) (ta
 		headerHeight := r.getHeaderHeight()
 		nTab := newLiveNotificationTab(id, r.width, r.height-headerHeight, r.dependencies)
 		return nTab, cmd
+	case CombinedTabKind:
+		channels := parseCombinedChannels(channel)
+
+		name := "combined"
+		if len(channels) > 0 {
+			name = strings.Join(channels, ",")
+		}
+
+		identity := account.DisplayName
+		if account.IsAnonymous {
+			identity = "Anonymous"
+		}
+
+		id, cmd := r.header.AddTab(name, identity)
+		headerHeight := r.getHeaderHeight()
+		nTab := newCombinedTab(id, r.width, r.height-headerHeight, account, channels, r.dependencies)
+		r.restoreInputHistory(nTab)
+		return nTab, cmd
 	}
 
 	r.handleResize()
@@ -1039,31 +1291,38 @@ func (r *Root) handleResize() {
 
 	// help
 	r.help.handleResize(r.width, r.height)
+	r.connStatus.handleResize(r.width)
 
+	var width, height int
 	if r.dependencies.UserConfig.Settings.VerticalTabList {
 		minWidth := r.header.MinWidth()
 		r.header.Resize(minWidth, r.height)
 
-		headerWidth := lipgloss.Width(r.header.View())
-		headerHeight := lipgloss.Height(r.header.View())
-
-		for i := range r.tabs {
-			// Tab height matches header height (status bar is rendered separately below both)
-			r.tabs[i].SetSize(r.width-headerWidth, headerHeight)
-			r.tabs[i].SetFullWidth(r.width) // for status bar to span full width
-			r.tabs[i].HandleResize()
-		}
-
-		return
+		// Tab height matches header height (status bar is rendered separately below both)
+		width = r.width - lipgloss.Width(r.header.View())
+		height = lipgloss.Height(r.header.View())
 	} else {
 		r.header.Resize(r.width-3, 0) // one placeholder space foreach side
+		width = r.width
+		height = r.height - r.getHeaderHeight()
 	}
 
-	// tab
-	headerHeight := r.getHeaderHeight()
+	// tabs of the split view share the space
+	panes := make(map[string]paneSize, len(r.split.tabIDs))
+	for i, size := range r.split.paneSizes(width, height) {
+		panes[r.split.tabIDs[i]] = size
+	}
 
 	for i := range r.tabs {
-		r.tabs[i].SetSize(r.width, r.height-headerHeight)
+		size, ok := panes[r.tabs[i].ID()]
+		if !ok || !r.split.active() {
+			size = paneSize{width: width, height: height}
+		}
+
+		r.tabs[i].SetSize(size.width, size.height)
+		if r.dependencies.UserConfig.Settings.VerticalTabList {
+			r.tabs[i].SetFullWidth(r.width) // for status bar to span full width
+		}
 		r.tabs[i].HandleResize()
 	}
 }
@@ -1145,6 +1404,7 @@ func (r *Root) handleVersionCheck(msg versionCheckMessage) tea.Cmd {
 func (r *Root) handlePersistedDataLoaded(msg persistedDataLoadedMessage) tea.Cmd {
 	cmds := make([]tea.Cmd, 0, len(msg.state.Tabs))
 	r.hasLoadedSession = true
+	r.inputHistory = msg.inputHistory
 
 	if msg.err != nil {
 		log.Logger.Err(msg.err).Msg("failed to load persisted data")
@@ -1153,7 +1413,8 @@ func (r *Root) handlePersistedDataLoaded(msg persistedDataLoadedMessage) tea.Cmd
 
 	// restore tabs
 	var hasActiveTab bool
-	for _, t := range msg.state.Tabs {
+	restoredIDs := make(map[int]string, len(msg.state.Tabs)) // index in state:tab id
+	for stateIndex, t := range msg.state.Tabs {
 		r.screenType = mainScreen
 
 		var (
@@ -1190,6 +1451,25 @@ func (r *Root) handlePersistedDataLoaded(msg persistedDataLoadedMessage) tea.Cmd
 			newTab, cmd = r.createTab(save.Account{}, "", MentionTabKind)
 		case LiveNotificationTabKind:
 			newTab, cmd = r.createTab(save.Account{}, "", LiveNotificationTabKind)
+		case CombinedTabKind:
+			var account save.Account
+
+			for _, a := range r.dependencies.Accounts {
+				if a.ID == t.IdentityID {
+					account = a
+				}
+			}
+
+			if account.ID == "" {
+				continue
+			}
+
+			newTab, cmd = r.createTab(account, t.Channel, CombinedTabKind)
+			if err := newTab.(*combinedTab).setFilter(t.Filter); err != nil {
+				log.Logger.Err(err).Str("filter", t.Filter).Msg("failed to restore combined chat filter")
+			}
+		default:
+			continue
 		}
 
 		cmds = append(cmds, cmd)
@@ -1199,6 +1479,7 @@ func (r *Root) handlePersistedDataLoaded(msg persistedDataLoadedMessage) tea.Cmd
 		}
 
 		r.tabs = append(r.tabs, newTab)
+		restoredIDs[stateIndex] = newTab.ID()
 
 		if t.IsFocused {
 			hasActiveTab = true
@@ -1217,6 +1498,19 @@ func (r *Root) handlePersistedDataLoaded(msg persistedDataLoadedMessage) tea.Cmd
 		r.tabs[0].Focus()
 	}
 
+	// restore split view
+	if msg.state.Split != nil {
+		if msg.state.Split.Vertical {
+			r.split.orientation = splitVertical
+		}
+
+		for _, i := range msg.state.Split.Tabs {
+			if id, ok := restoredIDs[i]; ok && TabKind(msg.state.Tabs[i].Kind) == BroadcastTabKind {
+				r.split.toggle(id)
+			}
+		}
+	}
+
 	r.handleResize()
 
 	// cache and broadcast channel suggestions to all tabs
@@ -1242,6 +1536,38 @@ func (r *Root) handlePersistedDataLoaded(msg persistedDataLoadedMessage) tea.Cmd
 	return tea.Batch(cmds...)
 }
 
+// handleIRCMessage forwards a live chat message of an account to the tabs, with the result of the user scripts applied.
+func (r *Root) handleIRCMessage(accountID string, ircer twitchirc.IRCer, scripted script.Result) tea.Cmd {
+	var cmds []tea.Cmd
+
+	if privateMsg, ok := ircer.(*twitchirc.PrivateMessage); ok {
+		ircer = applyScriptResult(privateMsg, scripted)
+	}
+
+	evt := r.buildChatEventMessage(accountID, "", ircer, false)
+	evt.displayModifier.highlight = scripted.Highlight
+	if evt.prepareCommand != "" {
+		cmds = append(cmds, tea.Raw(evt.prepareCommand))
+		evt.prepareCommand = ""
+	}
+
+	cmds = append(cmds, r.scriptActionCmds(evt, scripted)...)
+
+	if scripted.Hide {
+		return tea.Batch(cmds...)
+	}
+
+	cmds = append(cmds, r.linkPreviewCmd(evt))
+
+	for i := range r.tabs {
+		var cmd tea.Cmd
+		r.tabs[i], cmd = r.tabs[i].Update(evt)
+		cmds = append(cmds, cmd)
+	}
+
+	return tea.Batch(cmds...)
+}
+
 func (r *Root) buildChatEventMessage(accountID string, tabID string, ircer twitchirc.IRCer, isFakeEvent bool) chatEventMessage {
 	var (
 		channel                 string
@@ -1395,44 +1721,6 @@ func (r *Root) buildChatEventMessage(accountID string, tabID string, ircer twitc
 		replaceCommand += p
 	}
 
-	if r.dependencies.UserConfig.Settings.Security.CheckLinks && len(message) > 0 {
-		// Key link annotations on the whole space-delimited token so they match
-		// exactly in applyWordReplacements (same contract as emotes). The URL is
-		// annotated in place, preserving any surrounding punctuation in the token.
-		for _, token := range strings.Split(message, " ") {
-			annotated := token
-			for _, u := range extractValidURLs(token) {
-				resp, err := r.dependencies.ServerAPI.CheckLink(context.Background(), u)
-				if err != nil {
-					log.Logger.Info().Err(err).Str("url", u).Msg("failed to check link")
-					continue
-				}
-
-				parts := []string{http.StatusText(resp.RemoteStatusCode)}
-
-				if resp.RemoteContentType != "" {
-					before, _, _ := strings.Cut(resp.RemoteContentType, ";")
-					parts = append(parts, before)
-				}
-
-				for _, visited := range resp.VisitedURLs {
-					d, err := url.QueryUnescape(visited)
-					if err != nil {
-						log.Logger.Info().Err(err).Str("url", visited).Msg("failed to unescape url")
-						continue
-					}
-					parts = append(parts, d)
-				}
-
-				annotated = strings.Replace(annotated, u, fmt.Sprintf("%s [%s]", u, strings.Join(parts, ", ")), 1)
-			}
-
-			if annotated != token {
-				event.displayModifier.wordReplacements[token] = annotated
-			}
-		}
-	}
-
 	event.prepareCommand = replaceCommand
 	return event
 }
@@ -1456,13 +1744,55 @@ func (r *Root) imageCleanUpCommand() tea.Cmd {
 	})
 }
 
+// partCombinedChannels leaves the channels joined by a closed combined tab, unless another tab of the same account still shows them.
+func (r *Root) partCombinedChannels(closed *combinedTab) tea.Cmd {
+	if len(closed.channels) == 0 {
+		return nil
+	}
+
+	cmds := make([]tea.Cmd, 0, len(closed.channels)+1)
+	accountID := closed.AccountID()
+
+	for _, channel := range closed.channels {
+		inUse := slices.ContainsFunc(r.tabs, func(t tab) bool {
+			if combined, ok := t.(*combinedTab); ok {
+				return combined.joinsChannel(accountID, channel)
+			}
+
+			return t.Kind() == BroadcastTabKind && t.AccountID() == accountID && strings.EqualFold(t.Channel(), channel)
+		})
+
+		if inUse {
+			continue
+		}
+
+		log.Logger.Info().Str("channel", channel).Str("id", accountID).Msg("sending part message")
+		cmds = append(cmds, func() tea.Msg {
+			r.dependencies.Pool.SendIRC(accountID, twitchirc.PartMessage{Channel: channel})
+			return nil
+		})
+	}
+
+	cmds = append(cmds, func() tea.Msg {
+		r.dependencies.Pool.DisconnectIRC(accountID)
+		return nil
+	})
+
+	return tea.Sequence(cmds...)
+}
+
 func (r *Root) closeTab() {
 	if len(r.tabs) > r.tabCursor {
 		tabID := r.tabs[r.tabCursor].ID()
-		if r.tabs[r.tabCursor].Kind() == BroadcastTabKind {
-			r.tabs[r.tabCursor].(*broadcastTab).close()
+		r.storeInputHistory(r.tabs[r.tabCursor])
+		switch t := r.tabs[r.tabCursor].(type) {
+		case *broadcastTab:
+			t.close()
+		case *combinedTab:
+			t.close()
 		}
 		r.header.RemoveTab(tabID)
+		r.split.remove(tabID)
 		r.tabs = slices.DeleteFunc(r.tabs, func(t tab) bool {
 			return t.ID() == tabID
 		})
@@ -0,0 +1,232 @@
+package mainui
+
+import (
+	"strings"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/julez-dev/chatuino/script"
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+	"github.com/julez-dev/chatuino/wspool"
+	"github.com/rs/zerolog/log"
+)
+
+// scriptMemory is the number of message ids remembered to run scripts and their actions only once per message,
+// even when the message is received by multiple accounts.
+const scriptMemory = 512
+
+// scriptsDoneMessage carries the result of the user scripts for a message.
+type scriptsDoneMessage struct {
+	id     string
+	result script.Result
+}
+
+// recentIDs remembers the last n ids it has seen.
+type recentIDs struct {
+	ids  []string
+	next int
+	set  map[string]struct{}
+}
+
+func newRecentIDs(n int) *recentIDs {
+	return &recentIDs{ids: make([]string, n), set: make(map[string]struct{}, n)}
+}
+
+// add stores the id and reports whether it was not seen before.
+func (r *recentIDs) add(id string) bool {
+	if _, ok := r.set[id]; ok {
+		return false
+	}
+
+	delete(r.set, r.ids[r.next])
+	r.ids[r.next] = id
+	r.set[id] = struct{}{}
+	r.next = (r.next + 1) % len(r.ids)
+
+	return true
+}
+
+// evictedBy returns the id removed when id is added.
+func (r *recentIDs) evictedBy(id string) (string, bool) {
+	if _, ok := r.set[id]; ok {
+		return "", false
+	}
+
+	evicted := r.ids[r.next]
+	_, ok := r.set[evicted]
+	return evicted, ok
+}
+
+// scriptResults remembers the script results of the last n messages.
+type scriptResults struct {
+	ids     *recentIDs
+	results map[string]script.Result
+}
+
+func newScriptResults(n int) *scriptResults {
+	return &scriptResults{ids: newRecentIDs(n), results: make(map[string]script.Result, n)}
+}
+
+func (s *scriptResults) add(id string, result script.Result) {
+	if evicted, ok := s.ids.evictedBy(id); ok {
+		delete(s.results, evicted)
+	}
+
+	s.ids.add(id)
+	s.results[id] = result
+}
+
+func (s *scriptResults) get(id string) (script.Result, bool) {
+	result, ok := s.results[id]
+	return result, ok
+}
+
+func scriptMessage(msg *twitchirc.PrivateMessage) script.Message {
+	badges := make([]string, 0, len(msg.Badges))
+	for _, b := range msg.Badges {
+		badges = append(badges, b.Name)
+	}
+
+	return script.Message{
+		ID:           msg.ID,
+		Channel:      msg.ChannelUserName,
+		User:         msg.LoginName,
+		DisplayName:  msg.DisplayName,
+		Text:         msg.Message,
+		Badges:       badges,
+		Mod:          msg.Mod,
+		Subscriber:   msg.Subscriber,
+		VIP:          msg.VIP,
+		FirstMessage: msg.FirstMsg,
+	}
+}
+
+// queueScriptedEvent queues a live chat event. Events are delivered in the order they arrived, each waits
+// until the scripts ran for the messages before it.
+func (r *Root) queueScriptedEvent(evt wspool.IRCEvent) tea.Cmd {
+	r.scriptQueue = append(r.scriptQueue, evt)
+	return r.processScriptQueue()
+}
+
+// processScriptQueue delivers queued events until it reaches a message the scripts did not run for yet.
+// The scripts run for it in a command, the queue continues once its scriptsDoneMessage arrives.
+func (r *Root) processScriptQueue() tea.Cmd {
+	var cmds []tea.Cmd
+
+	for !r.scriptRunning && len(r.scriptQueue) > 0 {
+		evt := r.scriptQueue[0]
+
+		var result script.Result
+		if privateMsg, ok := evt.Message.(*twitchirc.PrivateMessage); ok {
+			cached, ok := r.scriptResults.get(privateMsg.ID)
+			if !ok {
+				r.scriptRunning = true
+				cmds = append(cmds, r.runScriptsCmd(privateMsg))
+				break
+			}
+
+			result = cached
+		}
+
+		r.scriptQueue[0] = wspool.IRCEvent{}
+		r.scriptQueue = r.scriptQueue[1:]
+
+		cmds = append(cmds, r.handleIRCMessage(evt.AccountID, evt.Message, result))
+	}
+
+	return tea.Batch(cmds...)
+}
+
+func (r *Root) handleScriptsDone(msg scriptsDoneMessage) tea.Cmd {
+	r.scriptRunning = false
+	r.scriptResults.add(msg.id, msg.result)
+
+	return r.processScriptQueue()
+}
+
+// runScriptsCmd passes a live chat message through the user scripts outside of Update.
+func (r *Root) runScriptsCmd(msg *twitchirc.PrivateMessage) tea.Cmd {
+	var (
+		scripts = r.dependencies.Scripts
+		input   = scriptMessage(msg)
+	)
+
+	return func() tea.Msg {
+		result, err := scripts.Run(input)
+		if err != nil {
+			log.Logger.Warn().Err(err).Str("channel", input.Channel).Str("message-id", input.ID).Msg("user script failed")
+		}
+
+		return scriptsDoneMessage{id: input.ID, result: result}
+	}
+}
+
+// applyScriptResult returns a copy of msg with the text rewritten by the scripts. Without a rewrite msg is returned,
+// the original message is left untouched for the chat logs.
+func applyScriptResult(msg *twitchirc.PrivateMessage, result script.Result) *twitchirc.PrivateMessage {
+	if result.Text == "" || result.Text == msg.Message {
+		return msg
+	}
+
+	msg = msg.Clone()
+	msg.Message = result.Text
+	msg.Emotes = nil // emote positions no longer match the rewritten text
+
+	return msg
+}
+
+// scriptActionCmds routes the event to the tabs of other channels and runs the commands requested by the scripts.
+// The actions run only for the first account receiving the message.
+func (r *Root) scriptActionCmds(evt chatEventMessage, result script.Result) []tea.Cmd {
+	msg, ok := evt.message.(*twitchirc.PrivateMessage)
+	if !ok || len(result.Routes) == 0 && len(result.Commands) == 0 || !r.scriptedActions.add(msg.ID) {
+		return nil
+	}
+
+	var cmds []tea.Cmd
+
+	for _, route := range result.Routes {
+		if strings.EqualFold(route, evt.channel) {
+			continue
+		}
+
+		for _, t := range r.tabs {
+			if t.Kind() != BroadcastTabKind || !strings.EqualFold(t.Channel(), route) {
+				continue
+			}
+
+			routed := evt
+			routed.tabID = t.ID()
+			routed.accountID = t.AccountID()
+			routed.channel = "" // shown outside of its own channel
+			routed.displayModifier.channelTag = channelTag(evt.channel)
+
+			cmds = append(cmds, func() tea.Msg {
+				return routed
+			})
+		}
+	}
+
+	if len(result.Commands) == 0 {
+		return cmds
+	}
+
+	client, ok := r.dependencies.APIUserClients[evt.accountID].(moderationAPIClient)
+	if !ok {
+		log.Logger.Warn().Str("account", evt.accountID).Msg("user script requested command but account can't run moderator commands")
+		return cmds
+	}
+
+	for _, command := range result.Commands {
+		name, argStr, _ := strings.Cut(strings.TrimPrefix(command, "/"), " ")
+
+		cmd := handleCommand(name, strings.Fields(argStr), msg.RoomID, msg.ChannelUserName, evt.accountID, client)
+		if cmd == nil {
+			log.Logger.Warn().Str("command", command).Msg("user script requested unknown command")
+			continue
+		}
+
+		cmds = append(cmds, cmd)
+	}
+
+	return cmds
+}
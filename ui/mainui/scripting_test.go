@@ -0,0 +1,100 @@
+package mainui
+
+import (
+	"sync/atomic"
+	"testing"
+
+	"github.com/julez-dev/chatuino/script"
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+	"github.com/julez-dev/chatuino/wspool"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecentIDs(t *testing.T) {
+	t.Parallel()
+
+	r := newRecentIDs(2)
+
+	require.True(t, r.add("a"))
+	require.False(t, r.add("a"))
+	require.True(t, r.add("b"))
+
+	// a is evicted once the memory is full
+	require.True(t, r.add("c"))
+	require.True(t, r.add("a"))
+	require.False(t, r.add("c"))
+}
+
+func TestApplyScriptResult(t *testing.T) {
+	t.Parallel()
+
+	original := &twitchirc.PrivateMessage{ID: "1", Message: "original", Emotes: []twitchirc.Emote{{ID: "1"}}}
+
+	msg := applyScriptResult(original, script.Result{Text: "rewritten"})
+	require.Equal(t, "rewritten", msg.Message)
+	require.Empty(t, msg.Emotes)
+
+	// the original message is kept for the chat logs
+	require.Equal(t, "original", original.Message)
+
+	require.Same(t, original, applyScriptResult(original, script.Result{Highlight: "#ff0000"}))
+}
+
+type countingScriptRunner struct {
+	calls atomic.Int64
+}
+
+func (c *countingScriptRunner) Run(script.Message) (script.Result, error) {
+	c.calls.Add(1)
+	return script.Result{}, nil
+}
+
+func TestRoot_ScriptQueue(t *testing.T) {
+	t.Parallel()
+
+	runner := &countingScriptRunner{}
+	r := &Root{
+		dependencies:    &DependencyContainer{Scripts: runner},
+		scriptResults:   newScriptResults(scriptMemory),
+		scriptedActions: newRecentIDs(scriptMemory),
+	}
+
+	// the same message received by two accounts, followed by a notice
+	cmd := r.queueScriptedEvent(wspool.IRCEvent{AccountID: "a", Message: &twitchirc.PrivateMessage{ID: "1"}})
+	require.NotNil(t, cmd)
+	r.queueScriptedEvent(wspool.IRCEvent{AccountID: "b", Message: &twitchirc.PrivateMessage{ID: "1"}})
+	r.queueScriptedEvent(wspool.IRCEvent{AccountID: "a", Message: &twitchirc.Notice{}})
+
+	require.True(t, r.scriptRunning)
+	require.Len(t, r.scriptQueue, 3, "events wait for the scripts of the message before them")
+	require.Zero(t, runner.calls.Load(), "scripts don't run in Update")
+
+	done, ok := cmd().(scriptsDoneMessage)
+	require.True(t, ok)
+
+	r.handleScriptsDone(done)
+	require.False(t, r.scriptRunning)
+	require.Empty(t, r.scriptQueue)
+
+	// a late copy of the message uses the result of the first run
+	r.queueScriptedEvent(wspool.IRCEvent{AccountID: "c", Message: &twitchirc.PrivateMessage{ID: "1"}})
+	require.Empty(t, r.scriptQueue)
+	require.Equal(t, int64(1), runner.calls.Load())
+}
+
+func TestScriptResults(t *testing.T) {
+	t.Parallel()
+
+	results := newScriptResults(2)
+	results.add("a", script.Result{Text: "a"})
+	results.add("b", script.Result{Text: "b"})
+	results.add("c", script.Result{Text: "c"})
+
+	_, ok := results.get("a")
+	require.False(t, ok, "the oldest result is evicted")
+
+	result, ok := results.get("c")
+	require.True(t, ok)
+	require.Equal(t, "c", result.Text)
+	require.Len(t, results.results, 2)
+}
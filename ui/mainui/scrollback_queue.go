@@ -0,0 +1,71 @@
+package mainui
+
+import (
+	"sync"
+
+	tea "charm.land/bubbletea/v2"
+)
+
+// scrollbackQueue runs the scrollback I/O of a chat in the order it was scheduled. Commands run concurrently,
+// without the queue a page could be popped before the spill writing it was done.
+//
+// Jobs don't wait for a turn, whoever waits for a job runs all jobs queued before it. A job whose command is
+// dropped and never run therefore doesn't block the queue, it is run by the next job waited for.
+type scrollbackQueue struct {
+	m       sync.Mutex // guards pending
+	pending []*scrollbackJob
+
+	runM sync.Mutex // held while jobs run, they run one at a time
+}
+
+type scrollbackJob struct {
+	fn   func()
+	done bool // guarded by runM
+}
+
+func newScrollbackQueue() *scrollbackQueue {
+	return &scrollbackQueue{}
+}
+
+// enqueue adds fn to the queue, it has to be called from Update to keep the order. The returned function runs
+// all jobs queued up to fn, if they did not run yet, and returns once fn is done.
+func (q *scrollbackQueue) enqueue(fn func()) func() {
+	job := &scrollbackJob{fn: fn}
+
+	q.m.Lock()
+	q.pending = append(q.pending, job)
+	q.m.Unlock()
+
+	return func() {
+		q.runUntil(job)
+	}
+}
+
+// runUntil runs the pending jobs in order until job is done.
+func (q *scrollbackQueue) runUntil(job *scrollbackJob) {
+	q.runM.Lock()
+	defer q.runM.Unlock()
+
+	for !job.done {
+		q.m.Lock()
+		next := q.pending[0]
+		q.pending = q.pending[1:]
+		q.m.Unlock()
+
+		next.fn()
+		next.done = true
+	}
+}
+
+// cmd queues fn and returns the command running it.
+func (q *scrollbackQueue) cmd(fn func() tea.Msg) tea.Cmd {
+	var msg tea.Msg
+	wait := q.enqueue(func() {
+		msg = fn()
+	})
+
+	return func() tea.Msg {
+		wait()
+		return msg
+	}
+}
@@ -0,0 +1,63 @@
+package mainui
+
+import (
+	"sync"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrollbackQueue(t *testing.T) {
+	t.Parallel()
+
+	t.Run("runs in the order queued", func(t *testing.T) {
+		t.Parallel()
+
+		q := newScrollbackQueue()
+
+		var (
+			m     sync.Mutex
+			order []int
+		)
+
+		cmds := make([]tea.Cmd, 0, 10)
+		for i := range 10 {
+			cmds = append(cmds, q.cmd(func() tea.Msg {
+				m.Lock()
+				order = append(order, i)
+				m.Unlock()
+				return i
+			}))
+		}
+
+		var wg sync.WaitGroup
+		for i := len(cmds) - 1; i >= 0; i-- {
+			wg.Go(func() {
+				require.Equal(t, i, cmds[i]())
+			})
+		}
+		wg.Wait()
+
+		require.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, order)
+	})
+
+	t.Run("command that never runs does not block later ones", func(t *testing.T) {
+		t.Parallel()
+
+		q := newScrollbackQueue()
+
+		var order []string
+		_ = q.cmd(func() tea.Msg {
+			order = append(order, "dropped")
+			return nil
+		})
+
+		wait := q.enqueue(func() {
+			order = append(order, "drop")
+		})
+		wait()
+
+		require.Equal(t, []string{"dropped", "drop"}, order)
+	})
+}
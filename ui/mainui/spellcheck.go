@@ -0,0 +1,39 @@
+package mainui
+
+import (
+	"github.com/julez-dev/chatuino/ui/component"
+)
+
+// emoteSpellChecker accepts the emotes of a channel in addition to the words of the dictionary.
+type emoteSpellChecker struct {
+	checker   SpellChecker
+	emotes    EmoteCache
+	channelID func() string
+}
+
+// newEmoteSpellChecker returns the spell checker for a message input, nil when spell checking is disabled.
+// channelID returns the channel the message is sent to, whose emotes are not spell checked.
+func newEmoteSpellChecker(deps *DependencyContainer, channelID func() string) component.SpellChecker {
+	if deps.SpellChecker == nil {
+		return nil
+	}
+
+	return emoteSpellChecker{
+		checker:   deps.SpellChecker,
+		emotes:    deps.EmoteCache,
+		channelID: channelID,
+	}
+}
+
+func (e emoteSpellChecker) Correct(word string) bool {
+	if e.checker.Correct(word) {
+		return true
+	}
+
+	if e.emotes == nil {
+		return false
+	}
+
+	_, ok := e.emotes.GetByText(e.channelID(), word)
+	return ok
+}
@@ -0,0 +1,102 @@
+package mainui
+
+import (
+	"slices"
+)
+
+const maxSplitPanes = 4
+
+type splitOrientation int
+
+const (
+	splitHorizontal splitOrientation = iota // panes side by side
+	splitVertical                           // panes stacked on top of each other
+)
+
+// splitLayout tiles multiple broadcast tabs in one screen. The split is shown while one of its tabs is focused.
+type splitLayout struct {
+	orientation splitOrientation
+	tabIDs      []string
+}
+
+type paneSize struct {
+	width, height int
+}
+
+// active reports whether the split has enough tabs to be shown.
+func (s *splitLayout) active() bool {
+	return len(s.tabIDs) > 1
+}
+
+func (s *splitLayout) contains(tabID string) bool {
+	return slices.Contains(s.tabIDs, tabID)
+}
+
+// toggle adds the tab to the split or removes it, when it is already part of it.
+// It returns false when the tab could not be added because the split is full.
+func (s *splitLayout) toggle(tabID string) bool {
+	if s.contains(tabID) {
+		s.remove(tabID)
+		return true
+	}
+
+	if len(s.tabIDs) >= maxSplitPanes {
+		return false
+	}
+
+	s.tabIDs = append(s.tabIDs, tabID)
+	return true
+}
+
+func (s *splitLayout) remove(tabID string) {
+	s.tabIDs = slices.DeleteFunc(s.tabIDs, func(id string) bool {
+		return id == tabID
+	})
+}
+
+func (s *splitLayout) toggleOrientation() {
+	if s.orientation == splitHorizontal {
+		s.orientation = splitVertical
+		return
+	}
+
+	s.orientation = splitHorizontal
+}
+
+// neighbour returns the pane step positions away from the given tab, wrapping around at both ends.
+func (s *splitLayout) neighbour(tabID string, step int) string {
+	i := slices.Index(s.tabIDs, tabID)
+	if i == -1 || len(s.tabIDs) == 0 {
+		return ""
+	}
+
+	n := len(s.tabIDs)
+	return s.tabIDs[((i+step)%n+n)%n]
+}
+
+// paneSizes divides the available space between the panes. Horizontal panes are separated by a one column wide border.
+func (s *splitLayout) paneSizes(width, height int) []paneSize {
+	n := len(s.tabIDs)
+	if n == 0 {
+		return nil
+	}
+
+	sizes := make([]paneSize, n)
+
+	if s.orientation == splitVertical {
+		for i := range sizes {
+			sizes[i] = paneSize{width: width, height: height / n}
+		}
+		sizes[n-1].height += height % n
+
+		return sizes
+	}
+
+	available := max(width-(n-1), 0)
+	for i := range sizes {
+		sizes[i] = paneSize{width: available / n, height: height}
+	}
+	sizes[n-1].width += available % n
+
+	return sizes
+}
@@ -0,0 +1,57 @@
+package mainui
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitLayout_Toggle(t *testing.T) {
+	t.Parallel()
+
+	s := splitLayout{}
+	require.False(t, s.active())
+
+	require.True(t, s.toggle("a"))
+	require.False(t, s.active())
+
+	for _, id := range []string{"b", "c", "d"} {
+		require.True(t, s.toggle(id))
+	}
+	require.True(t, s.active())
+
+	// split is full
+	require.False(t, s.toggle("e"))
+	require.Equal(t, []string{"a", "b", "c", "d"}, s.tabIDs)
+
+	// toggling a pane removes it
+	require.True(t, s.toggle("b"))
+	require.Equal(t, []string{"a", "c", "d"}, s.tabIDs)
+
+	s.remove("c")
+	s.remove("d")
+	require.False(t, s.active())
+}
+
+func TestSplitLayout_Neighbour(t *testing.T) {
+	t.Parallel()
+
+	s := splitLayout{tabIDs: []string{"a", "b", "c"}}
+
+	require.Equal(t, "b", s.neighbour("a", 1))
+	require.Equal(t, "a", s.neighbour("c", 1))
+	require.Equal(t, "c", s.neighbour("a", -1))
+	require.Equal(t, "", s.neighbour("x", 1))
+}
+
+func TestSplitLayout_PaneSizes(t *testing.T) {
+	t.Parallel()
+
+	s := splitLayout{tabIDs: []string{"a", "b", "c"}}
+
+	// two separator columns, remaining width is given to the last pane
+	require.Equal(t, []paneSize{{width: 32, height: 40}, {width: 32, height: 40}, {width: 34, height: 40}}, s.paneSizes(100, 40))
+
+	s.toggleOrientation()
+	require.Equal(t, []paneSize{{width: 100, height: 13}, {width: 100, height: 13}, {width: 100, height: 14}}, s.paneSizes(100, 40))
+}
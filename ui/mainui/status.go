@@ -87,6 +87,7 @@ type streamStatus struct {
 	maxWidthStyle   lipgloss.Style // for padded rendering; Width set at render time
 	statusHighlight lipgloss.Style // bold + status color for slow/follower mode values
 	updateHighlight lipgloss.Style // splash highlight for update notification
+	errorHighlight  lipgloss.Style // error color for accounts missing scopes
 	rightAlignStyle lipgloss.Style // right-aligned layout; Width set at render time
 }
 
@@ -103,6 +104,7 @@ func newStreamStatus(width, height int, tab *broadcastTab, accountID, channelID
 		maxWidthStyle:   lipgloss.NewStyle(), // Width set at render time
 		statusHighlight: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color(deps.UserConfig.Theme.StatusColor)),
 		updateHighlight: lipgloss.NewStyle().Foreground(lipgloss.Color(deps.UserConfig.Theme.SplashHighlightColor)),
+		errorHighlight:  lipgloss.NewStyle().Foreground(lipgloss.Color(deps.UserConfig.Theme.ChatErrorColor)),
 		rightAlignStyle: lipgloss.NewStyle().AlignHorizontal(lipgloss.Right), // Width set at render time
 	}
 }
@@ -250,5 +252,12 @@ func (s *streamStatus) View() string {
 		settingsBuilder.WriteString(s.updateHighlight.Render("New update available: " + s.tab.updateInfo.LatestVersion))
 	}
 
+	if len(s.tab.missingScopes) > 0 {
+		if settingsBuilder.Len() > 0 {
+			settingsBuilder.WriteString(" | ")
+		}
+		settingsBuilder.WriteString(s.errorHighlight.Render("Missing permissions, re-authorize with chatuino account"))
+	}
+
 	return padded(stateStr + s.rightAlignStyle.Width(s.width-lipgloss.Width(stateStr)).Render(settingsBuilder.String()))
 }
@@ -0,0 +1,158 @@
+package mainui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/julez-dev/chatuino/twitch/twitchapi"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sync/errgroup"
+)
+
+type blockListAPIClient interface {
+	APIClient
+	FetchUserBlockList(ctx context.Context, userID string) ([]twitchapi.BlockedUser, error)
+	BlockUser(ctx context.Context, targetUserID string) error
+	UnblockUser(ctx context.Context, targetUserID string) error
+}
+
+// twitchBlockListMessage changes the users blocked on Twitch, notice is run after the block list was updated.
+type twitchBlockListMessage struct {
+	blocked   []string
+	unblocked []string
+	notice    tea.Cmd
+}
+
+// importTwitchBlocksCommand fetches the Twitch block lists of all accounts when enabled in the settings.
+func (r *Root) importTwitchBlocksCommand() tea.Cmd {
+	if !r.dependencies.UserConfig.Settings.BlockSettings.ImportTwitchBlocks {
+		return nil
+	}
+
+	accounts := r.dependencies.Accounts
+	clients := r.dependencies.APIUserClients
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+
+		var (
+			mu      sync.Mutex
+			blocked []string
+			wg      errgroup.Group
+		)
+
+		for _, acc := range accounts {
+			if acc.IsAnonymous {
+				continue
+			}
+
+			client, ok := clients[acc.ID].(blockListAPIClient)
+			if !ok {
+				continue
+			}
+
+			wg.Go(func() error {
+				users, err := client.FetchUserBlockList(ctx, acc.ID)
+				if err != nil {
+					log.Logger.Err(err).Str("user_id", acc.ID).Msg("could not import twitch block list, the account may need to be authenticated again")
+					return nil
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				for _, u := range users {
+					blocked = append(blocked, u.UserLogin)
+				}
+
+				return nil
+			})
+		}
+
+		_ = wg.Wait()
+
+		return twitchBlockListMessage{blocked: blocked}
+	}
+}
+
+func (r *Root) handleTwitchBlockList(msg twitchBlockListMessage) tea.Cmd {
+	settings := &r.dependencies.UserConfig.Settings.BlockSettings
+	users := slices.Clone(settings.TwitchUsers)
+
+	for _, login := range msg.blocked {
+		if !slices.ContainsFunc(users, func(u string) bool { return strings.EqualFold(u, login) }) {
+			users = append(users, strings.ToLower(login))
+		}
+	}
+
+	users = slices.DeleteFunc(users, func(u string) bool {
+		return slices.ContainsFunc(msg.unblocked, func(login string) bool { return strings.EqualFold(u, login) })
+	})
+
+	settings.TwitchUsers = users
+
+	return msg.notice
+}
+
+// handleTwitchBlockCommand blocks or unblocks a user on Twitch for the account and updates the ignored users.
+func handleTwitchBlockCommand(deps *DependencyContainer, accountID string, block bool, args []string, notice func(string) tea.Cmd) tea.Cmd {
+	command := "/twitchunblock"
+	if block {
+		command = "/twitchblock"
+	}
+
+	if len(args) < 1 || args[0] == "" {
+		return notice(fmt.Sprintf("Expected Usage: %s <username>", command))
+	}
+
+	client, ok := deps.APIUserClients[accountID].(blockListAPIClient)
+	if !ok {
+		return notice(fmt.Sprintf("%s is not available for this account", command))
+	}
+
+	login := strings.ToLower(strings.TrimPrefix(args[0], "@"))
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		resp, err := client.GetUsers(ctx, []string{login}, nil)
+		if err != nil {
+			return notice(fmt.Sprintf("Could not find user %s: %s", login, err))()
+		}
+
+		if len(resp.Data) == 0 {
+			return notice(fmt.Sprintf("User %s does not exist", login))()
+		}
+
+		user := resp.Data[0]
+
+		if block {
+			err = client.BlockUser(ctx, user.ID)
+		} else {
+			err = client.UnblockUser(ctx, user.ID)
+		}
+
+		if err != nil {
+			return notice(fmt.Sprintf("Could not update Twitch block list, you may need to authenticate the account again: %s", err))()
+		}
+
+		if block {
+			return twitchBlockListMessage{
+				blocked: []string{user.Login},
+				notice:  notice(fmt.Sprintf("Blocked %s on Twitch", user.DisplayName)),
+			}
+		}
+
+		return twitchBlockListMessage{
+			unblocked: []string{user.Login},
+			notice:    notice(fmt.Sprintf("Unblocked %s on Twitch", user.DisplayName)),
+		}
+	}
+}
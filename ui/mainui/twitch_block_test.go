@@ -0,0 +1,61 @@
+package mainui
+
+import (
+	"context"
+	"testing"
+
+	tea "charm.land/bubbletea/v2"
+	"github.com/julez-dev/chatuino/twitch/twitchapi"
+	"github.com/julez-dev/chatuino/twitch/twitchirc"
+	"github.com/stretchr/testify/require"
+)
+
+type stubBlockListClient struct {
+	APIClient
+	blocked []string
+}
+
+func (s *stubBlockListClient) GetUsers(_ context.Context, logins []string, _ []string) (twitchapi.UserResponse, error) {
+	return twitchapi.UserResponse{Data: []twitchapi.UserData{{ID: "id-" + logins[0], Login: logins[0], DisplayName: logins[0]}}}, nil
+}
+
+func (s *stubBlockListClient) FetchUserBlockList(context.Context, string) ([]twitchapi.BlockedUser, error) {
+	return nil, nil
+}
+
+func (s *stubBlockListClient) BlockUser(_ context.Context, targetUserID string) error {
+	s.blocked = append(s.blocked, targetUserID)
+	return nil
+}
+
+func (s *stubBlockListClient) UnblockUser(context.Context, string) error {
+	s.blocked = nil
+	return nil
+}
+
+func TestTwitchBlockCommand(t *testing.T) {
+	t.Parallel()
+
+	client := &stubBlockListClient{}
+	r := &Root{dependencies: &DependencyContainer{APIUserClients: map[string]APIClient{"account": client}}}
+	notice := func(string) tea.Cmd { return nil }
+	msg := &twitchirc.PrivateMessage{DisplayName: "Troll", LoginName: "troll", Message: "hi"}
+
+	result := handleTwitchBlockCommand(r.dependencies, "account", true, []string{"@Troll"}, notice)()
+	update, ok := result.(twitchBlockListMessage)
+	require.True(t, ok)
+	require.Equal(t, []string{"id-troll"}, client.blocked)
+
+	r.handleTwitchBlockList(update)
+	require.Equal(t, []string{"troll"}, r.dependencies.UserConfig.Settings.BlockSettings.TwitchUsers)
+	require.True(t, messageMatchesBlocked(msg, r.dependencies.UserConfig.Settings.BlockSettings))
+
+	// importing the same user again does not add a duplicate
+	r.handleTwitchBlockList(twitchBlockListMessage{blocked: []string{"TROLL"}})
+	require.Len(t, r.dependencies.UserConfig.Settings.BlockSettings.TwitchUsers, 1)
+
+	update = handleTwitchBlockCommand(r.dependencies, "account", false, []string{"troll"}, notice)().(twitchBlockListMessage)
+	r.handleTwitchBlockList(update)
+	require.Empty(t, client.blocked)
+	require.False(t, messageMatchesBlocked(msg, r.dependencies.UserConfig.Settings.BlockSettings))
+}
@@ -243,11 +243,72 @@ func TestIRCConn_Reconnect(t *testing.T) {
 	go conn.Run()
 	defer conn.Close()
 
-	// Wait for reconnect (5s delay + connection time)
+	// Wait for reconnect (backoff delay + connection time)
 	time.Sleep(6 * time.Second)
 
 	require.GreaterOrEqual(t, connectCount.Load(), int32(2), "should reconnect after disconnect")
 	require.GreaterOrEqual(t, errorCount.Load(), int32(1), "should emit error on disconnect")
+
+	status := conn.Status()
+	require.GreaterOrEqual(t, status.Reconnects, 1)
+	require.Error(t, status.LastError)
+	require.False(t, status.LastErrorAt.IsZero())
+}
+
+func TestIRCConn_ServerRequestedReconnect(t *testing.T) {
+	t.Parallel()
+
+	var connectCount atomic.Int32
+
+	server := newTestIRCServer(t, func(ws *websocket.Conn) {
+		count := connectCount.Add(1)
+
+		// Read auth
+		for i := 0; i < 3; i++ {
+			ws.Read(context.Background())
+		}
+
+		if count == 1 {
+			ws.Write(context.Background(), websocket.MessageText, []byte(":tmi.twitch.tv RECONNECT\r\n"))
+
+			// Read until the client closes the connection
+			for {
+				if _, _, err := ws.Read(context.Background()); err != nil {
+					return
+				}
+			}
+		}
+
+		<-time.After(500 * time.Millisecond)
+	})
+	defer server.Close()
+
+	accounts := &mockAccountProvider{
+		account: save.Account{ID: "123", LoginName: "testuser", DisplayName: "testuser", AccessToken: "token"},
+	}
+
+	var errorCount atomic.Int32
+	sendFn := func(msg tea.Msg) {
+		if evt, ok := msg.(IRCEvent); ok && evt.Error != nil {
+			errorCount.Add(1)
+		}
+	}
+
+	conn := newIRCConn("123", accounts, zerolog.Nop(), sendFn)
+	conn.WSURL = wsURL(server)
+
+	go conn.Run()
+	defer conn.Close()
+
+	// RECONNECT skips the backoff delay
+	require.Eventually(t, func() bool {
+		return connectCount.Load() >= 2 && conn.Status().Connected
+	}, 400*time.Millisecond, 10*time.Millisecond)
+
+	status := conn.Status()
+	require.Equal(t, 1, status.Reconnects)
+	require.NoError(t, status.LastError)
+	require.Zero(t, errorCount.Load(), "requested reconnects are not errors")
 }
 
 func TestIRCConn_ChannelRejoin(t *testing.T) {
@@ -300,7 +361,7 @@ func TestIRCConn_ChannelRejoin(t *testing.T) {
 	conn.JoinChannel("channel2")
 	defer conn.Close()
 
-	// Collect JOINs (wait for reconnect: 5s delay + connection time)
+	// Collect JOINs (wait for reconnect: backoff delay + connection time)
 	var joins []string
 	timeout := time.After(6 * time.Second)
 loop:
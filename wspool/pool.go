@@ -143,6 +143,19 @@ func (p *Pool) JoinChannel(accountID, channel string) error {
 	return conn.JoinChannel(channel)
 }
 
+// IRCStatus returns the health of every open IRC connection, keyed by account ID.
+func (p *Pool) IRCStatus() map[string]twitchirc.ConnStatus {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	status := make(map[string]twitchirc.ConnStatus, len(p.ircConns))
+	for id, conn := range p.ircConns {
+		status[id] = conn.Status()
+	}
+
+	return status
+}
+
 // SubscribeEventSub creates an EventSub subscription.
 // Creates a new connection for the account if one doesn't exist.
 func (p *Pool) SubscribeEventSub(accountID string, req twitchapi.CreateEventSubSubscriptionRequest, service EventSubService) error {
@@ -229,6 +229,47 @@ func TestPool_JoinChannel(t *testing.T) {
 	}
 }
 
+func TestPool_IRCStatus(t *testing.T) {
+	t.Parallel()
+
+	server := newTestIRCServer(t, func(ws *websocket.Conn) {
+		for {
+			_, _, err := ws.Read(context.Background())
+			if err != nil {
+				return
+			}
+		}
+	})
+	defer server.Close()
+
+	accounts := &mockAccountProvider{
+		account: save.Account{ID: "123", DisplayName: "test", AccessToken: "token"},
+	}
+
+	pool := NewPool(accounts, zerolog.Nop())
+	pool.SetSend(func(tea.Msg) {})
+	pool.ircWSURL = wsURL(server)
+	defer pool.Close()
+
+	require.Empty(t, pool.IRCStatus())
+
+	require.NoError(t, pool.ConnectIRC("123"))
+	require.NoError(t, pool.JoinChannel("123", "testchannel"))
+
+	require.Eventually(t, func() bool {
+		return pool.IRCStatus()["123"].Connected
+	}, time.Second, 10*time.Millisecond)
+
+	status := pool.IRCStatus()["123"]
+	require.Equal(t, 1, status.JoinedChannels)
+	require.False(t, status.ConnectedSince.IsZero())
+	require.Zero(t, status.Reconnects)
+	require.NoError(t, status.LastError)
+
+	pool.DisconnectIRC("123")
+	require.Empty(t, pool.IRCStatus())
+}
+
 func TestPool_Close(t *testing.T) {
 	t.Parallel()
 